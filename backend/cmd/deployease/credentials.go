@@ -0,0 +1,118 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/Jesuloba-world/deployease/backend/pkg/client"
+)
+
+// credentials are what login stores for later commands.
+type credentials struct {
+	Server string        `json:"server"`
+	Email  string        `json:"email"`
+	Tokens client.Tokens `json:"tokens"`
+}
+
+var errNotLoggedIn = errors.New("not logged in, run deployease login first")
+
+// credentialsPath is $HOME/.deployease/credentials.json, next to the
+// server's own config search path.
+func credentialsPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find home directory: %w", err)
+	}
+	return filepath.Join(home, ".deployease", "credentials.json"), nil
+}
+
+func loadCredentials() (credentials, error) {
+	path, err := credentialsPath()
+	if err != nil {
+		return credentials{}, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return credentials{}, errNotLoggedIn
+		}
+		return credentials{}, fmt.Errorf("failed to read credentials: %w", err)
+	}
+
+	var creds credentials
+	if err := json.Unmarshal(data, &creds); err != nil {
+		return credentials{}, fmt.Errorf("failed to read credentials from %s: %w", path, err)
+	}
+	if creds.Server == "" || creds.Tokens.RefreshToken == "" {
+		return credentials{}, errNotLoggedIn
+	}
+	return creds, nil
+}
+
+// saveCredentials writes the credentials readable by the user only,
+// replacing the previous ones in one step.
+func saveCredentials(creds credentials) error {
+	path, err := credentialsPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("failed to create credentials directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(creds, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode credentials: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".credentials-*")
+	if err != nil {
+		return fmt.Errorf("failed to save credentials: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(append(data, '\n'))
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save credentials: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to save credentials: %w", err)
+	}
+	return nil
+}
+
+func removeCredentials() error {
+	path, err := credentialsPath()
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to remove credentials: %w", err)
+	}
+	return nil
+}
+
+// newClient returns a client signed in with the stored credentials, which
+// are updated whenever it refreshes its tokens.
+func newClient() (*client.Client, error) {
+	creds, err := loadCredentials()
+	if err != nil {
+		return nil, err
+	}
+	return client.New(creds.Server,
+		client.WithTokens(creds.Tokens),
+		client.WithRefreshHook(func(tokens client.Tokens) {
+			creds.Tokens = tokens
+			if err := saveCredentials(creds); err != nil {
+				fmt.Fprintln(os.Stderr, "Warning:", err)
+			}
+		}),
+	)
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/Jesuloba-world/deployease/backend/internal/cli"
+	"github.com/Jesuloba-world/deployease/backend/pkg/client"
+)
+
+const deployUsage = "deploy [--project P] --commit SHA [--branch B] [-f]"
+
+// runDeploy queues a deployment of a commit and, with -f, follows its logs
+// until it finishes, failing if the deployment does.
+func runDeploy(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("deploy", flag.ContinueOnError)
+	project := flags.String("project", "", "ID or name of the project")
+	commit := flags.String("commit", "", "git commit to deploy")
+	branch := flags.String("branch", "", "branch the commit is from; other than the project's branch deploys a preview")
+	var follow bool
+	flags.BoolVar(&follow, "follow", false, "follow the deployment's logs until it finishes")
+	flags.BoolVar(&follow, "f", false, "shorthand for --follow")
+	if err := parseFlags(flags, deployUsage, args); err != nil {
+		return err
+	}
+	if flags.NArg() > 0 {
+		return cli.NewUsageError(deployUsage, "unexpected arguments %q", flags.Args())
+	}
+	if *commit == "" {
+		return cli.NewUsageError(deployUsage, "--commit is required")
+	}
+
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+	p, err := resolveProject(ctx, c, *project, deployUsage)
+	if err != nil {
+		return err
+	}
+
+	d, err := c.CreateDeployment(ctx, p.ID, client.CreateDeploymentInput{
+		CommitHash: *commit,
+		Branch:     *branch,
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Created deployment %s of %s to %s on branch %s\n", d.ID, d.CommitHash, p.Name, d.Branch)
+
+	if !follow {
+		return nil
+	}
+	return followDeployment(ctx, c, p.ID, d.ID)
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Jesuloba-world/deployease/backend/internal/cli"
+	"github.com/Jesuloba-world/deployease/backend/pkg/client"
+)
+
+const (
+	loginUsage = "login [--server URL] --email EMAIL"
+
+	defaultServer = "http://localhost:8080"
+)
+
+// runLogin signs in and stores the tokens for the other commands. The
+// password is read from the first line of stdin, so it can be piped in by
+// scripts and stays out of the shell history.
+func runLogin(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("login", flag.ContinueOnError)
+	server := flags.String("server", defaultLoginServer(), "URL of the DeployEase server")
+	email := flags.String("email", "", "email address of your account")
+	if err := parseFlags(flags, loginUsage, args); err != nil {
+		return err
+	}
+	if flags.NArg() > 0 {
+		return cli.NewUsageError(loginUsage, "unexpected arguments %q", flags.Args())
+	}
+	if *email == "" {
+		return cli.NewUsageError(loginUsage, "--email is required")
+	}
+
+	c, err := client.New(*server)
+	if err != nil {
+		return err
+	}
+
+	password, err := cli.ReadPassword(os.Stdin)
+	if err != nil {
+		return err
+	}
+
+	user, err := c.Login(ctx, *email, password)
+	if err != nil {
+		return err
+	}
+
+	err = saveCredentials(credentials{
+		Server: strings.TrimSuffix(*server, "/"),
+		Email:  user.Email,
+		Tokens: c.Tokens(),
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Logged in to %s as %s\n", *server, user.Username)
+	return nil
+}
+
+// defaultLoginServer is $DEPLOYEASE_SERVER, else the server last logged in
+// to.
+func defaultLoginServer() string {
+	if server := os.Getenv("DEPLOYEASE_SERVER"); server != "" {
+		return server
+	}
+	if creds, err := loadCredentials(); err == nil {
+		return creds.Server
+	}
+	return defaultServer
+}
+
+// runLogout revokes the stored tokens and removes them. The credentials are
+// removed even if the server cannot be reached.
+func runLogout(ctx context.Context, args []string) error {
+	if len(args) > 0 {
+		return cli.NewUsageError("logout", "unexpected arguments %q", args)
+	}
+
+	c, err := newClient()
+	if errors.Is(err, errNotLoggedIn) {
+		fmt.Println("Not logged in")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := c.Logout(ctx); err != nil {
+		fmt.Fprintln(os.Stderr, "Warning: failed to revoke tokens:", err)
+	}
+	if err := removeCredentials(); err != nil {
+		return err
+	}
+	fmt.Println("Logged out")
+	return nil
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/Jesuloba-world/deployease/backend/internal/cli"
+	"github.com/Jesuloba-world/deployease/backend/pkg/client"
+)
+
+const logsUsage = "logs [--project P] [-f] [DEPLOYMENT_ID]"
+
+// runLogs prints the logs of a deployment, by default the project's active
+// one or, if none is live, its latest.
+func runLogs(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("logs", flag.ContinueOnError)
+	project := flags.String("project", "", "ID or name of the project")
+	var follow bool
+	flags.BoolVar(&follow, "follow", false, "keep printing new output until the deployment finishes or its container stops")
+	flags.BoolVar(&follow, "f", false, "shorthand for --follow")
+	if err := parseFlags(flags, logsUsage, args); err != nil {
+		return err
+	}
+	if flags.NArg() > 1 {
+		return cli.NewUsageError(logsUsage, "unexpected arguments %q", flags.Args()[1:])
+	}
+
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+	p, err := resolveProject(ctx, c, *project, logsUsage)
+	if err != nil {
+		return err
+	}
+
+	deploymentID := flags.Arg(0)
+	if deploymentID == "" {
+		if deploymentID, err = defaultDeployment(ctx, c, p); err != nil {
+			return err
+		}
+	}
+
+	_, err = c.StreamLogs(ctx, p.ID, deploymentID, client.StreamLogsOptions{Follow: follow}, printLogLine)
+	return err
+}
+
+// followDeployment streams a deployment's logs until it finishes and
+// fails if it did not succeed.
+func followDeployment(ctx context.Context, c *client.Client, projectID, deploymentID string) error {
+	status, err := c.StreamLogs(ctx, projectID, deploymentID, client.StreamLogsOptions{Follow: true}, printLogLine)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Deployment %s finished: %s\n", deploymentID, status)
+	if status != client.StatusSuccess {
+		return errExit
+	}
+	return nil
+}
+
+func printLogLine(l client.LogLine) error {
+	_, err := fmt.Printf("%s [%s] %s\n", l.Timestamp.Local().Format(time.TimeOnly), l.Stream, l.Line)
+	return err
+}
+
+func defaultDeployment(ctx context.Context, c *client.Client, p client.Project) (string, error) {
+	if p.ActiveDeploymentID != "" {
+		return p.ActiveDeploymentID, nil
+	}
+	page, err := c.ListDeployments(ctx, p.ID, client.ListDeploymentsOptions{ListOptions: client.ListOptions{Limit: 1}})
+	if err != nil {
+		return "", err
+	}
+	if len(page.Deployments) == 0 {
+		return "", errors.New(p.Name + " has no deployments")
+	}
+	return page.Deployments[0].ID, nil
+}
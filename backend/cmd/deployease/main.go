@@ -0,0 +1,105 @@
+// Command deployease is the DeployEase command line client. It signs in to
+// a DeployEase server and deploys, rolls back and follows the logs of the
+// signed-in user's projects.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/Jesuloba-world/deployease/backend/internal/cli"
+)
+
+// command is a subcommand of the client.
+type command struct {
+	name    string
+	usage   string
+	summary string
+	run     func(ctx context.Context, args []string) error
+}
+
+var commands = []command{
+	{name: "login", usage: loginUsage, summary: "Sign in to a DeployEase server", run: runLogin},
+	{name: "logout", usage: "logout", summary: "Sign out and forget the stored credentials", run: runLogout},
+	{name: "projects", usage: projectsUsage, summary: "List your projects", run: runProjects},
+	{name: "deploy", usage: deployUsage, summary: "Deploy a commit of a project", run: runDeploy},
+	{name: "logs", usage: logsUsage, summary: "Print or follow the logs of a deployment", run: runLogs},
+	{name: "rollback", usage: rollbackUsage, summary: "Roll back to an earlier successful deployment", run: runRollback},
+}
+
+// errExit exits with status 1 without printing anything further, for
+// commands that have already reported what went wrong.
+var errExit = errors.New("exit")
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	err := run(ctx, os.Args[1:])
+	interrupted := ctx.Err() != nil
+	stop()
+	if err == nil || errors.Is(err, flag.ErrHelp) {
+		return
+	}
+
+	switch {
+	case cli.IsUsageError(err):
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	case errors.Is(err, errExit):
+	case interrupted:
+		// Interrupted; nothing worth reporting.
+	default:
+		fmt.Fprintln(os.Stderr, "Error:", err)
+	}
+	os.Exit(1)
+}
+
+func run(ctx context.Context, args []string) error {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		printUsage()
+		if len(args) == 0 {
+			return cli.NewUsageError("<command> [arguments]", "no command given")
+		}
+		return flag.ErrHelp
+	}
+
+	for _, cmd := range commands {
+		if cmd.name == args[0] {
+			return cmd.run(ctx, args[1:])
+		}
+	}
+
+	printUsage()
+	return cli.NewUsageError("<command> [arguments]", "unknown command %q", args[0])
+}
+
+func printUsage() {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Usage: %s <command> [arguments]\n\nCommands:\n", cli.Name)
+	for _, cmd := range commands {
+		fmt.Fprintf(&b, "  %-52s %s\n", cmd.usage, cmd.summary)
+	}
+	b.WriteString("\nThe project defaults to $DEPLOYEASE_PROJECT and may be given by ID or name.\n")
+	fmt.Fprint(os.Stderr, b.String())
+}
+
+// parseFlags parses a command's flags, reporting mistakes as usage errors.
+func parseFlags(flags *flag.FlagSet, usage string, args []string) error {
+	flags.SetOutput(io.Discard)
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			fmt.Fprintf(os.Stderr, "usage: %s %s\n", cli.Name, usage)
+			flags.SetOutput(os.Stderr)
+			flags.PrintDefaults()
+			return err
+		}
+		return cli.NewUsageError(usage, "%v", err)
+	}
+	return nil
+}
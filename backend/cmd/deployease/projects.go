@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Jesuloba-world/deployease/backend/internal/cli"
+	"github.com/Jesuloba-world/deployease/backend/pkg/client"
+)
+
+const projectsUsage = "projects ls"
+
+func runProjects(ctx context.Context, args []string) error {
+	if len(args) != 1 || (args[0] != "ls" && args[0] != "list") {
+		return cli.NewUsageError(projectsUsage, "expected ls")
+	}
+
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+	projects, err := c.AllProjects(ctx)
+	if err != nil {
+		return err
+	}
+	if len(projects) == 0 {
+		fmt.Println("No projects")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "NAME\tID\tBRANCH\tACTIVE DEPLOYMENT\tUPDATED")
+	for _, p := range projects {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", p.Name, p.ID, p.Branch, orDash(p.ActiveDeploymentID), p.UpdatedAt.Local().Format(time.DateTime))
+	}
+	return w.Flush()
+}
+
+// resolveProject finds one of the user's projects by ID or, failing that,
+// by name. An empty reference falls back to $DEPLOYEASE_PROJECT.
+func resolveProject(ctx context.Context, c *client.Client, ref, usage string) (client.Project, error) {
+	if ref == "" {
+		ref = os.Getenv("DEPLOYEASE_PROJECT")
+	}
+	if ref == "" {
+		return client.Project{}, cli.NewUsageError(usage, "--project is required")
+	}
+
+	p, err := c.GetProject(ctx, ref)
+	if err == nil {
+		return p, nil
+	}
+	if !client.IsNotFound(err) {
+		return client.Project{}, err
+	}
+
+	projects, err := c.AllProjects(ctx)
+	if err != nil {
+		return client.Project{}, err
+	}
+	var matches []client.Project
+	for _, p := range projects {
+		if p.Name == ref {
+			matches = append(matches, p)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return client.Project{}, fmt.Errorf("no project has ID or name %q", ref)
+	case 1:
+		return matches[0], nil
+	default:
+		ids := make([]string, len(matches))
+		for i, p := range matches {
+			ids[i] = p.ID
+		}
+		return client.Project{}, errors.New("several projects are named " + ref + ", use one of their IDs: " + strings.Join(ids, ", "))
+	}
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/Jesuloba-world/deployease/backend/internal/cli"
+	"github.com/Jesuloba-world/deployease/backend/pkg/client"
+)
+
+const rollbackUsage = "rollback [--project P] [-f] [DEPLOYMENT_ID]"
+
+// runRollback re-runs the image of an earlier successful deployment, by
+// default the newest one that is not live.
+func runRollback(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("rollback", flag.ContinueOnError)
+	project := flags.String("project", "", "ID or name of the project")
+	var follow bool
+	flags.BoolVar(&follow, "follow", false, "follow the rollback's logs until it finishes")
+	flags.BoolVar(&follow, "f", false, "shorthand for --follow")
+	if err := parseFlags(flags, rollbackUsage, args); err != nil {
+		return err
+	}
+	if flags.NArg() > 1 {
+		return cli.NewUsageError(rollbackUsage, "unexpected arguments %q", flags.Args()[1:])
+	}
+
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+	p, err := resolveProject(ctx, c, *project, rollbackUsage)
+	if err != nil {
+		return err
+	}
+
+	targetID := flags.Arg(0)
+	if targetID == "" {
+		if targetID, err = rollbackTarget(ctx, c, p); err != nil {
+			return err
+		}
+	}
+
+	d, err := c.Rollback(ctx, p.ID, targetID)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Created deployment %s rolling %s back to %s\n", d.ID, p.Name, targetID)
+
+	if !follow {
+		return nil
+	}
+	return followDeployment(ctx, c, p.ID, d.ID)
+}
+
+// rollbackTarget returns the release before the one that is live: the
+// newest deployment of the project's branch that went live before it, with
+// a different image. A live rollback counts as the release it re-runs, so
+// rolling back twice goes back two releases.
+func rollbackTarget(ctx context.Context, c *client.Client, p client.Project) (string, error) {
+	var live client.Deployment
+	if p.ActiveDeploymentID != "" {
+		var err error
+		if live, err = c.GetDeployment(ctx, p.ID, p.ActiveDeploymentID); err != nil {
+			return "", err
+		}
+		if live.RollbackTargetID != "" {
+			image := live.ImageRef
+			if live, err = c.GetDeployment(ctx, p.ID, live.RollbackTargetID); err != nil {
+				return "", err
+			}
+			live.ImageRef = image
+		}
+	}
+
+	opts := client.ListDeploymentsOptions{
+		ListOptions: client.ListOptions{Limit: 100},
+		Branch:      p.Branch,
+		Statuses:    []string{client.StatusSuccess, client.StatusRolledBack},
+	}
+	for {
+		page, err := c.ListDeployments(ctx, p.ID, opts)
+		if err != nil {
+			return "", err
+		}
+		for _, d := range page.Deployments {
+			if d.DeployedAt == nil || d.ImageRef == "" || d.ImageRef == live.ImageRef {
+				continue
+			}
+			if live.ID == "" || d.CreatedAt.Before(live.CreatedAt) {
+				return d.ID, nil
+			}
+		}
+		if page.NextCursor == "" {
+			return "", errors.New(p.Name + " has no earlier release to roll back to")
+		}
+		opts.Cursor = page.NextCursor
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/Jesuloba-world/deployease/backend/internal/cli"
+	"github.com/Jesuloba-world/deployease/backend/internal/config"
+	"github.com/Jesuloba-world/deployease/backend/internal/secrets"
+)
+
+const configUsage = "config print|validate"
+
+// runConfig prints the effective configuration with its secrets redacted,
+// or checks that it is valid. Configuration failing validation never gets
+// this far; validate also checks the master key can be used.
+func runConfig(cfg *config.Config, args []string) error {
+	if len(args) != 1 {
+		return cli.NewUsageError(configUsage, "expected print or validate")
+	}
+
+	switch args[0] {
+	case "print":
+		out, err := yaml.Marshal(cfg.Redacted().Settings())
+		if err != nil {
+			return fmt.Errorf("failed to encode configuration: %w", err)
+		}
+		_, err = os.Stdout.Write(out)
+		return err
+	case "validate":
+		if _, err := secrets.NewCipher(cfg.Secrets); err != nil {
+			return fmt.Errorf("invalid secrets master key: %w", err)
+		}
+		fmt.Println("Configuration is valid")
+		return nil
+	default:
+		return cli.NewUsageError(configUsage, "unknown command %q", args[0])
+	}
+}
@@ -1,30 +1,92 @@
 package main
 
 import (
+	"errors"
+	"flag"
+	"fmt"
 	"log"
+	"os"
+	"strings"
 
 	"github.com/joho/godotenv"
 
-	"github.com/Jesuloba-world/deployease/backend/internal/app"
+	"github.com/Jesuloba-world/deployease/backend/internal/cli"
 	"github.com/Jesuloba-world/deployease/backend/internal/config"
 )
 
+// command is a subcommand of the server binary. Every command runs with the
+// configuration loaded from config files and DEPLOYEASE_ variables.
+type command struct {
+	name    string
+	usage   string
+	summary string
+	run     func(cfg *config.Config, args []string) error
+}
+
+var commands = []command{
+	{name: "serve", usage: "serve", summary: "Serve the API and edge proxy and process jobs (default)", run: runServe},
+	{name: "worker", usage: "worker", summary: "Process background jobs only", run: runWorker},
+	{name: "migrate", usage: migrateUsage, summary: "Apply, roll back or list database migrations", run: runMigrate},
+	{name: "config", usage: configUsage, summary: "Print the effective configuration or check that it is valid", run: runConfig},
+	{name: "user", usage: userUsage, summary: "Create a user, reading the password from stdin", run: runUser},
+}
+
 func main() {
 	// Load environment variables from .env file
 	if err := godotenv.Load(); err != nil {
 		log.Printf("Warning: .env file not found or could not be loaded: %v", err)
 	}
 
-	// load configuration
-	cfg, err := config.Load()
-	if err != nil {
-		log.Fatalf("failed to load configuration: %v", err)
+	if err := run(os.Args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return
+		}
+		if cli.IsUsageError(err) {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		log.Fatal(err)
 	}
+}
 
-	// load application
-	application := app.NewApp(cfg)
+// run dispatches to the named command. Without one the server is run, as
+// it was before the binary had commands.
+func run(args []string) error {
+	name := "serve"
+	if len(args) > 0 {
+		name, args = args[0], args[1:]
+	}
+	if name == "help" || name == "-h" || name == "--help" {
+		printUsage()
+		return flag.ErrHelp
+	}
+
+	for _, cmd := range commands {
+		if cmd.name != name {
+			continue
+		}
+		cfg, err := config.Load()
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+		if err := cmd.run(cfg, args); err != nil {
+			if cli.IsUsageError(err) {
+				return err
+			}
+			return fmt.Errorf("%s: %w", cmd.name, err)
+		}
+		return nil
+	}
+
+	printUsage()
+	return cli.NewUsageError("<command> [arguments]", "unknown command %q", name)
+}
 
-	if err := application.Run(); err != nil {
-		log.Fatalf("Application failed to start: %v", err)
+func printUsage() {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Usage: %s <command> [arguments]\n\nCommands:\n", cli.Name)
+	for _, cmd := range commands {
+		fmt.Fprintf(&b, "  %-52s %s\n", cmd.usage, cmd.summary)
 	}
+	fmt.Fprint(os.Stderr, b.String())
 }
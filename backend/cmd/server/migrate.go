@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pressly/goose/v3"
+
+	"github.com/Jesuloba-world/deployease/backend/internal/cli"
+	"github.com/Jesuloba-world/deployease/backend/internal/config"
+	database "github.com/Jesuloba-world/deployease/backend/internal/infrastructure/database/postgres"
+	"github.com/Jesuloba-world/deployease/backend/internal/migrate"
+)
+
+const migrateUsage = "migrate [up|down|status|redo]"
+
+// runMigrate applies, rolls back or lists the migrations. Without a
+// subcommand it applies every pending migration.
+func runMigrate(cfg *config.Config, args []string) error {
+	command := "up"
+	if len(args) > 0 {
+		command = args[0]
+	}
+	if len(args) > 1 {
+		return cli.NewUsageError(migrateUsage, "unexpected arguments %q", args[1:])
+	}
+
+	switch command {
+	case "up", "down", "redo", "status":
+	default:
+		return cli.NewUsageError(migrateUsage, "unknown command %q", command)
+	}
+
+	db, err := database.NewManager(&cfg.Database)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer db.Close()
+
+	provider, err := migrate.New(db.DBPool(), cfg.Database)
+	if err != nil {
+		return err
+	}
+	defer provider.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	switch command {
+	case "up":
+		results, err := provider.Up(ctx)
+		if err != nil {
+			return err
+		}
+		if len(results) == 0 {
+			fmt.Println("No pending migrations")
+		}
+		printResults(results...)
+	case "down":
+		result, err := provider.Down(ctx)
+		if err != nil {
+			return err
+		}
+		printResults(result)
+	case "redo":
+		down, err := provider.Down(ctx)
+		if err != nil {
+			return err
+		}
+		printResults(down)
+		up, err := provider.UpByOne(ctx)
+		if err != nil {
+			return err
+		}
+		printResults(up)
+	case "status":
+		statuses, err := provider.Status(ctx)
+		if err != nil {
+			return err
+		}
+		printStatus(statuses)
+	}
+	return nil
+}
+
+func printResults(results ...*goose.MigrationResult) {
+	for _, r := range results {
+		fmt.Println(r)
+	}
+}
+
+func printStatus(statuses []*goose.MigrationStatus) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "APPLIED AT\tMIGRATION")
+	for _, s := range statuses {
+		appliedAt := "Pending"
+		if s.State == goose.StateApplied {
+			appliedAt = s.AppliedAt.Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%s\t%s\n", appliedAt, s.Source.Path)
+	}
+	w.Flush()
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/Jesuloba-world/deployease/backend/internal/app"
+	"github.com/Jesuloba-world/deployease/backend/internal/cli"
+	"github.com/Jesuloba-world/deployease/backend/internal/config"
+)
+
+func runServe(cfg *config.Config, args []string) error {
+	if len(args) > 0 {
+		return cli.NewUsageError("serve", "unexpected arguments %q", args)
+	}
+
+	application, err := app.NewApp(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to initialize application: %w", err)
+	}
+	return application.Run()
+}
+
+func runWorker(cfg *config.Config, args []string) error {
+	if len(args) > 0 {
+		return cli.NewUsageError("worker", "unexpected arguments %q", args)
+	}
+
+	application, err := app.NewApp(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to initialize application: %w", err)
+	}
+	return application.RunWorker()
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"net/mail"
+	"os"
+	"regexp"
+
+	"github.com/Jesuloba-world/deployease/backend/internal/auth"
+	"github.com/Jesuloba-world/deployease/backend/internal/cli"
+	"github.com/Jesuloba-world/deployease/backend/internal/config"
+	database "github.com/Jesuloba-world/deployease/backend/internal/infrastructure/database/postgres"
+	"github.com/Jesuloba-world/deployease/backend/internal/infrastructure/database/postgres/sqlc"
+)
+
+const userUsage = "user create --username NAME --email EMAIL [--admin]"
+
+// The same rules the registration endpoint enforces.
+var usernamePattern = regexp.MustCompile(`^[a-zA-Z0-9_-]{3,50}$`)
+
+const (
+	minPasswordLength = 8
+	maxPasswordLength = 128
+)
+
+// runUser creates an account, typically the first administrator of a new
+// installation. The password is read from the first line of stdin so it
+// stays out of the shell history and process list.
+func runUser(cfg *config.Config, args []string) error {
+	if len(args) == 0 || args[0] != "create" {
+		return cli.NewUsageError(userUsage, "expected create")
+	}
+
+	flags := flag.NewFlagSet("user create", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	username := flags.String("username", "", "username of the new user")
+	email := flags.String("email", "", "email address of the new user")
+	admin := flags.Bool("admin", false, "make the user an administrator")
+	if err := flags.Parse(args[1:]); err != nil {
+		return cli.NewUsageError(userUsage, "%v", err)
+	}
+	if flags.NArg() > 0 {
+		return cli.NewUsageError(userUsage, "unexpected arguments %q", flags.Args())
+	}
+	if !usernamePattern.MatchString(*username) {
+		return cli.NewUsageError(userUsage, "username must be 3 to 50 letters, digits, hyphens or underscores")
+	}
+	if _, err := mail.ParseAddress(*email); err != nil || len(*email) > 255 {
+		return cli.NewUsageError(userUsage, "invalid email address %q", *email)
+	}
+
+	password, err := cli.ReadPassword(os.Stdin)
+	if err != nil {
+		return err
+	}
+	if len(password) < minPasswordLength || len(password) > maxPasswordLength {
+		return fmt.Errorf("password must be %d to %d characters", minPasswordLength, maxPasswordLength)
+	}
+
+	db, err := database.NewManager(&cfg.Database)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer db.Close()
+
+	authService, err := auth.NewService(sqlc.New(db.DBPool()))
+	if err != nil {
+		return fmt.Errorf("failed to create auth service: %w", err)
+	}
+
+	user, err := authService.Register(context.Background(), auth.RegisterParams{
+		Username: *username,
+		Email:    *email,
+		Password: password,
+		Admin:    *admin,
+	})
+	if err != nil {
+		return err
+	}
+
+	role := "user"
+	if user.IsAdmin {
+		role = "administrator"
+	}
+	fmt.Printf("Created %s %s (%s) with ID %s\n", role, user.Username, user.Email, user.ID)
+	return nil
+}
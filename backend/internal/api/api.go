@@ -1,31 +1,85 @@
 package api
 
 import (
+	"fmt"
+	"net/http"
+
 	"github.com/danielgtaylor/huma/v2"
 	"github.com/danielgtaylor/huma/v2/adapters/humabunrouter"
 	"github.com/uptrace/bunrouter"
 
 	"github.com/Jesuloba-world/deployease/backend/internal/api/handler"
 	"github.com/Jesuloba-world/deployease/backend/internal/api/routes"
+	"github.com/Jesuloba-world/deployease/backend/internal/auth"
+	"github.com/Jesuloba-world/deployease/backend/internal/certificate"
 	"github.com/Jesuloba-world/deployease/backend/internal/config"
+	"github.com/Jesuloba-world/deployease/backend/internal/container"
+	"github.com/Jesuloba-world/deployease/backend/internal/deployment"
+	"github.com/Jesuloba-world/deployease/backend/internal/domain"
+	"github.com/Jesuloba-world/deployease/backend/internal/envvar"
+	"github.com/Jesuloba-world/deployease/backend/internal/events"
+	database "github.com/Jesuloba-world/deployease/backend/internal/infrastructure/database/postgres"
+	"github.com/Jesuloba-world/deployease/backend/internal/infrastructure/database/postgres/sqlc"
+	"github.com/Jesuloba-world/deployease/backend/internal/infrastructure/session"
+	"github.com/Jesuloba-world/deployease/backend/internal/jobs"
+	"github.com/Jesuloba-world/deployease/backend/internal/manageddb"
+	"github.com/Jesuloba-world/deployease/backend/internal/objectstore"
+	"github.com/Jesuloba-world/deployease/backend/internal/project"
+	"github.com/Jesuloba-world/deployease/backend/internal/proxy"
+	"github.com/Jesuloba-world/deployease/backend/internal/secrets"
+	"github.com/Jesuloba-world/deployease/backend/internal/webhook"
 )
 
+// EventsPath serves the deployment event WebSocket.
+const EventsPath = "/ws"
+
+// Dependencies are the long-lived services the API handlers are built from.
+type Dependencies struct {
+	DB           *database.Manager
+	TokenService *auth.TokenService
+	SessionStore *session.Store
+	JobQueue     *jobs.Queue
+	Events       *events.Broker
+	Runtime      container.Runtime
+	Cipher       *secrets.Cipher
+	Resolver     domain.Resolver
+	Routes       proxy.Invalidator
+	ObjectStore  objectstore.Store
+}
+
 type API struct {
 	config  *config.Config
 	router  *bunrouter.Router
 	humaAPI huma.API
+	deps    Dependencies
 }
 
-func NewAPI(cfg config.Config, router *bunrouter.Router) *API {
+func NewAPI(cfg config.Config, router *bunrouter.Router, deps Dependencies) *API {
 	config := huma.DefaultConfig("DeployEase API", "1.0.0")
 	config.Info.Description = "DeployEase REST API - A modern deployment automation platform that streamlines application deployment workflows."
+	config.Components.SecuritySchemes = map[string]*huma.SecurityScheme{
+		routes.BearerAuthScheme: {
+			Type:         "http",
+			Scheme:       "bearer",
+			BearerFormat: "JWT",
+			Description:  "Access token returned by /auth/login or /auth/refresh",
+		},
+		routes.SessionCookieScheme: {
+			Type:        "apiKey",
+			In:          "cookie",
+			Name:        cfg.Session.CookieName,
+			Description: "Session cookie set by /auth/login",
+		},
+	}
 
 	api := humabunrouter.New(router, config)
+	api.UseMiddleware(requireAuth(api))
 
 	apiInstance := &API{
 		config:  &cfg,
 		router:  router,
 		humaAPI: api,
+		deps:    deps,
 	}
 
 	return apiInstance
@@ -35,7 +89,79 @@ func (a *API) GetHumaAPI() huma.API {
 	return a.humaAPI
 }
 
-func (a *API) InitializeAndRegisterRoutes() {
+func (a *API) InitializeAndRegisterRoutes() error {
+	queries := sqlc.New(a.deps.DB.DBPool())
+
 	healthHandler := handler.NewHealthHandler("1.0.0")
 	routes.RegisterHealthRoutes(a.humaAPI, healthHandler)
+
+	authService, err := auth.NewService(queries)
+	if err != nil {
+		return fmt.Errorf("failed to create auth service: %w", err)
+	}
+	authHandler := handler.NewAuthHandler(authService, a.deps.TokenService, a.deps.SessionStore, a.config.Session)
+	routes.RegisterAuthRoutes(a.humaAPI, authHandler)
+
+	projectService := project.NewService(queries, a.deps.Cipher)
+	projectHandler := handler.NewProjectHandler(projectService)
+	routes.RegisterProjectRoutes(a.humaAPI, projectHandler)
+
+	deploymentService := deployment.NewService(a.deps.DB.DBPool(), queries, a.deps.JobQueue, a.deps.Events, a.deps.Routes)
+	logTailer := deployment.NewLogTailer(queries, a.deps.Runtime)
+	deploymentHandler := handler.NewDeploymentHandler(projectService, deploymentService, logTailer)
+	routes.RegisterDeploymentRoutes(a.humaAPI, deploymentHandler)
+
+	envService := envvar.NewService(queries, a.deps.Cipher)
+	envHandler := handler.NewEnvHandler(projectService, envService, deploymentService)
+	routes.RegisterEnvRoutes(a.humaAPI, envHandler)
+
+	previewHandler := handler.NewPreviewHandler(projectService, deploymentService, a.config.Previews.Domain)
+	routes.RegisterPreviewRoutes(a.humaAPI, previewHandler)
+
+	databaseService := manageddb.NewService(a.deps.DB.DBPool(), queries, a.deps.JobQueue, a.deps.Cipher, a.deps.Runtime, a.config.Runtime, a.config.ManagedDatabases,
+		a.deps.ObjectStore, manageddb.NewRuntimeDumper(a.deps.Runtime), a.config.Backups)
+	databaseHandler := handler.NewDatabaseHandler(projectService, databaseService)
+	routes.RegisterDatabaseRoutes(a.humaAPI, databaseHandler)
+
+	domainService := domain.NewService(queries, a.deps.Resolver, a.config.Domains, a.deps.Routes)
+	certificateService := certificate.NewService(queries, a.deps.Cipher, a.config.ACME)
+	domainHandler := handler.NewDomainHandler(projectService, domainService, certificateService)
+	routes.RegisterDomainRoutes(a.humaAPI, domainHandler)
+
+	deliveries, err := webhook.NewDeliveries(a.config.Webhooks.DeliveryTTL)
+	if err != nil {
+		return fmt.Errorf("failed to create webhook delivery store: %w", err)
+	}
+	webhookService := webhook.NewService(queries, projectService, deploymentService, deliveries)
+	webhookHandler := handler.NewWebhookHandler(webhookService)
+	routes.RegisterWebhookRoutes(a.humaAPI, webhookHandler)
+
+	// WebSockets bypass Huma, which only speaks request/response.
+	eventsHandler := handler.NewEventsHandler(projectService, a.deps.Events, a.config.Events)
+	a.router.GET(EventsPath, bunrouter.HTTPHandler(eventsHandler))
+
+	// Certificate authorities fetch HTTP-01 challenge responses as plain
+	// text from a fixed path.
+	challengeHandler := certificate.NewChallengeHandler(queries)
+	a.router.GET(certificate.ChallengePath+":token", bunrouter.HTTPHandler(challengeHandler))
+
+	return nil
+}
+
+// requireAuth rejects requests to operations that declare a security
+// requirement when the auth middleware did not resolve a principal.
+func requireAuth(humaAPI huma.API) func(ctx huma.Context, next func(huma.Context)) {
+	return func(ctx huma.Context, next func(huma.Context)) {
+		if len(ctx.Operation().Security) == 0 {
+			next(ctx)
+			return
+		}
+
+		if _, ok := auth.PrincipalFromContext(ctx.Context()); !ok {
+			huma.WriteErr(humaAPI, ctx, http.StatusUnauthorized, "authentication required")
+			return
+		}
+
+		next(ctx)
+	}
 }
@@ -0,0 +1,78 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/uptrace/bunrouter"
+
+	"github.com/Jesuloba-world/deployease/backend/internal/api/routes"
+	"github.com/Jesuloba-world/deployease/backend/internal/auth"
+	"github.com/Jesuloba-world/deployease/backend/internal/config"
+)
+
+type pingOutput struct {
+	Body struct {
+		UserID string `json:"user_id"`
+	}
+}
+
+func TestRequireAuth(t *testing.T) {
+	router := bunrouter.New(bunrouter.Use(func(next bunrouter.HandlerFunc) bunrouter.HandlerFunc {
+		return func(w http.ResponseWriter, req bunrouter.Request) error {
+			if req.Header.Get("X-Test-User") != "" {
+				ctx := auth.WithPrincipal(req.Context(), &auth.Principal{UserID: req.Header.Get("X-Test-User")})
+				req = req.WithContext(ctx)
+			}
+			return next(w, req)
+		}
+	}))
+
+	cfg := config.Config{Session: config.SessionConfig{CookieName: "deployease_session"}}
+	apiInstance := NewAPI(cfg, router, Dependencies{})
+
+	huma.Register(apiInstance.GetHumaAPI(), huma.Operation{
+		OperationID: "protected",
+		Method:      http.MethodGet,
+		Path:        "/protected",
+		Security:    []map[string][]string{{routes.BearerAuthScheme: {}}},
+	}, func(ctx context.Context, input *struct{}) (*pingOutput, error) {
+		principal, _ := auth.PrincipalFromContext(ctx)
+		out := &pingOutput{}
+		out.Body.UserID = principal.UserID
+		return out, nil
+	})
+
+	huma.Register(apiInstance.GetHumaAPI(), huma.Operation{
+		OperationID: "public",
+		Method:      http.MethodGet,
+		Path:        "/public",
+	}, func(ctx context.Context, input *struct{}) (*pingOutput, error) {
+		return &pingOutput{}, nil
+	})
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/protected", nil))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	req := httptest.NewRequest(http.MethodGet, "/protected", nil)
+	req.Header.Set("X-Test-User", "user-1")
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "user-1")
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/public", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	openapi := apiInstance.GetHumaAPI().OpenAPI()
+	assert.Contains(t, openapi.Components.SecuritySchemes, routes.BearerAuthScheme)
+	assert.Contains(t, openapi.Components.SecuritySchemes, routes.SessionCookieScheme)
+	assert.NotEmpty(t, openapi.Paths["/protected"].Get.Security)
+	assert.Empty(t, openapi.Paths["/public"].Get.Security)
+}
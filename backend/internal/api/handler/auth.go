@@ -0,0 +1,241 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	gonanoid "github.com/matoous/go-nanoid/v2"
+
+	"github.com/Jesuloba-world/deployease/backend/internal/auth"
+	"github.com/Jesuloba-world/deployease/backend/internal/config"
+	"github.com/Jesuloba-world/deployease/backend/internal/infrastructure/database/postgres/sqlc"
+	"github.com/Jesuloba-world/deployease/backend/internal/infrastructure/session"
+)
+
+type AuthHandler struct {
+	authService   *auth.Service
+	tokenService  *auth.TokenService
+	sessionStore  *session.Store
+	sessionConfig config.SessionConfig
+}
+
+func NewAuthHandler(authService *auth.Service, tokenService *auth.TokenService, sessionStore *session.Store, sessionConfig config.SessionConfig) *AuthHandler {
+	return &AuthHandler{
+		authService:   authService,
+		tokenService:  tokenService,
+		sessionStore:  sessionStore,
+		sessionConfig: sessionConfig,
+	}
+}
+
+type UserResponseBody struct {
+	ID        string    `json:"id" doc:"Unique identifier of the user" example:"V1StGXR8_Z5jdHi6B-myT"`
+	Username  string    `json:"username" doc:"Username of the user" example:"johndoe"`
+	Email     string    `json:"email" doc:"Email address of the user" format:"email" example:"user@example.com"`
+	IsAdmin   bool      `json:"is_admin" doc:"Whether the user is an administrator"`
+	CreatedAt time.Time `json:"created_at" doc:"Timestamp when the user was created" format:"date-time"`
+}
+
+func newUserResponseBody(user sqlc.User) UserResponseBody {
+	return UserResponseBody{
+		ID:        user.ID,
+		Username:  user.Username,
+		Email:     user.Email,
+		IsAdmin:   user.IsAdmin,
+		CreatedAt: user.CreatedAt.Time,
+	}
+}
+
+type RegisterInput struct {
+	Body struct {
+		Username string `json:"username" doc:"Unique username" minLength:"3" maxLength:"50" pattern:"^[a-zA-Z0-9_-]+$" example:"johndoe"`
+		Email    string `json:"email" doc:"Unique email address" format:"email" maxLength:"255" example:"user@example.com"`
+		Password string `json:"password" doc:"Account password" minLength:"8" maxLength:"128"`
+	}
+}
+
+type RegisterResponse struct {
+	Body UserResponseBody `json:"body,inline"`
+}
+
+func (h *AuthHandler) Register(ctx context.Context, input *RegisterInput) (*RegisterResponse, error) {
+	user, err := h.authService.Register(ctx, auth.RegisterParams{
+		Username: input.Body.Username,
+		Email:    input.Body.Email,
+		Password: input.Body.Password,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, auth.ErrUsernameTaken), errors.Is(err, auth.ErrEmailTaken):
+			return nil, huma.Error409Conflict(err.Error())
+		default:
+			log.Printf("Failed to register user: %v", err)
+			return nil, huma.Error500InternalServerError("failed to register user")
+		}
+	}
+
+	return &RegisterResponse{
+		Body: newUserResponseBody(user),
+	}, nil
+}
+
+type LoginInput struct {
+	Body struct {
+		Email    string `json:"email" doc:"Email address of the account" format:"email" example:"user@example.com"`
+		Password string `json:"password" doc:"Account password" minLength:"1"`
+	}
+}
+
+type LoginResponseBody struct {
+	AccessToken  string           `json:"access_token" doc:"Signed JWT access token"`
+	RefreshToken string           `json:"refresh_token" doc:"Opaque refresh token used to obtain new access tokens"`
+	TokenType    string           `json:"token_type" doc:"Type of the access token" example:"Bearer"`
+	ExpiresIn    int64            `json:"expires_in" doc:"Lifetime of the access token in seconds" example:"900"`
+	User         UserResponseBody `json:"user" doc:"The authenticated user"`
+}
+
+type LoginResponse struct {
+	SetCookie http.Cookie       `header:"Set-Cookie" doc:"Session cookie for browser clients"`
+	Body      LoginResponseBody `json:"body,inline"`
+}
+
+func (h *AuthHandler) Login(ctx context.Context, input *LoginInput) (*LoginResponse, error) {
+	user, err := h.authService.Authenticate(ctx, input.Body.Email, input.Body.Password)
+	if err != nil {
+		if errors.Is(err, auth.ErrInvalidCredentials) {
+			return nil, huma.Error401Unauthorized(err.Error())
+		}
+		log.Printf("Failed to authenticate user: %v", err)
+		return nil, huma.Error500InternalServerError("failed to authenticate user")
+	}
+
+	tokens, err := h.tokenService.IssueTokens(ctx, user.ID)
+	if err != nil {
+		log.Printf("Failed to issue tokens: %v", err)
+		return nil, huma.Error500InternalServerError("failed to issue tokens")
+	}
+
+	sess := &session.Session{
+		ID:        gonanoid.Must(32),
+		UserID:    user.ID,
+		Data:      map[string]interface{}{},
+		ExpiresAt: time.Now().Add(h.sessionConfig.Expiration),
+	}
+	if err := h.sessionStore.Set(ctx, sess); err != nil {
+		log.Printf("Failed to create session: %v", err)
+		return nil, huma.Error500InternalServerError("failed to create session")
+	}
+
+	return &LoginResponse{
+		SetCookie: h.sessionCookie(sess.ID, sess.ExpiresAt),
+		Body: LoginResponseBody{
+			AccessToken:  tokens.AccessToken,
+			RefreshToken: tokens.RefreshToken,
+			TokenType:    "Bearer",
+			ExpiresIn:    int64(tokens.ExpiresIn.Seconds()),
+			User:         newUserResponseBody(user),
+		},
+	}, nil
+}
+
+type RefreshInput struct {
+	Body struct {
+		RefreshToken string `json:"refresh_token" doc:"Refresh token returned by login or a previous refresh" minLength:"1"`
+	}
+}
+
+type RefreshResponseBody struct {
+	AccessToken  string `json:"access_token" doc:"Signed JWT access token"`
+	RefreshToken string `json:"refresh_token" doc:"Replacement refresh token; the presented one can no longer be used"`
+	TokenType    string `json:"token_type" doc:"Type of the access token" example:"Bearer"`
+	ExpiresIn    int64  `json:"expires_in" doc:"Lifetime of the access token in seconds" example:"900"`
+}
+
+type RefreshResponse struct {
+	Body RefreshResponseBody `json:"body,inline"`
+}
+
+func (h *AuthHandler) Refresh(ctx context.Context, input *RefreshInput) (*RefreshResponse, error) {
+	tokens, err := h.tokenService.Refresh(ctx, input.Body.RefreshToken)
+	if err != nil {
+		switch {
+		case errors.Is(err, auth.ErrInvalidRefreshToken), errors.Is(err, auth.ErrRefreshTokenReused):
+			return nil, huma.Error401Unauthorized(err.Error())
+		default:
+			log.Printf("Failed to refresh tokens: %v", err)
+			return nil, huma.Error500InternalServerError("failed to refresh tokens")
+		}
+	}
+
+	return &RefreshResponse{
+		Body: RefreshResponseBody{
+			AccessToken:  tokens.AccessToken,
+			RefreshToken: tokens.RefreshToken,
+			TokenType:    "Bearer",
+			ExpiresIn:    int64(tokens.ExpiresIn.Seconds()),
+		},
+	}, nil
+}
+
+type LogoutInput struct{}
+
+type MessageResponseBody struct {
+	Message string `json:"message" doc:"Human readable result message" example:"Successfully logged out"`
+}
+
+type LogoutResponse struct {
+	SetCookie http.Cookie         `header:"Set-Cookie" doc:"Expired session cookie"`
+	Body      MessageResponseBody `json:"body,inline"`
+}
+
+func (h *AuthHandler) Logout(ctx context.Context, input *LogoutInput) (*LogoutResponse, error) {
+	principal, ok := auth.PrincipalFromContext(ctx)
+	if !ok {
+		return nil, huma.Error401Unauthorized("authentication required")
+	}
+
+	switch principal.Method {
+	case auth.AuthMethodBearer:
+		if err := h.tokenService.Revoke(ctx, principal.Claims); err != nil {
+			log.Printf("Failed to revoke tokens: %v", err)
+			return nil, huma.Error500InternalServerError("failed to log out")
+		}
+	case auth.AuthMethodSession:
+		if err := h.sessionStore.Delete(ctx, principal.SessionID); err != nil {
+			log.Printf("Failed to delete session: %v", err)
+			return nil, huma.Error500InternalServerError("failed to log out")
+		}
+	}
+
+	return &LogoutResponse{
+		SetCookie: h.sessionCookie("", time.Unix(0, 0)),
+		Body: MessageResponseBody{
+			Message: "Successfully logged out",
+		},
+	}, nil
+}
+
+func (h *AuthHandler) sessionCookie(value string, expires time.Time) http.Cookie {
+	return http.Cookie{
+		Name:     h.sessionConfig.CookieName,
+		Value:    value,
+		Path:     "/",
+		Expires:  expires,
+		HttpOnly: true,
+		Secure:   h.sessionConfig.SecureCookie,
+		SameSite: http.SameSiteLaxMode,
+	}
+}
+
+// requireUserID returns the ID of the authenticated caller.
+func requireUserID(ctx context.Context) (string, error) {
+	principal, ok := auth.PrincipalFromContext(ctx)
+	if !ok {
+		return "", huma.Error401Unauthorized("authentication required")
+	}
+	return principal.UserID, nil
+}
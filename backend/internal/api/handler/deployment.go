@@ -0,0 +1,422 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+
+	"github.com/Jesuloba-world/deployease/backend/internal/app/middleware"
+	"github.com/Jesuloba-world/deployease/backend/internal/deployment"
+	"github.com/Jesuloba-world/deployease/backend/internal/infrastructure/database/postgres/sqlc"
+	"github.com/Jesuloba-world/deployease/backend/internal/pagination"
+	"github.com/Jesuloba-world/deployease/backend/internal/project"
+)
+
+// sseKeepAliveInterval is how often an idle log stream sends a comment.
+const sseKeepAliveInterval = 15 * time.Second
+
+type DeploymentHandler struct {
+	projectService    *project.Service
+	deploymentService *deployment.Service
+	logTailer         *deployment.LogTailer
+}
+
+func NewDeploymentHandler(projectService *project.Service, deploymentService *deployment.Service, logTailer *deployment.LogTailer) *DeploymentHandler {
+	return &DeploymentHandler{
+		projectService:    projectService,
+		deploymentService: deploymentService,
+		logTailer:         logTailer,
+	}
+}
+
+type DeploymentResponseBody struct {
+	ID               string     `json:"id" doc:"Unique identifier of the deployment" example:"V1StGXR8_Z5jdHi6B-myT"`
+	ProjectID        string     `json:"project_id" doc:"Project the deployment belongs to"`
+	Branch           string     `json:"branch" doc:"Branch the deployment was made from; the project's own branch or a preview environment's" example:"main"`
+	Status           string     `json:"status" doc:"Current status of the deployment" enum:"pending,in_progress,success,failed,cancelled,rolled_back" example:"pending"`
+	CommitHash       string     `json:"commit_hash,omitempty" doc:"Git commit being deployed" example:"abc123def456"`
+	ImageRef         string     `json:"image_ref,omitempty" doc:"Container image built for the deployment" example:"deployease/v1stgxr8z5jdhi6bmyt:K8s9Hx2mQ1pLw7VbN3cRt"`
+	RollbackTargetID string     `json:"rollback_target_id,omitempty" doc:"Earlier deployment whose image this rollback re-runs" example:"K8s9Hx2mQ1pLw7VbN3cRt"`
+	DeployedAt       *time.Time `json:"deployed_at,omitempty" doc:"Timestamp when the deployment succeeded" format:"date-time"`
+	CreatedAt        time.Time  `json:"created_at" doc:"Timestamp when the deployment was requested" format:"date-time"`
+	UpdatedAt        time.Time  `json:"updated_at" doc:"Timestamp when the deployment last changed" format:"date-time"`
+}
+
+func newDeploymentResponseBody(d sqlc.Deployment) DeploymentResponseBody {
+	body := DeploymentResponseBody{
+		ID:               d.ID,
+		ProjectID:        d.ProjectID,
+		Branch:           d.Branch,
+		Status:           string(d.Status),
+		CommitHash:       d.CommitHash.String,
+		ImageRef:         d.ImageRef.String,
+		RollbackTargetID: d.RollbackTargetID.String,
+		CreatedAt:        d.CreatedAt.Time,
+		UpdatedAt:        d.UpdatedAt.Time,
+	}
+	if d.DeployedAt.Valid {
+		deployedAt := d.DeployedAt.Time
+		body.DeployedAt = &deployedAt
+	}
+	return body
+}
+
+type DeploymentResponse struct {
+	Body DeploymentResponseBody `json:"body,inline"`
+}
+
+type CreateDeploymentInput struct {
+	ProjectID string `path:"project_id" doc:"Unique identifier of the project" maxLength:"32"`
+	Body      struct {
+		CommitHash string `json:"commit_hash" doc:"Git commit to deploy" pattern:"^[0-9a-fA-F]{7,40}$" example:"abc123def456"`
+		Branch     string `json:"branch,omitempty" doc:"Branch the commit is from; defaults to the project's branch. Any other branch is deployed to its preview environment" maxLength:"255" example:"feature/login"`
+	}
+}
+
+func (h *DeploymentHandler) Create(ctx context.Context, input *CreateDeploymentInput) (*DeploymentResponse, error) {
+	p, err := h.ownedProject(ctx, input.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+
+	branch := strings.TrimSpace(input.Body.Branch)
+	if branch != "" {
+		if err := project.ValidateBranch(branch); err != nil {
+			return nil, huma.Error422UnprocessableEntity(err.Error())
+		}
+	}
+
+	d, err := h.deploymentService.CreateForBranch(ctx, p.ID, branch, input.Body.CommitHash)
+	if err != nil {
+		return nil, deploymentError(err)
+	}
+
+	return &DeploymentResponse{Body: newDeploymentResponseBody(d)}, nil
+}
+
+type ListDeploymentsInput struct {
+	ProjectID string   `path:"project_id" doc:"Unique identifier of the project" maxLength:"32"`
+	Branch    string   `query:"branch" doc:"Only return deployments of this branch, the history of one environment" maxLength:"255" example:"main"`
+	Status    []string `query:"status" doc:"Only return deployments in these statuses" enum:"pending,in_progress,success,failed,cancelled,rolled_back"`
+	Cursor    string   `query:"cursor" doc:"Opaque cursor returned as next_cursor by the previous page"`
+	Limit     int      `query:"limit" doc:"Maximum number of deployments to return" default:"20" minimum:"1" maximum:"100"`
+}
+
+type ListDeploymentsResponseBody struct {
+	Deployments []DeploymentResponseBody `json:"deployments" doc:"Deployments of the project, newest first"`
+	NextCursor  string                   `json:"next_cursor,omitempty" doc:"Cursor for the next page; omitted on the last page"`
+}
+
+type ListDeploymentsResponse struct {
+	Body ListDeploymentsResponseBody `json:"body,inline"`
+}
+
+func (h *DeploymentHandler) List(ctx context.Context, input *ListDeploymentsInput) (*ListDeploymentsResponse, error) {
+	p, err := h.ownedProject(ctx, input.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]sqlc.DeploymentStatus, 0, len(input.Status))
+	for _, status := range input.Status {
+		statuses = append(statuses, sqlc.DeploymentStatus(status))
+	}
+
+	page, err := h.deploymentService.List(ctx, deployment.ListParams{
+		ProjectID: p.ID,
+		Branch:    input.Branch,
+		Statuses:  statuses,
+		Cursor:    input.Cursor,
+		Limit:     input.Limit,
+	})
+	if err != nil {
+		return nil, deploymentError(err)
+	}
+
+	body := ListDeploymentsResponseBody{
+		Deployments: make([]DeploymentResponseBody, 0, len(page.Deployments)),
+		NextCursor:  page.NextCursor,
+	}
+	for _, d := range page.Deployments {
+		body.Deployments = append(body.Deployments, newDeploymentResponseBody(d))
+	}
+
+	return &ListDeploymentsResponse{Body: body}, nil
+}
+
+type DeploymentIDInput struct {
+	ProjectID    string `path:"project_id" doc:"Unique identifier of the project" maxLength:"32"`
+	DeploymentID string `path:"deployment_id" doc:"Unique identifier of the deployment" maxLength:"32"`
+}
+
+func (h *DeploymentHandler) Get(ctx context.Context, input *DeploymentIDInput) (*DeploymentResponse, error) {
+	p, err := h.ownedProject(ctx, input.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+
+	d, err := h.deploymentService.Get(ctx, p.ID, input.DeploymentID)
+	if err != nil {
+		return nil, deploymentError(err)
+	}
+
+	return &DeploymentResponse{Body: newDeploymentResponseBody(d)}, nil
+}
+
+func (h *DeploymentHandler) Cancel(ctx context.Context, input *DeploymentIDInput) (*DeploymentResponse, error) {
+	p, err := h.ownedProject(ctx, input.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+
+	d, err := h.deploymentService.Get(ctx, p.ID, input.DeploymentID)
+	if err != nil {
+		return nil, deploymentError(err)
+	}
+
+	d, err = h.deploymentService.Cancel(ctx, d.ID)
+	if err != nil {
+		return nil, deploymentError(err)
+	}
+
+	return &DeploymentResponse{Body: newDeploymentResponseBody(d)}, nil
+}
+
+// Rollback deploys the image of the deployment in the path again as a new
+// deployment.
+func (h *DeploymentHandler) Rollback(ctx context.Context, input *DeploymentIDInput) (*DeploymentResponse, error) {
+	p, err := h.ownedProject(ctx, input.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+
+	d, err := h.deploymentService.Rollback(ctx, p.ID, input.DeploymentID)
+	if err != nil {
+		return nil, deploymentError(err)
+	}
+
+	return &DeploymentResponse{Body: newDeploymentResponseBody(d)}, nil
+}
+
+type DeploymentLogsInput struct {
+	ProjectID    string `path:"project_id" doc:"Unique identifier of the project" maxLength:"32"`
+	DeploymentID string `path:"deployment_id" doc:"Unique identifier of the deployment" maxLength:"32"`
+	LastEventID  string `header:"Last-Event-ID" doc:"ID of the last event received; the stream resumes after it" maxLength:"32"`
+	Follow       bool   `query:"follow" doc:"Keep the stream open for new output until the deployment finishes or, once live, its container stops"`
+}
+
+type logEventData struct {
+	Stream    string    `json:"stream"`
+	Level     string    `json:"level"`
+	Line      string    `json:"line"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+type endEventData struct {
+	Status string `json:"status"`
+}
+
+// Logs streams the deployment's build, rollout and runtime output as
+// Server-Sent Events. Each event's ID can be sent back as Last-Event-ID to
+// resume the stream, which browsers do on their own when reconnecting.
+func (h *DeploymentHandler) Logs(ctx context.Context, input *DeploymentLogsInput) (*huma.StreamResponse, error) {
+	p, err := h.ownedProject(ctx, input.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+
+	d, err := h.deploymentService.Get(ctx, p.ID, input.DeploymentID)
+	if err != nil {
+		return nil, deploymentError(err)
+	}
+
+	cursor, err := deployment.ParseLogCursor(input.LastEventID)
+	if err != nil {
+		return nil, huma.Error400BadRequest(err.Error())
+	}
+
+	return &huma.StreamResponse{
+		Body: func(hctx huma.Context) {
+			h.streamLogs(hctx, d.ID, p.ID, cursor, input.Follow)
+		},
+	}, nil
+}
+
+func (h *DeploymentHandler) streamLogs(hctx huma.Context, deploymentID, projectID string, cursor deployment.LogCursor, follow bool) {
+	var (
+		ctx    context.Context
+		cancel context.CancelFunc
+	)
+	if w, ok := hctx.BodyWriter().(http.ResponseWriter); ok {
+		ctx, cancel = middleware.Stream(hctx.Context(), w)
+	} else {
+		ctx, cancel = context.WithCancel(hctx.Context())
+	}
+	defer cancel()
+
+	hctx.SetHeader("Content-Type", "text/event-stream")
+	hctx.SetHeader("Cache-Control", "no-cache")
+	// Stops nginx and similar proxies from buffering the stream.
+	hctx.SetHeader("X-Accel-Buffering", "no")
+	hctx.SetStatus(http.StatusOK)
+
+	stream := newSSEWriter(hctx.BodyWriter())
+	if err := stream.comment("connected"); err != nil {
+		return
+	}
+
+	// The keep-alive goroutine must be gone before the handler returns, or it
+	// could write to the response after the server has reused it.
+	keepAliveDone := make(chan struct{})
+	defer func() {
+		cancel()
+		<-keepAliveDone
+	}()
+	go func() {
+		defer close(keepAliveDone)
+		ticker := time.NewTicker(sseKeepAliveInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := stream.comment("keep-alive"); err != nil {
+					cancel()
+					return
+				}
+			}
+		}
+	}()
+
+	err := h.logTailer.Tail(ctx, deploymentID, cursor, follow, func(e deployment.LogEntry) error {
+		return stream.event(e.Cursor.String(), "log", logEventData{
+			Stream:    e.Stream,
+			Level:     e.Level,
+			Line:      e.Line,
+			Timestamp: e.Time,
+		})
+	})
+	if ctx.Err() != nil {
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to stream logs of deployment %s: %v", deploymentID, err)
+		_ = stream.event("", "error", map[string]string{"error": "failed to read deployment logs"})
+		return
+	}
+
+	d, err := h.deploymentService.Get(ctx, projectID, deploymentID)
+	if err != nil {
+		log.Printf("Failed to get deployment %s after streaming logs: %v", deploymentID, err)
+		return
+	}
+	_ = stream.event("", "end", endEventData{Status: string(d.Status)})
+}
+
+type SearchLogsInput struct {
+	ProjectID    string    `path:"project_id" doc:"Unique identifier of the project" maxLength:"32"`
+	DeploymentID string    `query:"deployment_id" doc:"Only search the logs of this deployment" maxLength:"32"`
+	Stream       string    `query:"stream" doc:"Only return lines from this stream" enum:"build,rollout,runtime"`
+	Query        string    `query:"q" doc:"Only return lines containing this text, ignoring case" maxLength:"256"`
+	Level        string    `query:"level" doc:"Only return lines of this level or more severe" enum:"debug,info,warn,error"`
+	Since        time.Time `query:"since" doc:"Only return lines written at or after this time" format:"date-time"`
+	Until        time.Time `query:"until" doc:"Only return lines written before this time" format:"date-time"`
+	Cursor       string    `query:"cursor" doc:"Opaque cursor returned as next_cursor by the previous page"`
+	Limit        int       `query:"limit" doc:"Maximum number of lines to return" default:"20" minimum:"1" maximum:"100"`
+}
+
+type LogLineResponseBody struct {
+	ID           int64     `json:"id" doc:"Unique identifier of the line" example:"1042"`
+	DeploymentID string    `json:"deployment_id" doc:"Deployment the line belongs to" example:"V1StGXR8_Z5jdHi6B-myT"`
+	Stream       string    `json:"stream" doc:"Output the line came from" enum:"build,rollout,runtime" example:"runtime"`
+	Level        string    `json:"level" doc:"Level detected from the line" enum:"debug,info,warn,error" example:"error"`
+	Line         string    `json:"line" doc:"The line itself" example:"ERROR connection refused"`
+	Timestamp    time.Time `json:"timestamp" doc:"When the line was written" format:"date-time"`
+}
+
+type SearchLogsResponseBody struct {
+	Logs       []LogLineResponseBody `json:"logs" doc:"Matching lines, newest first"`
+	NextCursor string                `json:"next_cursor,omitempty" doc:"Cursor for the next page; omitted on the last page"`
+}
+
+type SearchLogsResponse struct {
+	Body SearchLogsResponseBody `json:"body,inline"`
+}
+
+// SearchLogs searches the stored logs of every deployment of a project.
+func (h *DeploymentHandler) SearchLogs(ctx context.Context, input *SearchLogsInput) (*SearchLogsResponse, error) {
+	p, err := h.ownedProject(ctx, input.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := h.deploymentService.SearchLogs(ctx, deployment.LogSearchParams{
+		ProjectID:    p.ID,
+		DeploymentID: input.DeploymentID,
+		Stream:       input.Stream,
+		Query:        input.Query,
+		Level:        input.Level,
+		Since:        input.Since,
+		Until:        input.Until,
+		Cursor:       input.Cursor,
+		Limit:        input.Limit,
+	})
+	if err != nil {
+		return nil, deploymentError(err)
+	}
+
+	body := SearchLogsResponseBody{
+		Logs:       make([]LogLineResponseBody, 0, len(page.Logs)),
+		NextCursor: page.NextCursor,
+	}
+	for _, l := range page.Logs {
+		body.Logs = append(body.Logs, LogLineResponseBody{
+			ID:           l.ID,
+			DeploymentID: l.DeploymentID,
+			Stream:       l.Stream,
+			Level:        l.Level,
+			Line:         l.Line,
+			Timestamp:    l.CreatedAt.Time,
+		})
+	}
+
+	return &SearchLogsResponse{Body: body}, nil
+}
+
+// ownedProject loads the project from the path, making sure it belongs to the
+// authenticated user.
+func (h *DeploymentHandler) ownedProject(ctx context.Context, projectID string) (sqlc.Project, error) {
+	userID, err := requireUserID(ctx)
+	if err != nil {
+		return sqlc.Project{}, err
+	}
+
+	p, err := h.projectService.Get(ctx, userID, projectID)
+	if err != nil {
+		return sqlc.Project{}, projectError(err)
+	}
+
+	return p, nil
+}
+
+func deploymentError(err error) error {
+	switch {
+	case errors.Is(err, deployment.ErrNotFound), errors.Is(err, deployment.ErrPreviewNotFound):
+		return huma.Error404NotFound(err.Error())
+	case errors.Is(err, deployment.ErrInvalidTransition), errors.Is(err, deployment.ErrRollbackTarget):
+		return huma.Error409Conflict(err.Error())
+	case errors.Is(err, deployment.ErrPreviewsDisabled):
+		return huma.Error422UnprocessableEntity(err.Error())
+	case errors.Is(err, pagination.ErrInvalidCursor), errors.Is(err, deployment.ErrInvalidTimeRange):
+		return huma.Error422UnprocessableEntity(err.Error())
+	default:
+		log.Printf("Deployment operation failed: %v", err)
+		return huma.Error500InternalServerError("deployment operation failed")
+	}
+}
@@ -0,0 +1,254 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+
+	"github.com/Jesuloba-world/deployease/backend/internal/certificate"
+	"github.com/Jesuloba-world/deployease/backend/internal/domain"
+	"github.com/Jesuloba-world/deployease/backend/internal/infrastructure/database/postgres/sqlc"
+	"github.com/Jesuloba-world/deployease/backend/internal/project"
+)
+
+type DomainHandler struct {
+	projectService     *project.Service
+	domainService      *domain.Service
+	certificateService *certificate.Service
+}
+
+func NewDomainHandler(projectService *project.Service, domainService *domain.Service, certificateService *certificate.Service) *DomainHandler {
+	return &DomainHandler{
+		projectService:     projectService,
+		domainService:      domainService,
+		certificateService: certificateService,
+	}
+}
+
+type DomainVerificationBody struct {
+	Type  string `json:"type" doc:"DNS record type to create" enum:"TXT" example:"TXT"`
+	Name  string `json:"name" doc:"Name of the record" example:"_deployease-challenge.app.example.com"`
+	Value string `json:"value" doc:"Content of the record" example:"deployease-verification=3kq9x0v7m2c8r1t5w4y6z0a2b4d6f8h1"`
+}
+
+type DomainResponseBody struct {
+	ID            string                 `json:"id" doc:"Unique identifier of the domain" example:"V1StGXR8_Z5jdHi6B-myT"`
+	Domain        string                 `json:"domain" doc:"Host name attached to the project" example:"app.example.com"`
+	Status        string                 `json:"status" doc:"Whether the project has proven control of the domain" enum:"pending,verified,failed" example:"pending"`
+	Verification  DomainVerificationBody `json:"verification" doc:"DNS record that proves control of the domain"`
+	VerifiedAt    *time.Time             `json:"verified_at,omitempty" doc:"Timestamp when the domain was verified" format:"date-time"`
+	LastCheckedAt *time.Time             `json:"last_checked_at,omitempty" doc:"Timestamp of the last check of the record" format:"date-time"`
+	LastError     string                 `json:"last_error,omitempty" doc:"Why the last check did not verify the domain" example:"TXT record _deployease-challenge.app.example.com does not contain the verification token yet"`
+	CreatedAt     time.Time              `json:"created_at" doc:"Timestamp when the domain was attached" format:"date-time"`
+	UpdatedAt     time.Time              `json:"updated_at" doc:"Timestamp when the domain last changed" format:"date-time"`
+}
+
+func newDomainResponseBody(d sqlc.Domain) DomainResponseBody {
+	body := DomainResponseBody{
+		ID:     d.ID,
+		Domain: d.Name,
+		Status: string(d.Status),
+		Verification: DomainVerificationBody{
+			Type:  "TXT",
+			Name:  domain.RecordName(d.Name),
+			Value: domain.RecordValue(d.VerificationToken),
+		},
+		LastError: d.LastError.String,
+		CreatedAt: d.CreatedAt.Time,
+		UpdatedAt: d.UpdatedAt.Time,
+	}
+	if d.VerifiedAt.Valid {
+		verifiedAt := d.VerifiedAt.Time
+		body.VerifiedAt = &verifiedAt
+	}
+	if d.LastCheckedAt.Valid {
+		lastCheckedAt := d.LastCheckedAt.Time
+		body.LastCheckedAt = &lastCheckedAt
+	}
+	return body
+}
+
+type DomainResponse struct {
+	Body DomainResponseBody `json:"body,inline"`
+}
+
+type ListDomainsResponseBody struct {
+	Domains []DomainResponseBody `json:"domains" doc:"Custom domains of the project, oldest first"`
+}
+
+type ListDomainsResponse struct {
+	Body ListDomainsResponseBody `json:"body,inline"`
+}
+
+func (h *DomainHandler) List(ctx context.Context, input *ProjectIDInput) (*ListDomainsResponse, error) {
+	p, err := h.ownedProject(ctx, input.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+
+	domains, err := h.domainService.List(ctx, p.ID)
+	if err != nil {
+		return nil, domainError(err)
+	}
+
+	body := ListDomainsResponseBody{Domains: make([]DomainResponseBody, 0, len(domains))}
+	for _, d := range domains {
+		body.Domains = append(body.Domains, newDomainResponseBody(d))
+	}
+
+	return &ListDomainsResponse{Body: body}, nil
+}
+
+type CreateDomainInput struct {
+	ProjectID string `path:"project_id" doc:"Unique identifier of the project" maxLength:"32"`
+	Body      struct {
+		Domain string `json:"domain" doc:"Host name to attach to the project" minLength:"1" maxLength:"254" example:"app.example.com"`
+	}
+}
+
+func (h *DomainHandler) Create(ctx context.Context, input *CreateDomainInput) (*DomainResponse, error) {
+	p, err := h.ownedProject(ctx, input.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+
+	d, err := h.domainService.Add(ctx, p.ID, input.Body.Domain)
+	if err != nil {
+		return nil, domainError(err)
+	}
+
+	return &DomainResponse{Body: newDomainResponseBody(d)}, nil
+}
+
+type DomainIDInput struct {
+	ProjectID string `path:"project_id" doc:"Unique identifier of the project" maxLength:"32"`
+	DomainID  string `path:"domain_id" doc:"Unique identifier of the domain" maxLength:"32"`
+}
+
+func (h *DomainHandler) Get(ctx context.Context, input *DomainIDInput) (*DomainResponse, error) {
+	p, err := h.ownedProject(ctx, input.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+
+	d, err := h.domainService.Get(ctx, p.ID, input.DomainID)
+	if err != nil {
+		return nil, domainError(err)
+	}
+
+	return &DomainResponse{Body: newDomainResponseBody(d)}, nil
+}
+
+func (h *DomainHandler) Verify(ctx context.Context, input *DomainIDInput) (*DomainResponse, error) {
+	p, err := h.ownedProject(ctx, input.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+
+	d, err := h.domainService.Verify(ctx, p.ID, input.DomainID)
+	if err != nil {
+		return nil, domainError(err)
+	}
+
+	return &DomainResponse{Body: newDomainResponseBody(d)}, nil
+}
+
+type DeleteDomainResponse struct{}
+
+func (h *DomainHandler) Delete(ctx context.Context, input *DomainIDInput) (*DeleteDomainResponse, error) {
+	p, err := h.ownedProject(ctx, input.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := h.domainService.Delete(ctx, p.ID, input.DomainID); err != nil {
+		return nil, domainError(err)
+	}
+
+	return &DeleteDomainResponse{}, nil
+}
+
+type CertificateResponseBody struct {
+	Status    string     `json:"status" doc:"Whether a certificate has been issued for the domain" enum:"pending,issued,failed" example:"issued"`
+	NotBefore *time.Time `json:"not_before,omitempty" doc:"Start of the issued certificate's validity" format:"date-time"`
+	NotAfter  *time.Time `json:"not_after,omitempty" doc:"End of the issued certificate's validity" format:"date-time"`
+	IssuedAt  *time.Time `json:"issued_at,omitempty" doc:"Timestamp when the certificate was last issued or renewed" format:"date-time"`
+	RenewsAt  *time.Time `json:"renews_at,omitempty" doc:"Timestamp when the next issuance or renewal is attempted" format:"date-time"`
+	LastError string     `json:"last_error,omitempty" doc:"Why the last issuance failed" example:"authorization of app.example.com failed: acme: authorization error for app.example.com"`
+}
+
+func newCertificateResponseBody(c sqlc.Certificate) CertificateResponseBody {
+	body := CertificateResponseBody{
+		Status:    string(c.Status),
+		LastError: c.LastError.String,
+	}
+	if c.NotBefore.Valid {
+		notBefore := c.NotBefore.Time
+		body.NotBefore = &notBefore
+	}
+	if c.NotAfter.Valid {
+		notAfter := c.NotAfter.Time
+		body.NotAfter = &notAfter
+	}
+	if c.IssuedAt.Valid {
+		issuedAt := c.IssuedAt.Time
+		body.IssuedAt = &issuedAt
+	}
+	renewsAt := c.NextAttemptAt.Time
+	body.RenewsAt = &renewsAt
+	return body
+}
+
+type CertificateResponse struct {
+	Body CertificateResponseBody `json:"body,inline"`
+}
+
+func (h *DomainHandler) GetCertificate(ctx context.Context, input *DomainIDInput) (*CertificateResponse, error) {
+	p, err := h.ownedProject(ctx, input.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+
+	d, err := h.domainService.Get(ctx, p.ID, input.DomainID)
+	if err != nil {
+		return nil, domainError(err)
+	}
+
+	c, err := h.certificateService.Get(ctx, d.ID)
+	if err != nil {
+		return nil, domainError(err)
+	}
+
+	return &CertificateResponse{Body: newCertificateResponseBody(c)}, nil
+}
+
+func (h *DomainHandler) ownedProject(ctx context.Context, projectID string) (sqlc.Project, error) {
+	userID, err := requireUserID(ctx)
+	if err != nil {
+		return sqlc.Project{}, err
+	}
+
+	p, err := h.projectService.Get(ctx, userID, projectID)
+	if err != nil {
+		return sqlc.Project{}, projectError(err)
+	}
+
+	return p, nil
+}
+
+func domainError(err error) error {
+	switch {
+	case errors.Is(err, domain.ErrNotFound), errors.Is(err, certificate.ErrNotFound):
+		return huma.Error404NotFound(err.Error())
+	case errors.Is(err, domain.ErrTaken):
+		return huma.Error409Conflict(err.Error())
+	case errors.Is(err, domain.ErrInvalidName):
+		return huma.Error422UnprocessableEntity(err.Error())
+	default:
+		log.Printf("Domain operation failed: %v", err)
+		return huma.Error500InternalServerError("domain operation failed")
+	}
+}
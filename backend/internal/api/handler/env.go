@@ -0,0 +1,205 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+
+	"github.com/Jesuloba-world/deployease/backend/internal/deployment"
+	"github.com/Jesuloba-world/deployease/backend/internal/envvar"
+	"github.com/Jesuloba-world/deployease/backend/internal/infrastructure/database/postgres/sqlc"
+	"github.com/Jesuloba-world/deployease/backend/internal/project"
+)
+
+type EnvHandler struct {
+	projectService    *project.Service
+	envService        *envvar.Service
+	deploymentService *deployment.Service
+}
+
+func NewEnvHandler(projectService *project.Service, envService *envvar.Service, deploymentService *deployment.Service) *EnvHandler {
+	return &EnvHandler{
+		projectService:    projectService,
+		envService:        envService,
+		deploymentService: deploymentService,
+	}
+}
+
+type EnvVarResponseBody struct {
+	Key       string    `json:"key" doc:"Name of the variable" example:"LOG_LEVEL"`
+	Branch    string    `json:"branch,omitempty" doc:"Branch whose deployments the variable is overridden for; omitted for the project's own variables" example:"feature/login"`
+	Value     *string   `json:"value,omitempty" doc:"Value of the variable; never returned for secrets" example:"debug"`
+	Secret    bool      `json:"secret" doc:"Whether the value is encrypted at rest and hidden" example:"false"`
+	CreatedAt time.Time `json:"created_at" doc:"Timestamp when the variable was created" format:"date-time"`
+	UpdatedAt time.Time `json:"updated_at" doc:"Timestamp when the variable last changed" format:"date-time"`
+}
+
+func newEnvVarResponseBody(v sqlc.ProjectEnvVar) EnvVarResponseBody {
+	body := EnvVarResponseBody{
+		Key:       v.Key,
+		Branch:    v.Branch,
+		Secret:    v.Secret,
+		CreatedAt: v.CreatedAt.Time,
+		UpdatedAt: v.UpdatedAt.Time,
+	}
+	if !v.Secret {
+		value := v.Value.String
+		body.Value = &value
+	}
+	return body
+}
+
+type ListEnvVarsResponseBody struct {
+	Variables []EnvVarResponseBody `json:"variables" doc:"Environment variables of the project, or the overrides of a branch, ordered by name"`
+}
+
+type ListEnvVarsResponse struct {
+	Body ListEnvVarsResponseBody `json:"body,inline"`
+}
+
+type ListEnvVarsInput struct {
+	ProjectID string `path:"project_id" doc:"Unique identifier of the project" maxLength:"32"`
+	Branch    string `query:"branch" doc:"List the overrides of this branch instead of the project's own variables" maxLength:"255" example:"feature/login"`
+}
+
+func (h *EnvHandler) List(ctx context.Context, input *ListEnvVarsInput) (*ListEnvVarsResponse, error) {
+	p, err := h.ownedProject(ctx, input.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+
+	vars, err := h.envService.List(ctx, p.ID, input.Branch)
+	if err != nil {
+		return nil, envError(err)
+	}
+
+	body := ListEnvVarsResponseBody{Variables: make([]EnvVarResponseBody, 0, len(vars))}
+	for _, v := range vars {
+		body.Variables = append(body.Variables, newEnvVarResponseBody(v))
+	}
+
+	return &ListEnvVarsResponse{Body: body}, nil
+}
+
+type SetEnvVarInput struct {
+	ProjectID string `path:"project_id" doc:"Unique identifier of the project" maxLength:"32"`
+	Key       string `path:"key" doc:"Name of the variable" maxLength:"256" example:"LOG_LEVEL"`
+	Branch    string `query:"branch" doc:"Override the variable for deployments of this branch only" maxLength:"255" example:"feature/login"`
+	Redeploy  bool   `query:"redeploy" doc:"Redeploy the active deployment of the project, or of the branch, so it runs with the change"`
+	Body      struct {
+		Value  string `json:"value" doc:"Value of the variable" maxLength:"32768" example:"debug"`
+		Secret bool   `json:"secret,omitempty" doc:"Encrypt the value at rest and never return it"`
+	}
+}
+
+type SetEnvVarResponseBody struct {
+	Variable   EnvVarResponseBody      `json:"variable" doc:"The variable as stored"`
+	Deployment *DeploymentResponseBody `json:"deployment,omitempty" doc:"Deployment started to apply the change; omitted unless a redeploy was requested and there is an active deployment"`
+}
+
+type SetEnvVarResponse struct {
+	Body SetEnvVarResponseBody `json:"body,inline"`
+}
+
+func (h *EnvHandler) Set(ctx context.Context, input *SetEnvVarInput) (*SetEnvVarResponse, error) {
+	p, err := h.ownedProject(ctx, input.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := h.envService.Set(ctx, envvar.SetParams{
+		ProjectID: p.ID,
+		Branch:    input.Branch,
+		Key:       input.Key,
+		Value:     input.Body.Value,
+		Secret:    input.Body.Secret,
+	})
+	if err != nil {
+		return nil, envError(err)
+	}
+
+	body := SetEnvVarResponseBody{Variable: newEnvVarResponseBody(v)}
+	if input.Redeploy {
+		d, ok, err := h.redeploy(ctx, p.ID, input.Branch)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			deploymentBody := newDeploymentResponseBody(d)
+			body.Deployment = &deploymentBody
+		}
+	}
+
+	return &SetEnvVarResponse{Body: body}, nil
+}
+
+type DeleteEnvVarInput struct {
+	ProjectID string `path:"project_id" doc:"Unique identifier of the project" maxLength:"32"`
+	Key       string `path:"key" doc:"Name of the variable" maxLength:"256" example:"LOG_LEVEL"`
+	Branch    string `query:"branch" doc:"Delete the branch's override rather than the project's variable" maxLength:"255" example:"feature/login"`
+	Redeploy  bool   `query:"redeploy" doc:"Redeploy the active deployment of the project, or of the branch, so it runs without the variable"`
+}
+
+type DeleteEnvVarResponse struct{}
+
+func (h *EnvHandler) Delete(ctx context.Context, input *DeleteEnvVarInput) (*DeleteEnvVarResponse, error) {
+	p, err := h.ownedProject(ctx, input.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := h.envService.Delete(ctx, p.ID, input.Branch, input.Key); err != nil {
+		return nil, envError(err)
+	}
+
+	if input.Redeploy {
+		if _, _, err := h.redeploy(ctx, p.ID, input.Branch); err != nil {
+			return nil, err
+		}
+	}
+
+	return &DeleteEnvVarResponse{}, nil
+}
+
+// redeploy starts a deployment of the active image of the project, or of
+// branch. Without an active deployment there is nothing running with stale
+// settings, so it reports false rather than failing.
+func (h *EnvHandler) redeploy(ctx context.Context, projectID, branch string) (sqlc.Deployment, bool, error) {
+	d, err := h.deploymentService.Redeploy(ctx, projectID, branch)
+	if errors.Is(err, deployment.ErrNoActiveDeployment) {
+		return sqlc.Deployment{}, false, nil
+	}
+	if err != nil {
+		return sqlc.Deployment{}, false, deploymentError(err)
+	}
+	return d, true, nil
+}
+
+func (h *EnvHandler) ownedProject(ctx context.Context, projectID string) (sqlc.Project, error) {
+	userID, err := requireUserID(ctx)
+	if err != nil {
+		return sqlc.Project{}, err
+	}
+
+	p, err := h.projectService.Get(ctx, userID, projectID)
+	if err != nil {
+		return sqlc.Project{}, projectError(err)
+	}
+
+	return p, nil
+}
+
+func envError(err error) error {
+	switch {
+	case errors.Is(err, envvar.ErrNotFound):
+		return huma.Error404NotFound(err.Error())
+	case errors.Is(err, envvar.ErrInvalidKey), errors.Is(err, envvar.ErrReservedKey), errors.Is(err, project.ErrInvalidBranch):
+		return huma.Error422UnprocessableEntity(err.Error())
+	default:
+		log.Printf("Environment variable operation failed: %v", err)
+		return huma.Error500InternalServerError("environment variable operation failed")
+	}
+}
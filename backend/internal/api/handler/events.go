@@ -0,0 +1,178 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/coder/websocket/wsjson"
+
+	"github.com/Jesuloba-world/deployease/backend/internal/app/middleware"
+	"github.com/Jesuloba-world/deployease/backend/internal/auth"
+	"github.com/Jesuloba-world/deployease/backend/internal/config"
+	"github.com/Jesuloba-world/deployease/backend/internal/events"
+	"github.com/Jesuloba-world/deployease/backend/internal/project"
+)
+
+const (
+	clientMessageSubscribe   = "subscribe"
+	clientMessageUnsubscribe = "unsubscribe"
+
+	maxClientMessageSize = 4096
+)
+
+// EventsHandler serves the WebSocket endpoint clients use to follow the
+// deployments of their projects.
+type EventsHandler struct {
+	projectService *project.Service
+	broker         *events.Broker
+	cfg            config.EventsConfig
+}
+
+func NewEventsHandler(projectService *project.Service, broker *events.Broker, cfg config.EventsConfig) *EventsHandler {
+	return &EventsHandler{
+		projectService: projectService,
+		broker:         broker,
+		cfg:            cfg,
+	}
+}
+
+type clientMessage struct {
+	Type      string `json:"type"`
+	ProjectID string `json:"project_id"`
+}
+
+type serverMessage struct {
+	Type      string `json:"type"`
+	ProjectID string `json:"project_id,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+func (h *EventsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	principal, ok := auth.PrincipalFromContext(r.Context())
+	if !ok {
+		http.Error(w, "authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	ctx, cancel := middleware.Stream(r.Context(), w)
+	defer cancel()
+
+	conn, err := websocket.Accept(w, r, &websocket.AcceptOptions{
+		OriginPatterns: h.cfg.AllowedOrigins,
+	})
+	if err != nil {
+		// Accept has already written the response.
+		return
+	}
+	defer conn.CloseNow()
+	conn.SetReadLimit(maxClientMessageSize)
+
+	sub, err := h.broker.NewSubscriber()
+	if err != nil {
+		conn.Close(websocket.StatusGoingAway, "server shutting down")
+		return
+	}
+	defer h.broker.Remove(sub)
+
+	go func() {
+		defer cancel()
+		h.readLoop(ctx, conn, sub, principal.UserID)
+	}()
+
+	h.writeLoop(ctx, conn, sub)
+}
+
+// writeLoop forwards events and keeps the connection alive until the client
+// goes away or falls too far behind.
+func (h *EventsHandler) writeLoop(ctx context.Context, conn *websocket.Conn, sub *events.Subscriber) {
+	ticker := time.NewTicker(h.cfg.PingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case msg := <-sub.Messages():
+			writeCtx, cancel := context.WithTimeout(ctx, h.cfg.WriteTimeout)
+			err := conn.Write(writeCtx, websocket.MessageText, msg)
+			cancel()
+			if err != nil {
+				return
+			}
+
+		case <-sub.Dropped():
+			if errors.Is(sub.Err(), events.ErrSlowSubscriber) {
+				conn.Close(websocket.StatusPolicyViolation, "too slow to keep up with events")
+			} else {
+				conn.Close(websocket.StatusGoingAway, "server shutting down")
+			}
+			return
+
+		case <-ticker.C:
+			pingCtx, cancel := context.WithTimeout(ctx, h.cfg.PongTimeout)
+			err := conn.Ping(pingCtx)
+			cancel()
+			if err != nil {
+				return
+			}
+		}
+	}
+}
+
+// readLoop handles subscription requests. Reading also processes the pongs
+// writeLoop waits for, so it runs for the lifetime of the connection.
+func (h *EventsHandler) readLoop(ctx context.Context, conn *websocket.Conn, sub *events.Subscriber, userID string) {
+	for {
+		var msg clientMessage
+		if err := wsjson.Read(ctx, conn, &msg); err != nil {
+			return
+		}
+
+		reply := serverMessage{ProjectID: msg.ProjectID}
+		switch msg.Type {
+		case clientMessageSubscribe:
+			if err := h.subscribe(ctx, sub, userID, msg.ProjectID); err != nil {
+				reply.Type, reply.Error = "error", err.Error()
+			} else {
+				reply.Type = "subscribed"
+			}
+
+		case clientMessageUnsubscribe:
+			if err := h.broker.Unsubscribe(ctx, sub, msg.ProjectID); err != nil {
+				log.Printf("Failed to unsubscribe from project %s: %v", msg.ProjectID, err)
+			}
+			reply.Type = "unsubscribed"
+
+		default:
+			reply.Type, reply.Error = "error", "unknown message type"
+		}
+
+		writeCtx, cancel := context.WithTimeout(ctx, h.cfg.WriteTimeout)
+		err := wsjson.Write(writeCtx, conn, reply)
+		cancel()
+		if err != nil {
+			return
+		}
+	}
+}
+
+func (h *EventsHandler) subscribe(ctx context.Context, sub *events.Subscriber, userID, projectID string) error {
+	if _, err := h.projectService.Get(ctx, userID, projectID); err != nil {
+		if errors.Is(err, project.ErrNotFound) {
+			return err
+		}
+		log.Printf("Failed to authorize event subscription: %v", err)
+		return errors.New("subscription failed")
+	}
+
+	if err := h.broker.Subscribe(ctx, sub, projectID); err != nil {
+		log.Printf("Failed to subscribe to project %s: %v", projectID, err)
+		return errors.New("subscription failed")
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package handler
+
+import (
+	"context"
+	"time"
+
+	"github.com/Jesuloba-world/deployease/backend/internal/deployment"
+	"github.com/Jesuloba-world/deployease/backend/internal/infrastructure/database/postgres/sqlc"
+	"github.com/Jesuloba-world/deployease/backend/internal/project"
+)
+
+type PreviewHandler struct {
+	projectService    *project.Service
+	deploymentService *deployment.Service
+	domain            string
+}
+
+// NewPreviewHandler creates the preview environment handler. Previews are
+// reported as served under domain, which may be empty.
+func NewPreviewHandler(projectService *project.Service, deploymentService *deployment.Service, domain string) *PreviewHandler {
+	return &PreviewHandler{
+		projectService:    projectService,
+		deploymentService: deploymentService,
+		domain:            domain,
+	}
+}
+
+type PreviewResponseBody struct {
+	ID                 string    `json:"id" doc:"Unique identifier of the preview environment" example:"V1StGXR8_Z5jdHi6B-myT"`
+	Branch             string    `json:"branch" doc:"Branch the environment deploys" example:"feature/login"`
+	Subdomain          string    `json:"subdomain" doc:"Subdomain the environment is served under" example:"feature-login-x4k2m9qa"`
+	URL                string    `json:"url,omitempty" doc:"Address of the environment; omitted when no preview domain is configured" example:"https://feature-login-x4k2m9qa.preview.example.com"`
+	PullRequest        *int32    `json:"pull_request,omitempty" doc:"Pull request the branch is proposed in, once one is opened" example:"42"`
+	ActiveDeploymentID string    `json:"active_deployment_id,omitempty" doc:"Deployment currently serving the environment" example:"K8s9Hx2mQ1pLw7VbN3cRt"`
+	CreatedAt          time.Time `json:"created_at" doc:"Timestamp when the environment was created" format:"date-time"`
+	UpdatedAt          time.Time `json:"updated_at" doc:"Timestamp when the environment last changed" format:"date-time"`
+}
+
+func (h *PreviewHandler) newPreviewResponseBody(p sqlc.PreviewEnvironment) PreviewResponseBody {
+	body := PreviewResponseBody{
+		ID:                 p.ID,
+		Branch:             p.Branch,
+		Subdomain:          p.Subdomain,
+		URL:                deployment.PreviewURL(p, h.domain),
+		ActiveDeploymentID: p.ActiveDeploymentID.String,
+		CreatedAt:          p.CreatedAt.Time,
+		UpdatedAt:          p.UpdatedAt.Time,
+	}
+	if p.PullRequest.Valid {
+		number := p.PullRequest.Int32
+		body.PullRequest = &number
+	}
+	return body
+}
+
+type ListPreviewsResponseBody struct {
+	Previews []PreviewResponseBody `json:"previews" doc:"Preview environments of the project, oldest first"`
+}
+
+type ListPreviewsResponse struct {
+	Body ListPreviewsResponseBody `json:"body,inline"`
+}
+
+func (h *PreviewHandler) List(ctx context.Context, input *ProjectIDInput) (*ListPreviewsResponse, error) {
+	p, err := h.ownedProject(ctx, input.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+
+	previews, err := h.deploymentService.ListPreviews(ctx, p.ID)
+	if err != nil {
+		return nil, deploymentError(err)
+	}
+
+	body := ListPreviewsResponseBody{Previews: make([]PreviewResponseBody, 0, len(previews))}
+	for _, preview := range previews {
+		body.Previews = append(body.Previews, h.newPreviewResponseBody(preview))
+	}
+
+	return &ListPreviewsResponse{Body: body}, nil
+}
+
+type PreviewIDInput struct {
+	ProjectID string `path:"project_id" doc:"Unique identifier of the project" maxLength:"32"`
+	PreviewID string `path:"preview_id" doc:"Unique identifier of the preview environment" maxLength:"32"`
+}
+
+type PreviewResponse struct {
+	Body PreviewResponseBody `json:"body,inline"`
+}
+
+func (h *PreviewHandler) Get(ctx context.Context, input *PreviewIDInput) (*PreviewResponse, error) {
+	p, err := h.ownedProject(ctx, input.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+
+	preview, err := h.deploymentService.GetPreview(ctx, p.ID, input.PreviewID)
+	if err != nil {
+		return nil, deploymentError(err)
+	}
+
+	return &PreviewResponse{Body: h.newPreviewResponseBody(preview)}, nil
+}
+
+type DeletePreviewResponse struct{}
+
+// Delete tears a preview environment down as if its branch had been
+// deleted.
+func (h *PreviewHandler) Delete(ctx context.Context, input *PreviewIDInput) (*DeletePreviewResponse, error) {
+	p, err := h.ownedProject(ctx, input.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+
+	preview, err := h.deploymentService.GetPreview(ctx, p.ID, input.PreviewID)
+	if err != nil {
+		return nil, deploymentError(err)
+	}
+
+	if _, err := h.deploymentService.TeardownPreview(ctx, p.ID, preview.Branch); err != nil {
+		return nil, deploymentError(err)
+	}
+
+	return &DeletePreviewResponse{}, nil
+}
+
+func (h *PreviewHandler) ownedProject(ctx context.Context, projectID string) (sqlc.Project, error) {
+	userID, err := requireUserID(ctx)
+	if err != nil {
+		return sqlc.Project{}, err
+	}
+
+	p, err := h.projectService.Get(ctx, userID, projectID)
+	if err != nil {
+		return sqlc.Project{}, projectError(err)
+	}
+
+	return p, nil
+}
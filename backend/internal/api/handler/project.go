@@ -0,0 +1,248 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+
+	"github.com/Jesuloba-world/deployease/backend/internal/infrastructure/database/postgres/sqlc"
+	"github.com/Jesuloba-world/deployease/backend/internal/pagination"
+	"github.com/Jesuloba-world/deployease/backend/internal/project"
+)
+
+type ProjectHandler struct {
+	projectService *project.Service
+}
+
+func NewProjectHandler(projectService *project.Service) *ProjectHandler {
+	return &ProjectHandler{
+		projectService: projectService,
+	}
+}
+
+type ProjectResponseBody struct {
+	ID                 string    `json:"id" doc:"Unique identifier of the project" example:"V1StGXR8_Z5jdHi6B-myT"`
+	Name               string    `json:"name" doc:"Name of the project" example:"my-awesome-app"`
+	Description        string    `json:"description,omitempty" doc:"Description of the project" example:"A sample application"`
+	RepositoryURL      string    `json:"repository_url" doc:"Git repository the project is deployed from" example:"https://github.com/user/repo.git"`
+	Branch             string    `json:"branch" doc:"Branch whose pushes are deployed automatically" example:"main"`
+	Previews           bool      `json:"previews" doc:"Whether pushes to other branches are deployed to preview environments" example:"false"`
+	ActiveDeploymentID string    `json:"active_deployment_id,omitempty" doc:"Deployment currently serving the project's traffic" example:"deploy_789012"`
+	CreatedAt          time.Time `json:"created_at" doc:"Timestamp when the project was created" format:"date-time"`
+	UpdatedAt          time.Time `json:"updated_at" doc:"Timestamp when the project was last updated" format:"date-time"`
+}
+
+func newProjectResponseBody(p sqlc.Project) ProjectResponseBody {
+	return ProjectResponseBody{
+		ID:                 p.ID,
+		Name:               p.Name,
+		Description:        p.Description.String,
+		RepositoryURL:      p.RepositoryUrl,
+		Branch:             p.Branch,
+		Previews:           p.PreviewsEnabled,
+		ActiveDeploymentID: p.ActiveDeploymentID.String,
+		CreatedAt:          p.CreatedAt.Time,
+		UpdatedAt:          p.UpdatedAt.Time,
+	}
+}
+
+type ListProjectsInput struct {
+	Cursor string `query:"cursor" doc:"Opaque cursor returned as next_cursor by the previous page"`
+	Limit  int    `query:"limit" doc:"Maximum number of projects to return" default:"20" minimum:"1" maximum:"100"`
+}
+
+type ListProjectsResponseBody struct {
+	Projects   []ProjectResponseBody `json:"projects" doc:"Projects owned by the authenticated user, newest first"`
+	NextCursor string                `json:"next_cursor,omitempty" doc:"Cursor for the next page; omitted on the last page"`
+}
+
+type ListProjectsResponse struct {
+	Body ListProjectsResponseBody `json:"body,inline"`
+}
+
+func (h *ProjectHandler) List(ctx context.Context, input *ListProjectsInput) (*ListProjectsResponse, error) {
+	userID, err := requireUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := h.projectService.List(ctx, project.ListParams{
+		UserID: userID,
+		Cursor: input.Cursor,
+		Limit:  input.Limit,
+	})
+	if err != nil {
+		return nil, projectError(err)
+	}
+
+	body := ListProjectsResponseBody{
+		Projects:   make([]ProjectResponseBody, 0, len(page.Projects)),
+		NextCursor: page.NextCursor,
+	}
+	for _, p := range page.Projects {
+		body.Projects = append(body.Projects, newProjectResponseBody(p))
+	}
+
+	return &ListProjectsResponse{Body: body}, nil
+}
+
+type CreateProjectInput struct {
+	Body struct {
+		Name          string `json:"name" doc:"Name of the project" minLength:"1" maxLength:"255" example:"my-awesome-app"`
+		Description   string `json:"description,omitempty" doc:"Description of the project" maxLength:"2000"`
+		RepositoryURL string `json:"repository_url" doc:"Git URL of the repository to deploy" minLength:"1" example:"https://github.com/user/repo.git"`
+		Branch        string `json:"branch,omitempty" doc:"Branch whose pushes are deployed automatically; defaults to main" maxLength:"255" example:"main"`
+		Previews      bool   `json:"previews,omitempty" doc:"Deploy pushes to other branches to preview environments"`
+	}
+}
+
+type ProjectResponse struct {
+	Body ProjectResponseBody `json:"body,inline"`
+}
+
+func (h *ProjectHandler) Create(ctx context.Context, input *CreateProjectInput) (*ProjectResponse, error) {
+	userID, err := requireUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := h.projectService.Create(ctx, project.CreateParams{
+		UserID:        userID,
+		Name:          input.Body.Name,
+		Description:   input.Body.Description,
+		RepositoryURL: input.Body.RepositoryURL,
+		Branch:        input.Body.Branch,
+		Previews:      input.Body.Previews,
+	})
+	if err != nil {
+		return nil, projectError(err)
+	}
+
+	return &ProjectResponse{Body: newProjectResponseBody(p)}, nil
+}
+
+type ProjectIDInput struct {
+	ProjectID string `path:"project_id" doc:"Unique identifier of the project" maxLength:"32"`
+}
+
+func (h *ProjectHandler) Get(ctx context.Context, input *ProjectIDInput) (*ProjectResponse, error) {
+	userID, err := requireUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := h.projectService.Get(ctx, userID, input.ProjectID)
+	if err != nil {
+		return nil, projectError(err)
+	}
+
+	return &ProjectResponse{Body: newProjectResponseBody(p)}, nil
+}
+
+type UpdateProjectInput struct {
+	ProjectID string `path:"project_id" doc:"Unique identifier of the project" maxLength:"32"`
+	Body      struct {
+		Name          *string `json:"name,omitempty" doc:"New name of the project" minLength:"1" maxLength:"255"`
+		Description   *string `json:"description,omitempty" doc:"New description of the project" maxLength:"2000"`
+		RepositoryURL *string `json:"repository_url,omitempty" doc:"New git URL of the repository to deploy" minLength:"1"`
+		Branch        *string `json:"branch,omitempty" doc:"New branch to deploy pushes of" minLength:"1" maxLength:"255"`
+		Previews      *bool   `json:"previews,omitempty" doc:"Turn preview environments for other branches on or off"`
+	}
+}
+
+func (h *ProjectHandler) Update(ctx context.Context, input *UpdateProjectInput) (*ProjectResponse, error) {
+	userID, err := requireUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := h.projectService.Update(ctx, project.UpdateParams{
+		UserID:        userID,
+		ProjectID:     input.ProjectID,
+		Name:          input.Body.Name,
+		Description:   input.Body.Description,
+		RepositoryURL: input.Body.RepositoryURL,
+		Branch:        input.Body.Branch,
+		Previews:      input.Body.Previews,
+	})
+	if err != nil {
+		return nil, projectError(err)
+	}
+
+	return &ProjectResponse{Body: newProjectResponseBody(p)}, nil
+}
+
+type DeleteProjectResponse struct{}
+
+func (h *ProjectHandler) Delete(ctx context.Context, input *ProjectIDInput) (*DeleteProjectResponse, error) {
+	userID, err := requireUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := h.projectService.Delete(ctx, userID, input.ProjectID); err != nil {
+		return nil, projectError(err)
+	}
+
+	return &DeleteProjectResponse{}, nil
+}
+
+type WebhookSecretResponseBody struct {
+	Secret string `json:"secret" doc:"Secret the project's webhook deliveries must be signed with, or carry as the GitLab token" example:"q3J8vYk2LmT9wXbN5cR1hZ7dF4gS6pA0eU2iO8nK"`
+}
+
+type WebhookSecretResponse struct {
+	Body WebhookSecretResponseBody `json:"body,inline"`
+}
+
+func (h *ProjectHandler) GetWebhookSecret(ctx context.Context, input *ProjectIDInput) (*WebhookSecretResponse, error) {
+	userID, err := requireUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := h.projectService.Get(ctx, userID, input.ProjectID)
+	if err != nil {
+		return nil, projectError(err)
+	}
+	secret, err := h.projectService.WebhookSecret(p)
+	if err != nil {
+		return nil, projectError(err)
+	}
+
+	return &WebhookSecretResponse{Body: WebhookSecretResponseBody{Secret: secret}}, nil
+}
+
+func (h *ProjectHandler) RotateWebhookSecret(ctx context.Context, input *ProjectIDInput) (*WebhookSecretResponse, error) {
+	userID, err := requireUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := h.projectService.RotateWebhookSecret(ctx, userID, input.ProjectID)
+	if err != nil {
+		return nil, projectError(err)
+	}
+	secret, err := h.projectService.WebhookSecret(p)
+	if err != nil {
+		return nil, projectError(err)
+	}
+
+	return &WebhookSecretResponse{Body: WebhookSecretResponseBody{Secret: secret}}, nil
+}
+
+func projectError(err error) error {
+	switch {
+	case errors.Is(err, project.ErrNotFound), errors.Is(err, project.ErrNoWebhookSecret):
+		return huma.Error404NotFound(err.Error())
+	case errors.Is(err, project.ErrInvalidRepositoryURL), errors.Is(err, project.ErrInvalidBranch),
+		errors.Is(err, pagination.ErrInvalidCursor):
+		return huma.Error422UnprocessableEntity(err.Error())
+	default:
+		log.Printf("Project operation failed: %v", err)
+		return huma.Error500InternalServerError("project operation failed")
+	}
+}
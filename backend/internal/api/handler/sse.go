@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// sseWriter writes Server-Sent Events, flushing after each one. It is safe
+// for concurrent use so keep-alives can be sent while events are produced.
+type sseWriter struct {
+	mu  sync.Mutex
+	w   io.Writer
+	ctl *http.ResponseController
+}
+
+func newSSEWriter(w io.Writer) *sseWriter {
+	s := &sseWriter{w: w}
+	if rw, ok := w.(http.ResponseWriter); ok {
+		s.ctl = http.NewResponseController(rw)
+	}
+	return s
+}
+
+// event writes an event whose data is v encoded as JSON. An empty id leaves
+// the client's last event ID unchanged.
+func (s *sseWriter) event(id, name string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	var b strings.Builder
+	if id != "" {
+		fmt.Fprintf(&b, "id: %s\n", id)
+	}
+	fmt.Fprintf(&b, "event: %s\ndata: %s\n\n", name, data)
+	return s.write(b.String())
+}
+
+// comment writes a line clients ignore, which keeps idle connections from
+// being closed by proxies.
+func (s *sseWriter) comment(text string) error {
+	return s.write(": " + text + "\n\n")
+}
+
+func (s *sseWriter) write(frame string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := io.WriteString(s.w, frame); err != nil {
+		return err
+	}
+	if s.ctl != nil {
+		if err := s.ctl.Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+			return err
+		}
+	}
+	return nil
+}
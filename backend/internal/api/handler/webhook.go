@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/danielgtaylor/huma/v2"
+
+	"github.com/Jesuloba-world/deployease/backend/internal/webhook"
+)
+
+type WebhookHandler struct {
+	webhookService *webhook.Service
+}
+
+func NewWebhookHandler(webhookService *webhook.Service) *WebhookHandler {
+	return &WebhookHandler{
+		webhookService: webhookService,
+	}
+}
+
+type WebhookInput struct {
+	Provider string `path:"provider" doc:"Git provider sending the webhook" enum:"github,gitlab,bitbucket"`
+	// Signatures are computed over the body exactly as sent.
+	RawBody []byte
+
+	header http.Header
+}
+
+// Resolve keeps the request headers; each provider names its signature and
+// event headers differently.
+func (i *WebhookInput) Resolve(ctx huma.Context) []error {
+	i.header = http.Header{}
+	ctx.EachHeader(func(name, value string) {
+		i.header.Add(name, value)
+	})
+	return nil
+}
+
+type WebhookResponseBody struct {
+	Deployments []string `json:"deployments" doc:"Deployments started by the push"`
+	TornDown    []string `json:"torn_down,omitempty" doc:"Preview environments torn down by a deleted branch or closed pull request"`
+	Duplicate   bool     `json:"duplicate,omitempty" doc:"Set when the delivery had already been handled"`
+}
+
+type WebhookResponse struct {
+	Body WebhookResponseBody `json:"body,inline"`
+}
+
+func (h *WebhookHandler) Receive(ctx context.Context, input *WebhookInput) (*WebhookResponse, error) {
+	result, err := h.webhookService.Handle(ctx, input.Provider, input.header, input.RawBody)
+	if err != nil {
+		return nil, webhookError(err)
+	}
+
+	body := WebhookResponseBody{
+		Deployments: make([]string, 0, len(result.Deployments)),
+		Duplicate:   result.Duplicate,
+	}
+	for _, d := range result.Deployments {
+		body.Deployments = append(body.Deployments, d.ID)
+	}
+	for _, p := range result.TornDown {
+		body.TornDown = append(body.TornDown, p.ID)
+	}
+
+	return &WebhookResponse{Body: body}, nil
+}
+
+func webhookError(err error) error {
+	switch {
+	case errors.Is(err, webhook.ErrUnknownProvider):
+		return huma.Error404NotFound(err.Error())
+	case errors.Is(err, webhook.ErrInvalidSignature):
+		return huma.Error401Unauthorized(err.Error())
+	case errors.Is(err, webhook.ErrInvalidPayload):
+		return huma.Error400BadRequest(err.Error())
+	default:
+		log.Printf("Webhook delivery failed: %v", err)
+		return huma.Error500InternalServerError("webhook delivery failed")
+	}
+}
@@ -0,0 +1,51 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/danielgtaylor/huma/v2"
+
+	"github.com/Jesuloba-world/deployease/backend/internal/api/handler"
+)
+
+func RegisterAuthRoutes(humaAPI huma.API, authHandler *handler.AuthHandler) {
+	authGroup := huma.NewGroup(humaAPI, "/auth")
+
+	huma.Register(authGroup, huma.Operation{
+		OperationID:   "auth-register",
+		Method:        http.MethodPost,
+		Path:          "/register",
+		Summary:       "Register",
+		Description:   "Creates a new user account with a username, email and password",
+		Tags:          []string{"Authentication"},
+		DefaultStatus: http.StatusCreated,
+	}, authHandler.Register)
+
+	huma.Register(authGroup, huma.Operation{
+		OperationID: "auth-login",
+		Method:      http.MethodPost,
+		Path:        "/login",
+		Summary:     "Login",
+		Description: "Authenticates a user with their email and password",
+		Tags:        []string{"Authentication"},
+	}, authHandler.Login)
+
+	huma.Register(authGroup, huma.Operation{
+		OperationID: "auth-refresh",
+		Method:      http.MethodPost,
+		Path:        "/refresh",
+		Summary:     "Refresh Tokens",
+		Description: "Exchanges a refresh token for a new access token and a rotated refresh token",
+		Tags:        []string{"Authentication"},
+	}, authHandler.Refresh)
+
+	huma.Register(authGroup, huma.Operation{
+		OperationID: "auth-logout",
+		Method:      http.MethodPost,
+		Path:        "/logout",
+		Summary:     "Logout",
+		Description: "Revokes the caller's access token and refresh tokens, or ends their session",
+		Tags:        []string{"Authentication"},
+		Security:    authenticated,
+	}, authHandler.Logout)
+}
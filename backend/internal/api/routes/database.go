@@ -0,0 +1,115 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/danielgtaylor/huma/v2"
+
+	"github.com/Jesuloba-world/deployease/backend/internal/api/handler"
+)
+
+func RegisterDatabaseRoutes(humaAPI huma.API, databaseHandler *handler.DatabaseHandler) {
+	huma.Register(humaAPI, huma.Operation{
+		OperationID: "list-databases",
+		Method:      http.MethodGet,
+		Path:        "/projects/{project_id}/databases",
+		Summary:     "List Databases",
+		Description: "Returns the managed databases of a project",
+		Tags:        []string{"Databases"},
+		Security:    authenticated,
+	}, databaseHandler.List)
+
+	huma.Register(humaAPI, huma.Operation{
+		OperationID:   "create-database",
+		Method:        http.MethodPost,
+		Path:          "/projects/{project_id}/databases",
+		Summary:       "Create Database",
+		Description:   "Provisions a PostgreSQL or Redis database for a project; its deployments receive the connection string once it is running",
+		Tags:          []string{"Databases"},
+		Security:      authenticated,
+		DefaultStatus: http.StatusCreated,
+	}, databaseHandler.Create)
+
+	huma.Register(humaAPI, huma.Operation{
+		OperationID: "get-database",
+		Method:      http.MethodGet,
+		Path:        "/projects/{project_id}/databases/{database_id}",
+		Summary:     "Get Database",
+		Description: "Returns a single managed database of a project",
+		Tags:        []string{"Databases"},
+		Security:    authenticated,
+	}, databaseHandler.Get)
+
+	huma.Register(humaAPI, huma.Operation{
+		OperationID: "update-database",
+		Method:      http.MethodPatch,
+		Path:        "/projects/{project_id}/databases/{database_id}",
+		Summary:     "Update Database",
+		Description: "Changes the backup schedule and retention of a PostgreSQL database",
+		Tags:        []string{"Databases"},
+		Security:    authenticated,
+	}, databaseHandler.Update)
+
+	huma.Register(humaAPI, huma.Operation{
+		OperationID:   "delete-database",
+		Method:        http.MethodDelete,
+		Path:          "/projects/{project_id}/databases/{database_id}",
+		Summary:       "Delete Database",
+		Description:   "Deletes a managed database and its data",
+		Tags:          []string{"Databases"},
+		Security:      authenticated,
+		DefaultStatus: http.StatusAccepted,
+	}, databaseHandler.Delete)
+
+	huma.Register(humaAPI, huma.Operation{
+		OperationID: "list-database-backups",
+		Method:      http.MethodGet,
+		Path:        "/projects/{project_id}/databases/{database_id}/backups",
+		Summary:     "List Database Backups",
+		Description: "Returns the backups of a PostgreSQL database, newest first",
+		Tags:        []string{"Databases"},
+		Security:    authenticated,
+	}, databaseHandler.ListBackups)
+
+	huma.Register(humaAPI, huma.Operation{
+		OperationID:   "create-database-backup",
+		Method:        http.MethodPost,
+		Path:          "/projects/{project_id}/databases/{database_id}/backups",
+		Summary:       "Back Up Database",
+		Description:   "Queues a backup of a running PostgreSQL database outside its schedule",
+		Tags:          []string{"Databases"},
+		Security:      authenticated,
+		DefaultStatus: http.StatusAccepted,
+	}, databaseHandler.CreateBackup)
+
+	huma.Register(humaAPI, huma.Operation{
+		OperationID:   "restore-database",
+		Method:        http.MethodPost,
+		Path:          "/projects/{project_id}/databases/{database_id}/restore",
+		Summary:       "Restore Database",
+		Description:   "Queues restoring a backup into a running PostgreSQL database, replacing its contents; returns the restore to follow its progress",
+		Tags:          []string{"Databases"},
+		Security:      authenticated,
+		DefaultStatus: http.StatusAccepted,
+	}, databaseHandler.Restore)
+
+	huma.Register(humaAPI, huma.Operation{
+		OperationID: "list-database-restores",
+		Method:      http.MethodGet,
+		Path:        "/projects/{project_id}/databases/{database_id}/restores",
+		Summary:     "List Database Restores",
+		Description: "Returns the restores of a database, newest first",
+		Tags:        []string{"Databases"},
+		Security:    authenticated,
+	}, databaseHandler.ListRestores)
+
+	huma.Register(humaAPI, huma.Operation{
+		OperationID: "get-database-restore",
+		Method:      http.MethodGet,
+		Path:        "/projects/{project_id}/databases/{database_id}/restores/{restore_id}",
+		Summary:     "Get Database Restore",
+		Description: "Returns the status and progress of a restore",
+		Tags:        []string{"Databases"},
+		Security:    authenticated,
+	}, databaseHandler.GetRestore)
+}
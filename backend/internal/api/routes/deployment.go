@@ -0,0 +1,93 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/danielgtaylor/huma/v2"
+
+	"github.com/Jesuloba-world/deployease/backend/internal/api/handler"
+)
+
+func RegisterDeploymentRoutes(humaAPI huma.API, deploymentHandler *handler.DeploymentHandler) {
+	huma.Register(humaAPI, huma.Operation{
+		OperationID:   "create-deployment",
+		Method:        http.MethodPost,
+		Path:          "/projects/{project_id}/deployments",
+		Summary:       "Trigger Deployment",
+		Description:   "Enqueues a new pending deployment of the given commit",
+		Tags:          []string{"Deployments"},
+		Security:      authenticated,
+		DefaultStatus: http.StatusCreated,
+	}, deploymentHandler.Create)
+
+	huma.Register(humaAPI, huma.Operation{
+		OperationID: "list-deployments",
+		Method:      http.MethodGet,
+		Path:        "/projects/{project_id}/deployments",
+		Summary:     "List Deployments",
+		Description: "Returns the deployment history of a project, newest first, optionally filtered by status",
+		Tags:        []string{"Deployments"},
+		Security:    authenticated,
+	}, deploymentHandler.List)
+
+	huma.Register(humaAPI, huma.Operation{
+		OperationID: "get-deployment",
+		Method:      http.MethodGet,
+		Path:        "/projects/{project_id}/deployments/{deployment_id}",
+		Summary:     "Get Deployment",
+		Description: "Returns a single deployment of a project",
+		Tags:        []string{"Deployments"},
+		Security:    authenticated,
+	}, deploymentHandler.Get)
+
+	huma.Register(humaAPI, huma.Operation{
+		OperationID: "cancel-deployment",
+		Method:      http.MethodPost,
+		Path:        "/projects/{project_id}/deployments/{deployment_id}/cancel",
+		Summary:     "Cancel Deployment",
+		Description: "Cancels a pending or in-progress deployment. Returns 409 if the deployment already finished",
+		Tags:        []string{"Deployments"},
+		Security:    authenticated,
+	}, deploymentHandler.Cancel)
+
+	huma.Register(humaAPI, huma.Operation{
+		OperationID:   "rollback-deployment",
+		Method:        http.MethodPost,
+		Path:          "/projects/{project_id}/deployments/{deployment_id}/rollback",
+		Summary:       "Roll Back to Deployment",
+		Description:   "Creates a new deployment that re-runs the image of an earlier successful deployment. Returns 409 if the deployment never went live or is already active",
+		Tags:          []string{"Deployments"},
+		Security:      authenticated,
+		DefaultStatus: http.StatusCreated,
+	}, deploymentHandler.Rollback)
+
+	huma.Register(humaAPI, huma.Operation{
+		OperationID: "get-deployment-logs",
+		Method:      http.MethodGet,
+		Path:        "/projects/{project_id}/deployments/{deployment_id}/logs",
+		Summary:     "Stream Deployment Logs",
+		Description: "Streams build, rollout and runtime output as Server-Sent Events. Send the ID of the last event received as Last-Event-ID to resume",
+		Tags:        []string{"Deployments"},
+		Security:    authenticated,
+		Responses: map[string]*huma.Response{
+			"200": {
+				Description: "A stream of log events, followed by an end event carrying the deployment's status",
+				Content: map[string]*huma.MediaType{
+					"text/event-stream": {
+						Schema: &huma.Schema{Type: huma.TypeString},
+					},
+				},
+			},
+		},
+	}, deploymentHandler.Logs)
+
+	huma.Register(humaAPI, huma.Operation{
+		OperationID: "search-logs",
+		Method:      http.MethodGet,
+		Path:        "/projects/{project_id}/logs",
+		Summary:     "Search Logs",
+		Description: "Searches the stored build, rollout and runtime logs of a project's deployments, newest first. Logs are kept for the configured retention period",
+		Tags:        []string{"Deployments"},
+		Security:    authenticated,
+	}, deploymentHandler.SearchLogs)
+}
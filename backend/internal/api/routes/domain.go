@@ -0,0 +1,73 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/danielgtaylor/huma/v2"
+
+	"github.com/Jesuloba-world/deployease/backend/internal/api/handler"
+)
+
+func RegisterDomainRoutes(humaAPI huma.API, domainHandler *handler.DomainHandler) {
+	huma.Register(humaAPI, huma.Operation{
+		OperationID: "list-domains",
+		Method:      http.MethodGet,
+		Path:        "/projects/{project_id}/domains",
+		Summary:     "List Domains",
+		Description: "Returns the custom domains attached to a project",
+		Tags:        []string{"Domains"},
+		Security:    authenticated,
+	}, domainHandler.List)
+
+	huma.Register(humaAPI, huma.Operation{
+		OperationID:   "create-domain",
+		Method:        http.MethodPost,
+		Path:          "/projects/{project_id}/domains",
+		Summary:       "Add Domain",
+		Description:   "Attaches a custom domain to a project, pending verification of the DNS TXT record in the response",
+		Tags:          []string{"Domains"},
+		Security:      authenticated,
+		DefaultStatus: http.StatusCreated,
+	}, domainHandler.Create)
+
+	huma.Register(humaAPI, huma.Operation{
+		OperationID: "get-domain",
+		Method:      http.MethodGet,
+		Path:        "/projects/{project_id}/domains/{domain_id}",
+		Summary:     "Get Domain",
+		Description: "Returns a single custom domain of a project",
+		Tags:        []string{"Domains"},
+		Security:    authenticated,
+	}, domainHandler.Get)
+
+	huma.Register(humaAPI, huma.Operation{
+		OperationID: "verify-domain",
+		Method:      http.MethodPost,
+		Path:        "/projects/{project_id}/domains/{domain_id}/verify",
+		Summary:     "Verify Domain",
+		Description: "Checks the domain's DNS TXT record right away instead of waiting for the next periodic check",
+		Tags:        []string{"Domains"},
+		Security:    authenticated,
+	}, domainHandler.Verify)
+
+	huma.Register(humaAPI, huma.Operation{
+		OperationID: "get-domain-certificate",
+		Method:      http.MethodGet,
+		Path:        "/projects/{project_id}/domains/{domain_id}/certificate",
+		Summary:     "Get Domain Certificate",
+		Description: "Returns the status of the TLS certificate issued for a verified domain",
+		Tags:        []string{"Domains"},
+		Security:    authenticated,
+	}, domainHandler.GetCertificate)
+
+	huma.Register(humaAPI, huma.Operation{
+		OperationID:   "delete-domain",
+		Method:        http.MethodDelete,
+		Path:          "/projects/{project_id}/domains/{domain_id}",
+		Summary:       "Remove Domain",
+		Description:   "Detaches a custom domain from a project",
+		Tags:          []string{"Domains"},
+		Security:      authenticated,
+		DefaultStatus: http.StatusNoContent,
+	}, domainHandler.Delete)
+}
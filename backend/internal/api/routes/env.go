@@ -0,0 +1,42 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/danielgtaylor/huma/v2"
+
+	"github.com/Jesuloba-world/deployease/backend/internal/api/handler"
+)
+
+func RegisterEnvRoutes(humaAPI huma.API, envHandler *handler.EnvHandler) {
+	huma.Register(humaAPI, huma.Operation{
+		OperationID: "list-env-vars",
+		Method:      http.MethodGet,
+		Path:        "/projects/{project_id}/env",
+		Summary:     "List Environment Variables",
+		Description: "Returns the environment variables a project's containers run with; secret values are never returned",
+		Tags:        []string{"Environment"},
+		Security:    authenticated,
+	}, envHandler.List)
+
+	huma.Register(humaAPI, huma.Operation{
+		OperationID: "set-env-var",
+		Method:      http.MethodPut,
+		Path:        "/projects/{project_id}/env/{key}",
+		Summary:     "Set Environment Variable",
+		Description: "Creates or replaces an environment variable, optionally redeploying the project so it takes effect",
+		Tags:        []string{"Environment"},
+		Security:    authenticated,
+	}, envHandler.Set)
+
+	huma.Register(humaAPI, huma.Operation{
+		OperationID:   "delete-env-var",
+		Method:        http.MethodDelete,
+		Path:          "/projects/{project_id}/env/{key}",
+		Summary:       "Delete Environment Variable",
+		Description:   "Deletes an environment variable, optionally redeploying the project so it takes effect",
+		Tags:          []string{"Environment"},
+		Security:      authenticated,
+		DefaultStatus: http.StatusNoContent,
+	}, envHandler.Delete)
+}
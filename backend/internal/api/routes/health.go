@@ -6,7 +6,6 @@ import (
 	"github.com/danielgtaylor/huma/v2"
 
 	"github.com/Jesuloba-world/deployease/backend/internal/api/handler"
-
 )
 
 func RegisterHealthRoutes(humaAPI huma.API, healthHandler *handler.HealthHandler) {
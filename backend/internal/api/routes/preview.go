@@ -0,0 +1,42 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/danielgtaylor/huma/v2"
+
+	"github.com/Jesuloba-world/deployease/backend/internal/api/handler"
+)
+
+func RegisterPreviewRoutes(humaAPI huma.API, previewHandler *handler.PreviewHandler) {
+	huma.Register(humaAPI, huma.Operation{
+		OperationID: "list-previews",
+		Method:      http.MethodGet,
+		Path:        "/projects/{project_id}/previews",
+		Summary:     "List Preview Environments",
+		Description: "Returns the preview environments the project's branches are deployed to",
+		Tags:        []string{"Previews"},
+		Security:    authenticated,
+	}, previewHandler.List)
+
+	huma.Register(humaAPI, huma.Operation{
+		OperationID: "get-preview",
+		Method:      http.MethodGet,
+		Path:        "/projects/{project_id}/previews/{preview_id}",
+		Summary:     "Get Preview Environment",
+		Description: "Returns a single preview environment of a project",
+		Tags:        []string{"Previews"},
+		Security:    authenticated,
+	}, previewHandler.Get)
+
+	huma.Register(humaAPI, huma.Operation{
+		OperationID:   "delete-preview",
+		Method:        http.MethodDelete,
+		Path:          "/projects/{project_id}/previews/{preview_id}",
+		Summary:       "Tear Down Preview Environment",
+		Description:   "Cancels the environment's unfinished deployments, stops its containers and removes it",
+		Tags:          []string{"Previews"},
+		Security:      authenticated,
+		DefaultStatus: http.StatusNoContent,
+	}, previewHandler.Delete)
+}
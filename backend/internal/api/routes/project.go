@@ -0,0 +1,83 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/danielgtaylor/huma/v2"
+
+	"github.com/Jesuloba-world/deployease/backend/internal/api/handler"
+)
+
+func RegisterProjectRoutes(humaAPI huma.API, projectHandler *handler.ProjectHandler) {
+	huma.Register(humaAPI, huma.Operation{
+		OperationID: "list-projects",
+		Method:      http.MethodGet,
+		Path:        "/projects",
+		Summary:     "List Projects",
+		Description: "Returns the authenticated user's projects, newest first, using cursor pagination",
+		Tags:        []string{"Projects"},
+		Security:    authenticated,
+	}, projectHandler.List)
+
+	huma.Register(humaAPI, huma.Operation{
+		OperationID:   "create-project",
+		Method:        http.MethodPost,
+		Path:          "/projects",
+		Summary:       "Create Project",
+		Description:   "Creates a new project owned by the authenticated user",
+		Tags:          []string{"Projects"},
+		Security:      authenticated,
+		DefaultStatus: http.StatusCreated,
+	}, projectHandler.Create)
+
+	huma.Register(humaAPI, huma.Operation{
+		OperationID: "get-project",
+		Method:      http.MethodGet,
+		Path:        "/projects/{project_id}",
+		Summary:     "Get Project",
+		Description: "Returns a single project owned by the authenticated user",
+		Tags:        []string{"Projects"},
+		Security:    authenticated,
+	}, projectHandler.Get)
+
+	huma.Register(humaAPI, huma.Operation{
+		OperationID: "update-project",
+		Method:      http.MethodPatch,
+		Path:        "/projects/{project_id}",
+		Summary:     "Update Project",
+		Description: "Updates the provided fields of a project owned by the authenticated user",
+		Tags:        []string{"Projects"},
+		Security:    authenticated,
+	}, projectHandler.Update)
+
+	huma.Register(humaAPI, huma.Operation{
+		OperationID:   "delete-project",
+		Method:        http.MethodDelete,
+		Path:          "/projects/{project_id}",
+		Summary:       "Delete Project",
+		Description:   "Deletes a project owned by the authenticated user along with its deployments",
+		Tags:          []string{"Projects"},
+		Security:      authenticated,
+		DefaultStatus: http.StatusNoContent,
+	}, projectHandler.Delete)
+
+	huma.Register(humaAPI, huma.Operation{
+		OperationID: "get-project-webhook-secret",
+		Method:      http.MethodGet,
+		Path:        "/projects/{project_id}/webhook-secret",
+		Summary:     "Get Project Webhook Secret",
+		Description: "Returns the secret the project's git provider webhooks must be configured with",
+		Tags:        []string{"Projects"},
+		Security:    authenticated,
+	}, projectHandler.GetWebhookSecret)
+
+	huma.Register(humaAPI, huma.Operation{
+		OperationID: "rotate-project-webhook-secret",
+		Method:      http.MethodPost,
+		Path:        "/projects/{project_id}/webhook-secret/rotate",
+		Summary:     "Rotate Project Webhook Secret",
+		Description: "Replaces the project's webhook secret; deliveries signed with the old one are rejected from then on",
+		Tags:        []string{"Projects"},
+		Security:    authenticated,
+	}, projectHandler.RotateWebhookSecret)
+}
@@ -0,0 +1,13 @@
+package routes
+
+const (
+	BearerAuthScheme    = "bearerAuth"
+	SessionCookieScheme = "sessionCookie"
+)
+
+// authenticated is the security requirement for operations that need a
+// signed-in user. Either a bearer token or a session cookie satisfies it.
+var authenticated = []map[string][]string{
+	{BearerAuthScheme: {}},
+	{SessionCookieScheme: {}},
+}
@@ -0,0 +1,25 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/danielgtaylor/huma/v2"
+
+	"github.com/Jesuloba-world/deployease/backend/internal/api/handler"
+)
+
+// webhookMaxBodyBytes matches the largest payload GitHub delivers.
+const webhookMaxBodyBytes = 25 << 20
+
+func RegisterWebhookRoutes(humaAPI huma.API, webhookHandler *handler.WebhookHandler) {
+	huma.Register(humaAPI, huma.Operation{
+		OperationID:   "receive-webhook",
+		Method:        http.MethodPost,
+		Path:          "/webhooks/{provider}",
+		Summary:       "Receive Git Webhook",
+		Description:   "Verifies a push webhook from GitHub, GitLab or Bitbucket and deploys the pushed commit to every project that deploys the pushed branch of the repository",
+		Tags:          []string{"Webhooks"},
+		DefaultStatus: http.StatusAccepted,
+		MaxBodyBytes:  webhookMaxBodyBytes,
+	}, webhookHandler.Receive)
+}
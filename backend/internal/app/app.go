@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -14,32 +15,212 @@ import (
 
 	"github.com/Jesuloba-world/deployease/backend/internal/api"
 	"github.com/Jesuloba-world/deployease/backend/internal/app/middleware"
+	"github.com/Jesuloba-world/deployease/backend/internal/auth"
+	"github.com/Jesuloba-world/deployease/backend/internal/build"
+	"github.com/Jesuloba-world/deployease/backend/internal/certificate"
 	"github.com/Jesuloba-world/deployease/backend/internal/config"
+	"github.com/Jesuloba-world/deployease/backend/internal/container"
+	"github.com/Jesuloba-world/deployease/backend/internal/deployment"
+	"github.com/Jesuloba-world/deployease/backend/internal/domain"
+	"github.com/Jesuloba-world/deployease/backend/internal/envvar"
+	"github.com/Jesuloba-world/deployease/backend/internal/events"
+	"github.com/Jesuloba-world/deployease/backend/internal/infrastructure/database/dragonfly"
+	database "github.com/Jesuloba-world/deployease/backend/internal/infrastructure/database/postgres"
+	"github.com/Jesuloba-world/deployease/backend/internal/infrastructure/database/postgres/sqlc"
+	"github.com/Jesuloba-world/deployease/backend/internal/infrastructure/session"
+	"github.com/Jesuloba-world/deployease/backend/internal/jobs"
+	"github.com/Jesuloba-world/deployease/backend/internal/manageddb"
+	"github.com/Jesuloba-world/deployease/backend/internal/migrate"
+	"github.com/Jesuloba-world/deployease/backend/internal/objectstore"
+	"github.com/Jesuloba-world/deployease/backend/internal/proxy"
+	"github.com/Jesuloba-world/deployease/backend/internal/secrets"
 )
 
 type App struct {
 	config *config.Config
 	server *http.Server
-	router *bunrouter.Router
-	api    *api.API
+	// proxyServers are the edge proxy's HTTP and HTTPS listeners.
+	proxyServers []*http.Server
+	router       *bunrouter.Router
+	api          *api.API
+	db           *database.Manager
+
+	tokenService *auth.TokenService
+	sessionStore *session.Store
+	jobQueue     *jobs.Queue
+	worker       *jobs.Worker
+	broker       *events.Broker
+	logRetention *deployment.LogRetention
+	domainChecks *domain.Scheduler
+	backups      *manageddb.BackupScheduler
+	certificates *certificate.Scheduler
+	proxy        *proxy.Proxy
+	stopLogs     context.CancelFunc
 }
 
-func NewApp(cfg *config.Config) *App {
+func NewApp(cfg *config.Config) (*App, error) {
+	db, err := database.NewManager(&cfg.Database)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize database: %w", err)
+	}
+
+	if cfg.Database.AutoMigrate {
+		if err := autoMigrate(db, cfg.Database); err != nil {
+			db.Close()
+			return nil, err
+		}
+	}
+
+	if err := dragonfly.InitClient(cfg); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize dragonfly: %w", err)
+	}
+
+	tokenService, err := auth.NewTokenService(cfg.JWT)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create token service: %w", err)
+	}
+
+	sessionStore, err := session.NewStore()
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create session store: %w", err)
+	}
+
+	broker, err := events.NewBroker(cfg.Events)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create event broker: %w", err)
+	}
+
+	routes, err := proxy.NewNotifier()
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create route notifier: %w", err)
+	}
+
+	queries := sqlc.New(db.DBPool())
+	jobQueue := jobs.NewQueue(queries, cfg.Jobs.MaxAttempts)
+	worker := jobs.NewWorker(queries, cfg.Jobs)
+
+	cipher, err := secrets.NewCipher(cfg.Secrets)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create secrets cipher: %w", err)
+	}
+
+	runtime, err := container.NewDockerRuntime(cfg.Runtime)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create container runtime: %w", err)
+	}
+
+	objectStore, err := objectstore.New(cfg.Backups)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create backup store: %w", err)
+	}
+
+	deploymentService := deployment.NewService(db.DBPool(), queries, jobQueue, broker, routes)
+	pipeline := build.NewPipeline(build.NewDockerBuilder(cfg.Build.DockerBinary), cfg.Build)
+	prober := deployment.NewHTTPProber(cfg.Rollout.HealthPath, cfg.Rollout.ProbeInterval)
+	envService := envvar.NewService(queries, cipher)
+	databaseService := manageddb.NewService(db.DBPool(), queries, jobQueue, cipher, runtime, cfg.Runtime, cfg.ManagedDatabases,
+		objectStore, manageddb.NewRuntimeDumper(runtime), cfg.Backups)
+	// Variables set on the project override the databases' connection strings.
+	env := deployment.MergeEnv(databaseService, envService)
+	runner := deployment.NewRunner(deploymentService, pipeline, runtime, prober, env, cfg.Runtime, cfg.Rollout, cfg.Logs)
+	worker.Register(deployment.RunJobKind, runner.Handle)
+	worker.Register(deployment.RetireJobKind, runner.Retire)
+	worker.Register(deployment.VerifyJobKind, runner.Verify)
+	worker.Register(deployment.CollectLogsJobKind, runner.CollectLogs)
+	worker.Register(manageddb.ProvisionJobKind, databaseService.HandleProvision)
+	worker.Register(manageddb.DeprovisionJobKind, databaseService.HandleDeprovision)
+	worker.Register(manageddb.BackupJobKind, databaseService.HandleBackup)
+	worker.Register(manageddb.RestoreJobKind, databaseService.HandleRestore)
+
+	domainService := domain.NewService(queries, net.DefaultResolver, cfg.Domains, routes)
+	worker.Register(domain.CheckJobKind, domainService.HandleCheck)
+
+	certificateService := certificate.NewService(queries, cipher, cfg.ACME)
+	worker.Register(certificate.IssueJobKind, certificateService.HandleIssue)
+
+	edgeProxy, err := proxy.NewProxy(queries, runtime, prober, certificateService, certificate.NewChallengeHandler(queries), cfg.Server.Proxy, cfg.Runtime, cfg.Previews)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create edge proxy: %w", err)
+	}
+
 	router := bunrouter.New()
 
-	apiInstance := api.NewAPI(*cfg, router)
+	apiInstance := api.NewAPI(*cfg, router, api.Dependencies{
+		DB:           db,
+		TokenService: tokenService,
+		SessionStore: sessionStore,
+		JobQueue:     jobQueue,
+		Events:       broker,
+		Runtime:      runtime,
+		Cipher:       cipher,
+		Resolver:     net.DefaultResolver,
+		Routes:       routes,
+		ObjectStore:  objectStore,
+	})
 
 	return &App{
-		config: cfg,
-		router: router,
-		api:    apiInstance,
+		config:       cfg,
+		router:       router,
+		api:          apiInstance,
+		db:           db,
+		tokenService: tokenService,
+		sessionStore: sessionStore,
+		jobQueue:     jobQueue,
+		worker:       worker,
+		broker:       broker,
+		logRetention: deployment.NewLogRetention(db.DBPool(), cfg.Logs),
+		domainChecks: domain.NewScheduler(domainService, jobQueue),
+		backups:      manageddb.NewBackupScheduler(databaseService),
+		certificates: certificate.NewScheduler(certificateService, jobQueue),
+		proxy:        edgeProxy,
+	}, nil
+}
+
+// autoMigrate applies pending migrations. Replicas starting together take
+// turns on the migration lock, so each migration is applied once.
+func autoMigrate(db *database.Manager, cfg config.DatabaseConfig) error {
+	provider, err := migrate.New(db.DBPool(), cfg)
+	if err != nil {
+		return err
+	}
+	defer provider.Close()
+
+	applied, err := provider.Up(context.Background())
+	if err != nil {
+		return fmt.Errorf("failed to apply migrations: %w", err)
 	}
+	log.Printf("Database is up to date, applied %d migration(s)", len(applied))
+	return nil
 }
 
 func (a *App) Run() error {
 	a.setupMiddlewares()
 
-	a.api.InitializeAndRegisterRoutes()
+	if err := a.api.InitializeAndRegisterRoutes(); err != nil {
+		return err
+	}
+
+	if err := a.worker.Start(context.Background()); err != nil {
+		return fmt.Errorf("failed to start job worker: %w", err)
+	}
+
+	logsCtx, stopLogs := context.WithCancel(context.Background())
+	a.stopLogs = stopLogs
+	go a.logRetention.Run(logsCtx)
+	go a.domainChecks.Run(logsCtx)
+	go a.backups.Run(logsCtx)
+	if a.config.ACME.Enabled {
+		go a.certificates.Run(logsCtx)
+	}
 
 	a.server = &http.Server{
 		Addr:         fmt.Sprintf("%s:%s", a.config.Server.Host, a.config.Server.Port),
@@ -60,9 +241,52 @@ func (a *App) Run() error {
 		log.Println("Server stopped")
 	}()
 
+	if a.config.Server.Proxy.Enabled {
+		go a.proxy.Run(logsCtx)
+		a.startProxy()
+	}
+
 	return a.gracefulShutdown()
 }
 
+// startProxy starts the edge proxy's listeners. They have no write timeout:
+// proxied responses and WebSocket connections may stream for as long as the
+// application keeps them open.
+func (a *App) startProxy() {
+	cfg := a.config.Server.Proxy
+
+	a.proxyServers = append(a.proxyServers, &http.Server{
+		Addr:              net.JoinHostPort(cfg.Host, cfg.HTTPPort),
+		Handler:           a.proxy,
+		ReadHeaderTimeout: a.config.Server.ReadTimeout,
+		IdleTimeout:       a.config.Server.IdleTimeout,
+	})
+	if cfg.HTTPSPort != "" {
+		a.proxyServers = append(a.proxyServers, &http.Server{
+			Addr:              net.JoinHostPort(cfg.Host, cfg.HTTPSPort),
+			Handler:           a.proxy,
+			TLSConfig:         a.proxy.TLSConfig(),
+			ReadHeaderTimeout: a.config.Server.ReadTimeout,
+			IdleTimeout:       a.config.Server.IdleTimeout,
+		})
+	}
+
+	for _, server := range a.proxyServers {
+		go func() {
+			log.Printf("Starting edge proxy on %s", server.Addr)
+			var err error
+			if server.TLSConfig != nil {
+				err = server.ListenAndServeTLS("", "")
+			} else {
+				err = server.ListenAndServe()
+			}
+			if err != nil && err != http.ErrServerClosed {
+				log.Fatalf("Failed to start edge proxy: %v", err)
+			}
+		}()
+	}
+}
+
 func (a *App) gracefulShutdown() error {
 	// Wait for interrupt signal to gracefully shutdown the server
 	quit := make(chan os.Signal, 1)
@@ -80,10 +304,69 @@ func (a *App) gracefulShutdown() error {
 		return err
 	}
 
+	for _, server := range a.proxyServers {
+		if err := server.Shutdown(ctx); err != nil {
+			log.Printf("Edge proxy forced to shutdown: %v", err)
+		}
+	}
+
+	// Hijacked WebSocket connections are not covered by Shutdown
+	if err := a.broker.Close(); err != nil {
+		log.Printf("Failed to close event broker: %v", err)
+	}
+
+	// Stop maintaining log partitions, scheduling domain checks and
+	// certificate issuance and refreshing proxy routes
+	a.stopLogs()
+
+	a.drainWorker()
+	a.closeStores()
+
 	log.Println("Server exited gracefully")
 	return nil
 }
 
+// RunWorker processes background jobs without serving the API or the edge
+// proxy, so job processing can be scaled separately from serving. It
+// returns once an interrupt has let running jobs finish.
+func (a *App) RunWorker() error {
+	if err := a.worker.Start(context.Background()); err != nil {
+		return fmt.Errorf("failed to start job worker: %w", err)
+	}
+	log.Printf("Started job worker running up to %d jobs at a time", a.config.Jobs.Concurrency)
+	log.Printf("Environment: %s", a.config.Environment)
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+	log.Println("Shutting down job worker...")
+
+	a.drainWorker()
+	if err := a.broker.Close(); err != nil {
+		log.Printf("Failed to close event broker: %v", err)
+	}
+	a.closeStores()
+
+	log.Println("Job worker exited gracefully")
+	return nil
+}
+
+// drainWorker lets running jobs finish before the database goes away.
+func (a *App) drainWorker() {
+	ctx, cancel := context.WithTimeout(context.Background(), a.config.Jobs.ShutdownTimeout)
+	defer cancel()
+	if err := a.worker.Shutdown(ctx); err != nil {
+		log.Printf("Job worker forced to shutdown: %v", err)
+	}
+}
+
+func (a *App) closeStores() {
+	a.db.Close()
+	if err := dragonfly.CloseClient(); err != nil {
+		log.Printf("Failed to close Dragonfly client: %v", err)
+	}
+}
+
 func (a *App) setupMiddlewares() {
 	recovererConfig := middleware.DefaultRecovererConfig()
 	a.router.Use(middleware.Recoverer(recovererConfig))
@@ -99,4 +382,9 @@ func (a *App) setupMiddlewares() {
 
 	corsConfig := middleware.DefaultCORSConfig()
 	a.router.Use(middleware.CORS(corsConfig))
+
+	authConfig := middleware.DefaultAuthConfig(a.tokenService, a.sessionStore)
+	authConfig.CookieName = a.config.Session.CookieName
+	authConfig.QueryTokenParam = "token"
+	a.router.Use(middleware.Authenticate(authConfig))
 }
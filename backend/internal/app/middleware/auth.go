@@ -0,0 +1,121 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/uptrace/bunrouter"
+
+	"github.com/Jesuloba-world/deployease/backend/internal/auth"
+	"github.com/Jesuloba-world/deployease/backend/internal/infrastructure/session"
+)
+
+type TokenVerifier interface {
+	VerifyAccessToken(ctx context.Context, accessToken string) (*auth.AccessClaims, error)
+}
+
+type SessionGetter interface {
+	Get(ctx context.Context, sessionID string) (*session.Session, error)
+}
+
+type AuthConfig struct {
+	TokenVerifier TokenVerifier
+	SessionStore  SessionGetter
+	CookieName    string
+	// QueryTokenParam names a query parameter that may carry the access
+	// token on WebSocket upgrade requests, since browsers cannot set headers
+	// on those. Empty disables it.
+	QueryTokenParam string
+}
+
+func DefaultAuthConfig(tokenVerifier TokenVerifier, sessionStore SessionGetter) AuthConfig {
+	return AuthConfig{
+		TokenVerifier: tokenVerifier,
+		SessionStore:  sessionStore,
+		CookieName:    "deployease_session",
+	}
+}
+
+// Authenticate resolves the caller from a Bearer access token or a session
+// cookie and stores the resulting auth.Principal in the request context.
+// Requests without valid credentials are passed through unauthenticated;
+// enforcement is left to the operations that require it.
+func Authenticate(config AuthConfig) bunrouter.MiddlewareFunc {
+	return func(next bunrouter.HandlerFunc) bunrouter.HandlerFunc {
+		return func(w http.ResponseWriter, req bunrouter.Request) error {
+			ctx := req.Context()
+
+			if principal := resolvePrincipal(ctx, config, req); principal != nil {
+				req = req.WithContext(auth.WithPrincipal(ctx, principal))
+			}
+
+			return next(w, req)
+		}
+	}
+}
+
+func resolvePrincipal(ctx context.Context, config AuthConfig, req bunrouter.Request) *auth.Principal {
+	accessToken, ok := bearerToken(req.Header.Get("Authorization"))
+	if !ok {
+		accessToken, ok = queryToken(config, req)
+	}
+	if ok && config.TokenVerifier != nil {
+		claims, err := config.TokenVerifier.VerifyAccessToken(ctx, accessToken)
+		if err != nil {
+			if !errors.Is(err, auth.ErrInvalidToken) && !errors.Is(err, auth.ErrTokenRevoked) {
+				log.Printf("Failed to verify access token: %v", err)
+			}
+			return nil
+		}
+
+		return &auth.Principal{
+			UserID: claims.Subject,
+			Method: auth.AuthMethodBearer,
+			Claims: claims,
+		}
+	}
+
+	if config.SessionStore == nil {
+		return nil
+	}
+
+	cookie, err := req.Cookie(config.CookieName)
+	if err != nil || cookie.Value == "" {
+		return nil
+	}
+
+	sess, err := config.SessionStore.Get(ctx, cookie.Value)
+	if err != nil {
+		log.Printf("Failed to load session: %v", err)
+		return nil
+	}
+	if sess == nil {
+		return nil
+	}
+
+	return &auth.Principal{
+		UserID:    sess.UserID,
+		Method:    auth.AuthMethodSession,
+		SessionID: sess.ID,
+	}
+}
+
+func bearerToken(header string) (string, bool) {
+	scheme, token, ok := strings.Cut(header, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+func queryToken(config AuthConfig, req bunrouter.Request) (string, bool) {
+	if config.QueryTokenParam == "" || !strings.EqualFold(req.Header.Get("Upgrade"), "websocket") {
+		return "", false
+	}
+	token := req.URL.Query().Get(config.QueryTokenParam)
+	return token, token != ""
+}
@@ -0,0 +1,125 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bunrouter"
+
+	"github.com/Jesuloba-world/deployease/backend/internal/auth"
+	"github.com/Jesuloba-world/deployease/backend/internal/infrastructure/session"
+)
+
+type fakeTokenVerifier struct {
+	tokens map[string]*auth.AccessClaims
+}
+
+func (f *fakeTokenVerifier) VerifyAccessToken(ctx context.Context, accessToken string) (*auth.AccessClaims, error) {
+	claims, ok := f.tokens[accessToken]
+	if !ok {
+		return nil, auth.ErrInvalidToken
+	}
+	return claims, nil
+}
+
+type fakeSessionGetter struct {
+	sessions map[string]*session.Session
+}
+
+func (f *fakeSessionGetter) Get(ctx context.Context, sessionID string) (*session.Session, error) {
+	return f.sessions[sessionID], nil
+}
+
+func newAuthTestRouter(t *testing.T, got **auth.Principal) *bunrouter.Router {
+	t.Helper()
+
+	claims := &auth.AccessClaims{FamilyID: "family-1"}
+	claims.Subject = "user-bearer"
+
+	config := DefaultAuthConfig(
+		&fakeTokenVerifier{tokens: map[string]*auth.AccessClaims{"valid-token": claims}},
+		&fakeSessionGetter{sessions: map[string]*session.Session{
+			"valid-session": {ID: "valid-session", UserID: "user-session"},
+		}},
+	)
+
+	router := bunrouter.New(bunrouter.Use(Authenticate(config)))
+	router.GET("/", func(w http.ResponseWriter, req bunrouter.Request) error {
+		*got, _ = auth.PrincipalFromContext(req.Context())
+		return nil
+	})
+	return router
+}
+
+func TestAuthenticateBearerToken(t *testing.T) {
+	var principal *auth.Principal
+	router := newAuthTestRouter(t, &principal)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Authorization", "Bearer valid-token")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	require.NotNil(t, principal)
+	assert.Equal(t, "user-bearer", principal.UserID)
+	assert.Equal(t, auth.AuthMethodBearer, principal.Method)
+	assert.Equal(t, "family-1", principal.Claims.FamilyID)
+}
+
+func TestAuthenticateSessionCookie(t *testing.T) {
+	var principal *auth.Principal
+	router := newAuthTestRouter(t, &principal)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(&http.Cookie{Name: "deployease_session", Value: "valid-session"})
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	require.NotNil(t, principal)
+	assert.Equal(t, "user-session", principal.UserID)
+	assert.Equal(t, auth.AuthMethodSession, principal.Method)
+	assert.Equal(t, "valid-session", principal.SessionID)
+}
+
+func TestAuthenticateInvalidCredentials(t *testing.T) {
+	var principal *auth.Principal
+	router := newAuthTestRouter(t, &principal)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Authorization", "Bearer bogus")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+	assert.Nil(t, principal)
+
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(&http.Cookie{Name: "deployease_session", Value: "expired"})
+	router.ServeHTTP(httptest.NewRecorder(), req)
+	assert.Nil(t, principal)
+}
+
+func TestAuthenticateQueryTokenOnUpgrade(t *testing.T) {
+	claims := &auth.AccessClaims{}
+	claims.Subject = "user-ws"
+
+	config := DefaultAuthConfig(&fakeTokenVerifier{tokens: map[string]*auth.AccessClaims{"valid-token": claims}}, nil)
+	config.QueryTokenParam = "token"
+
+	var principal *auth.Principal
+	router := bunrouter.New(bunrouter.Use(Authenticate(config)))
+	router.GET("/ws", func(w http.ResponseWriter, req bunrouter.Request) error {
+		principal, _ = auth.PrincipalFromContext(req.Context())
+		return nil
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/ws?token=valid-token", nil)
+	router.ServeHTTP(httptest.NewRecorder(), req)
+	assert.Nil(t, principal, "query tokens are only accepted on WebSocket upgrades")
+
+	req = httptest.NewRequest(http.MethodGet, "/ws?token=valid-token", nil)
+	req.Header.Set("Upgrade", "websocket")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+	require.NotNil(t, principal)
+	assert.Equal(t, "user-ws", principal.UserID)
+	assert.Equal(t, auth.AuthMethodBearer, principal.Method)
+}
@@ -70,3 +70,9 @@ func (w *responseWriterWrapper) Write(data []byte) (int, error) {
 	}
 	return w.ResponseWriter.Write(data)
 }
+
+// Unwrap exposes the underlying writer to http.ResponseController and
+// WebSocket upgrades.
+func (w *responseWriterWrapper) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
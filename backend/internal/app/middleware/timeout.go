@@ -2,6 +2,8 @@ package middleware
 
 import (
 	"context"
+	"errors"
+	"log"
 	"net/http"
 	"time"
 
@@ -31,10 +33,14 @@ func NewTimeoutConfigFromServer(cfg *config.Config) TimeoutConfig {
 	}
 }
 
+type requestContextKey struct{}
+
 func Timeout(config TimeoutConfig) bunrouter.MiddlewareFunc {
 	return func(next bunrouter.HandlerFunc) bunrouter.HandlerFunc {
 		return func(w http.ResponseWriter, req bunrouter.Request) error {
 			ctx := req.Context()
+			// Remembered so Stream can escape the timeout.
+			ctx = context.WithValue(ctx, requestContextKey{}, ctx)
 			ctx, cancel := context.WithTimeout(ctx, config.Timeout)
 			defer cancel()
 
@@ -44,3 +50,25 @@ func Timeout(config TimeoutConfig) bunrouter.MiddlewareFunc {
 		}
 	}
 }
+
+// Stream exempts a long-lived response, such as an event stream or a
+// WebSocket, from the Timeout middleware and the server's write timeout. The
+// returned context keeps ctx's values but is only cancelled when the client
+// goes away; cancel it when the stream ends.
+func Stream(ctx context.Context, w http.ResponseWriter) (context.Context, context.CancelFunc) {
+	if err := http.NewResponseController(w).SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		log.Printf("Failed to clear write deadline for stream: %v", err)
+	}
+
+	parent, ok := ctx.Value(requestContextKey{}).(context.Context)
+	if !ok {
+		return context.WithCancel(ctx)
+	}
+
+	streamCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	stop := context.AfterFunc(parent, cancel)
+	return streamCtx, func() {
+		stop()
+		cancel()
+	}
+}
@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bunrouter"
+)
+
+type ctxKey struct{}
+
+func TestStreamOutlivesTimeout(t *testing.T) {
+	var streamCtx context.Context
+	var cancelStream context.CancelFunc
+
+	router := bunrouter.New(bunrouter.Use(Timeout(TimeoutConfig{Timeout: time.Millisecond})))
+	router.GET("/stream", func(w http.ResponseWriter, req bunrouter.Request) error {
+		streamCtx, cancelStream = Stream(req.Context(), w)
+		<-req.Context().Done()
+		return nil
+	})
+
+	ctx, cancelRequest := context.WithCancel(context.WithValue(context.Background(), ctxKey{}, "value"))
+	defer cancelRequest()
+
+	req := httptest.NewRequest(http.MethodGet, "/stream", nil).WithContext(ctx)
+	router.ServeHTTP(httptest.NewRecorder(), req)
+	require.NotNil(t, streamCtx)
+	defer cancelStream()
+
+	assert.NoError(t, streamCtx.Err(), "the timeout does not apply to streams")
+	assert.Equal(t, "value", streamCtx.Value(ctxKey{}))
+
+	cancelRequest()
+	select {
+	case <-streamCtx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("stream outlived its request")
+	}
+}
+
+func TestStreamWithoutTimeout(t *testing.T) {
+	ctx, cancel := Stream(context.Background(), httptest.NewRecorder())
+	require.NoError(t, ctx.Err())
+	cancel()
+	assert.Error(t, ctx.Err())
+}
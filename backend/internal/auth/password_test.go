@@ -0,0 +1,53 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestHashAndVerifyPassword(t *testing.T) {
+	hash, err := HashPassword("correct horse battery staple", DefaultArgon2Params())
+	require.NoError(t, err)
+	assert.Contains(t, hash, "$argon2id$v=19$")
+
+	ok, err := VerifyPassword("correct horse battery staple", hash)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = VerifyPassword("wrong password", hash)
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestHashPasswordUsesRandomSalt(t *testing.T) {
+	first, err := HashPassword("password123", DefaultArgon2Params())
+	require.NoError(t, err)
+	second, err := HashPassword("password123", DefaultArgon2Params())
+	require.NoError(t, err)
+
+	assert.NotEqual(t, first, second)
+}
+
+func TestVerifyPasswordBcrypt(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("legacy-password"), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	ok, err := VerifyPassword("legacy-password", string(hash))
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = VerifyPassword("not-it", string(hash))
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestVerifyPasswordInvalidHash(t *testing.T) {
+	_, err := VerifyPassword("password", "not-a-hash")
+	assert.ErrorIs(t, err, ErrInvalidHash)
+
+	_, err = VerifyPassword("password", "$argon2id$v=18$m=65536,t=1,p=4$c2FsdA$a2V5")
+	assert.ErrorIs(t, err, ErrIncompatibleVersion)
+}
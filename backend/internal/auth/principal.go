@@ -0,0 +1,31 @@
+package auth
+
+import "context"
+
+type AuthMethod string
+
+const (
+	AuthMethodBearer  AuthMethod = "bearer"
+	AuthMethodSession AuthMethod = "session"
+)
+
+// Principal identifies the authenticated caller of a request.
+type Principal struct {
+	UserID string
+	Method AuthMethod
+	// Claims is set when the caller authenticated with a bearer access token.
+	Claims *AccessClaims
+	// SessionID is set when the caller authenticated with a session cookie.
+	SessionID string
+}
+
+type principalContextKey struct{}
+
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalContextKey{}, principal)
+}
+
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(principalContextKey{}).(*Principal)
+	return principal, ok && principal != nil
+}
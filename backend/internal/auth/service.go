@@ -0,0 +1,113 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	gonanoid "github.com/matoous/go-nanoid/v2"
+
+	"github.com/Jesuloba-world/deployease/backend/internal/infrastructure/database/postgres/sqlc"
+)
+
+var (
+	ErrUsernameTaken      = errors.New("username is already taken")
+	ErrEmailTaken         = errors.New("email is already registered")
+	ErrInvalidCredentials = errors.New("invalid email or password")
+)
+
+const (
+	pgUniqueViolation = "23505"
+
+	usersUsernameConstraint = "users_username_key"
+	usersEmailConstraint    = "users_email_key"
+)
+
+type Service struct {
+	queries sqlc.Querier
+	params  Argon2Params
+	// dummyHash is verified against when a login targets an unknown email so
+	// that response timing does not reveal which accounts exist.
+	dummyHash string
+}
+
+func NewService(queries sqlc.Querier) (*Service, error) {
+	params := DefaultArgon2Params()
+
+	dummyHash, err := HashPassword("deployease-dummy-password", params)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Service{
+		queries:   queries,
+		params:    params,
+		dummyHash: dummyHash,
+	}, nil
+}
+
+type RegisterParams struct {
+	Username string
+	Email    string
+	Password string
+	// Admin is only set when bootstrapping accounts from the command line;
+	// sign-ups through the API never are.
+	Admin bool
+}
+
+func (s *Service) Register(ctx context.Context, params RegisterParams) (sqlc.User, error) {
+	passwordHash, err := HashPassword(params.Password, s.params)
+	if err != nil {
+		return sqlc.User{}, fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	user, err := s.queries.CreateUser(ctx, sqlc.CreateUserParams{
+		ID:           gonanoid.Must(),
+		Username:     strings.TrimSpace(params.Username),
+		Email:        NormalizeEmail(params.Email),
+		PasswordHash: passwordHash,
+		IsAdmin:      params.Admin,
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation {
+			switch pgErr.ConstraintName {
+			case usersUsernameConstraint:
+				return sqlc.User{}, ErrUsernameTaken
+			case usersEmailConstraint:
+				return sqlc.User{}, ErrEmailTaken
+			}
+		}
+		return sqlc.User{}, fmt.Errorf("failed to create user: %w", err)
+	}
+
+	return user, nil
+}
+
+func (s *Service) Authenticate(ctx context.Context, email, password string) (sqlc.User, error) {
+	user, err := s.queries.GetUserByEmail(ctx, NormalizeEmail(email))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			_, _ = VerifyPassword(password, s.dummyHash)
+			return sqlc.User{}, ErrInvalidCredentials
+		}
+		return sqlc.User{}, fmt.Errorf("failed to look up user: %w", err)
+	}
+
+	ok, err := VerifyPassword(password, user.PasswordHash)
+	if err != nil {
+		return sqlc.User{}, fmt.Errorf("failed to verify password: %w", err)
+	}
+	if !ok {
+		return sqlc.User{}, ErrInvalidCredentials
+	}
+
+	return user, nil
+}
+
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
@@ -0,0 +1,102 @@
+package auth
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Jesuloba-world/deployease/backend/internal/infrastructure/database/postgres/sqlc"
+)
+
+type fakeQuerier struct {
+	sqlc.Querier
+	users     map[string]sqlc.User
+	createErr error
+}
+
+func newFakeQuerier() *fakeQuerier {
+	return &fakeQuerier{users: map[string]sqlc.User{}}
+}
+
+func (f *fakeQuerier) CreateUser(ctx context.Context, arg sqlc.CreateUserParams) (sqlc.User, error) {
+	if f.createErr != nil {
+		return sqlc.User{}, f.createErr
+	}
+	user := sqlc.User{
+		ID:           arg.ID,
+		Username:     arg.Username,
+		Email:        arg.Email,
+		PasswordHash: arg.PasswordHash,
+		IsAdmin:      arg.IsAdmin,
+	}
+	f.users[arg.Email] = user
+	return user, nil
+}
+
+func (f *fakeQuerier) GetUserByEmail(ctx context.Context, email string) (sqlc.User, error) {
+	user, ok := f.users[email]
+	if !ok {
+		return sqlc.User{}, pgx.ErrNoRows
+	}
+	return user, nil
+}
+
+func TestServiceRegisterAndAuthenticate(t *testing.T) {
+	ctx := context.Background()
+	svc, err := NewService(newFakeQuerier())
+	require.NoError(t, err)
+
+	user, err := svc.Register(ctx, RegisterParams{
+		Username: "johndoe",
+		Email:    "  John@Example.com ",
+		Password: "supersecret",
+	})
+	require.NoError(t, err)
+	assert.NotEmpty(t, user.ID)
+	assert.Equal(t, "john@example.com", user.Email)
+	assert.NotEqual(t, "supersecret", user.PasswordHash)
+	assert.False(t, user.IsAdmin)
+
+	authenticated, err := svc.Authenticate(ctx, "JOHN@example.com", "supersecret")
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, authenticated.ID)
+
+	_, err = svc.Authenticate(ctx, "john@example.com", "wrong")
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+
+	_, err = svc.Authenticate(ctx, "nobody@example.com", "supersecret")
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+}
+
+func TestServiceRegisterAdmin(t *testing.T) {
+	svc, err := NewService(newFakeQuerier())
+	require.NoError(t, err)
+
+	user, err := svc.Register(context.Background(), RegisterParams{
+		Username: "admin",
+		Email:    "admin@example.com",
+		Password: "supersecret",
+		Admin:    true,
+	})
+	require.NoError(t, err)
+	assert.True(t, user.IsAdmin)
+}
+
+func TestServiceRegisterConflicts(t *testing.T) {
+	ctx := context.Background()
+	queries := newFakeQuerier()
+	svc, err := NewService(queries)
+	require.NoError(t, err)
+
+	queries.createErr = &pgconn.PgError{Code: "23505", ConstraintName: "users_username_key"}
+	_, err = svc.Register(ctx, RegisterParams{Username: "taken", Email: "a@example.com", Password: "password"})
+	assert.ErrorIs(t, err, ErrUsernameTaken)
+
+	queries.createErr = &pgconn.PgError{Code: "23505", ConstraintName: "users_email_key"}
+	_, err = svc.Register(ctx, RegisterParams{Username: "other", Email: "taken@example.com", Password: "password"})
+	assert.ErrorIs(t, err, ErrEmailTaken)
+}
@@ -0,0 +1,269 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	gonanoid "github.com/matoous/go-nanoid/v2"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/Jesuloba-world/deployease/backend/internal/config"
+	"github.com/Jesuloba-world/deployease/backend/internal/infrastructure/database/dragonfly"
+)
+
+const tokenIssuer = "deployease"
+
+var (
+	ErrClientNotInitialized = errors.New("Dragonfly client not initialized")
+	ErrInvalidToken         = errors.New("invalid or expired token")
+	ErrTokenRevoked         = errors.New("token has been revoked")
+	ErrInvalidRefreshToken  = errors.New("invalid or expired refresh token")
+	ErrRefreshTokenReused   = errors.New("refresh token reuse detected")
+)
+
+// consumeRefreshTokenScript atomically returns a refresh token record and
+// marks it as used, so two concurrent refreshes cannot both succeed.
+var consumeRefreshTokenScript = redis.NewScript(`
+local record = redis.call('HGETALL', KEYS[1])
+if #record == 0 then
+	return nil
+end
+redis.call('HINCRBY', KEYS[1], 'used', 1)
+return record
+`)
+
+// AccessClaims are the claims carried by a signed access token. FamilyID ties
+// the access token to the refresh token family it was issued with so both can
+// be revoked together.
+type AccessClaims struct {
+	FamilyID string `json:"fam"`
+	jwt.RegisteredClaims
+}
+
+type TokenPair struct {
+	AccessToken  string
+	RefreshToken string
+	ExpiresIn    time.Duration
+}
+
+type TokenService struct {
+	client     *redis.Client
+	secret     []byte
+	accessTTL  time.Duration
+	refreshTTL time.Duration
+	now        func() time.Time
+}
+
+func NewTokenService(cfg config.JWTConfig) (*TokenService, error) {
+	client := dragonfly.GetClient()
+	if client == nil {
+		return nil, ErrClientNotInitialized
+	}
+
+	if cfg.Secret == "" {
+		return nil, errors.New("JWT secret is required")
+	}
+
+	return &TokenService{
+		client:     client,
+		secret:     []byte(cfg.Secret),
+		accessTTL:  cfg.Expiration,
+		refreshTTL: cfg.RefreshExpiration,
+		now:        time.Now,
+	}, nil
+}
+
+// IssueTokens starts a new token family for the user and returns its first
+// access and refresh token.
+func (s *TokenService) IssueTokens(ctx context.Context, userID string) (*TokenPair, error) {
+	return s.issue(ctx, userID, gonanoid.Must())
+}
+
+// Refresh rotates a refresh token: the presented token is consumed and a new
+// pair in the same family is returned. Presenting a token that was already
+// consumed revokes the whole family, since it means the token was stolen.
+func (s *TokenService) Refresh(ctx context.Context, refreshToken string) (*TokenPair, error) {
+	key := refreshTokenKey(refreshToken)
+
+	record, err := consumeRefreshTokenScript.Run(ctx, s.client, []string{key}).StringSlice()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrInvalidRefreshToken
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to consume refresh token: %w", err)
+	}
+
+	fields := make(map[string]string, len(record)/2)
+	for i := 0; i+1 < len(record); i += 2 {
+		fields[record[i]] = record[i+1]
+	}
+
+	familyID := fields["family_id"]
+	if fields["used"] != "0" {
+		if err := s.RevokeFamily(ctx, familyID); err != nil {
+			return nil, err
+		}
+		return nil, ErrRefreshTokenReused
+	}
+
+	revoked, err := s.isFamilyRevoked(ctx, familyID)
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	return s.issue(ctx, fields["user_id"], familyID)
+}
+
+// VerifyAccessToken validates the signature and expiry of an access token and
+// checks that neither the token nor its family has been revoked.
+func (s *TokenService) VerifyAccessToken(ctx context.Context, accessToken string) (*AccessClaims, error) {
+	claims := &AccessClaims{}
+	_, err := jwt.ParseWithClaims(accessToken, claims, func(token *jwt.Token) (interface{}, error) {
+		return s.secret, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(tokenIssuer),
+		jwt.WithExpirationRequired(),
+		jwt.WithTimeFunc(s.now),
+	)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	n, err := s.client.Exists(ctx, revokedAccessKey(claims.ID), revokedFamilyKey(claims.FamilyID)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to check token revocation: %w", err)
+	}
+	if n > 0 {
+		return nil, ErrTokenRevoked
+	}
+
+	return claims, nil
+}
+
+// Revoke invalidates the given access token and every refresh token in its
+// family.
+func (s *TokenService) Revoke(ctx context.Context, claims *AccessClaims) error {
+	if ttl := claims.ExpiresAt.Sub(s.now()); ttl > 0 {
+		if err := s.client.Set(ctx, revokedAccessKey(claims.ID), 1, ttl).Err(); err != nil {
+			return fmt.Errorf("failed to revoke access token: %w", err)
+		}
+	}
+
+	return s.RevokeFamily(ctx, claims.FamilyID)
+}
+
+// RevokeFamily deletes every refresh token issued in the family and marks the
+// family as revoked so outstanding access tokens are rejected too.
+func (s *TokenService) RevokeFamily(ctx context.Context, familyID string) error {
+	familyKey := refreshFamilyKey(familyID)
+
+	tokenKeys, err := s.client.SMembers(ctx, familyKey).Result()
+	if err != nil {
+		return fmt.Errorf("failed to list refresh token family: %w", err)
+	}
+
+	_, err = s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, revokedFamilyKey(familyID), 1, s.refreshTTL)
+		if len(tokenKeys) > 0 {
+			pipe.Del(ctx, tokenKeys...)
+		}
+		pipe.Del(ctx, familyKey)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to revoke refresh token family: %w", err)
+	}
+
+	return nil
+}
+
+func (s *TokenService) issue(ctx context.Context, userID, familyID string) (*TokenPair, error) {
+	now := s.now()
+
+	claims := AccessClaims{
+		FamilyID: familyID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        gonanoid.Must(),
+			Subject:   userID,
+			Issuer:    tokenIssuer,
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(s.accessTTL)),
+		},
+	}
+
+	accessToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.secret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign access token: %w", err)
+	}
+
+	refreshToken, err := generateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+
+	key := refreshTokenKey(refreshToken)
+	familyKey := refreshFamilyKey(familyID)
+
+	_, err = s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, key, "user_id", userID, "family_id", familyID, "used", 0)
+		pipe.Expire(ctx, key, s.refreshTTL)
+		pipe.SAdd(ctx, familyKey, key)
+		pipe.Expire(ctx, familyKey, s.refreshTTL)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to store refresh token: %w", err)
+	}
+
+	return &TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    s.accessTTL,
+	}, nil
+}
+
+func (s *TokenService) isFamilyRevoked(ctx context.Context, familyID string) (bool, error) {
+	n, err := s.client.Exists(ctx, revokedFamilyKey(familyID)).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to check token family: %w", err)
+	}
+	return n > 0, nil
+}
+
+func generateOpaqueToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// refresh tokens are stored by hash so a leaked keyspace does not leak usable
+// tokens.
+func refreshTokenKey(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return fmt.Sprintf("refresh_token:%s", hex.EncodeToString(sum[:]))
+}
+
+func refreshFamilyKey(familyID string) string {
+	return fmt.Sprintf("refresh_family:%s", familyID)
+}
+
+func revokedFamilyKey(familyID string) string {
+	return fmt.Sprintf("revoked_family:%s", familyID)
+}
+
+func revokedAccessKey(tokenID string) string {
+	return fmt.Sprintf("revoked_access:%s", tokenID)
+}
@@ -0,0 +1,95 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
+
+	"github.com/Jesuloba-world/deployease/backend/internal/config"
+	"github.com/Jesuloba-world/deployease/backend/internal/infrastructure/database/dragonfly"
+)
+
+func setupTokenService(t *testing.T) *TokenService {
+	t.Helper()
+	testcontainers.SkipIfProviderIsNotHealthy(t)
+
+	ctx := context.Background()
+	container, err := dragonfly.StartDragonflyContainer(ctx)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		dragonfly.CloseClient()
+		container.Cleanup(ctx)
+	})
+
+	require.NoError(t, dragonfly.InitClient(container.GetConfig()))
+
+	svc, err := NewTokenService(config.JWTConfig{
+		Secret:            "test-secret",
+		Expiration:        15 * time.Minute,
+		RefreshExpiration: time.Hour,
+	})
+	require.NoError(t, err)
+	return svc
+}
+
+func TestTokenServiceIssueAndVerify(t *testing.T) {
+	ctx := context.Background()
+	svc := setupTokenService(t)
+
+	pair, err := svc.IssueTokens(ctx, "user-1")
+	require.NoError(t, err)
+	assert.Equal(t, 15*time.Minute, pair.ExpiresIn)
+
+	claims, err := svc.VerifyAccessToken(ctx, pair.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, "user-1", claims.Subject)
+	assert.NotEmpty(t, claims.FamilyID)
+
+	_, err = svc.VerifyAccessToken(ctx, pair.AccessToken+"x")
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestTokenServiceRefreshRotation(t *testing.T) {
+	ctx := context.Background()
+	svc := setupTokenService(t)
+
+	first, err := svc.IssueTokens(ctx, "user-1")
+	require.NoError(t, err)
+
+	second, err := svc.Refresh(ctx, first.RefreshToken)
+	require.NoError(t, err)
+	assert.NotEqual(t, first.RefreshToken, second.RefreshToken)
+
+	// replaying the consumed token revokes the whole family
+	_, err = svc.Refresh(ctx, first.RefreshToken)
+	assert.ErrorIs(t, err, ErrRefreshTokenReused)
+
+	_, err = svc.Refresh(ctx, second.RefreshToken)
+	assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+
+	_, err = svc.VerifyAccessToken(ctx, second.AccessToken)
+	assert.ErrorIs(t, err, ErrTokenRevoked)
+}
+
+func TestTokenServiceRevoke(t *testing.T) {
+	ctx := context.Background()
+	svc := setupTokenService(t)
+
+	pair, err := svc.IssueTokens(ctx, "user-1")
+	require.NoError(t, err)
+
+	claims, err := svc.VerifyAccessToken(ctx, pair.AccessToken)
+	require.NoError(t, err)
+
+	require.NoError(t, svc.Revoke(ctx, claims))
+
+	_, err = svc.VerifyAccessToken(ctx, pair.AccessToken)
+	assert.ErrorIs(t, err, ErrTokenRevoked)
+
+	_, err = svc.Refresh(ctx, pair.RefreshToken)
+	assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+}
@@ -0,0 +1,63 @@
+package build
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"sort"
+)
+
+// Spec is everything a Builder needs to produce an image.
+type Spec struct {
+	// ContextDir is the checked-out workspace used as the build context.
+	ContextDir string
+	// Dockerfile is the path of the Dockerfile relative to ContextDir.
+	Dockerfile string
+	// Tag is the image reference the result must be tagged with.
+	Tag    string
+	Labels map[string]string
+}
+
+// Builder turns a workspace into a tagged container image, writing its
+// progress to out as it goes.
+type Builder interface {
+	Build(ctx context.Context, spec Spec, out io.Writer) error
+}
+
+// DockerBuilder builds images with the docker CLI, so it works against
+// whatever engine the CLI is configured for, including BuildKit.
+type DockerBuilder struct {
+	Binary string
+}
+
+func NewDockerBuilder(binary string) *DockerBuilder {
+	if binary == "" {
+		binary = "docker"
+	}
+	return &DockerBuilder{Binary: binary}
+}
+
+func (b *DockerBuilder) Build(ctx context.Context, spec Spec, out io.Writer) error {
+	args := []string{"build", "--progress=plain", "--file", spec.Dockerfile, "--tag", spec.Tag}
+
+	keys := make([]string, 0, len(spec.Labels))
+	for k := range spec.Labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		args = append(args, "--label", fmt.Sprintf("%s=%s", k, spec.Labels[k]))
+	}
+	args = append(args, ".")
+
+	cmd := exec.CommandContext(ctx, b.Binary, args...)
+	cmd.Dir = spec.ContextDir
+	cmd.Stdout = out
+	cmd.Stderr = out
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("docker build failed: %w", err)
+	}
+	return nil
+}
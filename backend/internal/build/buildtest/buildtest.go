@@ -0,0 +1,126 @@
+// Package buildtest provides a local git remote and a fake Builder for tests
+// that exercise the build pipeline without network access or a container
+// engine.
+package buildtest
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/Jesuloba-world/deployease/backend/internal/build"
+)
+
+// BareRepo creates a bare git repository with one commit per entry in
+// commits, each mapping file paths to contents. It returns the repository path
+// and the commit hashes in order. The test is skipped if git is unavailable.
+func BareRepo(t *testing.T, commits ...map[string]string) (string, []string) {
+	t.Helper()
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	root := t.TempDir()
+	work := filepath.Join(root, "work")
+	bare := filepath.Join(root, "repo.git")
+
+	run(t, root, "init", "--quiet", "--initial-branch=main", work)
+
+	var hashes []string
+	for i, files := range commits {
+		for name, content := range files {
+			path := filepath.Join(work, name)
+			if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		run(t, work, "add", "--all")
+		run(t, work, "commit", "--quiet", "--allow-empty", "-m", fmt.Sprintf("commit %d", i+1))
+		hashes = append(hashes, strings.TrimSpace(run(t, work, "rev-parse", "HEAD")))
+	}
+
+	run(t, root, "clone", "--quiet", "--bare", work, bare)
+
+	return bare, hashes
+}
+
+func run(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+		"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com",
+	)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+	return string(out)
+}
+
+// Builder is a build.Builder that records what it was asked to build instead
+// of building it. Err, if set, is returned from every build.
+type Builder struct {
+	Output string
+	Err    error
+
+	mu     sync.Mutex
+	builds []Build
+}
+
+// Build is a snapshot of one call to Builder.Build, taken while the
+// workspace still existed.
+type Build struct {
+	Spec       build.Spec
+	Dockerfile string
+	Files      []string
+}
+
+func (b *Builder) Build(ctx context.Context, spec build.Spec, out io.Writer) error {
+	dockerfile, _ := os.ReadFile(filepath.Join(spec.ContextDir, spec.Dockerfile))
+
+	var files []string
+	_ = filepath.WalkDir(spec.ContextDir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && d.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		if !d.IsDir() {
+			rel, _ := filepath.Rel(spec.ContextDir, path)
+			files = append(files, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+
+	b.mu.Lock()
+	b.builds = append(b.builds, Build{Spec: spec, Dockerfile: string(dockerfile), Files: files})
+	b.mu.Unlock()
+
+	if b.Output != "" {
+		io.WriteString(out, b.Output)
+	}
+	return b.Err
+}
+
+// Builds returns every build requested so far.
+func (b *Builder) Builds() []Build {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]Build(nil), b.builds...)
+}
+
+var _ build.Builder = (*Builder)(nil)
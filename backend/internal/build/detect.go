@@ -0,0 +1,162 @@
+package build
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var ErrNoBuildStrategy = errors.New("no Dockerfile and no supported language detected")
+
+type Strategy string
+
+const (
+	StrategyDockerfile Strategy = "dockerfile"
+	StrategyGo         Strategy = "go"
+	StrategyNode       Strategy = "node"
+	StrategyPython     Strategy = "python"
+)
+
+// generatedDockerfile is written into the workspace when a project has no
+// Dockerfile of its own.
+const generatedDockerfile = ".deployease.Dockerfile"
+
+// Plan describes how a checked-out project will be built. Dockerfile is
+// relative to the workspace; for buildpack-style strategies Generated holds
+// the Dockerfile contents that must be written there first.
+type Plan struct {
+	Strategy   Strategy
+	Dockerfile string
+	Generated  string
+}
+
+// Detect inspects a workspace and picks a build strategy. A Dockerfile at the
+// root always wins; otherwise the project's language is inferred from its
+// manifest files.
+func Detect(dir string) (*Plan, error) {
+	if exists(dir, "Dockerfile") {
+		return &Plan{Strategy: StrategyDockerfile, Dockerfile: "Dockerfile"}, nil
+	}
+
+	web := procfileWeb(dir)
+
+	switch {
+	case exists(dir, "go.mod"):
+		return generated(StrategyGo, goDockerfile(web)), nil
+	case exists(dir, "package.json"):
+		return generated(StrategyNode, nodeDockerfile(dir, web)), nil
+	case exists(dir, "requirements.txt"), exists(dir, "pyproject.toml"):
+		content, err := pythonDockerfile(dir, web)
+		if err != nil {
+			return nil, err
+		}
+		return generated(StrategyPython, content), nil
+	}
+
+	return nil, ErrNoBuildStrategy
+}
+
+func generated(strategy Strategy, content string) *Plan {
+	return &Plan{Strategy: strategy, Dockerfile: generatedDockerfile, Generated: content}
+}
+
+func goDockerfile(web string) string {
+	cmd := `["/app/server"]`
+	if web != "" {
+		cmd = shellCmd(web)
+	}
+	return `FROM golang:1.24-alpine AS build
+WORKDIR /src
+COPY go.mod go.sum* ./
+RUN go mod download
+COPY . .
+RUN CGO_ENABLED=0 go build -o /app/server .
+
+FROM alpine:3.20
+WORKDIR /app
+COPY --from=build /app/server /app/server
+ENV PORT=8080
+EXPOSE 8080
+CMD ` + cmd + "\n"
+}
+
+func nodeDockerfile(dir, web string) string {
+	install := "npm install --omit=dev"
+	if exists(dir, "package-lock.json") {
+		install = "npm ci --omit=dev"
+	}
+	cmd := `["npm", "start"]`
+	if web != "" {
+		cmd = shellCmd(web)
+	}
+	return `FROM node:20-alpine
+WORKDIR /app
+COPY package*.json ./
+RUN ` + install + `
+COPY . .
+ENV NODE_ENV=production PORT=8080
+EXPOSE 8080
+CMD ` + cmd + "\n"
+}
+
+func pythonDockerfile(dir, web string) (string, error) {
+	install := "pip install --no-cache-dir -r requirements.txt"
+	if !exists(dir, "requirements.txt") {
+		install = "pip install --no-cache-dir ."
+	}
+
+	cmd := ""
+	switch {
+	case web != "":
+		cmd = shellCmd(web)
+	case exists(dir, "main.py"):
+		cmd = `["python", "main.py"]`
+	case exists(dir, "app.py"):
+		cmd = `["python", "app.py"]`
+	default:
+		return "", fmt.Errorf("%w: python project needs a Procfile web process, main.py or app.py", ErrNoBuildStrategy)
+	}
+
+	return `FROM python:3.12-slim
+WORKDIR /app
+COPY . .
+RUN ` + install + `
+ENV PYTHONUNBUFFERED=1 PORT=8080
+EXPOSE 8080
+CMD ` + cmd + "\n", nil
+}
+
+// procfileWeb returns the command of the web process in a Procfile, if any.
+func procfileWeb(dir string) string {
+	f, err := os.Open(filepath.Join(dir, "Procfile"))
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		name, cmd, ok := strings.Cut(scanner.Text(), ":")
+		if ok && strings.TrimSpace(name) == "web" {
+			return strings.TrimSpace(cmd)
+		}
+	}
+	return ""
+}
+
+// shellCmd renders cmd as an exec-form CMD that runs it through a shell. The
+// array is JSON-encoded because Docker parses exec form as JSON, which does
+// not accept every escape Go's %q produces.
+func shellCmd(cmd string) string {
+	b, _ := json.Marshal([]string{"/bin/sh", "-c", cmd})
+	return string(b)
+}
+
+func exists(dir, name string) bool {
+	info, err := os.Stat(filepath.Join(dir, name))
+	return err == nil && !info.IsDir()
+}
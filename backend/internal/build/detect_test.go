@@ -0,0 +1,111 @@
+package build
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+	}
+	return dir
+}
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string]string
+		strategy Strategy
+		contains []string
+	}{
+		{
+			name:     "dockerfile wins over manifests",
+			files:    map[string]string{"Dockerfile": "FROM scratch\n", "go.mod": "module x\n"},
+			strategy: StrategyDockerfile,
+		},
+		{
+			name:     "go module",
+			files:    map[string]string{"go.mod": "module x\n"},
+			strategy: StrategyGo,
+			contains: []string{"FROM golang:", "go build", `CMD ["/app/server"]`},
+		},
+		{
+			name:     "node with lockfile",
+			files:    map[string]string{"package.json": "{}", "package-lock.json": "{}"},
+			strategy: StrategyNode,
+			contains: []string{"FROM node:", "npm ci", `CMD ["npm", "start"]`},
+		},
+		{
+			name:     "node without lockfile",
+			files:    map[string]string{"package.json": "{}"},
+			strategy: StrategyNode,
+			contains: []string{"npm install"},
+		},
+		{
+			name:     "python with main.py",
+			files:    map[string]string{"requirements.txt": "flask\n", "main.py": ""},
+			strategy: StrategyPython,
+			contains: []string{"FROM python:", "-r requirements.txt", `CMD ["python", "main.py"]`},
+		},
+		{
+			name:     "procfile web process",
+			files:    map[string]string{"pyproject.toml": "", "Procfile": "worker: celery\nweb: gunicorn app:app\n"},
+			strategy: StrategyPython,
+			contains: []string{"pip install --no-cache-dir .", `CMD ["/bin/sh","-c","gunicorn app:app"]`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, err := Detect(writeFiles(t, tt.files))
+			require.NoError(t, err)
+			assert.Equal(t, tt.strategy, plan.Strategy)
+
+			if tt.strategy == StrategyDockerfile {
+				assert.Equal(t, "Dockerfile", plan.Dockerfile)
+				assert.Empty(t, plan.Generated)
+				return
+			}
+			assert.Equal(t, generatedDockerfile, plan.Dockerfile)
+			for _, s := range tt.contains {
+				assert.Contains(t, plan.Generated, s)
+			}
+		})
+	}
+}
+
+func TestDetectProcfileCmdIsJSON(t *testing.T) {
+	web := "gunicorn \"app:create_app()\" --log-level\tinfo \x01 \u00e9"
+	plan, err := Detect(writeFiles(t, map[string]string{
+		"requirements.txt": "",
+		"Procfile":         "web: " + web + "\n",
+	}))
+	require.NoError(t, err)
+
+	var line string
+	for _, l := range strings.Split(plan.Generated, "\n") {
+		if strings.HasPrefix(l, "CMD ") {
+			line = strings.TrimPrefix(l, "CMD ")
+		}
+	}
+	var args []string
+	require.NoError(t, json.Unmarshal([]byte(line), &args))
+	assert.Equal(t, []string{"/bin/sh", "-c", web}, args)
+}
+
+func TestDetectUnsupported(t *testing.T) {
+	_, err := Detect(writeFiles(t, map[string]string{"README.md": "hi"}))
+	assert.ErrorIs(t, err, ErrNoBuildStrategy)
+
+	_, err = Detect(writeFiles(t, map[string]string{"requirements.txt": ""}))
+	assert.ErrorIs(t, err, ErrNoBuildStrategy)
+}
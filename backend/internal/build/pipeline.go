@@ -0,0 +1,136 @@
+package build
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Jesuloba-world/deployease/backend/internal/config"
+)
+
+// Request identifies the source a deployment should be built from.
+type Request struct {
+	ProjectID     string
+	DeploymentID  string
+	RepositoryURL string
+	CommitHash    string
+}
+
+// Result is the outcome of a successful build.
+type Result struct {
+	Image    string
+	Commit   string
+	Strategy Strategy
+}
+
+// Pipeline checks out a deployment's commit into a scratch workspace, works
+// out how to build it and hands it to a Builder. The workspace is removed
+// when the run finishes, whether or not the build succeeded.
+type Pipeline struct {
+	builder     Builder
+	workDir     string
+	imagePrefix string
+	timeout     time.Duration
+}
+
+func NewPipeline(builder Builder, cfg config.BuildConfig) *Pipeline {
+	workDir := cfg.WorkDir
+	if workDir == "" {
+		workDir = os.TempDir()
+	}
+	return &Pipeline{
+		builder:     builder,
+		workDir:     workDir,
+		imagePrefix: cfg.ImagePrefix,
+		timeout:     cfg.Timeout,
+	}
+}
+
+// Run builds the requested commit, streaming checkout and build output to out.
+func (p *Pipeline) Run(ctx context.Context, req Request, out io.Writer) (*Result, error) {
+	if p.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.timeout)
+		defer cancel()
+	}
+
+	if err := os.MkdirAll(p.workDir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create build directory: %w", err)
+	}
+	workspace, err := os.MkdirTemp(p.workDir, "build-"+req.DeploymentID+"-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create workspace: %w", err)
+	}
+	defer os.RemoveAll(workspace)
+
+	src := filepath.Join(workspace, "src")
+
+	fmt.Fprintf(out, "==> Checking out %s at %s\n", req.RepositoryURL, req.CommitHash)
+	commit, err := Checkout(ctx, req.RepositoryURL, req.CommitHash, src, out)
+	if err != nil {
+		return nil, err
+	}
+
+	plan, err := Detect(src)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Fprintf(out, "==> Using %s build strategy\n", plan.Strategy)
+
+	if plan.Generated != "" {
+		if err := os.WriteFile(filepath.Join(src, plan.Dockerfile), []byte(plan.Generated), 0o644); err != nil {
+			return nil, fmt.Errorf("failed to write generated Dockerfile: %w", err)
+		}
+	}
+
+	image := p.imageRef(req)
+	fmt.Fprintf(out, "==> Building image %s\n", image)
+
+	err = p.builder.Build(ctx, Spec{
+		ContextDir: src,
+		Dockerfile: plan.Dockerfile,
+		Tag:        image,
+		Labels: map[string]string{
+			"io.deployease.project":    req.ProjectID,
+			"io.deployease.deployment": req.DeploymentID,
+			"io.deployease.commit":     commit,
+		},
+	}, out)
+	if err != nil {
+		return nil, err
+	}
+
+	fmt.Fprintf(out, "==> Built %s\n", image)
+
+	return &Result{
+		Image:    image,
+		Commit:   commit,
+		Strategy: plan.Strategy,
+	}, nil
+}
+
+// imageRef tags every deployment's image separately so earlier images stay
+// around for rollbacks. Repository names only allow lowercase alphanumerics
+// between separators, so the project ID is squashed to fit; the deployment ID
+// in the tag keeps references unique.
+func (p *Pipeline) imageRef(req Request) string {
+	repo := strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return -1
+	}, strings.ToLower(req.ProjectID))
+	if p.imagePrefix != "" {
+		repo = strings.TrimSuffix(p.imagePrefix, "/") + "/" + repo
+	}
+
+	tag := req.DeploymentID
+	if strings.HasPrefix(tag, "-") {
+		tag = "d" + tag
+	}
+	return fmt.Sprintf("%s:%s", repo, tag)
+}
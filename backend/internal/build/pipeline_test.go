@@ -0,0 +1,111 @@
+package build_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Jesuloba-world/deployease/backend/internal/build"
+	"github.com/Jesuloba-world/deployease/backend/internal/build/buildtest"
+	"github.com/Jesuloba-world/deployease/backend/internal/config"
+)
+
+func newPipeline(t *testing.T, builder build.Builder) (*build.Pipeline, string) {
+	t.Helper()
+	workDir := t.TempDir()
+	return build.NewPipeline(builder, config.BuildConfig{
+		WorkDir:     workDir,
+		ImagePrefix: "registry.local/deployease",
+		Timeout:     time.Minute,
+	}), workDir
+}
+
+func TestPipelineBuildsRequestedCommit(t *testing.T) {
+	repo, hashes := buildtest.BareRepo(t,
+		map[string]string{"go.mod": "module example.com/app\n", "main.go": "package main\n"},
+		map[string]string{"Dockerfile": "FROM scratch\n", "v2.txt": "second\n"},
+	)
+
+	builder := &buildtest.Builder{Output: "step 1/1\n"}
+	pipeline, workDir := newPipeline(t, builder)
+
+	var out bytes.Buffer
+	result, err := pipeline.Run(context.Background(), build.Request{
+		ProjectID:     "Proj_1",
+		DeploymentID:  "dep1",
+		RepositoryURL: repo,
+		CommitHash:    hashes[0][:7],
+	}, &out)
+	require.NoError(t, err)
+
+	assert.Equal(t, hashes[0], result.Commit)
+	assert.Equal(t, build.StrategyGo, result.Strategy)
+	assert.Equal(t, "registry.local/deployease/proj1:dep1", result.Image)
+	assert.Contains(t, out.String(), "step 1/1")
+
+	builds := builder.Builds()
+	require.Len(t, builds, 1)
+	assert.Equal(t, result.Image, builds[0].Spec.Tag)
+	assert.Equal(t, hashes[0], builds[0].Spec.Labels["io.deployease.commit"])
+	assert.Contains(t, builds[0].Dockerfile, "go build")
+	assert.Contains(t, builds[0].Files, "main.go")
+	assert.NotContains(t, builds[0].Files, "v2.txt", "later commits must not leak into the workspace")
+
+	result, err = pipeline.Run(context.Background(), build.Request{
+		ProjectID:     "Proj_1",
+		DeploymentID:  "dep2",
+		RepositoryURL: repo,
+		CommitHash:    hashes[1],
+	}, &out)
+	require.NoError(t, err)
+	assert.Equal(t, build.StrategyDockerfile, result.Strategy)
+	assert.Equal(t, "FROM scratch\n", builder.Builds()[1].Dockerfile)
+
+	entries, err := os.ReadDir(workDir)
+	require.NoError(t, err)
+	assert.Empty(t, entries, "workspaces are removed after the build")
+}
+
+func TestPipelineFailures(t *testing.T) {
+	repo, hashes := buildtest.BareRepo(t, map[string]string{"README.md": "nothing to build\n"})
+
+	builder := &buildtest.Builder{}
+	pipeline, workDir := newPipeline(t, builder)
+
+	_, err := pipeline.Run(context.Background(), build.Request{
+		ProjectID:     "p1",
+		DeploymentID:  "d1",
+		RepositoryURL: repo,
+		CommitHash:    "0000000",
+	}, &bytes.Buffer{})
+	assert.ErrorIs(t, err, build.ErrCheckoutFailed)
+
+	_, err = pipeline.Run(context.Background(), build.Request{
+		ProjectID:     "p1",
+		DeploymentID:  "d2",
+		RepositoryURL: repo,
+		CommitHash:    hashes[0],
+	}, &bytes.Buffer{})
+	assert.ErrorIs(t, err, build.ErrNoBuildStrategy)
+	assert.Empty(t, builder.Builds())
+
+	repo, hashes = buildtest.BareRepo(t, map[string]string{"Dockerfile": "FROM scratch\n"})
+	builder.Err = errors.New("engine unavailable")
+	_, err = pipeline.Run(context.Background(), build.Request{
+		ProjectID:     "p1",
+		DeploymentID:  "d3",
+		RepositoryURL: repo,
+		CommitHash:    hashes[0],
+	}, &bytes.Buffer{})
+	assert.ErrorIs(t, err, builder.Err)
+
+	entries, err := os.ReadDir(workDir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
@@ -0,0 +1,53 @@
+package build
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+var ErrCheckoutFailed = errors.New("failed to check out source")
+
+// Checkout clones repoURL into dir and detaches HEAD at commit, which may be
+// a full or abbreviated hash. Git's progress output is written to out. It
+// returns the full hash that was checked out.
+func Checkout(ctx context.Context, repoURL, commit, dir string, out io.Writer) (string, error) {
+	if strings.HasPrefix(commit, "-") {
+		return "", fmt.Errorf("%w: invalid commit %q", ErrCheckoutFailed, commit)
+	}
+
+	if err := git(ctx, "", out, "-c", "protocol.ext.allow=never", "clone", "--no-checkout", "--", repoURL, dir); err != nil {
+		return "", err
+	}
+
+	if err := git(ctx, dir, out, "checkout", "--detach", commit, "--"); err != nil {
+		return "", err
+	}
+
+	var head bytes.Buffer
+	if err := git(ctx, dir, &head, "rev-parse", "HEAD"); err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(head.String()), nil
+}
+
+func git(ctx context.Context, dir string, out io.Writer, args ...string) error {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	cmd.Stdout = out
+	cmd.Stderr = out
+	// Never block on a credential prompt; private repositories must be
+	// reachable with credentials the server already has.
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%w: git %s: %v", ErrCheckoutFailed, strings.Join(args, " "), err)
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package certificate
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"golang.org/x/crypto/acme"
+
+	"github.com/Jesuloba-world/deployease/backend/internal/infrastructure/database/postgres/sqlc"
+)
+
+// acmeClient returns a client for the configured directory, registering an
+// account the first time one is needed. The account is shared by every
+// instance: when two register at once, the one stored first wins.
+func (s *Service) acmeClient(ctx context.Context) (*acme.Client, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.client != nil {
+		return s.client, nil
+	}
+
+	account, err := s.queries.GetACMEAccount(ctx, s.cfg.DirectoryURL)
+	if errors.Is(err, pgx.ErrNoRows) {
+		account, err = s.register(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	keyPEM, err := s.cipher.Open(account.EncryptedKey, []byte(accountAssociatedData(account.DirectoryUrl)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt ACME account key: %w", err)
+	}
+	key, err := decodeKey(keyPEM)
+	if err != nil {
+		return nil, err
+	}
+
+	s.client = &acme.Client{
+		Key:          key,
+		KID:          acme.KeyID(account.Uri),
+		DirectoryURL: s.cfg.DirectoryURL,
+		HTTPClient:   s.httpClient,
+	}
+	return s.client, nil
+}
+
+// register creates an account with a fresh key, agreeing to the authority's
+// terms of service, and stores it.
+func (s *Service) register(ctx context.Context) (sqlc.AcmeAccount, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return sqlc.AcmeAccount{}, fmt.Errorf("failed to generate ACME account key: %w", err)
+	}
+
+	client := &acme.Client{
+		Key:          key,
+		DirectoryURL: s.cfg.DirectoryURL,
+		HTTPClient:   s.httpClient,
+	}
+	account := &acme.Account{}
+	if s.cfg.Email != "" {
+		account.Contact = []string{"mailto:" + s.cfg.Email}
+	}
+	if _, err := client.Register(ctx, account, acme.AcceptTOS); err != nil && !errors.Is(err, acme.ErrAccountAlreadyExists) {
+		return sqlc.AcmeAccount{}, fmt.Errorf("failed to register ACME account: %w", err)
+	}
+
+	keyPEM, err := encodeKey(key)
+	if err != nil {
+		return sqlc.AcmeAccount{}, err
+	}
+	sealed, err := s.cipher.Seal(keyPEM, []byte(accountAssociatedData(s.cfg.DirectoryURL)))
+	if err != nil {
+		return sqlc.AcmeAccount{}, fmt.Errorf("failed to encrypt ACME account key: %w", err)
+	}
+
+	stored, err := s.queries.CreateACMEAccount(ctx, sqlc.CreateACMEAccountParams{
+		DirectoryUrl: s.cfg.DirectoryURL,
+		Uri:          string(client.KID),
+		Email:        s.cfg.Email,
+		EncryptedKey: sealed,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		stored, err = s.queries.GetACMEAccount(ctx, s.cfg.DirectoryURL)
+	}
+	if err != nil {
+		return sqlc.AcmeAccount{}, fmt.Errorf("failed to store ACME account: %w", err)
+	}
+	return stored, nil
+}
+
+func encodeKey(key *ecdsa.PrivateKey) ([]byte, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode private key: %w", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
+
+func decodeKey(keyPEM []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(keyPEM)
+	if block == nil {
+		return nil, errors.New("private key is not PEM encoded")
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key: %w", err)
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported private key type %T", key)
+	}
+	return signer, nil
+}
+
+func accountAssociatedData(directoryURL string) string {
+	return "acme_accounts/" + directoryURL
+}
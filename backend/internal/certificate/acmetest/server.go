@@ -0,0 +1,707 @@
+// Package acmetest provides a small in-process ACME certificate authority for
+// tests, in the spirit of Let's Encrypt's Pebble. It implements the parts of
+// RFC 8555 the certificate package uses: account registration, orders,
+// HTTP-01 validation against a configurable address, finalization and
+// certificate download. Requests must carry a valid ES256 JWS and a nonce
+// the server handed out.
+package acmetest
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/acme"
+)
+
+const (
+	problemMalformed     = "urn:ietf:params:acme:error:malformed"
+	problemBadNonce      = "urn:ietf:params:acme:error:badNonce"
+	problemUnauthorized  = "urn:ietf:params:acme:error:unauthorized"
+	problemNoAccount     = "urn:ietf:params:acme:error:accountDoesNotExist"
+	problemOrderNotReady = "urn:ietf:params:acme:error:orderNotReady"
+	problemBadCSR        = "urn:ietf:params:acme:error:badCSR"
+	problemConnection    = "urn:ietf:params:acme:error:connection"
+)
+
+// Server is a test certificate authority. HTTP-01 challenges are validated
+// by requesting /.well-known/acme-challenge/<token> from ChallengeURL with
+// the Host header set to the domain being validated.
+type Server struct {
+	// ChallengeURL is the base URL challenge responses are fetched from.
+	ChallengeURL string
+	// CertLifetime is how long issued certificates are valid for. It
+	// defaults to 90 days, as with Let's Encrypt.
+	CertLifetime time.Duration
+
+	srv    *httptest.Server
+	caKey  *ecdsa.PrivateKey
+	caCert *x509.Certificate
+
+	mu       sync.Mutex
+	next     int
+	nonces   map[string]bool
+	accounts map[string]*account // by URL
+	orders   map[string]*order   // by URL
+	authzs   map[string]*authz   // by URL
+	issued   map[string][]byte   // certificate URL to DER
+}
+
+type account struct {
+	url        string
+	key        *ecdsa.PublicKey
+	thumbprint string
+	contact    []string
+}
+
+type order struct {
+	url         string
+	account     string
+	status      string
+	identifiers []identifier
+	authzs      []string
+	certificate string
+	notAfter    time.Time
+}
+
+type authz struct {
+	url        string
+	account    string
+	identifier identifier
+	status     string
+	challenge  challenge
+}
+
+type challenge struct {
+	url    string
+	token  string
+	status string
+	err    *problem
+}
+
+type identifier struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+type problem struct {
+	Type   string `json:"type"`
+	Detail string `json:"detail"`
+	status int
+}
+
+// NewServer starts a certificate authority that is shut down when the test
+// ends.
+func NewServer(t testing.TB, challengeURL string) *Server {
+	t.Helper()
+
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate CA key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "acmetest root"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(10 * 365 * 24 * time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatalf("failed to create CA certificate: %v", err)
+	}
+	caCert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("failed to parse CA certificate: %v", err)
+	}
+
+	s := &Server{
+		ChallengeURL: challengeURL,
+		CertLifetime: 90 * 24 * time.Hour,
+		caKey:        caKey,
+		caCert:       caCert,
+		nonces:       make(map[string]bool),
+		accounts:     make(map[string]*account),
+		orders:       make(map[string]*order),
+		authzs:       make(map[string]*authz),
+		issued:       make(map[string][]byte),
+	}
+	s.srv = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	t.Cleanup(s.srv.Close)
+	return s
+}
+
+// DirectoryURL is the URL ACME clients are configured with.
+func (s *Server) DirectoryURL() string {
+	return s.srv.URL + "/directory"
+}
+
+// Roots holds the authority's root certificate, for verifying issued
+// certificates.
+func (s *Server) Roots() *x509.CertPool {
+	pool := x509.NewCertPool()
+	pool.AddCert(s.caCert)
+	return pool
+}
+
+// Accounts returns how many accounts have been registered.
+func (s *Server) Accounts() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.accounts)
+}
+
+// Issued returns how many certificates have been issued.
+func (s *Server) Issued() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.issued)
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Replay-Nonce", s.newNonce())
+	w.Header().Set("Cache-Control", "no-store")
+
+	if r.URL.Path == "/directory" {
+		writeJSON(w, http.StatusOK, map[string]string{
+			"newNonce":   s.srv.URL + "/nonce",
+			"newAccount": s.srv.URL + "/account",
+			"newOrder":   s.srv.URL + "/order",
+			"revokeCert": s.srv.URL + "/revoke",
+			"keyChange":  s.srv.URL + "/key-change",
+		})
+		return
+	}
+	if r.URL.Path == "/nonce" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	req, p := s.verify(r)
+	if p != nil {
+		writeProblem(w, p)
+		return
+	}
+
+	switch {
+	case r.URL.Path == "/account":
+		s.newAccount(w, req)
+	case r.URL.Path == "/order":
+		s.newOrder(w, req)
+	case strings.HasPrefix(r.URL.Path, "/order/"):
+		s.getOrder(w, req)
+	case strings.HasPrefix(r.URL.Path, "/authz/"):
+		s.getAuthz(w, req)
+	case strings.HasPrefix(r.URL.Path, "/chal/"):
+		s.respondChallenge(w, req)
+	case strings.HasPrefix(r.URL.Path, "/finalize/"):
+		s.finalize(w, req)
+	case strings.HasPrefix(r.URL.Path, "/cert/"):
+		s.getCert(w, req)
+	default:
+		writeProblem(w, &problem{Type: problemMalformed, Detail: "unknown resource", status: http.StatusNotFound})
+	}
+}
+
+// request is a verified JWS request.
+type request struct {
+	url     string
+	payload []byte
+	// account is set for requests signed with an account's kid.
+	account *account
+	// jwk is set for requests carrying their key, which only creating an
+	// account does.
+	jwk *ecdsa.PublicKey
+}
+
+func (s *Server) verify(r *http.Request) (*request, *problem) {
+	var jws struct {
+		Protected string `json:"protected"`
+		Payload   string `json:"payload"`
+		Signature string `json:"signature"`
+	}
+	if err := json.NewDecoder(io.LimitReader(r.Body, 1<<20)).Decode(&jws); err != nil {
+		return nil, malformed("request is not a flattened JWS")
+	}
+
+	rawHeader, err := base64.RawURLEncoding.DecodeString(jws.Protected)
+	if err != nil {
+		return nil, malformed("protected header is not base64url")
+	}
+	var header struct {
+		Alg   string          `json:"alg"`
+		Nonce string          `json:"nonce"`
+		URL   string          `json:"url"`
+		KID   string          `json:"kid"`
+		JWK   json.RawMessage `json:"jwk"`
+	}
+	if err := json.Unmarshal(rawHeader, &header); err != nil {
+		return nil, malformed("protected header is not JSON")
+	}
+	if header.Alg != "ES256" {
+		return nil, malformed("only ES256 is supported")
+	}
+	if header.URL != s.srv.URL+r.URL.Path {
+		return nil, &problem{Type: problemUnauthorized, Detail: "url does not match the request", status: http.StatusUnauthorized}
+	}
+	if !s.useNonce(header.Nonce) {
+		return nil, &problem{Type: problemBadNonce, Detail: "unknown nonce", status: http.StatusBadRequest}
+	}
+
+	req := &request{url: header.URL}
+	var key *ecdsa.PublicKey
+	switch {
+	case header.KID != "" && header.JWK == nil:
+		s.mu.Lock()
+		req.account = s.accounts[header.KID]
+		s.mu.Unlock()
+		if req.account == nil {
+			return nil, &problem{Type: problemNoAccount, Detail: "unknown account", status: http.StatusBadRequest}
+		}
+		key = req.account.key
+	case header.KID == "" && header.JWK != nil:
+		key, err = parseJWK(header.JWK)
+		if err != nil {
+			return nil, malformed(err.Error())
+		}
+		req.jwk = key
+	default:
+		return nil, malformed("exactly one of kid and jwk must be set")
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(jws.Signature)
+	if err != nil || len(sig) != 64 {
+		return nil, malformed("signature is not a base64url ES256 signature")
+	}
+	digest := sha256.Sum256([]byte(jws.Protected + "." + jws.Payload))
+	rs, ss := new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:])
+	if !ecdsa.Verify(key, digest[:], rs, ss) {
+		return nil, &problem{Type: problemUnauthorized, Detail: "signature does not verify", status: http.StatusUnauthorized}
+	}
+
+	req.payload, err = base64.RawURLEncoding.DecodeString(jws.Payload)
+	if err != nil {
+		return nil, malformed("payload is not base64url")
+	}
+	return req, nil
+}
+
+func (s *Server) newAccount(w http.ResponseWriter, req *request) {
+	if req.jwk == nil {
+		writeProblem(w, malformed("new accounts must be requested with a jwk"))
+		return
+	}
+	var payload struct {
+		Contact            []string `json:"contact"`
+		OnlyReturnExisting bool     `json:"onlyReturnExisting"`
+	}
+	if err := json.Unmarshal(req.payload, &payload); err != nil {
+		writeProblem(w, malformed("account payload is not JSON"))
+		return
+	}
+	thumbprint, err := acme.JWKThumbprint(req.jwk)
+	if err != nil {
+		writeProblem(w, malformed(err.Error()))
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, a := range s.accounts {
+		if a.thumbprint == thumbprint {
+			w.Header().Set("Location", a.url)
+			writeJSON(w, http.StatusOK, accountJSON(a))
+			return
+		}
+	}
+	if payload.OnlyReturnExisting {
+		writeProblem(w, &problem{Type: problemNoAccount, Detail: "no account for this key", status: http.StatusBadRequest})
+		return
+	}
+
+	a := &account{url: s.newURL("account"), key: req.jwk, thumbprint: thumbprint, contact: payload.Contact}
+	s.accounts[a.url] = a
+	w.Header().Set("Location", a.url)
+	writeJSON(w, http.StatusCreated, accountJSON(a))
+}
+
+func (s *Server) newOrder(w http.ResponseWriter, req *request) {
+	if req.account == nil {
+		writeProblem(w, malformed("orders must be requested with a kid"))
+		return
+	}
+	var payload struct {
+		Identifiers []identifier `json:"identifiers"`
+	}
+	if err := json.Unmarshal(req.payload, &payload); err != nil || len(payload.Identifiers) == 0 {
+		writeProblem(w, malformed("order must name at least one identifier"))
+		return
+	}
+	for _, id := range payload.Identifiers {
+		if id.Type != "dns" || id.Value == "" || strings.HasPrefix(id.Value, "*.") {
+			writeProblem(w, &problem{Type: "urn:ietf:params:acme:error:rejectedIdentifier", Detail: "only DNS names are supported", status: http.StatusBadRequest})
+			return
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	o := &order{
+		url:         s.newURL("order"),
+		account:     req.account.url,
+		status:      acme.StatusPending,
+		identifiers: payload.Identifiers,
+	}
+	for _, id := range payload.Identifiers {
+		z := &authz{
+			url:        s.newURL("authz"),
+			account:    req.account.url,
+			identifier: id,
+			status:     acme.StatusPending,
+			challenge: challenge{
+				url:    s.newURL("chal"),
+				token:  randomToken(),
+				status: acme.StatusPending,
+			},
+		}
+		s.authzs[z.url] = z
+		o.authzs = append(o.authzs, z.url)
+	}
+	s.orders[o.url] = o
+
+	w.Header().Set("Location", o.url)
+	writeJSON(w, http.StatusCreated, s.orderJSON(o))
+}
+
+func (s *Server) getOrder(w http.ResponseWriter, req *request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	o, p := s.ownOrder(req, req.url)
+	if p != nil {
+		writeProblem(w, p)
+		return
+	}
+	w.Header().Set("Location", o.url)
+	writeJSON(w, http.StatusOK, s.orderJSON(o))
+}
+
+func (s *Server) getAuthz(w http.ResponseWriter, req *request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	z := s.authzs[req.url]
+	if z == nil || req.account == nil || z.account != req.account.url {
+		writeProblem(w, &problem{Type: problemMalformed, Detail: "unknown authorization", status: http.StatusNotFound})
+		return
+	}
+	writeJSON(w, http.StatusOK, authzJSON(z))
+}
+
+// respondChallenge validates the challenge right away, fetching the key
+// authorization over HTTP as a real authority would.
+func (s *Server) respondChallenge(w http.ResponseWriter, req *request) {
+	s.mu.Lock()
+	var z *authz
+	for _, candidate := range s.authzs {
+		if candidate.challenge.url == req.url {
+			z = candidate
+		}
+	}
+	if z == nil || req.account == nil || z.account != req.account.url {
+		s.mu.Unlock()
+		writeProblem(w, &problem{Type: problemMalformed, Detail: "unknown challenge", status: http.StatusNotFound})
+		return
+	}
+	name, token, thumbprint, pending := z.identifier.Value, z.challenge.token, req.account.thumbprint, z.challenge.status == acme.StatusPending
+	s.mu.Unlock()
+
+	var verr *problem
+	if pending {
+		verr = s.validate(name, token+"."+thumbprint, token)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if pending {
+		if verr != nil {
+			z.challenge.status, z.challenge.err, z.status = acme.StatusInvalid, verr, acme.StatusInvalid
+		} else {
+			z.challenge.status, z.status = acme.StatusValid, acme.StatusValid
+		}
+		s.advanceOrders()
+	}
+	writeJSON(w, http.StatusOK, challengeJSON(z.challenge))
+}
+
+func (s *Server) validate(name, want, token string) *problem {
+	r, err := http.NewRequest(http.MethodGet, strings.TrimSuffix(s.ChallengeURL, "/")+"/.well-known/acme-challenge/"+token, nil)
+	if err != nil {
+		return &problem{Type: problemConnection, Detail: err.Error()}
+	}
+	r.Host = name
+
+	res, err := http.DefaultClient.Do(r)
+	if err != nil {
+		return &problem{Type: problemConnection, Detail: fmt.Sprintf("fetching challenge for %s: %v", name, err)}
+	}
+	defer res.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(res.Body, 4096))
+	if res.StatusCode != http.StatusOK {
+		return &problem{Type: problemUnauthorized, Detail: fmt.Sprintf("invalid response from %s: %d", name, res.StatusCode)}
+	}
+	if strings.TrimSpace(string(body)) != want {
+		return &problem{Type: problemUnauthorized, Detail: fmt.Sprintf("key authorization served for %s does not match", name)}
+	}
+	return nil
+}
+
+// advanceOrders moves pending orders on once all of their authorizations are
+// decided.
+func (s *Server) advanceOrders() {
+	for _, o := range s.orders {
+		if o.status != acme.StatusPending {
+			continue
+		}
+		ready := true
+		for _, url := range o.authzs {
+			switch s.authzs[url].status {
+			case acme.StatusInvalid:
+				o.status = acme.StatusInvalid
+			case acme.StatusPending:
+				ready = false
+			}
+		}
+		if o.status == acme.StatusPending && ready {
+			o.status = acme.StatusReady
+		}
+	}
+}
+
+func (s *Server) finalize(w http.ResponseWriter, req *request) {
+	var payload struct {
+		CSR string `json:"csr"`
+	}
+	if err := json.Unmarshal(req.payload, &payload); err != nil {
+		writeProblem(w, malformed("finalize payload is not JSON"))
+		return
+	}
+	der, err := base64.RawURLEncoding.DecodeString(payload.CSR)
+	if err != nil {
+		writeProblem(w, &problem{Type: problemBadCSR, Detail: "csr is not base64url", status: http.StatusBadRequest})
+		return
+	}
+	csr, err := x509.ParseCertificateRequest(der)
+	if err != nil || csr.CheckSignature() != nil {
+		writeProblem(w, &problem{Type: problemBadCSR, Detail: "csr does not parse or verify", status: http.StatusBadRequest})
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	o, p := s.ownOrder(req, strings.Replace(req.url, "/finalize/", "/order/", 1))
+	if p != nil {
+		writeProblem(w, p)
+		return
+	}
+	if o.status != acme.StatusReady {
+		writeProblem(w, &problem{Type: problemOrderNotReady, Detail: "order is " + o.status, status: http.StatusForbidden})
+		return
+	}
+
+	names := make([]string, 0, len(o.identifiers))
+	for _, id := range o.identifiers {
+		names = append(names, id.Value)
+	}
+	requested := slices.Clone(csr.DNSNames)
+	slices.Sort(names)
+	slices.Sort(requested)
+	if !slices.Equal(names, slices.Compact(requested)) {
+		writeProblem(w, &problem{Type: problemBadCSR, Detail: "csr names do not match the order", status: http.StatusBadRequest})
+		return
+	}
+
+	s.next++
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(int64(s.next) + 1),
+		Subject:      pkix.Name{CommonName: names[0]},
+		DNSNames:     names,
+		NotBefore:    now.Add(-time.Minute),
+		NotAfter:     now.Add(s.CertLifetime),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	leaf, err := x509.CreateCertificate(rand.Reader, template, s.caCert, csr.PublicKey, s.caKey)
+	if err != nil {
+		writeProblem(w, &problem{Type: "urn:ietf:params:acme:error:serverInternal", Detail: err.Error(), status: http.StatusInternalServerError})
+		return
+	}
+
+	o.status = acme.StatusValid
+	o.certificate = s.srv.URL + "/cert/" + strconv.Itoa(s.next)
+	o.notAfter = template.NotAfter
+	s.issued[o.certificate] = leaf
+
+	w.Header().Set("Location", o.url)
+	writeJSON(w, http.StatusOK, s.orderJSON(o))
+}
+
+func (s *Server) getCert(w http.ResponseWriter, req *request) {
+	s.mu.Lock()
+	leaf, ok := s.issued[req.url]
+	s.mu.Unlock()
+	if !ok || req.account == nil {
+		writeProblem(w, &problem{Type: problemMalformed, Detail: "unknown certificate", status: http.StatusNotFound})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/pem-certificate-chain")
+	w.WriteHeader(http.StatusOK)
+	pem.Encode(w, &pem.Block{Type: "CERTIFICATE", Bytes: leaf})
+	pem.Encode(w, &pem.Block{Type: "CERTIFICATE", Bytes: s.caCert.Raw})
+}
+
+func (s *Server) ownOrder(req *request, url string) (*order, *problem) {
+	o := s.orders[url]
+	if o == nil || req.account == nil || o.account != req.account.url {
+		return nil, &problem{Type: problemMalformed, Detail: "unknown order", status: http.StatusNotFound}
+	}
+	return o, nil
+}
+
+func (s *Server) newURL(kind string) string {
+	s.next++
+	return s.srv.URL + "/" + kind + "/" + strconv.Itoa(s.next)
+}
+
+func (s *Server) newNonce() string {
+	nonce := randomToken()
+	s.mu.Lock()
+	s.nonces[nonce] = true
+	s.mu.Unlock()
+	return nonce
+}
+
+func (s *Server) useNonce(nonce string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.nonces[nonce] {
+		return false
+	}
+	delete(s.nonces, nonce)
+	return true
+}
+
+func (s *Server) orderJSON(o *order) map[string]any {
+	body := map[string]any{
+		"status":         o.status,
+		"identifiers":    o.identifiers,
+		"authorizations": o.authzs,
+		"finalize":       strings.Replace(o.url, "/order/", "/finalize/", 1),
+	}
+	if o.certificate != "" {
+		body["certificate"] = o.certificate
+		body["notAfter"] = o.notAfter.Format(time.RFC3339)
+	}
+	return body
+}
+
+func accountJSON(a *account) map[string]any {
+	return map[string]any{"status": acme.StatusValid, "contact": a.contact}
+}
+
+func authzJSON(z *authz) map[string]any {
+	return map[string]any{
+		"identifier": z.identifier,
+		"status":     z.status,
+		"challenges": []map[string]any{challengeJSON(z.challenge)},
+	}
+}
+
+func challengeJSON(c challenge) map[string]any {
+	body := map[string]any{
+		"type":   "http-01",
+		"url":    c.url,
+		"token":  c.token,
+		"status": c.status,
+	}
+	if c.err != nil {
+		body["error"] = c.err
+	}
+	return body
+}
+
+func parseJWK(raw json.RawMessage) (*ecdsa.PublicKey, error) {
+	var jwk struct {
+		Kty string `json:"kty"`
+		Crv string `json:"crv"`
+		X   string `json:"x"`
+		Y   string `json:"y"`
+	}
+	if err := json.Unmarshal(raw, &jwk); err != nil {
+		return nil, fmt.Errorf("jwk is not JSON")
+	}
+	if jwk.Kty != "EC" || jwk.Crv != "P-256" {
+		return nil, fmt.Errorf("only P-256 keys are supported")
+	}
+	x, errX := base64.RawURLEncoding.DecodeString(jwk.X)
+	y, errY := base64.RawURLEncoding.DecodeString(jwk.Y)
+	if errX != nil || errY != nil {
+		return nil, fmt.Errorf("jwk coordinates are not base64url")
+	}
+	key := &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+	if !key.Curve.IsOnCurve(key.X, key.Y) {
+		return nil, fmt.Errorf("jwk is not a point on P-256")
+	}
+	return key, nil
+}
+
+func randomToken() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func malformed(detail string) *problem {
+	return &problem{Type: problemMalformed, Detail: detail, status: http.StatusBadRequest}
+}
+
+func writeProblem(w http.ResponseWriter, p *problem) {
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(p.status)
+	json.NewEncoder(w).Encode(p)
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,49 @@
+package certificate
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/Jesuloba-world/deployease/backend/internal/infrastructure/database/postgres/sqlc"
+)
+
+// ChallengePath is the path prefix certificate authorities fetch HTTP-01
+// challenge responses from, followed by the challenge token.
+const ChallengePath = "/.well-known/acme-challenge/"
+
+// ChallengeHandler serves the key authorizations of challenges in
+// progress. They are read from the database, so the authority may reach any
+// instance, not just the one running the order.
+type ChallengeHandler struct {
+	queries sqlc.Querier
+}
+
+func NewChallengeHandler(queries sqlc.Querier) *ChallengeHandler {
+	return &ChallengeHandler{queries: queries}
+}
+
+func (h *ChallengeHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	token := strings.TrimPrefix(r.URL.Path, ChallengePath)
+	if token == "" || token == r.URL.Path || strings.Contains(token, "/") {
+		http.NotFound(w, r)
+		return
+	}
+
+	keyAuth, err := h.queries.GetACMEChallenge(r.Context(), token)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			http.NotFound(w, r)
+			return
+		}
+		log.Printf("Failed to get ACME challenge: %v", err)
+		http.Error(w, "failed to get challenge", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain")
+	w.Write([]byte(keyAuth))
+}
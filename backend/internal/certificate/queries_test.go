@@ -0,0 +1,154 @@
+package certificate
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
+
+	database "github.com/Jesuloba-world/deployease/backend/internal/infrastructure/database/postgres"
+	"github.com/Jesuloba-world/deployease/backend/internal/infrastructure/database/postgres/sqlc"
+)
+
+// setupQueries runs the certificate queries against a migrated database
+// with the verified domains d1 and d2 and the pending domain d3.
+func setupQueries(t *testing.T) (*sqlc.Queries, *database.TestContainer) {
+	t.Helper()
+	testcontainers.SkipIfProviderIsNotHealthy(t)
+
+	tc := database.SetupTestContainer(t)
+	t.Cleanup(func() { tc.Cleanup(t) })
+	tc.ApplyMigrations(t, "../../sql/migrations")
+
+	ctx := context.Background()
+	_, err := tc.Pool.Exec(ctx, `INSERT INTO users (id, username, email, password_hash) VALUES ('u1', 'user', 'user@example.com', 'x')`)
+	require.NoError(t, err)
+	_, err = tc.Pool.Exec(ctx, `INSERT INTO projects (id, name, repository_url, user_id) VALUES ('p1', 'app', 'https://github.com/user/app.git', 'u1')`)
+	require.NoError(t, err)
+	_, err = tc.Pool.Exec(ctx, `INSERT INTO domains (id, project_id, name, status, verification_token) VALUES
+		('d1', 'p1', 'a.example.com', 'verified', 'token-d1'),
+		('d2', 'p1', 'b.example.com', 'verified', 'token-d2'),
+		('d3', 'p1', 'c.example.com', 'pending', 'token-d3')`)
+	require.NoError(t, err)
+
+	return sqlc.New(tc.Pool), tc
+}
+
+func TestCreateMissingCertificatesOnlyForVerifiedDomains(t *testing.T) {
+	ctx := context.Background()
+	q, _ := setupQueries(t)
+
+	now := time.Now()
+	n, err := q.CreateMissingCertificates(ctx, timestamp(now))
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), n)
+
+	_, err = q.GetCertificate(ctx, "d3")
+	assert.ErrorIs(t, err, pgx.ErrNoRows, "pending domains get no certificate")
+
+	// Existing certificates are left alone, so a later run neither fails
+	// nor reschedules them.
+	n, err = q.CreateMissingCertificates(ctx, timestamp(now.Add(time.Hour)))
+	require.NoError(t, err)
+	assert.Zero(t, n)
+
+	c, err := q.GetCertificate(ctx, "d1")
+	require.NoError(t, err)
+	assert.Equal(t, sqlc.CertificateStatusPending, c.Status)
+	assert.WithinDuration(t, now, c.NextAttemptAt.Time, time.Millisecond)
+}
+
+func TestClaimDueCertificatesSkipsLockedCertificates(t *testing.T) {
+	ctx := context.Background()
+	q, tc := setupQueries(t)
+
+	now := time.Now()
+	_, err := tc.Pool.Exec(ctx, `INSERT INTO certificates (domain_id, next_attempt_at) VALUES
+		('d1', $1), ('d2', $2), ('d3', $1)`, now.Add(-2*time.Minute), now.Add(-time.Minute))
+	require.NoError(t, err)
+
+	params := sqlc.ClaimDueCertificatesParams{
+		LeaseUntil: timestamp(now.Add(10 * time.Minute)),
+		Now:        timestamp(now),
+		BatchSize:  5,
+	}
+
+	tx, err := tc.Pool.Begin(ctx)
+	require.NoError(t, err)
+	defer tx.Rollback(ctx)
+	held, err := q.WithTx(tx).ClaimDueCertificates(ctx, sqlc.ClaimDueCertificatesParams{
+		LeaseUntil: params.LeaseUntil,
+		Now:        params.Now,
+		BatchSize:  1,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"d1"}, held, "the most overdue certificate is claimed first")
+
+	ids, err := q.ClaimDueCertificates(ctx, params)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"d2"}, ids, "locked certificates and unverified domains are skipped")
+	require.NoError(t, tx.Commit(ctx))
+
+	ids, err = q.ClaimDueCertificates(ctx, params)
+	require.NoError(t, err)
+	assert.Empty(t, ids, "claimed certificates are leased until they come due again")
+
+	c, err := q.GetCertificate(ctx, "d1")
+	require.NoError(t, err)
+	assert.WithinDuration(t, now.Add(10*time.Minute), c.NextAttemptAt.Time, time.Millisecond)
+}
+
+func TestUpdateCertificateFailureKeepsIssuedCertificate(t *testing.T) {
+	ctx := context.Background()
+	q, _ := setupQueries(t)
+
+	now := time.Now()
+	_, err := q.CreateMissingCertificates(ctx, timestamp(now))
+	require.NoError(t, err)
+
+	_, err = q.GetIssuedCertificateByName(ctx, "a.example.com")
+	assert.ErrorIs(t, err, pgx.ErrNoRows, "a pending certificate is not served")
+
+	_, err = q.UpdateCertificateIssued(ctx, sqlc.UpdateCertificateIssuedParams{
+		CertificatePem: pgtype.Text{String: "cert", Valid: true},
+		EncryptedKey:   []byte("key"),
+		NotBefore:      timestamp(now),
+		NotAfter:       timestamp(now.Add(90 * 24 * time.Hour)),
+		IssuedAt:       timestamp(now),
+		NextAttemptAt:  timestamp(now.Add(60 * 24 * time.Hour)),
+		DomainID:       "d1",
+	})
+	require.NoError(t, err)
+
+	c, err := q.UpdateCertificateFailure(ctx, sqlc.UpdateCertificateFailureParams{
+		Status:        sqlc.CertificateStatusIssued,
+		LastError:     pgtype.Text{String: "rate limited", Valid: true},
+		NextAttemptAt: timestamp(now.Add(time.Hour)),
+		DomainID:      "d1",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "rate limited", c.LastError.String)
+	assert.WithinDuration(t, now.Add(time.Hour), c.NextAttemptAt.Time, time.Millisecond)
+
+	served, err := q.GetIssuedCertificateByName(ctx, "a.example.com")
+	require.NoError(t, err)
+	assert.Equal(t, "cert", served.CertificatePem.String, "a failed renewal keeps serving the issued certificate")
+	assert.Equal(t, []byte("key"), served.EncryptedKey)
+
+	c, err = q.UpdateCertificateIssued(ctx, sqlc.UpdateCertificateIssuedParams{
+		CertificatePem: pgtype.Text{String: "renewed", Valid: true},
+		EncryptedKey:   []byte("key2"),
+		NotBefore:      timestamp(now),
+		NotAfter:       timestamp(now.Add(90 * 24 * time.Hour)),
+		IssuedAt:       timestamp(now),
+		NextAttemptAt:  timestamp(now.Add(60 * 24 * time.Hour)),
+		DomainID:       "d1",
+	})
+	require.NoError(t, err)
+	assert.False(t, c.LastError.Valid, "issuing clears the last error")
+}
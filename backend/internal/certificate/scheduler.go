@@ -0,0 +1,125 @@
+package certificate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/Jesuloba-world/deployease/backend/internal/infrastructure/database/postgres/sqlc"
+	"github.com/Jesuloba-world/deployease/backend/internal/jobs"
+)
+
+// IssueJobKind issues or renews a domain's certificate.
+const IssueJobKind = "certificate.issue"
+
+// issueBatchSize caps how many issuances are queued per tick.
+const issueBatchSize = 50
+
+type IssuePayload struct {
+	DomainID string `json:"domain_id"`
+}
+
+// HandleIssue runs a queued issuance. Domains deleted in the meantime or no
+// longer verified are skipped.
+func (s *Service) HandleIssue(ctx context.Context, job sqlc.Job) error {
+	var payload IssuePayload
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return jobs.Permanent(fmt.Errorf("invalid certificate issue payload: %w", err))
+	}
+
+	d, err := s.queries.GetDomain(ctx, payload.DomainID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		return fmt.Errorf("failed to get domain: %w", err)
+	}
+	if d.Status != sqlc.DomainStatusVerified {
+		return nil
+	}
+
+	c, err := s.Issue(ctx, d)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+	if c.LastError.Valid {
+		log.Printf("Failed to issue certificate for %s: %s", d.Name, c.LastError.String)
+	}
+	return nil
+}
+
+// Scheduler gives newly verified domains a certificate and queues the
+// issuances and renewals that have come due.
+type Scheduler struct {
+	service *Service
+	queue   *jobs.Queue
+}
+
+func NewScheduler(service *Service, queue *jobs.Queue) *Scheduler {
+	return &Scheduler{
+		service: service,
+		queue:   queue,
+	}
+}
+
+// Run schedules due issuances right away and then every check interval
+// until ctx is done.
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.service.cfg.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.Schedule(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("Failed to schedule certificate issuance: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Schedule adds a pending certificate for every verified domain without
+// one and queues an issuance for every certificate that is due, returning
+// how many were queued. Claimed certificates are not due again for a retry
+// interval, so an issuance whose job is lost is still retried.
+func (s *Scheduler) Schedule(ctx context.Context) (int, error) {
+	now := s.service.now()
+
+	if _, err := s.service.queries.CreateMissingCertificates(ctx, timestamp(now)); err != nil {
+		return 0, fmt.Errorf("failed to create certificates: %w", err)
+	}
+
+	queued := 0
+	for {
+		ids, err := s.service.queries.ClaimDueCertificates(ctx, sqlc.ClaimDueCertificatesParams{
+			LeaseUntil: timestamp(now.Add(s.service.cfg.RetryInterval)),
+			Now:        timestamp(now),
+			BatchSize:  issueBatchSize,
+		})
+		if err != nil {
+			return queued, fmt.Errorf("failed to claim certificates: %w", err)
+		}
+
+		for _, id := range ids {
+			if _, err := s.queue.Enqueue(ctx, IssueJobKind, IssuePayload{DomainID: id}); err != nil {
+				return queued, err
+			}
+			queued++
+		}
+
+		if len(ids) < issueBatchSize {
+			return queued, nil
+		}
+	}
+}
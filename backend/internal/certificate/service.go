@@ -0,0 +1,290 @@
+// Package certificate obtains TLS certificates for verified custom domains
+// from an ACME certificate authority such as Let's Encrypt. Control of a
+// domain is proven with HTTP-01 challenges served from the API's router.
+// Certificates are renewed ahead of expiry; their private keys, like the
+// ACME account key, are encrypted at rest.
+package certificate
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"golang.org/x/crypto/acme"
+
+	"github.com/Jesuloba-world/deployease/backend/internal/config"
+	"github.com/Jesuloba-world/deployease/backend/internal/infrastructure/database/postgres/sqlc"
+	"github.com/Jesuloba-world/deployease/backend/internal/secrets"
+)
+
+var ErrNotFound = errors.New("certificate not found")
+
+type Service struct {
+	queries    sqlc.Querier
+	cipher     *secrets.Cipher
+	cfg        config.ACMEConfig
+	httpClient *http.Client
+	now        func() time.Time
+
+	mu     sync.Mutex
+	client *acme.Client
+}
+
+func NewService(queries sqlc.Querier, cipher *secrets.Cipher, cfg config.ACMEConfig) *Service {
+	return &Service{
+		queries:    queries,
+		cipher:     cipher,
+		cfg:        cfg,
+		httpClient: http.DefaultClient,
+		now:        time.Now,
+	}
+}
+
+// Get returns the certificate of a domain. Domains are given one once they
+// are verified.
+func (s *Service) Get(ctx context.Context, domainID string) (sqlc.Certificate, error) {
+	c, err := s.queries.GetCertificate(ctx, domainID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return sqlc.Certificate{}, ErrNotFound
+		}
+		return sqlc.Certificate{}, fmt.Errorf("failed to get certificate: %w", err)
+	}
+	return c, nil
+}
+
+// Load returns the issued certificate of the verified domain with the given
+// name, ready to serve.
+func (s *Service) Load(ctx context.Context, name string) (*tls.Certificate, error) {
+	c, err := s.queries.GetIssuedCertificateByName(ctx, name)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get certificate: %w", err)
+	}
+
+	keyPEM, err := s.cipher.Open(c.EncryptedKey, []byte(keyAssociatedData(c.DomainID)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt certificate key: %w", err)
+	}
+
+	cert, err := tls.X509KeyPair([]byte(c.CertificatePem.String), keyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load certificate: %w", err)
+	}
+	return &cert, nil
+}
+
+// Issue obtains a certificate for the verified domain and stores it, due
+// for renewal the configured time before it expires. A failed attempt is
+// recorded on the certificate and tried again after the retry interval; a
+// certificate that was issued before keeps being served until it expires.
+// Only failing to store the outcome is returned as an error.
+func (s *Service) Issue(ctx context.Context, d sqlc.Domain) (sqlc.Certificate, error) {
+	issued, issueErr := s.obtain(ctx, d.Name)
+	if issueErr != nil {
+		return s.recordFailure(ctx, d.ID, issueErr)
+	}
+
+	sealed, err := s.cipher.Seal(issued.keyPEM, []byte(keyAssociatedData(d.ID)))
+	if err != nil {
+		return sqlc.Certificate{}, fmt.Errorf("failed to encrypt certificate key: %w", err)
+	}
+
+	c, err := s.queries.UpdateCertificateIssued(ctx, sqlc.UpdateCertificateIssuedParams{
+		DomainID:       d.ID,
+		CertificatePem: pgtype.Text{String: string(issued.chainPEM), Valid: true},
+		EncryptedKey:   sealed,
+		NotBefore:      timestamp(issued.leaf.NotBefore),
+		NotAfter:       timestamp(issued.leaf.NotAfter),
+		IssuedAt:       timestamp(s.now()),
+		NextAttemptAt:  timestamp(s.renewAt(issued.leaf)),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return sqlc.Certificate{}, ErrNotFound
+		}
+		return sqlc.Certificate{}, fmt.Errorf("failed to store certificate: %w", err)
+	}
+	return c, nil
+}
+
+// renewAt is when a certificate is due for renewal. Certificates that are
+// valid for less than the renewal window, which only test authorities
+// issue, are renewed two thirds into their lifetime instead.
+func (s *Service) renewAt(leaf *x509.Certificate) time.Time {
+	renewAt := leaf.NotAfter.Add(-s.cfg.RenewBefore)
+	if renewAt.Before(leaf.NotBefore) {
+		renewAt = leaf.NotBefore.Add(leaf.NotAfter.Sub(leaf.NotBefore) * 2 / 3)
+	}
+	return renewAt
+}
+
+func (s *Service) recordFailure(ctx context.Context, domainID string, issueErr error) (sqlc.Certificate, error) {
+	current, err := s.Get(ctx, domainID)
+	if err != nil {
+		return sqlc.Certificate{}, err
+	}
+
+	now := s.now()
+	status := sqlc.CertificateStatusFailed
+	if current.Status == sqlc.CertificateStatusIssued && current.NotAfter.Time.After(now) {
+		status = sqlc.CertificateStatusIssued
+	}
+
+	c, err := s.queries.UpdateCertificateFailure(ctx, sqlc.UpdateCertificateFailureParams{
+		DomainID:      domainID,
+		Status:        status,
+		LastError:     pgtype.Text{String: issueErr.Error(), Valid: true},
+		NextAttemptAt: timestamp(now.Add(s.cfg.RetryInterval)),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return sqlc.Certificate{}, ErrNotFound
+		}
+		return sqlc.Certificate{}, fmt.Errorf("failed to update certificate: %w", err)
+	}
+	return c, nil
+}
+
+type issuedCertificate struct {
+	chainPEM []byte
+	keyPEM   []byte
+	leaf     *x509.Certificate
+}
+
+// obtain runs an ACME order for name: every pending authorization is
+// completed with an HTTP-01 challenge, then a CSR for a fresh key is
+// submitted and the resulting chain downloaded.
+func (s *Service) obtain(ctx context.Context, name string) (issuedCertificate, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.cfg.IssueTimeout)
+	defer cancel()
+
+	client, err := s.acmeClient(ctx)
+	if err != nil {
+		return issuedCertificate{}, err
+	}
+
+	order, err := client.AuthorizeOrder(ctx, acme.DomainIDs(name))
+	if err != nil {
+		return issuedCertificate{}, fmt.Errorf("failed to create order: %w", err)
+	}
+	for _, url := range order.AuthzURLs {
+		if err := s.authorize(ctx, client, url); err != nil {
+			return issuedCertificate{}, err
+		}
+	}
+	if order, err = client.WaitOrder(ctx, order.URI); err != nil {
+		return issuedCertificate{}, fmt.Errorf("order did not become ready: %w", err)
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return issuedCertificate{}, fmt.Errorf("failed to generate certificate key: %w", err)
+	}
+	csr, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject:  pkix.Name{CommonName: name},
+		DNSNames: []string{name},
+	}, key)
+	if err != nil {
+		return issuedCertificate{}, fmt.Errorf("failed to create certificate request: %w", err)
+	}
+
+	chain, _, err := client.CreateOrderCert(ctx, order.FinalizeURL, csr, true)
+	if err != nil {
+		return issuedCertificate{}, fmt.Errorf("failed to finalize order: %w", err)
+	}
+
+	leaf, err := x509.ParseCertificate(chain[0])
+	if err != nil {
+		return issuedCertificate{}, fmt.Errorf("failed to parse issued certificate: %w", err)
+	}
+	if err := leaf.VerifyHostname(name); err != nil {
+		return issuedCertificate{}, fmt.Errorf("issued certificate does not cover %s: %w", name, err)
+	}
+
+	keyPEM, err := encodeKey(key)
+	if err != nil {
+		return issuedCertificate{}, err
+	}
+
+	var chainPEM []byte
+	for _, der := range chain {
+		chainPEM = append(chainPEM, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})...)
+	}
+
+	return issuedCertificate{chainPEM: chainPEM, keyPEM: keyPEM, leaf: leaf}, nil
+}
+
+// authorize completes the authorization at url with its HTTP-01 challenge,
+// publishing the key authorization for the challenge handler while the
+// authority validates it. Authorizations that are already valid are left
+// alone.
+func (s *Service) authorize(ctx context.Context, client *acme.Client, url string) error {
+	authz, err := client.GetAuthorization(ctx, url)
+	if err != nil {
+		return fmt.Errorf("failed to get authorization: %w", err)
+	}
+	if authz.Status == acme.StatusValid {
+		return nil
+	}
+
+	var chal *acme.Challenge
+	for _, c := range authz.Challenges {
+		if c.Type == "http-01" {
+			chal = c
+			break
+		}
+	}
+	if chal == nil {
+		return fmt.Errorf("authority offered no http-01 challenge for %s", authz.Identifier.Value)
+	}
+
+	keyAuth, err := client.HTTP01ChallengeResponse(chal.Token)
+	if err != nil {
+		return fmt.Errorf("failed to compute key authorization: %w", err)
+	}
+
+	if err := s.queries.UpsertACMEChallenge(ctx, sqlc.UpsertACMEChallengeParams{
+		Token:            chal.Token,
+		KeyAuthorization: keyAuth,
+		ExpiresAt:        timestamp(s.now().Add(s.cfg.IssueTimeout)),
+	}); err != nil {
+		return fmt.Errorf("failed to store challenge: %w", err)
+	}
+	defer func() {
+		if err := s.queries.DeleteACMEChallenge(context.WithoutCancel(ctx), chal.Token); err != nil {
+			log.Printf("Failed to delete ACME challenge: %v", err)
+		}
+	}()
+
+	if _, err := client.Accept(ctx, chal); err != nil {
+		return fmt.Errorf("failed to accept challenge: %w", err)
+	}
+	if _, err := client.WaitAuthorization(ctx, url); err != nil {
+		return fmt.Errorf("authorization of %s failed: %w", authz.Identifier.Value, err)
+	}
+	return nil
+}
+
+func keyAssociatedData(domainID string) string {
+	return "certificates/" + domainID
+}
+
+func timestamp(t time.Time) pgtype.Timestamptz {
+	return pgtype.Timestamptz{Time: t, Valid: true}
+}
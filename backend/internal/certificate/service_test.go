@@ -0,0 +1,382 @@
+package certificate
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Jesuloba-world/deployease/backend/internal/certificate/acmetest"
+	"github.com/Jesuloba-world/deployease/backend/internal/config"
+	"github.com/Jesuloba-world/deployease/backend/internal/infrastructure/database/postgres/sqlc"
+	"github.com/Jesuloba-world/deployease/backend/internal/jobs"
+	"github.com/Jesuloba-world/deployease/backend/internal/secrets"
+)
+
+// fakeQuerier is shared by the service and the challenge handler, which run
+// on different goroutines while the authority validates a challenge.
+type fakeQuerier struct {
+	sqlc.Querier
+	mu           sync.Mutex
+	domains      map[string]sqlc.Domain
+	certificates map[string]sqlc.Certificate
+	accounts     map[string]sqlc.AcmeAccount
+	challenges   map[string]sqlc.AcmeChallenge
+	jobs         []sqlc.Job
+}
+
+func (f *fakeQuerier) GetDomain(ctx context.Context, id string) (sqlc.Domain, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	d, ok := f.domains[id]
+	if !ok {
+		return sqlc.Domain{}, pgx.ErrNoRows
+	}
+	return d, nil
+}
+
+func (f *fakeQuerier) GetACMEAccount(ctx context.Context, directoryURL string) (sqlc.AcmeAccount, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	a, ok := f.accounts[directoryURL]
+	if !ok {
+		return sqlc.AcmeAccount{}, pgx.ErrNoRows
+	}
+	return a, nil
+}
+
+func (f *fakeQuerier) CreateACMEAccount(ctx context.Context, arg sqlc.CreateACMEAccountParams) (sqlc.AcmeAccount, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.accounts[arg.DirectoryUrl]; ok {
+		return sqlc.AcmeAccount{}, pgx.ErrNoRows
+	}
+	a := sqlc.AcmeAccount{
+		DirectoryUrl: arg.DirectoryUrl,
+		Uri:          arg.Uri,
+		Email:        arg.Email,
+		EncryptedKey: arg.EncryptedKey,
+	}
+	f.accounts[a.DirectoryUrl] = a
+	return a, nil
+}
+
+func (f *fakeQuerier) UpsertACMEChallenge(ctx context.Context, arg sqlc.UpsertACMEChallengeParams) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.challenges[arg.Token] = sqlc.AcmeChallenge{Token: arg.Token, KeyAuthorization: arg.KeyAuthorization, ExpiresAt: arg.ExpiresAt}
+	return nil
+}
+
+func (f *fakeQuerier) GetACMEChallenge(ctx context.Context, token string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	c, ok := f.challenges[token]
+	if !ok || !c.ExpiresAt.Time.After(time.Now()) {
+		return "", pgx.ErrNoRows
+	}
+	return c.KeyAuthorization, nil
+}
+
+func (f *fakeQuerier) DeleteACMEChallenge(ctx context.Context, token string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.challenges, token)
+	return nil
+}
+
+func (f *fakeQuerier) CreateMissingCertificates(ctx context.Context, nextAttemptAt pgtype.Timestamptz) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var n int64
+	for id, d := range f.domains {
+		if _, ok := f.certificates[id]; ok || d.Status != sqlc.DomainStatusVerified {
+			continue
+		}
+		f.certificates[id] = sqlc.Certificate{DomainID: id, Status: sqlc.CertificateStatusPending, NextAttemptAt: nextAttemptAt}
+		n++
+	}
+	return n, nil
+}
+
+func (f *fakeQuerier) ClaimDueCertificates(ctx context.Context, arg sqlc.ClaimDueCertificatesParams) ([]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	ids := []string{}
+	for id, c := range f.certificates {
+		if len(ids) == int(arg.BatchSize) {
+			break
+		}
+		if f.domains[id].Status == sqlc.DomainStatusVerified && !c.NextAttemptAt.Time.After(arg.Now.Time) {
+			c.NextAttemptAt = arg.LeaseUntil
+			f.certificates[id] = c
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+func (f *fakeQuerier) GetCertificate(ctx context.Context, domainID string) (sqlc.Certificate, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	c, ok := f.certificates[domainID]
+	if !ok {
+		return sqlc.Certificate{}, pgx.ErrNoRows
+	}
+	return c, nil
+}
+
+func (f *fakeQuerier) GetIssuedCertificateByName(ctx context.Context, name string) (sqlc.Certificate, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for id, d := range f.domains {
+		c, ok := f.certificates[id]
+		if d.Name == name && d.Status == sqlc.DomainStatusVerified && ok && c.CertificatePem.Valid {
+			return c, nil
+		}
+	}
+	return sqlc.Certificate{}, pgx.ErrNoRows
+}
+
+func (f *fakeQuerier) UpdateCertificateIssued(ctx context.Context, arg sqlc.UpdateCertificateIssuedParams) (sqlc.Certificate, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	c, ok := f.certificates[arg.DomainID]
+	if !ok {
+		return sqlc.Certificate{}, pgx.ErrNoRows
+	}
+	c.Status = sqlc.CertificateStatusIssued
+	c.CertificatePem = arg.CertificatePem
+	c.EncryptedKey = arg.EncryptedKey
+	c.NotBefore = arg.NotBefore
+	c.NotAfter = arg.NotAfter
+	c.IssuedAt = arg.IssuedAt
+	c.LastError = pgtype.Text{}
+	c.NextAttemptAt = arg.NextAttemptAt
+	f.certificates[c.DomainID] = c
+	return c, nil
+}
+
+func (f *fakeQuerier) UpdateCertificateFailure(ctx context.Context, arg sqlc.UpdateCertificateFailureParams) (sqlc.Certificate, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	c, ok := f.certificates[arg.DomainID]
+	if !ok {
+		return sqlc.Certificate{}, pgx.ErrNoRows
+	}
+	c.Status = arg.Status
+	c.LastError = arg.LastError
+	c.NextAttemptAt = arg.NextAttemptAt
+	f.certificates[c.DomainID] = c
+	return c, nil
+}
+
+func (f *fakeQuerier) EnqueueJob(ctx context.Context, arg sqlc.EnqueueJobParams) (sqlc.Job, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	job := sqlc.Job{ID: arg.ID, Kind: arg.Kind, Payload: arg.Payload}
+	f.jobs = append(f.jobs, job)
+	return job, nil
+}
+
+type fixture struct {
+	svc     *Service
+	queries *fakeQuerier
+	ca      *acmetest.Server
+	cipher  *secrets.Cipher
+	cfg     config.ACMEConfig
+	now     time.Time
+}
+
+// setupService wires the service to a test authority that validates
+// challenges against the challenge handler, as it would through the API's
+// router.
+func setupService(t *testing.T) *fixture {
+	t.Helper()
+	cipher, err := secrets.NewCipher(config.SecretsConfig{MasterKey: "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY="})
+	require.NoError(t, err)
+
+	f := &fixture{
+		queries: &fakeQuerier{
+			domains:      make(map[string]sqlc.Domain),
+			certificates: make(map[string]sqlc.Certificate),
+			accounts:     make(map[string]sqlc.AcmeAccount),
+			challenges:   make(map[string]sqlc.AcmeChallenge),
+		},
+		cipher: cipher,
+		now:    time.Now(),
+	}
+
+	challenges := httptest.NewServer(NewChallengeHandler(f.queries))
+	t.Cleanup(challenges.Close)
+	f.ca = acmetest.NewServer(t, challenges.URL)
+
+	f.cfg = config.ACMEConfig{
+		Enabled:       true,
+		DirectoryURL:  f.ca.DirectoryURL(),
+		Email:         "ops@example.com",
+		RenewBefore:   30 * 24 * time.Hour,
+		CheckInterval: time.Minute,
+		RetryInterval: time.Hour,
+		IssueTimeout:  30 * time.Second,
+	}
+	f.svc = f.newService()
+	return f
+}
+
+func (f *fixture) newService() *Service {
+	svc := NewService(f.queries, f.cipher, f.cfg)
+	svc.now = func() time.Time { return f.now }
+	return svc
+}
+
+func (f *fixture) addDomain(id, name string, status sqlc.DomainStatus) sqlc.Domain {
+	d := sqlc.Domain{ID: id, ProjectID: "p1", Name: name, Status: status}
+	f.queries.domains[id] = d
+	return d
+}
+
+func TestIssueStoresCertificate(t *testing.T) {
+	ctx := context.Background()
+	f := setupService(t)
+	d := f.addDomain("d1", "app.example.com", sqlc.DomainStatusVerified)
+	_, err := f.queries.CreateMissingCertificates(ctx, timestamp(f.now))
+	require.NoError(t, err)
+
+	c, err := f.svc.Issue(ctx, d)
+	require.NoError(t, err)
+	assert.Equal(t, sqlc.CertificateStatusIssued, c.Status)
+	assert.False(t, c.LastError.Valid)
+	assert.Equal(t, c.NotAfter.Time.Add(-30*24*time.Hour), c.NextAttemptAt.Time, "renewal is due 30 days before expiry")
+	assert.False(t, bytes.Contains(c.EncryptedKey, []byte("PRIVATE KEY")), "the key is stored encrypted")
+	assert.Empty(t, f.queries.challenges, "challenges are removed once validated")
+
+	account := f.queries.accounts[f.cfg.DirectoryURL]
+	assert.NotEmpty(t, account.Uri)
+	assert.Equal(t, "ops@example.com", account.Email)
+
+	cert, err := f.svc.Load(ctx, "app.example.com")
+	require.NoError(t, err)
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	require.NoError(t, err)
+	_, err = leaf.Verify(x509.VerifyOptions{DNSName: "app.example.com", Roots: f.ca.Roots()})
+	assert.NoError(t, err)
+
+	_, err = f.svc.Load(ctx, "www.example.com")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	// Another instance reuses the stored account.
+	_, err = f.newService().Issue(ctx, d)
+	require.NoError(t, err)
+	assert.Equal(t, 1, f.ca.Accounts())
+	assert.Equal(t, 2, f.ca.Issued())
+}
+
+func TestIssueFailureKeepsIssuedCertificate(t *testing.T) {
+	ctx := context.Background()
+	f := setupService(t)
+	issued := f.addDomain("d1", "app.example.com", sqlc.DomainStatusVerified)
+	fresh := f.addDomain("d2", "www.example.com", sqlc.DomainStatusVerified)
+	_, err := f.queries.CreateMissingCertificates(ctx, timestamp(f.now))
+	require.NoError(t, err)
+
+	before, err := f.svc.Issue(ctx, issued)
+	require.NoError(t, err)
+
+	// The authority can no longer reach the challenge responses.
+	unreachable := httptest.NewServer(http.NotFoundHandler())
+	t.Cleanup(unreachable.Close)
+	f.ca.ChallengeURL = unreachable.URL
+
+	c, err := f.svc.Issue(ctx, issued)
+	require.NoError(t, err)
+	assert.Equal(t, sqlc.CertificateStatusIssued, c.Status)
+	assert.Contains(t, c.LastError.String, "authorization of app.example.com failed")
+	assert.Equal(t, before.CertificatePem, c.CertificatePem)
+	assert.Equal(t, f.now.Add(time.Hour), c.NextAttemptAt.Time)
+
+	c, err = f.svc.Issue(ctx, fresh)
+	require.NoError(t, err)
+	assert.Equal(t, sqlc.CertificateStatusFailed, c.Status)
+	assert.False(t, c.CertificatePem.Valid)
+
+	_, err = f.svc.Load(ctx, "app.example.com")
+	assert.NoError(t, err, "the earlier certificate is still served")
+}
+
+func TestChallengeHandler(t *testing.T) {
+	f := setupService(t)
+	require.NoError(t, f.queries.UpsertACMEChallenge(context.Background(), sqlc.UpsertACMEChallengeParams{
+		Token:            "tok",
+		KeyAuthorization: "tok.thumbprint",
+		ExpiresAt:        timestamp(time.Now().Add(time.Minute)),
+	}))
+	h := NewChallengeHandler(f.queries)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, ChallengePath+"tok", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "tok.thumbprint", rec.Body.String())
+
+	for _, path := range []string{ChallengePath + "other", ChallengePath, ChallengePath + "tok/extra"} {
+		rec = httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, http.StatusNotFound, rec.Code, path)
+	}
+}
+
+func TestSchedulerQueuesDueIssuance(t *testing.T) {
+	ctx := context.Background()
+	f := setupService(t)
+	scheduler := NewScheduler(f.svc, jobs.NewQueue(f.queries, 3))
+
+	f.addDomain("d1", "app.example.com", sqlc.DomainStatusVerified)
+	f.addDomain("d2", "www.example.com", sqlc.DomainStatusPending)
+
+	n, err := scheduler.Schedule(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n, "only verified domains get a certificate")
+	n, err = scheduler.Schedule(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, n, "claimed issuances are not queued twice")
+
+	require.Len(t, f.queries.jobs, 1)
+	job := f.queries.jobs[0]
+	assert.Equal(t, IssueJobKind, job.Kind)
+	var payload IssuePayload
+	require.NoError(t, json.Unmarshal(job.Payload, &payload))
+	assert.Equal(t, "d1", payload.DomainID)
+	require.NoError(t, f.svc.HandleIssue(ctx, job))
+
+	c, err := f.svc.Get(ctx, "d1")
+	require.NoError(t, err)
+	assert.Equal(t, sqlc.CertificateStatusIssued, c.Status)
+
+	// Nothing is due until the renewal window opens.
+	f.queries.jobs = nil
+	f.now = c.NextAttemptAt.Time.Add(-time.Minute)
+	n, err = scheduler.Schedule(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, n)
+	f.now = c.NextAttemptAt.Time
+	n, err = scheduler.Schedule(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	// Domains that failed verification in the meantime are skipped.
+	d := f.queries.domains["d1"]
+	d.Status = sqlc.DomainStatusFailed
+	f.queries.domains["d1"] = d
+	require.NoError(t, f.svc.HandleIssue(ctx, f.queries.jobs[0]))
+	assert.Equal(t, 1, f.ca.Issued())
+}
@@ -0,0 +1,65 @@
+// Package cli holds the command line plumbing shared by the server binary
+// and the deployease client.
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// Name is the name the binary was invoked as, used in usage messages so the
+// server and the client each describe themselves.
+var Name = filepath.Base(os.Args[0])
+
+// UsageError is a mistake in the command line, which exits with status 2.
+type UsageError struct {
+	Usage string
+	Msg   string
+}
+
+func (e *UsageError) Error() string {
+	return e.Msg + "\nusage: " + Name + " " + e.Usage
+}
+
+func NewUsageError(usage, format string, args ...any) error {
+	return &UsageError{Usage: usage, Msg: fmt.Sprintf(format, args...)}
+}
+
+// IsUsageError reports whether err is or wraps a UsageError.
+func IsUsageError(err error) bool {
+	var uErr *UsageError
+	return errors.As(err, &uErr)
+}
+
+// ReadPassword prompts for a password without echoing it when f is a
+// terminal, and otherwise reads it from the first line of f, so it can be
+// piped in.
+func ReadPassword(f *os.File) (string, error) {
+	var password string
+	if fd := int(f.Fd()); term.IsTerminal(fd) {
+		fmt.Fprint(os.Stderr, "Password: ")
+		b, err := term.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return "", fmt.Errorf("failed to read password: %w", err)
+		}
+		password = string(b)
+	} else {
+		line, err := bufio.NewReader(f).ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return "", fmt.Errorf("failed to read password: %w", err)
+		}
+		password = strings.TrimRight(line, "\r\n")
+	}
+	if password == "" {
+		return "", errors.New("no password given")
+	}
+	return password, nil
+}
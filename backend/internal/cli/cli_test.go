@@ -0,0 +1,44 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUsageError(t *testing.T) {
+	defer func(name string) { Name = name }(Name)
+	Name = "deployease"
+
+	err := NewUsageError("logs [DEPLOYMENT]", "unexpected arguments %q", []string{"a", "b"})
+	assert.Equal(t, "unexpected arguments [\"a\" \"b\"]\nusage: deployease logs [DEPLOYMENT]", err.Error())
+	assert.True(t, IsUsageError(fmt.Errorf("wrapped: %w", err)))
+	assert.False(t, IsUsageError(os.ErrNotExist))
+}
+
+func pipe(t *testing.T, input string) *os.File {
+	t.Helper()
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	t.Cleanup(func() { r.Close() })
+	_, err = w.WriteString(input)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	return r
+}
+
+func TestReadPasswordFromPipe(t *testing.T) {
+	password, err := ReadPassword(pipe(t, "s3cret pass\r\nignored\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "s3cret pass", password)
+
+	password, err = ReadPassword(pipe(t, "no-newline"))
+	require.NoError(t, err)
+	assert.Equal(t, "no-newline", password)
+
+	_, err = ReadPassword(pipe(t, ""))
+	assert.EqualError(t, err, "no password given")
+}
@@ -13,7 +13,22 @@ type Config struct {
 	Server      ServerConfig   `mapstructure:"server"`
 	Database    DatabaseConfig `mapstructure:"database"`
 	JWT         JWTConfig      `mapstructure:"jwt"`
+	Session     SessionConfig  `mapstructure:"session"`
 	Redis       RedisConfig    `mapstructure:"redis"`
+	Jobs        JobsConfig     `mapstructure:"jobs"`
+	Build       BuildConfig    `mapstructure:"build"`
+	Runtime     RuntimeConfig  `mapstructure:"runtime"`
+	Rollout     RolloutConfig  `mapstructure:"rollout"`
+	Events      EventsConfig   `mapstructure:"events"`
+	Logs        LogsConfig     `mapstructure:"logs"`
+	Secrets     SecretsConfig  `mapstructure:"secrets"`
+	Webhooks    WebhooksConfig `mapstructure:"webhooks"`
+	Previews    PreviewsConfig `mapstructure:"previews"`
+	Domains     DomainsConfig  `mapstructure:"domains"`
+	ACME        ACMEConfig     `mapstructure:"acme"`
+
+	ManagedDatabases ManagedDatabasesConfig `mapstructure:"managed_databases"`
+	Backups          BackupsConfig          `mapstructure:"backups"`
 }
 type ServerConfig struct {
 	Port         string        `mapstructure:"port"`
@@ -21,6 +36,27 @@ type ServerConfig struct {
 	ReadTimeout  time.Duration `mapstructure:"read_timeout"`
 	WriteTimeout time.Duration `mapstructure:"write_timeout"`
 	IdleTimeout  time.Duration `mapstructure:"idle_timeout"`
+	// Proxy is the separate listener deployed applications are served on.
+	Proxy ProxyConfig `mapstructure:"proxy"`
+}
+
+// ProxyConfig holds the edge proxy that routes requests for verified custom
+// domains and preview subdomains to the deployments serving them.
+type ProxyConfig struct {
+	// Enabled turns on the proxy listeners.
+	Enabled bool   `mapstructure:"enabled"`
+	Host    string `mapstructure:"host"`
+	// HTTPPort also answers HTTP-01 challenges, so certificate authorities
+	// must be able to reach it on port 80.
+	HTTPPort string `mapstructure:"http_port"`
+	// HTTPSPort serves the certificates issued for custom domains. Empty
+	// disables TLS.
+	HTTPSPort string `mapstructure:"https_port"`
+	// RefreshInterval is how often the routing table is reloaded even if no
+	// change has been announced.
+	RefreshInterval time.Duration `mapstructure:"refresh_interval"`
+	// HealthInterval is how often every upstream is probed.
+	HealthInterval time.Duration `mapstructure:"health_interval"`
 }
 
 type DatabaseConfig struct {
@@ -34,12 +70,22 @@ type DatabaseConfig struct {
 	MaxIdleConns    int           `mapstructure:"max_idle_conns"`
 	ConnMaxLifetime time.Duration `mapstructure:"conn_max_lifetime"`
 	ConnMaxIdleTime time.Duration `mapstructure:"conn_max_idle_time"`
-	MigrationsDir   string        `mapstructure:"migrations_dir"`
+	// MigrationsDir overrides the migrations embedded in the binary.
+	MigrationsDir string `mapstructure:"migrations_dir"`
+	// AutoMigrate applies pending migrations at startup.
+	AutoMigrate bool `mapstructure:"auto_migrate"`
 }
 
 type JWTConfig struct {
-	Secret     string        `mapstructure:"secret"`
-	Expiration time.Duration `mapstructure:"expiration"`
+	Secret            string        `mapstructure:"secret"`
+	Expiration        time.Duration `mapstructure:"expiration"`
+	RefreshExpiration time.Duration `mapstructure:"refresh_expiration"`
+}
+
+type SessionConfig struct {
+	CookieName   string        `mapstructure:"cookie_name"`
+	Expiration   time.Duration `mapstructure:"expiration"`
+	SecureCookie bool          `mapstructure:"secure_cookie"`
 }
 
 type RedisConfig struct {
@@ -49,6 +95,160 @@ type RedisConfig struct {
 	DB       int    `mapstructure:"db"`
 }
 
+type JobsConfig struct {
+	Concurrency       int           `mapstructure:"concurrency"`
+	PollInterval      time.Duration `mapstructure:"poll_interval"`
+	VisibilityTimeout time.Duration `mapstructure:"visibility_timeout"`
+	MaxAttempts       int           `mapstructure:"max_attempts"`
+	BaseBackoff       time.Duration `mapstructure:"base_backoff"`
+	MaxBackoff        time.Duration `mapstructure:"max_backoff"`
+	ShutdownTimeout   time.Duration `mapstructure:"shutdown_timeout"`
+}
+
+type BuildConfig struct {
+	WorkDir      string        `mapstructure:"work_dir"`
+	DockerBinary string        `mapstructure:"docker_binary"`
+	ImagePrefix  string        `mapstructure:"image_prefix"`
+	Timeout      time.Duration `mapstructure:"timeout"`
+}
+
+type RuntimeConfig struct {
+	DockerHost       string        `mapstructure:"docker_host"`
+	DockerAPIVersion string        `mapstructure:"docker_api_version"`
+	Network          string        `mapstructure:"network"`
+	ContainerPort    int           `mapstructure:"container_port"`
+	StopTimeout      time.Duration `mapstructure:"stop_timeout"`
+}
+
+type RolloutConfig struct {
+	HealthPath     string        `mapstructure:"health_path"`
+	HealthTimeout  time.Duration `mapstructure:"health_timeout"`
+	ProbeInterval  time.Duration `mapstructure:"probe_interval"`
+	DrainGrace     time.Duration `mapstructure:"drain_grace"`
+	RollbackWindow time.Duration `mapstructure:"rollback_window"`
+	VerifyInterval time.Duration `mapstructure:"verify_interval"`
+}
+
+type EventsConfig struct {
+	PingInterval time.Duration `mapstructure:"ping_interval"`
+	PongTimeout  time.Duration `mapstructure:"pong_timeout"`
+	WriteTimeout time.Duration `mapstructure:"write_timeout"`
+	SendBuffer   int           `mapstructure:"send_buffer"`
+	// AllowedOrigins are host patterns cross-origin WebSocket clients may
+	// connect from; same-origin connections are always allowed.
+	AllowedOrigins []string `mapstructure:"allowed_origins"`
+}
+
+type LogsConfig struct {
+	// Retention is how long deployment logs are kept. Logs are stored in
+	// daily partitions, so they are dropped a whole day at a time.
+	Retention time.Duration `mapstructure:"retention"`
+	// PartitionsAhead is how many days of partitions are created in advance.
+	PartitionsAhead     int           `mapstructure:"partitions_ahead"`
+	MaintenanceInterval time.Duration `mapstructure:"maintenance_interval"`
+	// CollectInterval is how often a running container's output is copied
+	// into its deployment's stored log.
+	CollectInterval time.Duration `mapstructure:"collect_interval"`
+}
+
+type SecretsConfig struct {
+	// MasterKey is the base64 encoding of at least 32 random bytes. Values
+	// encrypted at rest cannot be read without it, so it must be kept
+	// separately from database backups.
+	MasterKey string `mapstructure:"master_key"`
+}
+
+// WebhooksConfig holds the settings of git provider webhooks. Deliveries
+// are verified with the secret of the project they are for, not with
+// settings shared by every project.
+type WebhooksConfig struct {
+	// DeliveryTTL is how long delivery IDs are remembered so redelivered
+	// webhooks are not deployed twice.
+	DeliveryTTL time.Duration `mapstructure:"delivery_ttl"`
+}
+
+// PreviewsConfig holds the settings of branch preview environments.
+type PreviewsConfig struct {
+	// Domain is the wildcard domain previews are served under; each preview
+	// gets its own subdomain of it.
+	Domain string `mapstructure:"domain"`
+}
+
+// DomainsConfig holds how often custom domains are checked for the DNS
+// record proving a project controls them.
+type DomainsConfig struct {
+	// CheckInterval is how often checks that have come due are queued.
+	CheckInterval time.Duration `mapstructure:"check_interval"`
+	// RetryInterval is how long a pending domain waits between checks.
+	RetryInterval time.Duration `mapstructure:"retry_interval"`
+	// RecheckInterval is how long a verified domain waits before it is
+	// checked again.
+	RecheckInterval time.Duration `mapstructure:"recheck_interval"`
+	// VerificationTimeout is how long a domain stays pending before it
+	// fails.
+	VerificationTimeout time.Duration `mapstructure:"verification_timeout"`
+}
+
+// ACMEConfig holds how TLS certificates for verified custom domains are
+// obtained from an ACME certificate authority such as Let's Encrypt.
+type ACMEConfig struct {
+	// Enabled turns on issuing certificates for verified domains.
+	Enabled bool `mapstructure:"enabled"`
+	// DirectoryURL is the ACME directory of the certificate authority.
+	DirectoryURL string `mapstructure:"directory_url"`
+	// Email is registered with the account so the authority can send
+	// expiry notices. Optional.
+	Email string `mapstructure:"email"`
+	// RenewBefore is how long before expiry a certificate is renewed.
+	RenewBefore time.Duration `mapstructure:"renew_before"`
+	// CheckInterval is how often certificates that have come due are
+	// queued for issuance.
+	CheckInterval time.Duration `mapstructure:"check_interval"`
+	// RetryInterval is how long a failed issuance waits before it is tried
+	// again.
+	RetryInterval time.Duration `mapstructure:"retry_interval"`
+	// IssueTimeout bounds a single issuance, including the authority
+	// validating the HTTP-01 challenge.
+	IssueTimeout time.Duration `mapstructure:"issue_timeout"`
+}
+
+// ManagedDatabasesConfig holds how the PostgreSQL and Redis databases
+// projects provision are run. Each runs in its own container next to the
+// deployments, on the runtime's network when one is set.
+type ManagedDatabasesConfig struct {
+	// PostgresImage and RedisImage are the images databases are run from,
+	// tagged with the version a database asks for.
+	PostgresImage string `mapstructure:"postgres_image"`
+	RedisImage    string `mapstructure:"redis_image"`
+	// ReadyTimeout is how long a new database has to start accepting
+	// connections before its provisioning fails.
+	ReadyTimeout time.Duration `mapstructure:"ready_timeout"`
+}
+
+// BackupsConfig holds where backups of managed databases are stored and
+// how often scheduled ones are checked for.
+type BackupsConfig struct {
+	// Store is the object store backups are kept in: "filesystem" or "s3".
+	Store string `mapstructure:"store"`
+	// Dir is the directory of the filesystem store.
+	Dir string   `mapstructure:"dir"`
+	S3  S3Config `mapstructure:"s3"`
+	// CheckInterval is how often backups that have come due are queued.
+	CheckInterval time.Duration `mapstructure:"check_interval"`
+	// Timeout bounds a single backup or restore.
+	Timeout time.Duration `mapstructure:"timeout"`
+}
+
+// S3Config locates a bucket of an S3-compatible object store, such as AWS
+// S3 or MinIO. Objects are addressed path-style, as endpoint/bucket/key.
+type S3Config struct {
+	Endpoint        string `mapstructure:"endpoint"`
+	Region          string `mapstructure:"region"`
+	Bucket          string `mapstructure:"bucket"`
+	AccessKeyID     string `mapstructure:"access_key_id"`
+	SecretAccessKey string `mapstructure:"secret_access_key"`
+}
+
 func Load() (*Config, error) {
 	v := viper.New()
 
@@ -94,6 +294,12 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("server.read_timeout", "15s")
 	v.SetDefault("server.write_timeout", "15s")
 	v.SetDefault("server.idle_timeout", "60s")
+	v.SetDefault("server.proxy.enabled", false)
+	v.SetDefault("server.proxy.host", "0.0.0.0")
+	v.SetDefault("server.proxy.http_port", "80")
+	v.SetDefault("server.proxy.https_port", "443")
+	v.SetDefault("server.proxy.refresh_interval", "30s")
+	v.SetDefault("server.proxy.health_interval", "5s")
 
 	// Database defaults
 	v.SetDefault("database.host", "localhost")
@@ -106,17 +312,107 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("database.max_idle_conns", 5)
 	v.SetDefault("database.conn_max_lifetime", "5m")
 	v.SetDefault("database.conn_max_idle_time", "5m")
-	v.SetDefault("database.migrations_dir", "migrations")
+	v.SetDefault("database.migrations_dir", "")
+	v.SetDefault("database.auto_migrate", false)
 
 	// JWT defaults
 	v.SetDefault("jwt.secret", "your-secret-key")
-	v.SetDefault("jwt.expiration", "24h")
+	v.SetDefault("jwt.expiration", "15m")
+	v.SetDefault("jwt.refresh_expiration", "720h")
+
+	// Session defaults
+	v.SetDefault("session.cookie_name", "deployease_session")
+	v.SetDefault("session.expiration", "168h")
+	v.SetDefault("session.secure_cookie", false)
 
 	// Redis defaults
 	v.SetDefault("redis.host", "localhost")
 	v.SetDefault("redis.port", "6379")
 	v.SetDefault("redis.password", "")
 	v.SetDefault("redis.db", 0)
+
+	// Job queue defaults
+	v.SetDefault("jobs.concurrency", 4)
+	v.SetDefault("jobs.poll_interval", "1s")
+	v.SetDefault("jobs.visibility_timeout", "5m")
+	v.SetDefault("jobs.max_attempts", 5)
+	v.SetDefault("jobs.base_backoff", "5s")
+	v.SetDefault("jobs.max_backoff", "10m")
+	v.SetDefault("jobs.shutdown_timeout", "5m")
+
+	// Build defaults
+	v.SetDefault("build.work_dir", "")
+	v.SetDefault("build.docker_binary", "docker")
+	v.SetDefault("build.image_prefix", "deployease")
+	v.SetDefault("build.timeout", "30m")
+
+	// Runtime defaults
+	v.SetDefault("runtime.docker_host", "unix:///var/run/docker.sock")
+	v.SetDefault("runtime.docker_api_version", "v1.43")
+	v.SetDefault("runtime.network", "")
+	v.SetDefault("runtime.container_port", 8080)
+	v.SetDefault("runtime.stop_timeout", "10s")
+
+	// Rollout defaults
+	v.SetDefault("rollout.health_path", "/")
+	v.SetDefault("rollout.health_timeout", "60s")
+	v.SetDefault("rollout.probe_interval", "2s")
+	v.SetDefault("rollout.drain_grace", "30s")
+	v.SetDefault("rollout.rollback_window", "5m")
+	v.SetDefault("rollout.verify_interval", "15s")
+
+	// Events defaults
+	v.SetDefault("events.ping_interval", "30s")
+	v.SetDefault("events.pong_timeout", "10s")
+	v.SetDefault("events.write_timeout", "10s")
+	v.SetDefault("events.send_buffer", 256)
+	v.SetDefault("events.allowed_origins", []string{})
+
+	// Logs defaults
+	v.SetDefault("logs.retention", "720h")
+	v.SetDefault("logs.partitions_ahead", 3)
+	v.SetDefault("logs.maintenance_interval", "1h")
+	v.SetDefault("logs.collect_interval", "10s")
+
+	// Secrets defaults
+	v.SetDefault("secrets.master_key", "")
+
+	// Webhooks defaults
+	v.SetDefault("webhooks.delivery_ttl", "72h")
+
+	// Previews defaults
+	v.SetDefault("previews.domain", "")
+
+	// Domains defaults
+	v.SetDefault("domains.check_interval", "1m")
+	v.SetDefault("domains.retry_interval", "5m")
+	v.SetDefault("domains.recheck_interval", "24h")
+	v.SetDefault("domains.verification_timeout", "72h")
+
+	// ACME defaults
+	v.SetDefault("acme.enabled", false)
+	v.SetDefault("acme.directory_url", "https://acme-v02.api.letsencrypt.org/directory")
+	v.SetDefault("acme.email", "")
+	v.SetDefault("acme.renew_before", "720h")
+	v.SetDefault("acme.check_interval", "5m")
+	v.SetDefault("acme.retry_interval", "1h")
+	v.SetDefault("acme.issue_timeout", "2m")
+
+	// Managed databases defaults
+	v.SetDefault("managed_databases.postgres_image", "postgres")
+	v.SetDefault("managed_databases.redis_image", "redis")
+	v.SetDefault("managed_databases.ready_timeout", "2m")
+
+	// Backups defaults
+	v.SetDefault("backups.store", "filesystem")
+	v.SetDefault("backups.dir", "/var/lib/deployease/backups")
+	v.SetDefault("backups.s3.endpoint", "")
+	v.SetDefault("backups.s3.region", "us-east-1")
+	v.SetDefault("backups.s3.bucket", "")
+	v.SetDefault("backups.s3.access_key_id", "")
+	v.SetDefault("backups.s3.secret_access_key", "")
+	v.SetDefault("backups.check_interval", "1m")
+	v.SetDefault("backups.timeout", "1h")
 }
 
 func (c *Config) Validate() error {
@@ -124,6 +420,14 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("server port is required")
 	}
 
+	if c.Server.Proxy.Enabled && c.Server.Proxy.HTTPPort == "" {
+		return fmt.Errorf("proxy http port is required when the proxy is enabled")
+	}
+
+	if c.Server.Proxy.RefreshInterval <= 0 || c.Server.Proxy.HealthInterval <= 0 {
+		return fmt.Errorf("proxy refresh and health intervals must be positive")
+	}
+
 	if c.Database.Host == "" {
 		return fmt.Errorf("database host is required")
 	}
@@ -132,6 +436,93 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("JWT secret must be set and not use default value")
 	}
 
+	if c.Secrets.MasterKey == "" {
+		return fmt.Errorf("secrets master key is required")
+	}
+
+	if c.Jobs.Concurrency < 1 {
+		return fmt.Errorf("jobs concurrency must be at least 1")
+	}
+	if c.Jobs.PollInterval <= 0 {
+		return fmt.Errorf("jobs poll interval must be positive")
+	}
+	// The worker heartbeats every half visibility timeout, so anything
+	// shorter would extend leases faster than it could usefully run jobs.
+	if c.Jobs.VisibilityTimeout < time.Second {
+		return fmt.Errorf("jobs visibility timeout must be at least 1s")
+	}
+	if c.Jobs.MaxAttempts < 1 {
+		return fmt.Errorf("jobs max attempts must be at least 1")
+	}
+	if c.Jobs.BaseBackoff <= 0 || c.Jobs.MaxBackoff < c.Jobs.BaseBackoff {
+		return fmt.Errorf("jobs base backoff must be positive and no greater than max backoff")
+	}
+
+	if c.Rollout.ProbeInterval <= 0 || c.Rollout.VerifyInterval <= 0 {
+		return fmt.Errorf("rollout probe and verify intervals must be positive")
+	}
+
+	if c.Events.PingInterval <= 0 || c.Events.SendBuffer < 1 {
+		return fmt.Errorf("events ping interval and send buffer must be positive")
+	}
+
+	if c.Logs.Retention < 24*time.Hour {
+		return fmt.Errorf("logs retention must be at least a day")
+	}
+
+	if c.Logs.MaintenanceInterval <= 0 || c.Logs.CollectInterval <= 0 {
+		return fmt.Errorf("logs maintenance and collect intervals must be positive")
+	}
+
+	if strings.ContainsAny(c.Previews.Domain, "/:*") {
+		return fmt.Errorf("previews domain must be a bare domain name")
+	}
+
+	if c.Domains.CheckInterval <= 0 || c.Domains.RetryInterval <= 0 || c.Domains.RecheckInterval <= 0 {
+		return fmt.Errorf("domains check, retry and recheck intervals must be positive")
+	}
+
+	if c.Domains.VerificationTimeout < c.Domains.RetryInterval {
+		return fmt.Errorf("domains verification timeout must be at least the retry interval")
+	}
+
+	if c.ACME.Enabled && c.ACME.DirectoryURL == "" {
+		return fmt.Errorf("acme directory url is required when acme is enabled")
+	}
+
+	if c.ACME.RenewBefore <= 0 || c.ACME.CheckInterval <= 0 || c.ACME.RetryInterval <= 0 || c.ACME.IssueTimeout <= 0 {
+		return fmt.Errorf("acme renew before, check and retry intervals and issue timeout must be positive")
+	}
+
+	if c.ManagedDatabases.PostgresImage == "" || c.ManagedDatabases.RedisImage == "" {
+		return fmt.Errorf("managed databases postgres and redis images are required")
+	}
+
+	if c.ManagedDatabases.ReadyTimeout <= 0 {
+		return fmt.Errorf("managed databases ready timeout must be positive")
+	}
+
+	switch c.Backups.Store {
+	case "filesystem":
+		if c.Backups.Dir == "" {
+			return fmt.Errorf("backups dir is required for the filesystem store")
+		}
+	case "s3":
+		if c.Backups.S3.Endpoint == "" || c.Backups.S3.Bucket == "" || c.Backups.S3.AccessKeyID == "" || c.Backups.S3.SecretAccessKey == "" {
+			return fmt.Errorf("backups s3 endpoint, bucket and credentials are required for the s3 store")
+		}
+	default:
+		return fmt.Errorf("backups store must be filesystem or s3")
+	}
+
+	if c.Backups.CheckInterval <= 0 || c.Backups.Timeout <= 0 {
+		return fmt.Errorf("backups check interval and timeout must be positive")
+	}
+
+	if c.Jobs.VisibilityTimeout <= 0 {
+		return fmt.Errorf("jobs visibility timeout must be positive")
+	}
+
 	return nil
 }
 
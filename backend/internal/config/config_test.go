@@ -2,10 +2,18 @@ package config
 
 import (
 	"os"
+	"strings"
 	"testing"
 	"time"
 )
 
+const testMasterKey = "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY="
+
+func TestMain(m *testing.M) {
+	os.Setenv("DEPLOYEASE_SECRETS_MASTER_KEY", testMasterKey)
+	os.Exit(m.Run())
+}
+
 func TestLoad(t *testing.T) {
 	// set required env variables
 	os.Setenv("DEPLOYEASE_JWT_SECRET", "test-jwt-secret")
@@ -26,6 +34,10 @@ func TestLoad(t *testing.T) {
 		t.Errorf("expected server host to be 0.0.0.0, got %s", cfg.Server.Host)
 	}
 
+	if cfg.Server.Proxy.Enabled || cfg.Server.Proxy.HTTPPort != "80" {
+		t.Errorf("expected proxy to be disabled on port 80, got enabled=%t port %s", cfg.Server.Proxy.Enabled, cfg.Server.Proxy.HTTPPort)
+	}
+
 	if cfg.Database.Host != "localhost" {
 		t.Errorf("expected database host to be localhost, got %s", cfg.Database.Host)
 	}
@@ -33,6 +45,10 @@ func TestLoad(t *testing.T) {
 	if cfg.Database.Port != "5432" {
 		t.Errorf("expected database port to be 5432, got %s", cfg.Database.Port)
 	}
+
+	if cfg.Database.MigrationsDir != "" || cfg.Database.AutoMigrate {
+		t.Errorf("expected embedded migrations without auto-migrate, got dir %q auto_migrate=%t", cfg.Database.MigrationsDir, cfg.Database.AutoMigrate)
+	}
 }
 
 func TestLoadWithEnvironmentalVariables(t *testing.T) {
@@ -105,8 +121,155 @@ func TestConfigurationTypes(t *testing.T) {
 		t.Errorf("Expected idle timeout to be %v, got %v", expectedIdleTimeout, cfg.Server.IdleTimeout)
 	}
 
-	expectedJWTExpiration := 24 * time.Hour
+	expectedJWTExpiration := 15 * time.Minute
 	if cfg.JWT.Expiration != expectedJWTExpiration {
 		t.Errorf("Expected JWT expiration to be %v, got %v", expectedJWTExpiration, cfg.JWT.Expiration)
 	}
+
+	expectedRefreshExpiration := 720 * time.Hour
+	if cfg.JWT.RefreshExpiration != expectedRefreshExpiration {
+		t.Errorf("Expected JWT refresh expiration to be %v, got %v", expectedRefreshExpiration, cfg.JWT.RefreshExpiration)
+	}
+
+	expectedVisibilityTimeout := 5 * time.Minute
+	if cfg.Jobs.VisibilityTimeout != expectedVisibilityTimeout {
+		t.Errorf("Expected jobs visibility timeout to be %v, got %v", expectedVisibilityTimeout, cfg.Jobs.VisibilityTimeout)
+	}
+
+	expectedLogRetention := 30 * 24 * time.Hour
+	if cfg.Logs.Retention != expectedLogRetention {
+		t.Errorf("Expected log retention to be %v, got %v", expectedLogRetention, cfg.Logs.Retention)
+	}
+
+	expectedRenewBefore := 30 * 24 * time.Hour
+	if cfg.ACME.RenewBefore != expectedRenewBefore {
+		t.Errorf("Expected ACME renew before to be %v, got %v", expectedRenewBefore, cfg.ACME.RenewBefore)
+	}
+
+	expectedProxyRefresh := 30 * time.Second
+	if cfg.Server.Proxy.RefreshInterval != expectedProxyRefresh {
+		t.Errorf("Expected proxy refresh interval to be %v, got %v", expectedProxyRefresh, cfg.Server.Proxy.RefreshInterval)
+	}
+
+	expectedReadyTimeout := 2 * time.Minute
+	if cfg.ManagedDatabases.ReadyTimeout != expectedReadyTimeout {
+		t.Errorf("Expected managed databases ready timeout to be %v, got %v", expectedReadyTimeout, cfg.ManagedDatabases.ReadyTimeout)
+	}
+
+	if cfg.Backups.Store != "filesystem" {
+		t.Errorf("Expected backups store to be filesystem, got %s", cfg.Backups.Store)
+	}
+}
+
+func TestConfigValidationRequiresMasterKey(t *testing.T) {
+	os.Setenv("DEPLOYEASE_JWT_SECRET", "test-jwt-secret")
+	os.Unsetenv("DEPLOYEASE_SECRETS_MASTER_KEY")
+	defer func() {
+		os.Unsetenv("DEPLOYEASE_JWT_SECRET")
+		os.Setenv("DEPLOYEASE_SECRETS_MASTER_KEY", testMasterKey)
+	}()
+
+	_, err := Load()
+	if err == nil || !strings.Contains(err.Error(), "master key") {
+		t.Errorf("Expected validation error for missing secrets master key, got %v", err)
+	}
+}
+
+func TestConfigValidationRequiresS3Bucket(t *testing.T) {
+	os.Setenv("DEPLOYEASE_JWT_SECRET", "test-jwt-secret")
+	os.Setenv("DEPLOYEASE_BACKUPS_STORE", "s3")
+	os.Setenv("DEPLOYEASE_BACKUPS_S3_ENDPOINT", "http://localhost:9000")
+	defer func() {
+		os.Unsetenv("DEPLOYEASE_JWT_SECRET")
+		os.Unsetenv("DEPLOYEASE_BACKUPS_STORE")
+		os.Unsetenv("DEPLOYEASE_BACKUPS_S3_ENDPOINT")
+	}()
+
+	_, err := Load()
+	if err == nil || !strings.Contains(err.Error(), "backups s3") {
+		t.Errorf("Expected validation error for incomplete s3 store, got %v", err)
+	}
+}
+
+func TestRedacted(t *testing.T) {
+	cfg := Config{
+		Database: DatabaseConfig{Host: "db", Password: "db-password"},
+		JWT:      JWTConfig{Secret: "jwt-secret"},
+		Secrets:  SecretsConfig{MasterKey: testMasterKey},
+		Backups:  BackupsConfig{S3: S3Config{AccessKeyID: "AKID", SecretAccessKey: "s3-secret"}},
+	}
+
+	redactedCfg := cfg.Redacted()
+
+	if redactedCfg.Database.Password != redacted || redactedCfg.JWT.Secret != redacted ||
+		redactedCfg.Secrets.MasterKey != redacted || redactedCfg.Backups.S3.SecretAccessKey != redacted {
+		t.Errorf("expected secrets to be redacted, got %+v", redactedCfg)
+	}
+
+	if redactedCfg.Redis.Password != "" {
+		t.Errorf("expected unset secrets to stay empty, got %q", redactedCfg.Redis.Password)
+	}
+
+	if redactedCfg.Database.Host != "db" || redactedCfg.Backups.S3.AccessKeyID != "AKID" {
+		t.Errorf("expected other settings to be kept, got host %q access key %q", redactedCfg.Database.Host, redactedCfg.Backups.S3.AccessKeyID)
+	}
+
+	if cfg.JWT.Secret != "jwt-secret" {
+		t.Errorf("expected the original configuration to be unchanged, got JWT secret %q", cfg.JWT.Secret)
+	}
+}
+
+func TestSettings(t *testing.T) {
+	cfg := Config{
+		Server:  ServerConfig{Port: "8080", ReadTimeout: 90 * time.Second},
+		Backups: BackupsConfig{S3: S3Config{Region: "eu-west-1"}},
+	}
+
+	settings := cfg.Settings()
+
+	server, ok := settings["server"].(map[string]any)
+	if !ok {
+		t.Fatalf("expected server settings to be a map, got %T", settings["server"])
+	}
+	if server["port"] != "8080" || server["read_timeout"] != "1m30s" {
+		t.Errorf("expected port 8080 and read timeout 1m30s, got %v and %v", server["port"], server["read_timeout"])
+	}
+
+	backups := settings["backups"].(map[string]any)
+	s3 := backups["s3"].(map[string]any)
+	if s3["region"] != "eu-west-1" {
+		t.Errorf("expected s3 region eu-west-1, got %v", s3["region"])
+	}
+
+	if _, ok := settings["managed_databases"]; !ok {
+		t.Errorf("expected managed_databases settings, got keys %v", settings)
+	}
+}
+
+func TestConfigValidationJobs(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want string
+	}{
+		{"zero poll interval", map[string]string{"DEPLOYEASE_JOBS_POLL_INTERVAL": "0s"}, "jobs poll interval"},
+		{"sub-second visibility timeout", map[string]string{"DEPLOYEASE_JOBS_VISIBILITY_TIMEOUT": "500ms"}, "jobs visibility timeout"},
+		{"zero max attempts", map[string]string{"DEPLOYEASE_JOBS_MAX_ATTEMPTS": "0"}, "jobs max attempts"},
+		{"zero base backoff", map[string]string{"DEPLOYEASE_JOBS_BASE_BACKOFF": "0s"}, "jobs base backoff"},
+		{"max below base backoff", map[string]string{"DEPLOYEASE_JOBS_BASE_BACKOFF": "1m", "DEPLOYEASE_JOBS_MAX_BACKOFF": "30s"}, "jobs base backoff"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("DEPLOYEASE_JWT_SECRET", "test-jwt-secret")
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			_, err := Load()
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected jobs validation error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
 }
@@ -0,0 +1,53 @@
+package config
+
+import (
+	"reflect"
+	"time"
+)
+
+// redacted replaces secrets in configuration printed for humans.
+const redacted = "[REDACTED]"
+
+// Redacted returns a copy of the configuration with every secret that is
+// set replaced, safe to print or attach to bug reports.
+func (c Config) Redacted() Config {
+	for _, secret := range []*string{
+		&c.Database.Password,
+		&c.JWT.Secret,
+		&c.Redis.Password,
+		&c.Secrets.MasterKey,
+		&c.Backups.S3.SecretAccessKey,
+	} {
+		if *secret != "" {
+			*secret = redacted
+		}
+	}
+	return c
+}
+
+// Settings returns the configuration keyed the way config files name it,
+// with durations written as they are configured, such as "1m30s".
+func (c Config) Settings() map[string]any {
+	return settings(reflect.ValueOf(c))
+}
+
+func settings(v reflect.Value) map[string]any {
+	out := make(map[string]any, v.NumField())
+	for i := range v.NumField() {
+		key := v.Type().Field(i).Tag.Get("mapstructure")
+		if key == "" {
+			continue
+		}
+
+		field := v.Field(i)
+		switch {
+		case field.Type() == reflect.TypeOf(time.Duration(0)):
+			out[key] = time.Duration(field.Int()).String()
+		case field.Kind() == reflect.Struct:
+			out[key] = settings(field)
+		default:
+			out[key] = field.Interface()
+		}
+	}
+	return out
+}
@@ -0,0 +1,258 @@
+// Package containertest provides an in-memory container.Runtime for tests.
+package containertest
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Jesuloba-world/deployease/backend/internal/container"
+)
+
+// Runtime keeps containers in memory. Containers "run" as soon as they are
+// started and stay running until stopped. Setting StartErr makes every Start
+// fail, which is how tests simulate an application that crashes on boot;
+// PullErr does the same for pulls. ExecFunc stands in for commands run with
+// Exec; without it they exit 0 with no output.
+type Runtime struct {
+	StartErr error
+	PullErr  error
+	ExecFunc func(id string, opts container.ExecOptions) (int, error)
+
+	mu         sync.Mutex
+	next       int
+	containers map[string]*fakeContainer
+	pulled     []string
+	now        func() time.Time
+}
+
+type fakeContainer struct {
+	spec container.Spec
+	info container.Info
+	logs []logLine
+}
+
+type logLine struct {
+	time time.Time
+	text string
+}
+
+func NewRuntime() *Runtime {
+	return &Runtime{
+		containers: make(map[string]*fakeContainer),
+		now:        time.Now,
+	}
+}
+
+func (r *Runtime) Pull(ctx context.Context, image string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.PullErr != nil {
+		return r.PullErr
+	}
+	r.pulled = append(r.pulled, image)
+	return nil
+}
+
+// Pulled returns the images pulled so far, in order.
+func (r *Runtime) Pulled() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.pulled...)
+}
+
+func (r *Runtime) Create(ctx context.Context, spec container.Spec) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, c := range r.containers {
+		if spec.Name != "" && c.info.Name == spec.Name {
+			return "", fmt.Errorf("%w: name %q is already in use", container.ErrConflict, spec.Name)
+		}
+	}
+
+	r.next++
+	id := fmt.Sprintf("container-%d", r.next)
+	r.containers[id] = &fakeContainer{
+		spec: spec,
+		info: container.Info{
+			ID:        id,
+			Name:      spec.Name,
+			Image:     spec.Image,
+			State:     container.StateCreated,
+			Labels:    maps.Clone(spec.Labels),
+			IPAddress: fmt.Sprintf("10.0.0.%d", r.next),
+		},
+	}
+	return id, nil
+}
+
+func (r *Runtime) Start(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c, err := r.get(id)
+	if err != nil {
+		return err
+	}
+	if r.StartErr != nil {
+		c.info.State = container.StateExited
+		c.info.ExitCode = 1
+		return r.StartErr
+	}
+
+	c.info.State = container.StateRunning
+	c.info.StartedAt = r.now()
+	c.appendLog(r.now(), fmt.Sprintf("started %s", c.spec.Image))
+	return nil
+}
+
+func (r *Runtime) Stop(ctx context.Context, id string, timeout time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c, err := r.get(id)
+	if err != nil {
+		return err
+	}
+	if c.info.State == container.StateRunning {
+		c.info.State = container.StateExited
+		c.info.FinishedAt = r.now()
+	}
+	return nil
+}
+
+func (r *Runtime) Remove(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c, err := r.get(id)
+	if err != nil {
+		return err
+	}
+	delete(r.containers, c.info.ID)
+	return nil
+}
+
+func (r *Runtime) Inspect(ctx context.Context, id string) (*container.Info, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c, err := r.get(id)
+	if err != nil {
+		return nil, err
+	}
+	info := c.info
+	info.Labels = maps.Clone(c.info.Labels)
+	return &info, nil
+}
+
+// Logs returns the output written so far; Follow is not simulated.
+func (r *Runtime) Logs(ctx context.Context, id string, opts container.LogsOptions) (io.ReadCloser, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c, err := r.get(id)
+	if err != nil {
+		return nil, err
+	}
+	lines := c.logs
+	if opts.Tail > 0 && len(lines) > opts.Tail {
+		lines = lines[len(lines)-opts.Tail:]
+	}
+	var b strings.Builder
+	for _, l := range lines {
+		if !opts.Since.IsZero() && l.time.Before(opts.Since) {
+			continue
+		}
+		if opts.Timestamps {
+			b.WriteString(l.time.UTC().Format(time.RFC3339Nano) + " ")
+		}
+		b.WriteString(l.text + "\n")
+	}
+	return io.NopCloser(strings.NewReader(b.String())), nil
+}
+
+// Exec runs ExecFunc for running containers, failing like the engine does
+// for stopped ones.
+func (r *Runtime) Exec(ctx context.Context, id string, opts container.ExecOptions) (int, error) {
+	r.mu.Lock()
+	c, err := r.get(id)
+	if err == nil && c.info.State != container.StateRunning {
+		err = fmt.Errorf("%w: container %s is not running", container.ErrConflict, id)
+	}
+	exec := r.ExecFunc
+	r.mu.Unlock()
+	if err != nil {
+		return 0, err
+	}
+
+	if exec == nil {
+		return 0, nil
+	}
+	return exec(c.info.ID, opts)
+}
+
+// WriteLog appends a line to a container's log output.
+func (r *Runtime) WriteLog(id, line string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if c, ok := r.containers[id]; ok {
+		c.appendLog(r.now(), line)
+	}
+}
+
+// appendLog keeps timestamps strictly increasing at the microsecond
+// precision logs are stored with, as resuming after a line relies on them.
+func (c *fakeContainer) appendLog(t time.Time, text string) {
+	t = t.Truncate(time.Microsecond)
+	if n := len(c.logs); n > 0 && !t.After(c.logs[n-1].time) {
+		t = c.logs[n-1].time.Add(time.Microsecond)
+	}
+	c.logs = append(c.logs, logLine{time: t, text: text})
+}
+
+// Spec returns the spec a container was created from.
+func (r *Runtime) Spec(id string) (container.Spec, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	c, ok := r.containers[id]
+	if !ok {
+		return container.Spec{}, false
+	}
+	return c.spec, true
+}
+
+// Running returns the IDs of running containers carrying the given label.
+func (r *Runtime) Running(label, value string) []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var ids []string
+	for id, c := range r.containers {
+		if c.info.State == container.StateRunning && c.info.Labels[label] == value {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// get looks a container up by ID or name, like the Docker API does.
+func (r *Runtime) get(id string) (*fakeContainer, error) {
+	if c, ok := r.containers[id]; ok {
+		return c, nil
+	}
+	for _, c := range r.containers {
+		if c.info.Name != "" && c.info.Name == id {
+			return c, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", container.ErrNotFound, id)
+}
+
+var _ container.Runtime = (*Runtime)(nil)
@@ -0,0 +1,513 @@
+package container
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Jesuloba-world/deployease/backend/internal/config"
+)
+
+// DockerRuntime talks to the Docker Engine API over its unix socket.
+type DockerRuntime struct {
+	client     *http.Client
+	dial       func(ctx context.Context) (net.Conn, error)
+	apiVersion string
+}
+
+func NewDockerRuntime(cfg config.RuntimeConfig) (*DockerRuntime, error) {
+	socket, ok := strings.CutPrefix(cfg.DockerHost, "unix://")
+	if !ok {
+		return nil, fmt.Errorf("unsupported docker host %q: only unix:// sockets are supported", cfg.DockerHost)
+	}
+
+	dial := func(ctx context.Context) (net.Conn, error) {
+		var d net.Dialer
+		return d.DialContext(ctx, "unix", socket)
+	}
+	transport := &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return dial(ctx)
+		},
+	}
+
+	return &DockerRuntime{
+		client:     &http.Client{Transport: transport},
+		dial:       dial,
+		apiVersion: cfg.DockerAPIVersion,
+	}, nil
+}
+
+type dockerCreateRequest struct {
+	Image        string              `json:"Image"`
+	Env          []string            `json:"Env,omitempty"`
+	Cmd          []string            `json:"Cmd,omitempty"`
+	Labels       map[string]string   `json:"Labels,omitempty"`
+	ExposedPorts map[string]struct{} `json:"ExposedPorts,omitempty"`
+	HostConfig   dockerHostConfig    `json:"HostConfig"`
+}
+
+type dockerHostConfig struct {
+	NetworkMode   string              `json:"NetworkMode,omitempty"`
+	RestartPolicy dockerRestartPolicy `json:"RestartPolicy"`
+	Memory        int64               `json:"Memory,omitempty"`
+}
+
+type dockerRestartPolicy struct {
+	Name string `json:"Name"`
+}
+
+// Pull fetches an image. The engine streams progress as JSON messages and
+// reports a failure part way through in one of them rather than through the
+// status code.
+func (r *DockerRuntime) Pull(ctx context.Context, image string) error {
+	resp, err := r.request(ctx, http.MethodPost, "/images/create", url.Values{"fromImage": {image}}, nil)
+	if err != nil {
+		return fmt.Errorf("failed to pull image: %w", err)
+	}
+	defer resp.Body.Close()
+
+	dec := json.NewDecoder(resp.Body)
+	for {
+		var msg struct {
+			Error string `json:"error"`
+		}
+		if err := dec.Decode(&msg); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return fmt.Errorf("failed to read pull progress: %w", err)
+		}
+		if msg.Error != "" {
+			return fmt.Errorf("failed to pull image %s: %s", image, msg.Error)
+		}
+	}
+}
+
+func (r *DockerRuntime) Create(ctx context.Context, spec Spec) (string, error) {
+	body := dockerCreateRequest{
+		Image:  spec.Image,
+		Labels: spec.Labels,
+		Cmd:    spec.Cmd,
+		HostConfig: dockerHostConfig{
+			NetworkMode:   spec.Network,
+			RestartPolicy: dockerRestartPolicy{Name: "unless-stopped"},
+			Memory:        spec.Memory,
+		},
+	}
+
+	keys := make([]string, 0, len(spec.Env))
+	for k := range spec.Env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		body.Env = append(body.Env, k+"="+spec.Env[k])
+	}
+
+	if spec.Port > 0 {
+		body.ExposedPorts = map[string]struct{}{fmt.Sprintf("%d/tcp", spec.Port): {}}
+	}
+
+	query := url.Values{}
+	if spec.Name != "" {
+		query.Set("name", spec.Name)
+	}
+
+	var created struct {
+		ID string `json:"Id"`
+	}
+	if err := r.do(ctx, http.MethodPost, "/containers/create", query, body, &created); err != nil {
+		return "", fmt.Errorf("failed to create container: %w", err)
+	}
+
+	return created.ID, nil
+}
+
+func (r *DockerRuntime) Start(ctx context.Context, id string) error {
+	if err := r.do(ctx, http.MethodPost, "/containers/"+id+"/start", nil, nil, nil); err != nil {
+		return fmt.Errorf("failed to start container: %w", err)
+	}
+	return nil
+}
+
+// Stop gives the container timeout to exit before it is killed. The engine
+// takes whole seconds, so the timeout is rounded up rather than cut to zero.
+func (r *DockerRuntime) Stop(ctx context.Context, id string, timeout time.Duration) error {
+	query := url.Values{"t": {strconv.Itoa(int(math.Ceil(timeout.Seconds())))}}
+	if err := r.do(ctx, http.MethodPost, "/containers/"+id+"/stop", query, nil, nil); err != nil {
+		return fmt.Errorf("failed to stop container: %w", err)
+	}
+	return nil
+}
+
+func (r *DockerRuntime) Remove(ctx context.Context, id string) error {
+	query := url.Values{"force": {"true"}, "v": {"true"}}
+	err := r.do(ctx, http.MethodDelete, "/containers/"+id, query, nil, nil)
+	if err != nil {
+		return fmt.Errorf("failed to remove container: %w", err)
+	}
+	return nil
+}
+
+type dockerInspectResponse struct {
+	ID     string `json:"Id"`
+	Name   string `json:"Name"`
+	Config struct {
+		Image  string            `json:"Image"`
+		Labels map[string]string `json:"Labels"`
+	} `json:"Config"`
+	State struct {
+		Status     string    `json:"Status"`
+		ExitCode   int       `json:"ExitCode"`
+		StartedAt  time.Time `json:"StartedAt"`
+		FinishedAt time.Time `json:"FinishedAt"`
+	} `json:"State"`
+	NetworkSettings struct {
+		IPAddress string `json:"IPAddress"`
+		Networks  map[string]struct {
+			IPAddress string `json:"IPAddress"`
+		} `json:"Networks"`
+	} `json:"NetworkSettings"`
+}
+
+func (r *DockerRuntime) Inspect(ctx context.Context, id string) (*Info, error) {
+	var resp dockerInspectResponse
+	if err := r.do(ctx, http.MethodGet, "/containers/"+id+"/json", nil, nil, &resp); err != nil {
+		return nil, fmt.Errorf("failed to inspect container: %w", err)
+	}
+
+	info := &Info{
+		ID:         resp.ID,
+		Name:       strings.TrimPrefix(resp.Name, "/"),
+		Image:      resp.Config.Image,
+		State:      State(resp.State.Status),
+		ExitCode:   resp.State.ExitCode,
+		StartedAt:  resp.State.StartedAt,
+		FinishedAt: resp.State.FinishedAt,
+		Labels:     resp.Config.Labels,
+		IPAddress:  resp.NetworkSettings.IPAddress,
+	}
+	if info.IPAddress == "" {
+		// Containers on user-defined networks only report an address per
+		// network. Pick deterministically if there are several.
+		names := make([]string, 0, len(resp.NetworkSettings.Networks))
+		for name := range resp.NetworkSettings.Networks {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if ip := resp.NetworkSettings.Networks[name].IPAddress; ip != "" {
+				info.IPAddress = ip
+				break
+			}
+		}
+	}
+
+	return info, nil
+}
+
+func (r *DockerRuntime) Logs(ctx context.Context, id string, opts LogsOptions) (io.ReadCloser, error) {
+	query := url.Values{
+		"stdout":     {"true"},
+		"stderr":     {"true"},
+		"follow":     {strconv.FormatBool(opts.Follow)},
+		"timestamps": {strconv.FormatBool(opts.Timestamps)},
+	}
+	if opts.Tail > 0 {
+		query.Set("tail", strconv.Itoa(opts.Tail))
+	}
+	if !opts.Since.IsZero() {
+		// The engine accepts fractional seconds, which lets callers resume
+		// from the timestamp of the last line they read.
+		query.Set("since", fmt.Sprintf("%d.%09d", opts.Since.Unix(), opts.Since.Nanosecond()))
+	}
+
+	resp, err := r.request(ctx, http.MethodGet, "/containers/"+id+"/logs", query, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get container logs: %w", err)
+	}
+
+	// Containers are created without a TTY, so the engine multiplexes
+	// stdout and stderr into frames.
+	return newDemuxReader(resp.Body), nil
+}
+
+type dockerExecRequest struct {
+	AttachStdin  bool     `json:"AttachStdin"`
+	AttachStdout bool     `json:"AttachStdout"`
+	AttachStderr bool     `json:"AttachStderr"`
+	Tty          bool     `json:"Tty"`
+	Cmd          []string `json:"Cmd"`
+}
+
+// Exec creates an exec instance, streams its input and output over a
+// hijacked connection and then asks the engine for the exit code.
+func (r *DockerRuntime) Exec(ctx context.Context, id string, opts ExecOptions) (int, error) {
+	body := dockerExecRequest{
+		AttachStdin:  opts.Stdin != nil,
+		AttachStdout: true,
+		AttachStderr: true,
+		Cmd:          opts.Cmd,
+	}
+	var created struct {
+		ID string `json:"Id"`
+	}
+	if err := r.do(ctx, http.MethodPost, "/containers/"+id+"/exec", nil, body, &created); err != nil {
+		return 0, fmt.Errorf("failed to create exec: %w", err)
+	}
+
+	conn, output, err := r.hijack(ctx, "/exec/"+created.ID+"/start", map[string]bool{"Detach": false, "Tty": false})
+	if err != nil {
+		return 0, fmt.Errorf("failed to start exec: %w", err)
+	}
+	defer conn.Close()
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	// Failing to write to the command only means it exited without reading
+	// everything, which its exit code reports; failing to read the input is
+	// ours to report.
+	input := &errorRecorder{r: opts.Stdin}
+	inputDone := make(chan struct{})
+	if opts.Stdin != nil {
+		go func() {
+			defer close(inputDone)
+			_, _ = io.Copy(conn, input)
+			if cw, ok := conn.(interface{ CloseWrite() error }); ok {
+				_ = cw.CloseWrite()
+			}
+		}()
+	} else {
+		close(inputDone)
+	}
+
+	err = demuxCopy(orDiscard(opts.Stdout), orDiscard(opts.Stderr), output)
+	conn.Close()
+	<-inputDone
+	if ctx.Err() != nil {
+		return 0, ctx.Err()
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read exec output: %w", err)
+	}
+	if input.err != nil {
+		return 0, fmt.Errorf("failed to read exec input: %w", input.err)
+	}
+
+	var inspected struct {
+		Running  bool `json:"Running"`
+		ExitCode int  `json:"ExitCode"`
+	}
+	if err := r.do(ctx, http.MethodGet, "/exec/"+created.ID+"/json", nil, nil, &inspected); err != nil {
+		return 0, fmt.Errorf("failed to inspect exec: %w", err)
+	}
+	if inspected.Running {
+		return 0, fmt.Errorf("exec %s closed its output but is still running", created.ID)
+	}
+	return inspected.ExitCode, nil
+}
+
+// hijack sends a request the engine answers by taking over the connection,
+// as it does to attach to an exec, and returns the raw connection along
+// with a reader for whatever the engine sent after its response headers.
+// The http.Client cannot be used for this because writing stdin needs the
+// connection's write side closed on its own.
+func (r *DockerRuntime) hijack(ctx context.Context, path string, body any) (net.Conn, *bufio.Reader, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, nil, err
+	}
+	u := url.URL{Scheme: "http", Host: "docker", Path: "/" + r.apiVersion + path}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u.String(), bytes.NewReader(data))
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "tcp")
+
+	conn, err := r.dial(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := req.Write(conn); err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+	// Older engines answer 200 instead of switching protocols, then stream
+	// the same way.
+	if resp.StatusCode != http.StatusSwitchingProtocols && resp.StatusCode != http.StatusOK {
+		defer conn.Close()
+		return nil, nil, responseError(resp)
+	}
+	return conn, br, nil
+}
+
+func (r *DockerRuntime) do(ctx context.Context, method, path string, query url.Values, body, out any) error {
+	resp, err := r.request(ctx, method, path, query, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if out == nil {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode docker response: %w", err)
+	}
+	return nil
+}
+
+// request sends an API request and returns the response for any 2xx or 304
+// status; 304 is what the engine answers when a container is already in the
+// requested state.
+func (r *DockerRuntime) request(ctx context.Context, method, path string, query url.Values, body any) (*http.Response, error) {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	u := url.URL{Scheme: "http", Host: "docker", Path: "/" + r.apiVersion + path, RawQuery: query.Encode()}
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), reader)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode < 300 || resp.StatusCode == http.StatusNotModified {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+	return nil, responseError(resp)
+}
+
+// responseError turns an engine error response into an error, mapping the
+// statuses callers act on to ErrNotFound and ErrConflict.
+func responseError(resp *http.Response) error {
+	var apiErr struct {
+		Message string `json:"message"`
+	}
+	_ = json.NewDecoder(resp.Body).Decode(&apiErr)
+
+	switch resp.StatusCode {
+	case http.StatusNotFound:
+		return fmt.Errorf("%w: %s", ErrNotFound, apiErr.Message)
+	case http.StatusConflict:
+		return fmt.Errorf("%w: %s", ErrConflict, apiErr.Message)
+	default:
+		return fmt.Errorf("docker API returned %d: %s", resp.StatusCode, apiErr.Message)
+	}
+}
+
+// demuxReader strips the 8-byte frame headers the engine puts in front of
+// each chunk of a multiplexed stdout/stderr stream.
+type demuxReader struct {
+	body      io.ReadCloser
+	src       *bufio.Reader
+	remaining uint32
+}
+
+func newDemuxReader(body io.ReadCloser) *demuxReader {
+	return &demuxReader{body: body, src: bufio.NewReader(body)}
+}
+
+func (d *demuxReader) Read(p []byte) (int, error) {
+	for d.remaining == 0 {
+		var header [8]byte
+		if _, err := io.ReadFull(d.src, header[:]); err != nil {
+			if err == io.ErrUnexpectedEOF {
+				err = io.EOF
+			}
+			return 0, err
+		}
+		d.remaining = binary.BigEndian.Uint32(header[4:])
+	}
+
+	if uint32(len(p)) > d.remaining {
+		p = p[:d.remaining]
+	}
+	n, err := d.src.Read(p)
+	d.remaining -= uint32(n)
+	return n, err
+}
+
+func (d *demuxReader) Close() error {
+	return d.body.Close()
+}
+
+// demuxCopy splits a multiplexed stream into stdout and stderr until the
+// engine closes it.
+func demuxCopy(stdout, stderr io.Writer, src io.Reader) error {
+	var header [8]byte
+	for {
+		if _, err := io.ReadFull(src, header[:]); err != nil {
+			if err == io.EOF || errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		dst := stdout
+		if header[0] == 2 {
+			dst = stderr
+		}
+		size := int64(binary.BigEndian.Uint32(header[4:]))
+		if _, err := io.CopyN(dst, src, size); err != nil {
+			return err
+		}
+	}
+}
+
+func orDiscard(w io.Writer) io.Writer {
+	if w == nil {
+		return io.Discard
+	}
+	return w
+}
+
+// errorRecorder remembers the first error other than io.EOF its reader
+// returns.
+type errorRecorder struct {
+	r   io.Reader
+	err error
+}
+
+func (e *errorRecorder) Read(p []byte) (int, error) {
+	n, err := e.r.Read(p)
+	if err != nil && err != io.EOF && e.err == nil {
+		e.err = err
+	}
+	return n, err
+}
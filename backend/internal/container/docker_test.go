@@ -0,0 +1,262 @@
+package container
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Jesuloba-world/deployease/backend/internal/config"
+)
+
+// newEngine serves handler on a unix socket and returns a runtime bound to it.
+func newEngine(t *testing.T, handler http.Handler) *DockerRuntime {
+	t.Helper()
+
+	// Socket paths are limited to ~100 bytes, which t.TempDir can exceed.
+	dir, err := os.MkdirTemp("", "docker")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	socket := filepath.Join(dir, "docker.sock")
+	listener, err := net.Listen("unix", socket)
+	require.NoError(t, err)
+
+	server := httptest.NewUnstartedServer(handler)
+	server.Listener = listener
+	server.Start()
+	t.Cleanup(server.Close)
+
+	rt, err := NewDockerRuntime(config.RuntimeConfig{
+		DockerHost:       "unix://" + socket,
+		DockerAPIVersion: "v1.43",
+	})
+	require.NoError(t, err)
+	return rt
+}
+
+func TestDockerRuntimeLifecycle(t *testing.T) {
+	var created dockerCreateRequest
+	var calls []string
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1.43/containers/create", func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, "create "+r.URL.Query().Get("name"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&created))
+		w.WriteHeader(http.StatusCreated)
+		io.WriteString(w, `{"Id":"abc123","Warnings":[]}`)
+	})
+	mux.HandleFunc("POST /v1.43/containers/{id}/start", func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, "start "+r.PathValue("id"))
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("POST /v1.43/containers/{id}/stop", func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, "stop "+r.PathValue("id")+" t="+r.URL.Query().Get("t"))
+		// Already stopped.
+		w.WriteHeader(http.StatusNotModified)
+	})
+	mux.HandleFunc("DELETE /v1.43/containers/{id}", func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, "remove "+r.PathValue("id")+" force="+r.URL.Query().Get("force"))
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("GET /v1.43/containers/{id}/json", func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("id") != "abc123" {
+			w.WriteHeader(http.StatusNotFound)
+			io.WriteString(w, `{"message":"No such container: `+r.PathValue("id")+`"}`)
+			return
+		}
+		io.WriteString(w, `{
+			"Id": "abc123",
+			"Name": "/web",
+			"Config": {"Image": "app:1", "Labels": {"io.deployease.project": "p1"}},
+			"State": {"Status": "running", "ExitCode": 0, "StartedAt": "2025-06-27T10:00:00Z", "FinishedAt": "0001-01-01T00:00:00Z"},
+			"NetworkSettings": {"IPAddress": "", "Networks": {"deployease": {"IPAddress": "172.20.0.5"}}}
+		}`)
+	})
+
+	rt := newEngine(t, mux)
+	ctx := context.Background()
+
+	id, err := rt.Create(ctx, Spec{
+		Name:    "web",
+		Image:   "app:1",
+		Env:     map[string]string{"PORT": "8080", "A": "1"},
+		Labels:  map[string]string{LabelProject: "p1"},
+		Cmd:     []string{"serve", "--verbose"},
+		Port:    8080,
+		Network: "deployease",
+		Memory:  256 << 20,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "abc123", id)
+	assert.Equal(t, "app:1", created.Image)
+	assert.Equal(t, []string{"A=1", "PORT=8080"}, created.Env)
+	assert.Contains(t, created.ExposedPorts, "8080/tcp")
+	assert.Equal(t, "deployease", created.HostConfig.NetworkMode)
+	assert.Equal(t, []string{"serve", "--verbose"}, created.Cmd)
+	assert.Equal(t, int64(256<<20), created.HostConfig.Memory)
+
+	require.NoError(t, rt.Start(ctx, id))
+	require.NoError(t, rt.Stop(ctx, id, 10*time.Second))
+	require.NoError(t, rt.Remove(ctx, id))
+
+	info, err := rt.Inspect(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, "web", info.Name)
+	assert.True(t, info.Running())
+	assert.Equal(t, "172.20.0.5", info.IPAddress)
+	assert.Equal(t, "p1", info.Labels[LabelProject])
+
+	_, err = rt.Inspect(ctx, "missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	assert.Equal(t, []string{
+		"create web",
+		"start abc123",
+		"stop abc123 t=10",
+		"remove abc123 force=true",
+	}, calls)
+}
+
+func TestDockerRuntimeStopRoundsTimeoutUp(t *testing.T) {
+	var timeouts []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1.43/containers/{id}/stop", func(w http.ResponseWriter, r *http.Request) {
+		timeouts = append(timeouts, r.URL.Query().Get("t"))
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	rt := newEngine(t, mux)
+	ctx := context.Background()
+	for _, timeout := range []time.Duration{0, 500 * time.Millisecond, 1500 * time.Millisecond, 10 * time.Second} {
+		require.NoError(t, rt.Stop(ctx, "abc123", timeout))
+	}
+	assert.Equal(t, []string{"0", "1", "2", "10"}, timeouts)
+}
+
+// frame encodes s as one chunk of a multiplexed stdout/stderr stream.
+func frame(stream byte, s string) []byte {
+	header := make([]byte, 8)
+	header[0] = stream
+	binary.BigEndian.PutUint32(header[4:], uint32(len(s)))
+	return append(header, s...)
+}
+
+func TestDockerRuntimeLogsDemultiplexes(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1.43/containers/{id}/logs", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "true", r.URL.Query().Get("follow"))
+		assert.Equal(t, "50", r.URL.Query().Get("tail"))
+		w.Header().Set("Content-Type", "application/vnd.docker.multiplexed-stream")
+		w.Write(frame(1, "hello\n"))
+		w.Write(frame(2, "oops\n"))
+		w.Write(frame(1, "bye\n"))
+	})
+
+	rt := newEngine(t, mux)
+	logs, err := rt.Logs(context.Background(), "abc123", LogsOptions{Follow: true, Tail: 50})
+	require.NoError(t, err)
+	defer logs.Close()
+
+	out, err := io.ReadAll(logs)
+	require.NoError(t, err)
+	assert.Equal(t, "hello\noops\nbye\n", string(out))
+}
+
+func TestDockerRuntimeExec(t *testing.T) {
+	var created dockerExecRequest
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1.43/containers/{id}/exec", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "db", r.PathValue("id"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&created))
+		w.WriteHeader(http.StatusCreated)
+		io.WriteString(w, `{"Id":"exec1"}`)
+	})
+	mux.HandleFunc("POST /v1.43/exec/{id}/start", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "tcp", r.Header.Get("Upgrade"))
+		var start map[string]bool
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&start))
+		assert.False(t, start["Detach"])
+		conn, buf, err := w.(http.Hijacker).Hijack()
+		require.NoError(t, err)
+		defer conn.Close()
+
+		buf.WriteString("HTTP/1.1 101 UPGRADED\r\nConnection: Upgrade\r\nUpgrade: tcp\r\n\r\n")
+		require.NoError(t, buf.Flush())
+
+		// Echo stdin back once the client closes its side.
+		input, err := io.ReadAll(buf)
+		require.NoError(t, err)
+		conn.Write(frame(1, strings.ToUpper(string(input))))
+		conn.Write(frame(2, "warning\n"))
+	})
+	mux.HandleFunc("GET /v1.43/exec/{id}/json", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "exec1", r.PathValue("id"))
+		io.WriteString(w, `{"Running":false,"ExitCode":3}`)
+	})
+
+	rt := newEngine(t, mux)
+	var stdout, stderr strings.Builder
+	code, err := rt.Exec(context.Background(), "db", ExecOptions{
+		Cmd:    []string{"pg_restore", "--clean"},
+		Stdin:  strings.NewReader("dump\n"),
+		Stdout: &stdout,
+		Stderr: &stderr,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 3, code)
+	assert.Equal(t, "DUMP\n", stdout.String())
+	assert.Equal(t, "warning\n", stderr.String())
+	assert.Equal(t, []string{"pg_restore", "--clean"}, created.Cmd)
+	assert.True(t, created.AttachStdin)
+}
+
+func TestDockerRuntimeExecMissingContainer(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1.43/containers/{id}/exec", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		io.WriteString(w, `{"message":"No such container: `+r.PathValue("id")+`"}`)
+	})
+
+	rt := newEngine(t, mux)
+	_, err := rt.Exec(context.Background(), "missing", ExecOptions{Cmd: []string{"true"}})
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestDockerRuntimePullReportsStreamedErrors(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1.43/images/create", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"status":"Pulling from library/`+r.URL.Query().Get("fromImage")+`"}`+"\n")
+		if r.URL.Query().Get("fromImage") == "postgres:nope" {
+			io.WriteString(w, `{"error":"manifest unknown"}`+"\n")
+			return
+		}
+		io.WriteString(w, `{"status":"Download complete"}`+"\n")
+	})
+
+	rt := newEngine(t, mux)
+	ctx := context.Background()
+
+	require.NoError(t, rt.Pull(ctx, "postgres:16"))
+
+	err := rt.Pull(ctx, "postgres:nope")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "manifest unknown")
+}
+
+func TestNewDockerRuntimeRequiresUnixSocket(t *testing.T) {
+	_, err := NewDockerRuntime(config.RuntimeConfig{DockerHost: "tcp://localhost:2375"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,104 @@
+// Package container runs deployed applications. The Runtime interface keeps
+// the deployment pipeline independent of the engine underneath it.
+package container
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+var (
+	ErrNotFound = errors.New("container not found")
+	ErrConflict = errors.New("container conflict")
+)
+
+// Label keys set on every container DeployEase creates.
+const (
+	LabelProject    = "io.deployease.project"
+	LabelDeployment = "io.deployease.deployment"
+	LabelDatabase   = "io.deployease.database"
+)
+
+type State string
+
+const (
+	StateCreated    State = "created"
+	StateRunning    State = "running"
+	StateRestarting State = "restarting"
+	StatePaused     State = "paused"
+	StateExited     State = "exited"
+	StateDead       State = "dead"
+)
+
+// Spec describes a container to create.
+type Spec struct {
+	Name   string
+	Image  string
+	Env    map[string]string
+	Labels map[string]string
+	// Cmd replaces the image's default command when set.
+	Cmd []string
+	// Port is the TCP port the application listens on inside the container.
+	Port    int
+	Network string
+	// Memory limits the container's memory in bytes; zero means no limit.
+	Memory int64
+}
+
+// Info is the observed state of a container.
+type Info struct {
+	ID         string
+	Name       string
+	Image      string
+	State      State
+	ExitCode   int
+	StartedAt  time.Time
+	FinishedAt time.Time
+	Labels     map[string]string
+	// IPAddress is the container's address on its network, used to reach
+	// the application on Spec.Port.
+	IPAddress string
+}
+
+func (i *Info) Running() bool {
+	return i.State == StateRunning
+}
+
+type LogsOptions struct {
+	Follow     bool
+	Timestamps bool
+	// Tail limits output to the last Tail lines; zero means everything.
+	Tail  int
+	Since time.Time
+}
+
+// ExecOptions describes a command to run inside a running container.
+type ExecOptions struct {
+	Cmd []string
+	// Stdin, when set, is copied to the command's standard input, which is
+	// closed once Stdin is exhausted.
+	Stdin io.Reader
+	// Stdout and Stderr receive the command's output; nil discards it.
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+// Runtime manages the lifecycle of application containers. Stop and Remove
+// are idempotent; every method returns ErrNotFound for unknown containers.
+type Runtime interface {
+	// Pull fetches an image, such as a database server's, from its
+	// registry. Images built by DeployEase are already local.
+	Pull(ctx context.Context, image string) error
+	Create(ctx context.Context, spec Spec) (string, error)
+	Start(ctx context.Context, id string) error
+	Stop(ctx context.Context, id string, timeout time.Duration) error
+	Remove(ctx context.Context, id string) error
+	Inspect(ctx context.Context, id string) (*Info, error)
+	// Logs returns the container's combined stdout and stderr.
+	Logs(ctx context.Context, id string, opts LogsOptions) (io.ReadCloser, error)
+	// Exec runs a command in a running container and returns its exit
+	// code once it finishes. A non-zero exit code is not an error.
+	Exec(ctx context.Context, id string, opts ExecOptions) (int, error)
+}
@@ -0,0 +1,122 @@
+package deployment
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/Jesuloba-world/deployease/backend/internal/container"
+	"github.com/Jesuloba-world/deployease/backend/internal/infrastructure/database/postgres/sqlc"
+	"github.com/Jesuloba-world/deployease/backend/internal/jobs"
+)
+
+// CollectLogsJobKind copies a container's output into its deployment's
+// stored log, so it outlives the container.
+const CollectLogsJobKind = "deployment.collect_logs"
+
+const collectBatchSize = 500
+
+type CollectLogsPayload struct {
+	DeploymentID string `json:"deployment_id"`
+	// ContainerID ends the job's chain once the deployment's container is
+	// replaced, since the new container starts a chain of its own.
+	ContainerID string `json:"container_id"`
+}
+
+// CollectLogs stores what the container named in the job payload has written
+// since the last run, then schedules the next run for as long as the
+// deployment keeps that container.
+func (r *Runner) CollectLogs(ctx context.Context, job sqlc.Job) error {
+	var payload CollectLogsPayload
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return jobs.Permanent(fmt.Errorf("invalid collect logs job payload: %w", err))
+	}
+
+	d, err := r.service.queries.GetDeployment(ctx, payload.DeploymentID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		return fmt.Errorf("failed to get deployment: %w", err)
+	}
+	if d.ContainerID.String != payload.ContainerID {
+		return nil
+	}
+
+	if err := r.storeRuntimeLogs(ctx, d.ID, payload.ContainerID); err != nil {
+		if errors.Is(err, container.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+	return r.scheduleCollect(ctx, payload)
+}
+
+func (r *Runner) scheduleCollect(ctx context.Context, payload CollectLogsPayload) error {
+	_, err := r.service.queue.Enqueue(ctx, CollectLogsJobKind, payload, jobs.RunAt(time.Now().Add(r.logsCfg.CollectInterval)))
+	return err
+}
+
+// storeRuntimeLogs appends the container's output after the last stored
+// line of it to the deployment's log.
+func (r *Runner) storeRuntimeLogs(ctx context.Context, deploymentID, containerID string) error {
+	latest, err := r.service.queries.GetLatestDeploymentLogTime(ctx, sqlc.GetLatestDeploymentLogTimeParams{
+		DeploymentID: deploymentID,
+		Stream:       LogStreamRuntime,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get latest runtime log: %w", err)
+	}
+
+	rc, err := r.runtime.Logs(ctx, containerID, container.LogsOptions{
+		Timestamps: true,
+		Since:      latest.Time,
+	})
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	batch := sqlc.AppendDeploymentLogsParams{
+		DeploymentID: deploymentID,
+		Stream:       LogStreamRuntime,
+	}
+	flush := func() error {
+		if len(batch.Lines) == 0 {
+			return nil
+		}
+		if err := r.service.queries.AppendDeploymentLogs(ctx, batch); err != nil {
+			return fmt.Errorf("failed to append deployment logs: %w", err)
+		}
+		batch.Levels, batch.Lines, batch.CreatedAts = nil, nil, nil
+		return nil
+	}
+
+	err = scanRuntimeLogs(rc, latest.Time, func(ts time.Time, line string) error {
+		batch.Levels = append(batch.Levels, DetectLogLevel(line))
+		batch.Lines = append(batch.Lines, line)
+		batch.CreatedAts = append(batch.CreatedAts, pgtype.Timestamptz{Time: ts, Valid: true})
+		if len(batch.Lines) >= collectBatchSize {
+			return flush()
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return flush()
+}
+
+// collectFinalLogs stores a container's remaining output before it is
+// removed.
+func (r *Runner) collectFinalLogs(ctx context.Context, deploymentID, containerID string) {
+	if err := r.storeRuntimeLogs(ctx, deploymentID, containerID); err != nil && !errors.Is(err, container.ErrNotFound) {
+		log.Printf("Failed to store runtime logs of deployment %s: %v", deploymentID, err)
+	}
+}
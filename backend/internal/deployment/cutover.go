@@ -0,0 +1,280 @@
+package deployment
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/Jesuloba-world/deployease/backend/internal/container"
+	"github.com/Jesuloba-world/deployease/backend/internal/infrastructure/database/postgres/sqlc"
+	"github.com/Jesuloba-world/deployease/backend/internal/jobs"
+)
+
+const (
+	// RetireJobKind stops a superseded deployment's container once its
+	// in-flight requests have had time to drain.
+	RetireJobKind = "deployment.retire"
+	// VerifyJobKind re-checks a freshly activated deployment and rolls the
+	// project back if it has become unhealthy.
+	VerifyJobKind = "deployment.verify"
+
+	// verifyAttempts is how many consecutive probe intervals a live
+	// deployment may fail before it is rolled back.
+	verifyAttempts = 3
+)
+
+type RetirePayload struct {
+	DeploymentID string `json:"deployment_id"`
+}
+
+type VerifyPayload struct {
+	DeploymentID string `json:"deployment_id"`
+	// PreviousID is the deployment that was active before this one; empty
+	// for a project's first deployment.
+	PreviousID string    `json:"previous_id,omitempty"`
+	Until      time.Time `json:"until"`
+}
+
+// Retire stops and removes the container of the deployment named in the job
+// payload, unless its environment has since been rolled back to it.
+func (r *Runner) Retire(ctx context.Context, job sqlc.Job) error {
+	var payload RetirePayload
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return jobs.Permanent(fmt.Errorf("invalid retire job payload: %w", err))
+	}
+
+	d, err := r.service.queries.GetDeployment(ctx, payload.DeploymentID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		return fmt.Errorf("failed to get deployment: %w", err)
+	}
+	if !d.ContainerID.Valid {
+		return nil
+	}
+
+	active, err := r.service.queries.IsDeploymentActive(ctx, d.ID)
+	if err != nil {
+		return fmt.Errorf("failed to check deployment: %w", err)
+	}
+	if active {
+		return nil
+	}
+
+	if err := r.runtime.Stop(ctx, d.ContainerID.String, r.cfg.StopTimeout); err != nil && !errors.Is(err, container.ErrNotFound) {
+		return err
+	}
+	r.discard(ctx, d.ID, d.ContainerID.String)
+	return nil
+}
+
+// Verify probes the deployment named in the job payload while it is inside
+// its rollback window. A deployment that stops answering is replaced by the
+// one it superseded.
+func (r *Runner) Verify(ctx context.Context, job sqlc.Job) error {
+	var payload VerifyPayload
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return jobs.Permanent(fmt.Errorf("invalid verify job payload: %w", err))
+	}
+
+	d, err := r.service.queries.GetDeployment(ctx, payload.DeploymentID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		return fmt.Errorf("failed to get deployment: %w", err)
+	}
+	if d.Status != sqlc.DeploymentStatusSuccess {
+		return nil
+	}
+
+	active, err := r.service.queries.IsDeploymentActive(ctx, d.ID)
+	if err != nil {
+		return fmt.Errorf("failed to check deployment: %w", err)
+	}
+	if !active {
+		// Superseded, rolled back or torn down already; nothing left to
+		// guard.
+		return nil
+	}
+
+	p, err := r.service.queries.GetProject(ctx, d.ProjectID)
+	if err != nil {
+		return fmt.Errorf("failed to get project: %w", err)
+	}
+
+	err = r.waitHealthy(ctx, d.ContainerID.String, verifyAttempts*r.rolloutCfg.ProbeInterval)
+	if err == nil {
+		if time.Now().Add(r.rolloutCfg.VerifyInterval).Before(payload.Until) {
+			return r.scheduleVerify(ctx, payload)
+		}
+		return nil
+	}
+	if ctx.Err() != nil || !errors.Is(err, ErrUnhealthy) {
+		return err
+	}
+
+	return r.rollBack(ctx, p, d, payload.PreviousID, err)
+}
+
+// rollBack brings the previous deployment's container back and hands the
+// project over to it. If there is nothing healthy to go back to the current
+// deployment stays active; a sick application beats none at all.
+func (r *Runner) rollBack(ctx context.Context, p sqlc.Project, d sqlc.Deployment, previousID string, cause error) error {
+	logs := r.service.NewLogWriter(context.WithoutCancel(ctx), d, LogStreamRollout)
+	defer func() {
+		if err := logs.Close(); err != nil {
+			log.Printf("Failed to store rollout logs for deployment %s: %v", d.ID, err)
+		}
+	}()
+
+	fmt.Fprintf(logs, "==> %v\n", cause)
+
+	var prev sqlc.Deployment
+	if previousID != "" {
+		var err error
+		prev, err = r.service.queries.GetDeployment(ctx, previousID)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("failed to get previous deployment: %w", err)
+		}
+	}
+	if prev.ID == "" || !prev.ImageRef.Valid {
+		fmt.Fprintln(logs, "==> No previous deployment to roll back to")
+		return nil
+	}
+
+	fmt.Fprintf(logs, "==> Rolling back to deployment %s\n", prev.ID)
+	if err := r.revive(ctx, p, prev, logs); err != nil {
+		var failure *rolloutError
+		if ctx.Err() != nil || !errors.As(err, &failure) {
+			return err
+		}
+		fmt.Fprintf(logs, "==> Rollback failed: %v\n", err)
+		return nil
+	}
+
+	_, err := r.service.RollBack(ctx, d.ID, prev.ID)
+	if errors.Is(err, ErrNotActive) || errors.Is(err, ErrInvalidTransition) {
+		// Another deployment took over while we were reviving this one.
+		if err := r.enqueueRetire(ctx, prev.ID, time.Now()); err != nil {
+			log.Printf("Failed to schedule retirement of deployment %s: %v", prev.ID, err)
+		}
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := r.runtime.Stop(ctx, d.ContainerID.String, r.cfg.StopTimeout); err != nil && !errors.Is(err, container.ErrNotFound) {
+		log.Printf("Failed to stop container of deployment %s: %v", d.ID, err)
+	}
+	r.discard(ctx, d.ID, d.ContainerID.String)
+
+	fmt.Fprintf(logs, "==> Rolled back to deployment %s\n", prev.ID)
+	return nil
+}
+
+// revive makes sure a previous deployment has a healthy container, starting
+// its old one if it is still around or creating a new one from its image.
+func (r *Runner) revive(ctx context.Context, p sqlc.Project, prev sqlc.Deployment, logs io.Writer) error {
+	if prev.ContainerID.Valid {
+		info, err := r.runtime.Inspect(ctx, prev.ContainerID.String)
+		switch {
+		case err == nil:
+			if !info.Running() {
+				if err := r.runtime.Start(ctx, info.ID); err != nil {
+					return &rolloutError{err: err}
+				}
+			}
+			return r.reviveHealthy(ctx, prev.ID, info.ID)
+		case !errors.Is(err, container.ErrNotFound):
+			return err
+		}
+	}
+
+	id, err := r.startContainer(ctx, p, prev, prev.ImageRef.String, logs)
+	if err != nil {
+		return err
+	}
+	return r.reviveHealthy(ctx, prev.ID, id)
+}
+
+func (r *Runner) reviveHealthy(ctx context.Context, deploymentID, containerID string) error {
+	err := r.waitHealthy(ctx, containerID, r.rolloutCfg.HealthTimeout)
+	if err == nil {
+		return nil
+	}
+	if ctx.Err() != nil || !errors.Is(err, ErrUnhealthy) {
+		return err
+	}
+	r.discard(context.WithoutCancel(ctx), deploymentID, containerID)
+	return &rolloutError{err: err}
+}
+
+// waitHealthy probes a container's application until it answers or timeout
+// passes. A container that is gone or not running is unhealthy outright.
+func (r *Runner) waitHealthy(ctx context.Context, containerID string, timeout time.Duration) error {
+	if containerID == "" {
+		return fmt.Errorf("%w: no container", ErrUnhealthy)
+	}
+
+	info, err := r.runtime.Inspect(ctx, containerID)
+	if err != nil {
+		if errors.Is(err, container.ErrNotFound) {
+			return fmt.Errorf("%w: container is gone", ErrUnhealthy)
+		}
+		return err
+	}
+	if !info.Running() {
+		return fmt.Errorf("%w: container %s with exit code %d", ErrUnhealthy, info.State, info.ExitCode)
+	}
+
+	addr := net.JoinHostPort(info.IPAddress, strconv.Itoa(r.cfg.ContainerPort))
+	return pollHealthy(ctx, r.prober, addr, timeout, r.rolloutCfg.ProbeInterval)
+}
+
+// scheduleRetire queues the retirement of every other container the
+// deployment's environment still runs, after the drain grace period.
+// Containers of the project's other environments are left alone.
+func (r *Runner) scheduleRetire(ctx context.Context, d sqlc.Deployment) {
+	previous, err := r.service.queries.ListDeploymentsWithContainers(ctx, sqlc.ListDeploymentsWithContainersParams{
+		ProjectID: d.ProjectID,
+		Branch:    d.Branch,
+		ID:        d.ID,
+	})
+	if err != nil {
+		log.Printf("Failed to list previous containers of project %s branch %s: %v", d.ProjectID, d.Branch, err)
+		return
+	}
+
+	at := time.Now().Add(r.rolloutCfg.DrainGrace)
+	for _, prev := range previous {
+		if err := r.enqueueRetire(ctx, prev.ID, at); err != nil {
+			log.Printf("Failed to schedule retirement of deployment %s: %v", prev.ID, err)
+		}
+	}
+}
+
+func (r *Runner) enqueueRetire(ctx context.Context, deploymentID string, at time.Time) error {
+	_, err := r.service.queue.Enqueue(ctx, RetireJobKind, RetirePayload{DeploymentID: deploymentID}, jobs.RunAt(at))
+	return err
+}
+
+// scheduleVerify queues the next health check of a live deployment. Nothing
+// is scheduled when automatic rollback is disabled.
+func (r *Runner) scheduleVerify(ctx context.Context, payload VerifyPayload) error {
+	if r.rolloutCfg.RollbackWindow <= 0 {
+		return nil
+	}
+	_, err := r.service.queue.Enqueue(ctx, VerifyJobKind, payload, jobs.RunAt(time.Now().Add(r.rolloutCfg.VerifyInterval)))
+	return err
+}
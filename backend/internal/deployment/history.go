@@ -0,0 +1,241 @@
+package deployment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	gonanoid "github.com/matoous/go-nanoid/v2"
+
+	"github.com/Jesuloba-world/deployease/backend/internal/infrastructure/database/postgres/sqlc"
+	"github.com/Jesuloba-world/deployease/backend/internal/pagination"
+)
+
+// Create records a new pending deployment of commitHash from the project's
+// own branch and enqueues the job that builds and rolls it out.
+func (s *Service) Create(ctx context.Context, projectID, commitHash string) (sqlc.Deployment, error) {
+	return s.CreateForBranch(ctx, projectID, "", commitHash)
+}
+
+// CreateForBranch records a new pending deployment of commitHash from
+// branch, the project's own when empty, and enqueues the job that builds
+// and rolls it out. Both happen in one transaction so a deployment is never
+// left without a job to run it. A deployment of any other branch goes to
+// the branch's preview environment, which is created if need be.
+func (s *Service) CreateForBranch(ctx context.Context, projectID, branch, commitHash string) (sqlc.Deployment, error) {
+	var d sqlc.Deployment
+
+	err := s.withTx(ctx, func(q *sqlc.Queries) error {
+		p, err := q.GetProject(ctx, projectID)
+		if err != nil {
+			return fmt.Errorf("failed to get project: %w", err)
+		}
+		if branch == "" {
+			branch = p.Branch
+		}
+		if branch != p.Branch {
+			if _, err := ensurePreview(ctx, q, p, branch); err != nil {
+				return err
+			}
+		}
+
+		d, err = q.CreateDeployment(ctx, sqlc.CreateDeploymentParams{
+			ID:         gonanoid.Must(),
+			ProjectID:  projectID,
+			CommitHash: pgtype.Text{String: strings.ToLower(strings.TrimSpace(commitHash)), Valid: true},
+			Branch:     branch,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create deployment: %w", err)
+		}
+
+		if _, err := s.queue.WithQuerier(q).Enqueue(ctx, RunJobKind, RunPayload{DeploymentID: d.ID}); err != nil {
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		return sqlc.Deployment{}, err
+	}
+
+	s.publishStatus(ctx, d)
+	return d, nil
+}
+
+// Rollback records a new pending deployment that re-runs the image of an
+// earlier one of the project's deployments and enqueues its rollout in the
+// environment of the same branch. Only deployments that once went live and
+// still have their image can be rolled back to; the environment's active
+// deployment cannot.
+func (s *Service) Rollback(ctx context.Context, projectID, targetID string) (sqlc.Deployment, error) {
+	var d sqlc.Deployment
+
+	err := s.withTx(ctx, func(q *sqlc.Queries) error {
+		target, err := q.GetDeploymentForProject(ctx, sqlc.GetDeploymentForProjectParams{
+			ID:        targetID,
+			ProjectID: projectID,
+		})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrNotFound
+			}
+			return fmt.Errorf("failed to get deployment: %w", err)
+		}
+
+		if !target.DeployedAt.Valid || !target.ImageRef.Valid {
+			return fmt.Errorf("%w: deployment %s is %s", ErrRollbackTarget, target.ID, target.Status)
+		}
+
+		env, err := lockEnvironment(ctx, q, projectID, target.Branch)
+		if err != nil {
+			return err
+		}
+		if env.activeDeploymentID() == target.ID {
+			return fmt.Errorf("%w: deployment %s is already active", ErrRollbackTarget, target.ID)
+		}
+
+		d, err = q.CreateRollbackDeployment(ctx, sqlc.CreateRollbackDeploymentParams{
+			ID:               gonanoid.Must(),
+			ProjectID:        projectID,
+			CommitHash:       target.CommitHash,
+			ImageRef:         target.ImageRef,
+			RollbackTargetID: pgtype.Text{String: target.ID, Valid: true},
+			Branch:           target.Branch,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create deployment: %w", err)
+		}
+
+		if _, err := s.queue.WithQuerier(q).Enqueue(ctx, RunJobKind, RunPayload{DeploymentID: d.ID}); err != nil {
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		return sqlc.Deployment{}, err
+	}
+
+	s.publishStatus(ctx, d)
+	return d, nil
+}
+
+// Redeploy records a new pending deployment that re-runs the image of the
+// active deployment of branch, the project's own when empty, and enqueues
+// its rollout, so the container is replaced with one started from the
+// current settings.
+func (s *Service) Redeploy(ctx context.Context, projectID, branch string) (sqlc.Deployment, error) {
+	var d sqlc.Deployment
+
+	err := s.withTx(ctx, func(q *sqlc.Queries) error {
+		env, err := lockEnvironment(ctx, q, projectID, branch)
+		if errors.Is(err, pgx.ErrNoRows) || errors.Is(err, ErrPreviewNotFound) {
+			return ErrNoActiveDeployment
+		}
+		if err != nil {
+			return err
+		}
+		if env.activeDeploymentID() == "" {
+			return ErrNoActiveDeployment
+		}
+
+		active, err := q.GetDeployment(ctx, env.activeDeploymentID())
+		if err != nil {
+			return fmt.Errorf("failed to get deployment: %w", err)
+		}
+
+		d, err = q.CreateRollbackDeployment(ctx, sqlc.CreateRollbackDeploymentParams{
+			ID:         gonanoid.Must(),
+			ProjectID:  projectID,
+			CommitHash: active.CommitHash,
+			ImageRef:   active.ImageRef,
+			Branch:     active.Branch,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create deployment: %w", err)
+		}
+
+		if _, err := s.queue.WithQuerier(q).Enqueue(ctx, RunJobKind, RunPayload{DeploymentID: d.ID}); err != nil {
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		return sqlc.Deployment{}, err
+	}
+
+	s.publishStatus(ctx, d)
+	return d, nil
+}
+
+func (s *Service) Get(ctx context.Context, projectID, deploymentID string) (sqlc.Deployment, error) {
+	d, err := s.queries.GetDeploymentForProject(ctx, sqlc.GetDeploymentForProjectParams{
+		ID:        deploymentID,
+		ProjectID: projectID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return sqlc.Deployment{}, ErrNotFound
+		}
+		return sqlc.Deployment{}, fmt.Errorf("failed to get deployment: %w", err)
+	}
+
+	return d, nil
+}
+
+type ListParams struct {
+	ProjectID string
+	Branch    string
+	Statuses  []sqlc.DeploymentStatus
+	Cursor    string
+	Limit     int
+}
+
+type Page struct {
+	Deployments []sqlc.Deployment
+	NextCursor  string
+}
+
+// List returns a project's deployments newest first, optionally restricted to
+// one branch and the given statuses.
+func (s *Service) List(ctx context.Context, params ListParams) (*Page, error) {
+	limit := pagination.Limit(params.Limit)
+
+	arg := sqlc.ListDeploymentsForProjectParams{
+		ProjectID: params.ProjectID,
+		Branch:    pgtype.Text{String: params.Branch, Valid: params.Branch != ""},
+		Statuses:  make([]string, 0, len(params.Statuses)),
+		PageLimit: int32(limit + 1),
+	}
+	for _, status := range params.Statuses {
+		arg.Statuses = append(arg.Statuses, string(status))
+	}
+
+	if params.Cursor != "" {
+		createdAt, id, err := pagination.DecodeCursor(params.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		arg.CursorCreatedAt = pgtype.Timestamptz{Time: createdAt, Valid: true}
+		arg.CursorID = pgtype.Text{String: id, Valid: true}
+	}
+
+	deployments, err := s.queries.ListDeploymentsForProject(ctx, arg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deployments: %w", err)
+	}
+
+	page := &Page{Deployments: deployments}
+	if len(deployments) > limit {
+		page.Deployments = deployments[:limit]
+		last := page.Deployments[limit-1]
+		page.NextCursor = pagination.EncodeCursor(last.CreatedAt.Time, last.ID)
+	}
+
+	return page, nil
+}
@@ -0,0 +1,213 @@
+package deployment
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/Jesuloba-world/deployease/backend/internal/events"
+	"github.com/Jesuloba-world/deployease/backend/internal/infrastructure/database/postgres/sqlc"
+)
+
+const (
+	LogStreamBuild   = "build"
+	LogStreamRollout = "rollout"
+
+	logBatchSize     = 100
+	logFlushInterval = time.Second
+)
+
+// Log levels, from least to most severe.
+const (
+	LogLevelDebug = "debug"
+	LogLevelInfo  = "info"
+	LogLevelWarn  = "warn"
+	LogLevelError = "error"
+)
+
+var logLevels = []string{LogLevelDebug, LogLevelInfo, LogLevelWarn, LogLevelError}
+
+// LogLevelsFrom returns level and every level more severe than it, or nil if
+// level is not a known level.
+func LogLevelsFrom(level string) []string {
+	for i, l := range logLevels {
+		if l == level {
+			return logLevels[i:]
+		}
+	}
+	return nil
+}
+
+// levelPrefixLen bounds how far into a line DetectLogLevel looks, since the
+// level is reported near the start of a line, if at all.
+const levelPrefixLen = 80
+
+// DetectLogLevel guesses the level of a line of application or build output
+// from the first level-like word in it, such as "ERROR", "warn:" or
+// "level=debug". Lines without one are info.
+func DetectLogLevel(line string) string {
+	if len(line) > levelPrefixLen {
+		line = line[:levelPrefixLen]
+	}
+
+	words := strings.FieldsFunc(strings.ToLower(line), func(r rune) bool {
+		return (r < 'a' || r > 'z') && (r < '0' || r > '9')
+	})
+	for _, w := range words {
+		switch w {
+		case "debug", "debu", "dbg", "trace", "trc":
+			return LogLevelDebug
+		case "warn", "warning", "wrn":
+			return LogLevelWarn
+		case "error", "erro", "err", "fatal", "ftl", "panic", "critical", "crit":
+			return LogLevelError
+		case "info", "inf", "notice":
+			return LogLevelInfo
+		}
+	}
+	return LogLevelInfo
+}
+
+// LogWriter splits output into lines and appends them to a deployment's log.
+// Lines are batched to keep insert volume down but flushed at least every
+// logFlushInterval while output keeps arriving, so followers see progress.
+// Close must be called to flush what is left. A failure to store logs never
+// fails the write, since it should not abort the build producing them; the
+// first error is reported by Close instead and later output is dropped.
+type LogWriter struct {
+	ctx          context.Context
+	queries      sqlc.Querier
+	events       events.Publisher
+	projectID    string
+	deploymentID string
+	stream       string
+	now          func() time.Time
+
+	mu        sync.Mutex
+	partial   []byte
+	pending   []string
+	times     []pgtype.Timestamptz
+	lastFlush time.Time
+	err       error
+}
+
+// NewLogWriter returns a writer appending to the deployment's log. Stored
+// lines are also published to the deployment's project.
+func (s *Service) NewLogWriter(ctx context.Context, d sqlc.Deployment, stream string) *LogWriter {
+	w := newLogWriter(ctx, s.queries, d.ID, stream)
+	w.events = s.events
+	w.projectID = d.ProjectID
+	return w
+}
+
+func newLogWriter(ctx context.Context, queries sqlc.Querier, deploymentID, stream string) *LogWriter {
+	return &LogWriter{
+		ctx:          ctx,
+		queries:      queries,
+		deploymentID: deploymentID,
+		stream:       stream,
+		now:          time.Now,
+		lastFlush:    time.Now(),
+	}
+}
+
+func (w *LogWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.err != nil {
+		return len(p), nil
+	}
+
+	w.partial = append(w.partial, p...)
+	for {
+		i := bytes.IndexByte(w.partial, '\n')
+		if i < 0 {
+			break
+		}
+		w.add(string(bytes.TrimRight(w.partial[:i], "\r")))
+		w.partial = w.partial[i+1:]
+	}
+
+	if len(w.pending) >= logBatchSize || w.now().Sub(w.lastFlush) >= logFlushInterval {
+		_ = w.flush()
+	}
+
+	return len(p), nil
+}
+
+// Close flushes buffered lines, including a trailing line without a newline.
+func (w *LogWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.err != nil {
+		return w.err
+	}
+
+	if len(w.partial) > 0 {
+		w.add(string(bytes.TrimRight(w.partial, "\r")))
+		w.partial = nil
+	}
+	return w.flush()
+}
+
+// add queues a line, stamped with when it was written.
+func (w *LogWriter) add(line string) {
+	w.pending = append(w.pending, line)
+	w.times = append(w.times, pgtype.Timestamptz{Time: w.now(), Valid: true})
+}
+
+func (w *LogWriter) flush() error {
+	w.lastFlush = w.now()
+	if len(w.pending) == 0 {
+		return nil
+	}
+
+	levels := make([]string, len(w.pending))
+	for i, line := range w.pending {
+		levels[i] = DetectLogLevel(line)
+	}
+
+	err := w.queries.AppendDeploymentLogs(w.ctx, sqlc.AppendDeploymentLogsParams{
+		DeploymentID: w.deploymentID,
+		Stream:       w.stream,
+		Levels:       levels,
+		Lines:        w.pending,
+		CreatedAts:   w.times,
+	})
+	if err != nil {
+		w.err = fmt.Errorf("failed to append deployment logs: %w", err)
+		return w.err
+	}
+
+	w.publish(w.pending)
+	w.pending, w.times = nil, nil
+	return nil
+}
+
+func (w *LogWriter) publish(lines []string) {
+	if w.events == nil {
+		return
+	}
+
+	err := w.events.Publish(w.ctx, events.Event{
+		Type: events.TypeDeploymentLog,
+		Data: events.EventData{
+			ProjectID:    w.projectID,
+			DeploymentID: w.deploymentID,
+			Stream:       w.stream,
+			Lines:        lines,
+			Timestamp:    w.lastFlush,
+		},
+	})
+	if err != nil {
+		log.Printf("Failed to publish logs of deployment %s: %v", w.deploymentID, err)
+	}
+}
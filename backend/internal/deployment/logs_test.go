@@ -0,0 +1,156 @@
+package deployment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Jesuloba-world/deployease/backend/internal/events"
+	"github.com/Jesuloba-world/deployease/backend/internal/infrastructure/database/postgres/sqlc"
+)
+
+type logQuerier struct {
+	sqlc.Querier
+	batches [][]string
+	err     error
+}
+
+func (q *logQuerier) AppendDeploymentLogs(ctx context.Context, arg sqlc.AppendDeploymentLogsParams) error {
+	if q.err != nil {
+		return q.err
+	}
+	q.batches = append(q.batches, append([]string(nil), arg.Lines...))
+	return nil
+}
+
+func TestLogWriterSplitsLines(t *testing.T) {
+	q := &logQuerier{}
+	w := newLogWriter(context.Background(), q, "d1", LogStreamBuild)
+
+	fmt.Fprint(w, "first\r\nsec")
+	fmt.Fprint(w, "ond\n")
+	fmt.Fprint(w, "no newline")
+	assert.Empty(t, q.batches, "lines are buffered until a batch fills up")
+
+	require.NoError(t, w.Close())
+	require.Len(t, q.batches, 1)
+	assert.Equal(t, []string{"first", "second", "no newline"}, q.batches[0])
+}
+
+func TestLogWriterFlushes(t *testing.T) {
+	q := &logQuerier{}
+	w := newLogWriter(context.Background(), q, "d1", LogStreamBuild)
+
+	for i := 0; i < logBatchSize; i++ {
+		fmt.Fprintf(w, "line %d\n", i)
+	}
+	require.Len(t, q.batches, 1)
+	assert.Len(t, q.batches[0], logBatchSize)
+
+	now := time.Now()
+	w.now = func() time.Time { return now.Add(logFlushInterval) }
+	fmt.Fprint(w, "late\n")
+	require.Len(t, q.batches, 2)
+	assert.Equal(t, []string{"late"}, q.batches[1])
+}
+
+type recordingPublisher struct {
+	events []events.Event
+}
+
+func (p *recordingPublisher) Publish(ctx context.Context, event events.Event) error {
+	p.events = append(p.events, event)
+	return nil
+}
+
+func TestLogWriterPublishesStoredLines(t *testing.T) {
+	q := &logQuerier{}
+	pub := &recordingPublisher{}
+	w := newLogWriter(context.Background(), q, "d1", LogStreamBuild)
+	w.events, w.projectID = pub, "p1"
+
+	fmt.Fprint(w, "one\ntwo\n")
+	require.NoError(t, w.Close())
+
+	require.Len(t, pub.events, 1)
+	assert.Equal(t, events.TypeDeploymentLog, pub.events[0].Type)
+	assert.Equal(t, events.EventData{
+		ProjectID:    "p1",
+		DeploymentID: "d1",
+		Stream:       LogStreamBuild,
+		Lines:        []string{"one", "two"},
+		Timestamp:    pub.events[0].Data.Timestamp,
+	}, pub.events[0].Data)
+
+	q.err = errors.New("db down")
+	w = newLogWriter(context.Background(), q, "d1", LogStreamBuild)
+	w.events, w.projectID = pub, "p1"
+	fmt.Fprint(w, "lost\n")
+	require.Error(t, w.Close())
+	assert.Len(t, pub.events, 1, "lines that were not stored are not published")
+}
+
+func TestLogWriterReportsStoreErrorsOnClose(t *testing.T) {
+	q := &logQuerier{err: errors.New("db down")}
+	w := newLogWriter(context.Background(), q, "d1", LogStreamBuild)
+
+	for i := 0; i <= logBatchSize; i++ {
+		n, err := fmt.Fprintf(w, "line %d\n", i)
+		require.NoError(t, err)
+		assert.Positive(t, n)
+	}
+
+	assert.ErrorIs(t, w.Close(), q.err)
+}
+
+func TestLogWriterDetectsLevels(t *testing.T) {
+	q := &levelQuerier{}
+	w := newLogWriter(context.Background(), q, "d1", LogStreamBuild)
+
+	fmt.Fprint(w, "step 1\nERROR: build failed\n")
+	require.NoError(t, w.Close())
+	assert.Equal(t, []string{LogLevelInfo, LogLevelError}, q.levels)
+	assert.Len(t, q.times, 2)
+}
+
+type levelQuerier struct {
+	sqlc.Querier
+	levels []string
+	times  []pgtype.Timestamptz
+}
+
+func (q *levelQuerier) AppendDeploymentLogs(ctx context.Context, arg sqlc.AppendDeploymentLogsParams) error {
+	q.levels = append(q.levels, arg.Levels...)
+	q.times = append(q.times, arg.CreatedAts...)
+	return nil
+}
+
+func TestDetectLogLevel(t *testing.T) {
+	tests := map[string]string{
+		"listening on :8080":                          LogLevelInfo,
+		"ERROR connection refused":                    LogLevelError,
+		"2025/06/30 12:00:00 [warn] disk almost full": LogLevelWarn,
+		`{"level":"debug","msg":"cache miss"}`:        LogLevelDebug,
+		"time=2025-06-30T12:00:00Z level=ERROR msg=x": LogLevelError,
+		"12:00:00 INF request served":                 LogLevelInfo,
+		"panic: runtime error: index out of range":    LogLevelError,
+		"Warning: npm lockfile is out of date":        LogLevelWarn,
+		strings.Repeat("x ", 50) + "error much later": LogLevelInfo,
+	}
+	for line, want := range tests {
+		assert.Equal(t, want, DetectLogLevel(line), line)
+	}
+}
+
+func TestLogLevelsFrom(t *testing.T) {
+	assert.Equal(t, []string{LogLevelWarn, LogLevelError}, LogLevelsFrom(LogLevelWarn))
+	assert.Len(t, LogLevelsFrom(LogLevelDebug), 4)
+	assert.Nil(t, LogLevelsFrom("verbose"))
+}
@@ -0,0 +1,232 @@
+package deployment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	gonanoid "github.com/matoous/go-nanoid/v2"
+
+	"github.com/Jesuloba-world/deployease/backend/internal/infrastructure/database/postgres/sqlc"
+)
+
+var (
+	ErrPreviewNotFound  = errors.New("preview environment not found")
+	ErrPreviewsDisabled = errors.New("preview environments are not enabled for this project")
+)
+
+const (
+	// subdomainAlphabet keeps the random part of preview subdomains valid
+	// in a DNS label.
+	subdomainAlphabet = "0123456789abcdefghijklmnopqrstuvwxyz"
+	// maxSubdomainSlug leaves room for the random suffix within the 63
+	// characters of a DNS label.
+	maxSubdomainSlug = 40
+)
+
+var subdomainInvalid = regexp.MustCompile(`[^a-z0-9]+`)
+
+// environment is the row that records which deployment serves a branch of
+// a project: the project itself for its own branch, or the branch's preview
+// environment for any other.
+type environment struct {
+	project sqlc.Project
+	preview *sqlc.PreviewEnvironment
+}
+
+func (e environment) activeDeploymentID() string {
+	if e.preview != nil {
+		return e.preview.ActiveDeploymentID.String
+	}
+	return e.project.ActiveDeploymentID.String
+}
+
+func (e environment) setActiveDeployment(ctx context.Context, q *sqlc.Queries, deploymentID string) error {
+	active := pgtype.Text{String: deploymentID, Valid: deploymentID != ""}
+	if e.preview != nil {
+		return q.SetPreviewEnvironmentActiveDeployment(ctx, sqlc.SetPreviewEnvironmentActiveDeploymentParams{
+			ActiveDeploymentID: active,
+			ID:                 e.preview.ID,
+		})
+	}
+	return q.SetProjectActiveDeployment(ctx, sqlc.SetProjectActiveDeploymentParams{
+		ActiveDeploymentID: active,
+		ID:                 e.project.ID,
+	})
+}
+
+// lockEnvironment locks the environment of branch inside q's transaction,
+// the project's own when branch is empty. The project row is always locked
+// first so cutovers, rollbacks and teardowns of a project are serialised.
+// It returns ErrPreviewNotFound if the branch has no preview environment.
+func lockEnvironment(ctx context.Context, q *sqlc.Queries, projectID, branch string) (environment, error) {
+	p, err := q.GetProjectForUpdate(ctx, projectID)
+	if err != nil {
+		return environment{}, fmt.Errorf("failed to lock project: %w", err)
+	}
+	if branch == "" || branch == p.Branch {
+		return environment{project: p}, nil
+	}
+
+	preview, err := q.GetPreviewEnvironmentByBranchForUpdate(ctx, sqlc.GetPreviewEnvironmentByBranchForUpdateParams{
+		ProjectID: projectID,
+		Branch:    branch,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return environment{}, ErrPreviewNotFound
+		}
+		return environment{}, fmt.Errorf("failed to lock preview environment: %w", err)
+	}
+	return environment{project: p, preview: &preview}, nil
+}
+
+// ListPreviews returns the project's preview environments, oldest first.
+func (s *Service) ListPreviews(ctx context.Context, projectID string) ([]sqlc.PreviewEnvironment, error) {
+	previews, err := s.queries.ListPreviewEnvironments(ctx, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list preview environments: %w", err)
+	}
+	return previews, nil
+}
+
+func (s *Service) GetPreview(ctx context.Context, projectID, previewID string) (sqlc.PreviewEnvironment, error) {
+	preview, err := s.queries.GetPreviewEnvironmentForProject(ctx, sqlc.GetPreviewEnvironmentForProjectParams{
+		ID:        previewID,
+		ProjectID: projectID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return sqlc.PreviewEnvironment{}, ErrPreviewNotFound
+		}
+		return sqlc.PreviewEnvironment{}, fmt.Errorf("failed to get preview environment: %w", err)
+	}
+	return preview, nil
+}
+
+// TeardownPreview removes the preview environment of branch. Its
+// unfinished deployments are cancelled and the containers of the rest are
+// retired right away. The branch's variable overrides are kept, so a
+// preview of the branch started again runs with them.
+func (s *Service) TeardownPreview(ctx context.Context, projectID, branch string) (sqlc.PreviewEnvironment, error) {
+	var (
+		preview   sqlc.PreviewEnvironment
+		cancelled []sqlc.Deployment
+	)
+
+	err := s.withTx(ctx, func(q *sqlc.Queries) error {
+		env, err := lockEnvironment(ctx, q, projectID, branch)
+		if err != nil {
+			return err
+		}
+		if env.preview == nil {
+			return ErrPreviewNotFound
+		}
+		preview = *env.preview
+
+		if err := q.DeletePreviewEnvironment(ctx, preview.ID); err != nil {
+			return fmt.Errorf("failed to delete preview environment: %w", err)
+		}
+
+		cancelled, err = q.CancelBranchDeployments(ctx, sqlc.CancelBranchDeploymentsParams{
+			ProjectID: projectID,
+			Branch:    branch,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to cancel deployments: %w", err)
+		}
+
+		running, err := q.ListDeploymentsWithContainers(ctx, sqlc.ListDeploymentsWithContainersParams{
+			ProjectID: projectID,
+			Branch:    branch,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to list deployment containers: %w", err)
+		}
+
+		// Containers of deployments cancelled mid-rollout are removed by
+		// their runner once it notices.
+		queue := s.queue.WithQuerier(q)
+		for _, d := range running {
+			_, err := queue.Enqueue(ctx, RetireJobKind, RetirePayload{DeploymentID: d.ID})
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return sqlc.PreviewEnvironment{}, err
+	}
+
+	for _, d := range cancelled {
+		s.publishStatus(ctx, d)
+	}
+	s.invalidateRoutes(ctx)
+	return preview, nil
+}
+
+// LinkPullRequest records the pull request a preview environment's branch
+// is proposed in.
+func (s *Service) LinkPullRequest(ctx context.Context, projectID, branch string, number int) (sqlc.PreviewEnvironment, error) {
+	preview, err := s.queries.SetPreviewEnvironmentPullRequest(ctx, sqlc.SetPreviewEnvironmentPullRequestParams{
+		PullRequest: pgtype.Int4{Int32: int32(number), Valid: true},
+		ProjectID:   projectID,
+		Branch:      branch,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return sqlc.PreviewEnvironment{}, ErrPreviewNotFound
+		}
+		return sqlc.PreviewEnvironment{}, fmt.Errorf("failed to link pull request: %w", err)
+	}
+	return preview, nil
+}
+
+// ensurePreview creates the preview environment of branch unless it
+// exists already.
+func ensurePreview(ctx context.Context, q *sqlc.Queries, p sqlc.Project, branch string) (sqlc.PreviewEnvironment, error) {
+	if !p.PreviewsEnabled {
+		return sqlc.PreviewEnvironment{}, ErrPreviewsDisabled
+	}
+
+	preview, err := q.UpsertPreviewEnvironment(ctx, sqlc.UpsertPreviewEnvironmentParams{
+		ID:        gonanoid.Must(),
+		ProjectID: p.ID,
+		Branch:    branch,
+		Subdomain: previewSubdomain(branch),
+	})
+	if err != nil {
+		return sqlc.PreviewEnvironment{}, fmt.Errorf("failed to create preview environment: %w", err)
+	}
+	return preview, nil
+}
+
+// previewSubdomain names a new preview environment after its branch, with
+// a random suffix that keeps branches of the same name in different
+// projects, and slugs that collide, apart.
+func previewSubdomain(branch string) string {
+	slug := subdomainInvalid.ReplaceAllString(strings.ToLower(branch), "-")
+	slug = strings.Trim(slug, "-")
+	if len(slug) > maxSubdomainSlug {
+		slug = strings.TrimRight(slug[:maxSubdomainSlug], "-")
+	}
+	if slug == "" {
+		slug = "preview"
+	}
+	return slug + "-" + gonanoid.MustGenerate(subdomainAlphabet, 8)
+}
+
+// PreviewURL is where a preview environment is served under domain, or
+// empty if previews have no domain configured.
+func PreviewURL(preview sqlc.PreviewEnvironment, domain string) string {
+	if domain == "" {
+		return ""
+	}
+	return "https://" + preview.Subdomain + "." + domain
+}
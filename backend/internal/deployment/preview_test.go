@@ -0,0 +1,36 @@
+package deployment
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Jesuloba-world/deployease/backend/internal/infrastructure/database/postgres/sqlc"
+)
+
+func TestPreviewSubdomain(t *testing.T) {
+	tests := []struct {
+		branch string
+		slug   string
+	}{
+		{"feature/login", "feature-login"},
+		{"Fix_Bug-42", "fix-bug-42"},
+		{"--release/", "release"},
+		{"ü", "preview"},
+		{strings.Repeat("a", 39) + "/bcd", strings.Repeat("a", 39)},
+	}
+	for _, tt := range tests {
+		sub := previewSubdomain(tt.branch)
+		assert.True(t, strings.HasPrefix(sub, tt.slug+"-"), "%q: %q", tt.branch, sub)
+		assert.Len(t, sub, len(tt.slug)+9, tt.branch)
+		assert.Regexp(t, `^[a-z0-9]([a-z0-9-]*[a-z0-9])?$`, sub)
+	}
+	assert.NotEqual(t, previewSubdomain("main"), previewSubdomain("main"))
+}
+
+func TestPreviewURL(t *testing.T) {
+	preview := sqlc.PreviewEnvironment{Subdomain: "feature-login-abc12345"}
+	assert.Equal(t, "https://feature-login-abc12345.preview.example.com", PreviewURL(preview, "preview.example.com"))
+	assert.Empty(t, PreviewURL(preview, ""))
+}
@@ -0,0 +1,85 @@
+package deployment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/Jesuloba-world/deployease/backend/internal/proxy"
+)
+
+var ErrUnhealthy = errors.New("deployment failed its health check")
+
+// HTTPProber issues a GET against a fixed path. Any response below 500
+// counts as healthy: the application is up and answering, even if it has no
+// route for the probe path. Rollouts and the edge proxy's health checks
+// share it as their proxy.Prober.
+type HTTPProber struct {
+	client *http.Client
+	path   string
+}
+
+func NewHTTPProber(path string, timeout time.Duration) *HTTPProber {
+	if path == "" || path[0] != '/' {
+		path = "/" + path
+	}
+	return &HTTPProber{
+		client: &http.Client{
+			Timeout: timeout,
+			// A redirect is an answer; following it could leave the container.
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		path: path,
+	}
+}
+
+func (p *HTTPProber) Probe(ctx context.Context, addr string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://"+addr+p.path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", "DeployEase-HealthCheck")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrUnhealthy, err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("%w: %s returned %d", ErrUnhealthy, p.path, resp.StatusCode)
+	}
+	return nil
+}
+
+// pollHealthy probes addr every interval until it answers or timeout passes,
+// returning the last probe error in the latter case.
+func pollHealthy(ctx context.Context, prober proxy.Prober, addr string, timeout, interval time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		err := prober.Probe(ctx, addr)
+		if err == nil {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			if errors.Is(err, ErrUnhealthy) {
+				return err
+			}
+			return fmt.Errorf("%w: %v", ErrUnhealthy, err)
+		case <-ticker.C:
+		}
+	}
+}
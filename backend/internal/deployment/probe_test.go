@@ -0,0 +1,67 @@
+package deployment
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPProber(t *testing.T) {
+	var status atomic.Int32
+	status.Store(http.StatusOK)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/healthz", r.URL.Path)
+		w.WriteHeader(int(status.Load()))
+	}))
+	defer srv.Close()
+
+	addr := strings.TrimPrefix(srv.URL, "http://")
+	prober := NewHTTPProber("healthz", time.Second)
+	ctx := context.Background()
+
+	require.NoError(t, prober.Probe(ctx, addr))
+
+	status.Store(http.StatusNotFound)
+	assert.NoError(t, prober.Probe(ctx, addr), "any answer below 500 means the app is up")
+
+	status.Store(http.StatusFound)
+	assert.NoError(t, prober.Probe(ctx, addr))
+
+	status.Store(http.StatusServiceUnavailable)
+	assert.ErrorIs(t, prober.Probe(ctx, addr), ErrUnhealthy)
+
+	srv.Close()
+	assert.ErrorIs(t, prober.Probe(ctx, addr), ErrUnhealthy)
+}
+
+type countingProber struct {
+	calls     atomic.Int32
+	healthyAt int32
+}
+
+func (p *countingProber) Probe(ctx context.Context, addr string) error {
+	if p.calls.Add(1) >= p.healthyAt {
+		return nil
+	}
+	return ErrUnhealthy
+}
+
+func TestPollHealthy(t *testing.T) {
+	ctx := context.Background()
+
+	p := &countingProber{healthyAt: 3}
+	require.NoError(t, pollHealthy(ctx, p, "app:8080", time.Second, time.Millisecond))
+	assert.Equal(t, int32(3), p.calls.Load())
+
+	p = &countingProber{healthyAt: 1 << 30}
+	err := pollHealthy(ctx, p, "app:8080", 20*time.Millisecond, time.Millisecond)
+	assert.ErrorIs(t, err, ErrUnhealthy)
+	assert.Greater(t, p.calls.Load(), int32(1))
+}
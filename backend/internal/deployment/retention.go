@@ -0,0 +1,169 @@
+package deployment
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/Jesuloba-world/deployease/backend/internal/config"
+	"github.com/Jesuloba-world/deployease/backend/internal/infrastructure/database/postgres/sqlc"
+)
+
+const (
+	logPartitionPrefix = "deployment_logs_p"
+	logPartitionLayout = "20060102"
+
+	// logRetentionLockID keeps instances from maintaining partitions at the
+	// same time.
+	logRetentionLockID = 7_245_810_311
+)
+
+// LogRetention keeps deployment logs partitioned by day. It creates the
+// partitions upcoming days are written to and drops the ones that have
+// passed the retention period.
+type LogRetention struct {
+	db  TxBeginner
+	cfg config.LogsConfig
+	now func() time.Time
+}
+
+func NewLogRetention(db TxBeginner, cfg config.LogsConfig) *LogRetention {
+	return &LogRetention{
+		db:  db,
+		cfg: cfg,
+		now: time.Now,
+	}
+}
+
+// Run maintains the partitions right away and then every maintenance
+// interval until ctx is done.
+func (r *LogRetention) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.cfg.MaintenanceInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := r.Maintain(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("Failed to maintain deployment log partitions: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Maintain creates the partitions for today and the configured number of
+// days ahead, and drops the partitions and default-partition rows older than
+// the retention period. It does nothing while another instance is at it.
+func (r *LogRetention) Maintain(ctx context.Context) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var locked bool
+	if err := tx.QueryRow(ctx, `SELECT pg_try_advisory_xact_lock($1)`, logRetentionLockID).Scan(&locked); err != nil {
+		return fmt.Errorf("failed to lock log partitions: %w", err)
+	}
+	if !locked {
+		return nil
+	}
+
+	queries := sqlc.New(tx)
+	partitions, err := queries.ListDeploymentLogPartitions(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list log partitions: %w", err)
+	}
+	existing := make(map[string]bool, len(partitions))
+	for _, name := range partitions {
+		existing[name] = true
+	}
+
+	now := r.now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	for i := 0; i <= r.cfg.PartitionsAhead; i++ {
+		day := today.AddDate(0, 0, i)
+		if existing[logPartitionName(day)] {
+			continue
+		}
+		if err := createLogPartition(ctx, tx, day); err != nil {
+			return err
+		}
+	}
+
+	cutoff := now.Add(-r.cfg.Retention)
+	for _, name := range partitions {
+		day, ok := parseLogPartitionName(name)
+		if !ok || day.AddDate(0, 0, 1).After(cutoff) {
+			continue
+		}
+		if _, err := tx.Exec(ctx, `DROP TABLE `+pgx.Identifier{name}.Sanitize()); err != nil {
+			return fmt.Errorf("failed to drop log partition %s: %w", name, err)
+		}
+	}
+
+	if _, err := queries.DeleteDefaultPartitionLogsBefore(ctx, pgtype.Timestamptz{Time: cutoff, Valid: true}); err != nil {
+		return fmt.Errorf("failed to delete expired logs: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit log partitions: %w", err)
+	}
+	return nil
+}
+
+// createLogPartition adds the partition for day. Lines already written to
+// the default partition for that day are moved into it, since a partition
+// cannot be added over rows the default partition holds.
+func createLogPartition(ctx context.Context, tx pgx.Tx, day time.Time) error {
+	name := logPartitionName(day)
+	table := pgx.Identifier{name}.Sanitize()
+	from, to := day, day.AddDate(0, 0, 1)
+
+	statements := []struct {
+		sql  string
+		args []any
+	}{
+		{sql: `CREATE TABLE ` + table + ` (LIKE deployment_logs INCLUDING DEFAULTS INCLUDING CONSTRAINTS)`},
+		{
+			sql: `WITH moved AS (
+				DELETE FROM deployment_logs_default WHERE created_at >= $1 AND created_at < $2 RETURNING *
+			)
+			INSERT INTO ` + table + ` SELECT * FROM moved`,
+			args: []any{from, to},
+		},
+		// Partition bounds must be literals; both are formatted here.
+		{sql: fmt.Sprintf(`ALTER TABLE deployment_logs ATTACH PARTITION %s FOR VALUES FROM ('%s') TO ('%s')`,
+			table, from.Format(time.RFC3339), to.Format(time.RFC3339))},
+	}
+	for _, stmt := range statements {
+		if _, err := tx.Exec(ctx, stmt.sql, stmt.args...); err != nil {
+			return fmt.Errorf("failed to create log partition %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func logPartitionName(day time.Time) string {
+	return logPartitionPrefix + day.Format(logPartitionLayout)
+}
+
+func parseLogPartitionName(name string) (time.Time, bool) {
+	suffix, ok := strings.CutPrefix(name, logPartitionPrefix)
+	if !ok {
+		return time.Time{}, false
+	}
+	day, err := time.Parse(logPartitionLayout, suffix)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return day, true
+}
@@ -0,0 +1,74 @@
+package deployment
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Jesuloba-world/deployease/backend/internal/config"
+)
+
+func TestLogRetention(t *testing.T) {
+	ctx := context.Background()
+	svc, tc := setupService(t)
+	insertDeployment(t, tc, "d1")
+
+	// Written before any daily partition exists, so into the default one.
+	now := time.Date(2025, 6, 30, 12, 0, 0, 0, time.UTC)
+	insertLog(t, tc, "d1", LogStreamBuild, LogLevelInfo, "today", now)
+	insertLog(t, tc, "d1", LogStreamBuild, LogLevelInfo, "last week", now.AddDate(0, 0, -7))
+	insertLog(t, tc, "d1", LogStreamBuild, LogLevelInfo, "expired", now.AddDate(0, 0, -40))
+
+	retention := NewLogRetention(tc.Pool, config.LogsConfig{Retention: 30 * 24 * time.Hour, PartitionsAhead: 2})
+	retention.now = func() time.Time { return now }
+	require.NoError(t, retention.Maintain(ctx))
+	require.NoError(t, retention.Maintain(ctx), "maintenance is idempotent")
+
+	partitions, err := svc.queries.ListDeploymentLogPartitions(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"deployment_logs_default",
+		"deployment_logs_p20250630",
+		"deployment_logs_p20250701",
+		"deployment_logs_p20250702",
+	}, partitions)
+
+	partitionOf := func(line string) string {
+		t.Helper()
+		var partition string
+		err := tc.Pool.QueryRow(ctx, `SELECT tableoid::regclass::text FROM deployment_logs WHERE line = $1`, line).Scan(&partition)
+		require.NoError(t, err)
+		return partition
+	}
+	assert.Equal(t, "deployment_logs_p20250630", partitionOf("today"), "lines move into their day's partition")
+	assert.Equal(t, "deployment_logs_default", partitionOf("last week"))
+
+	var expired int
+	require.NoError(t, tc.Pool.QueryRow(ctx, `SELECT COUNT(*) FROM deployment_logs WHERE line = 'expired'`).Scan(&expired))
+	assert.Zero(t, expired)
+
+	// Once a whole day has passed the retention period its partition goes.
+	retention.now = func() time.Time { return now.AddDate(0, 0, 31) }
+	require.NoError(t, retention.Maintain(ctx))
+
+	partitions, err = svc.queries.ListDeploymentLogPartitions(ctx)
+	require.NoError(t, err)
+	assert.NotContains(t, partitions, "deployment_logs_p20250630")
+	assert.Contains(t, partitions, "deployment_logs_p20250701")
+	assert.Contains(t, partitions, "deployment_logs_p20250802")
+}
+
+func TestParseLogPartitionName(t *testing.T) {
+	day := time.Date(2025, 6, 30, 0, 0, 0, 0, time.UTC)
+	got, ok := parseLogPartitionName(logPartitionName(day))
+	require.True(t, ok)
+	assert.True(t, day.Equal(got))
+
+	for _, name := range []string{"deployment_logs_default", "deployment_logs_p2025", "jobs"} {
+		_, ok := parseLogPartitionName(name)
+		assert.False(t, ok, name)
+	}
+}
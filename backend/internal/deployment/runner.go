@@ -0,0 +1,313 @@
+package deployment
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"maps"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/Jesuloba-world/deployease/backend/internal/build"
+	"github.com/Jesuloba-world/deployease/backend/internal/config"
+	"github.com/Jesuloba-world/deployease/backend/internal/container"
+	"github.com/Jesuloba-world/deployease/backend/internal/infrastructure/database/postgres/sqlc"
+	"github.com/Jesuloba-world/deployease/backend/internal/jobs"
+	"github.com/Jesuloba-world/deployease/backend/internal/proxy"
+	"github.com/Jesuloba-world/deployease/backend/internal/secrets"
+)
+
+// RunJobKind is the job that takes a pending deployment through its build
+// and rollout.
+const RunJobKind = "deployment.run"
+
+type RunPayload struct {
+	DeploymentID string `json:"deployment_id"`
+}
+
+// EnvSource supplies the environment variables a project's containers are
+// started with; deployments of a branch may have their own.
+type EnvSource interface {
+	Resolve(ctx context.Context, projectID, branch string) (map[string]string, error)
+}
+
+type mergedEnv []EnvSource
+
+// MergeEnv combines several sources into one. Later sources win, so a
+// project's own variables can override ones DeployEase provides, such as
+// the connection strings of its managed databases.
+func MergeEnv(sources ...EnvSource) EnvSource {
+	return mergedEnv(sources)
+}
+
+func (m mergedEnv) Resolve(ctx context.Context, projectID, branch string) (map[string]string, error) {
+	env := make(map[string]string)
+	for _, source := range m {
+		vars, err := source.Resolve(ctx, projectID, branch)
+		if err != nil {
+			return nil, err
+		}
+		maps.Copy(env, vars)
+	}
+	return env, nil
+}
+
+// Runner executes deployment jobs on the worker pool.
+type Runner struct {
+	service    *Service
+	pipeline   *build.Pipeline
+	runtime    container.Runtime
+	prober     proxy.Prober
+	env        EnvSource
+	cfg        config.RuntimeConfig
+	rolloutCfg config.RolloutConfig
+	logsCfg    config.LogsConfig
+}
+
+func NewRunner(service *Service, pipeline *build.Pipeline, runtime container.Runtime, prober proxy.Prober, env EnvSource, cfg config.RuntimeConfig, rollout config.RolloutConfig, logs config.LogsConfig) *Runner {
+	return &Runner{
+		service:    service,
+		pipeline:   pipeline,
+		runtime:    runtime,
+		prober:     prober,
+		env:        env,
+		cfg:        cfg,
+		rolloutCfg: rollout,
+		logsCfg:    logs,
+	}
+}
+
+// rolloutError marks a failure of the deployment itself, such as a broken
+// build or a container that will not start, as opposed to an infrastructure
+// error that is worth retrying the job for.
+type rolloutError struct {
+	err error
+}
+
+func (e *rolloutError) Error() string { return e.err.Error() }
+func (e *rolloutError) Unwrap() error { return e.err }
+
+// Handle builds the deployment named in the job payload, starts its
+// container and, once the container passes its health check, cuts the
+// environment of its branch over to it. The previous container keeps
+// serving until then. A failed build or rollout fails the deployment rather
+// than the job; only infrastructure errors are returned so the job is
+// retried.
+func (r *Runner) Handle(ctx context.Context, job sqlc.Job) error {
+	var payload RunPayload
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return jobs.Permanent(fmt.Errorf("invalid deployment job payload: %w", err))
+	}
+
+	d, err := r.service.queries.GetDeployment(ctx, payload.DeploymentID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return jobs.Permanent(ErrNotFound)
+		}
+		return fmt.Errorf("failed to get deployment: %w", err)
+	}
+
+	switch d.Status {
+	case sqlc.DeploymentStatusPending:
+		if _, err := r.service.Start(ctx, d.ID); err != nil {
+			if errors.Is(err, ErrInvalidTransition) {
+				// Cancelled between the read and the lock.
+				return nil
+			}
+			return err
+		}
+	case sqlc.DeploymentStatusInProgress:
+		// A previous attempt died part way through; start over.
+	default:
+		return nil
+	}
+
+	p, err := r.service.queries.GetProject(ctx, d.ProjectID)
+	if err != nil {
+		return fmt.Errorf("failed to get project: %w", err)
+	}
+
+	// Logs are still flushed if the job is interrupted mid-build.
+	logs := r.service.NewLogWriter(context.WithoutCancel(ctx), d, LogStreamBuild)
+	containerID, err := r.rollout(ctx, p, d, logs)
+	if err != nil {
+		fmt.Fprintf(logs, "==> Deployment failed: %v\n", err)
+	}
+	if err := logs.Close(); err != nil {
+		log.Printf("Failed to store build logs for deployment %s: %v", d.ID, err)
+	}
+
+	if err != nil {
+		var failure *rolloutError
+		if ctx.Err() != nil || !errors.As(err, &failure) {
+			// Interrupted or an infrastructure problem; retry the job.
+			return err
+		}
+		return r.finish(ctx, d.ID, sqlc.DeploymentStatusFailed)
+	}
+
+	_, previous, err := r.service.Activate(ctx, d.ID)
+	if errors.Is(err, ErrInvalidTransition) || errors.Is(err, ErrPreviewNotFound) {
+		// Cancelled, or its preview torn down, while rolling out; the new
+		// container must not linger.
+		r.discard(ctx, d.ID, containerID)
+		return r.finish(ctx, d.ID, sqlc.DeploymentStatusCancelled)
+	}
+	if err != nil {
+		return err
+	}
+
+	r.scheduleRetire(ctx, d)
+	err = r.scheduleVerify(ctx, VerifyPayload{
+		DeploymentID: d.ID,
+		PreviousID:   previous,
+		Until:        time.Now().Add(r.rolloutCfg.RollbackWindow),
+	})
+	if err != nil {
+		log.Printf("Failed to schedule health checks of deployment %s: %v", d.ID, err)
+	}
+	return nil
+}
+
+// rollout builds the deployment's image, starts a container from it and
+// waits for it to become healthy, returning the container ID. Rollbacks, and
+// retries of a job that got past the build, reuse the recorded image.
+func (r *Runner) rollout(ctx context.Context, p sqlc.Project, d sqlc.Deployment, logs io.Writer) (string, error) {
+	image := d.ImageRef.String
+	if d.ImageRef.Valid {
+		if d.RollbackTargetID.Valid {
+			fmt.Fprintf(logs, "==> Rolling back to deployment %s\n", d.RollbackTargetID.String)
+		}
+		fmt.Fprintf(logs, "==> Using image %s\n", image)
+	} else {
+		result, err := r.pipeline.Run(ctx, build.Request{
+			ProjectID:     p.ID,
+			DeploymentID:  d.ID,
+			RepositoryURL: p.RepositoryUrl,
+			CommitHash:    d.CommitHash.String,
+		}, logs)
+		if err != nil {
+			return "", &rolloutError{err: err}
+		}
+
+		_, err = r.service.queries.SetDeploymentImage(ctx, sqlc.SetDeploymentImageParams{
+			ImageRef: pgtype.Text{String: result.Image, Valid: true},
+			ID:       d.ID,
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to record deployment image: %w", err)
+		}
+		image = result.Image
+	}
+
+	id, err := r.startContainer(ctx, p, d, image, logs)
+	if err != nil {
+		return "", err
+	}
+
+	fmt.Fprintf(logs, "==> Waiting for %s to pass its health check\n", r.rolloutCfg.HealthPath)
+	if err := r.waitHealthy(ctx, id, r.rolloutCfg.HealthTimeout); err != nil {
+		r.discard(context.WithoutCancel(ctx), d.ID, id)
+		if ctx.Err() != nil || !errors.Is(err, ErrUnhealthy) {
+			return "", err
+		}
+		return "", &rolloutError{err: err}
+	}
+	fmt.Fprintln(logs, "==> Health check passed")
+
+	return id, nil
+}
+
+func (r *Runner) startContainer(ctx context.Context, p sqlc.Project, d sqlc.Deployment, image string, logs io.Writer) (string, error) {
+	name := containerName(d.ID)
+
+	// A previous attempt may have got as far as creating the container.
+	if err := r.runtime.Remove(ctx, name); err != nil && !errors.Is(err, container.ErrNotFound) {
+		return "", fmt.Errorf("failed to remove stale container: %w", err)
+	}
+
+	env, err := r.env.Resolve(ctx, p.ID, d.Branch)
+	if err != nil {
+		if errors.Is(err, secrets.ErrDecrypt) {
+			// Retrying will not make a secret readable.
+			return "", &rolloutError{err: err}
+		}
+		return "", fmt.Errorf("failed to load environment variables: %w", err)
+	}
+	env["PORT"] = strconv.Itoa(r.cfg.ContainerPort)
+
+	fmt.Fprintf(logs, "==> Starting container %s\n", name)
+	id, err := r.runtime.Create(ctx, container.Spec{
+		Name:  name,
+		Image: image,
+		Env:   env,
+		Labels: map[string]string{
+			container.LabelProject:    p.ID,
+			container.LabelDeployment: d.ID,
+		},
+		Port:    r.cfg.ContainerPort,
+		Network: r.cfg.Network,
+	})
+	if err != nil {
+		return "", &rolloutError{err: err}
+	}
+
+	_, err = r.service.queries.SetDeploymentContainer(ctx, sqlc.SetDeploymentContainerParams{
+		ContainerID: pgtype.Text{String: id, Valid: true},
+		ID:          d.ID,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to record deployment container: %w", err)
+	}
+
+	if err := r.runtime.Start(ctx, id); err != nil {
+		r.discard(context.WithoutCancel(ctx), d.ID, id)
+		return "", &rolloutError{err: err}
+	}
+
+	err = r.scheduleCollect(ctx, CollectLogsPayload{DeploymentID: d.ID, ContainerID: id})
+	if err != nil {
+		log.Printf("Failed to schedule log collection of deployment %s: %v", d.ID, err)
+	}
+
+	fmt.Fprintf(logs, "==> Container %s is running\n", name)
+	return id, nil
+}
+
+// discard removes a deployment's container and forgets it, keeping what the
+// container wrote.
+func (r *Runner) discard(ctx context.Context, deploymentID, containerID string) {
+	r.collectFinalLogs(ctx, deploymentID, containerID)
+
+	if err := r.runtime.Remove(ctx, containerID); err != nil && !errors.Is(err, container.ErrNotFound) {
+		log.Printf("Failed to remove container of deployment %s: %v", deploymentID, err)
+		return
+	}
+
+	_, err := r.service.queries.SetDeploymentContainer(ctx, sqlc.SetDeploymentContainerParams{
+		ID: deploymentID,
+	})
+	if err != nil {
+		log.Printf("Failed to clear container of deployment %s: %v", deploymentID, err)
+	}
+}
+
+// finish moves the deployment to its final status. A deployment cancelled
+// while it was building stays cancelled.
+func (r *Runner) finish(ctx context.Context, deploymentID string, status sqlc.DeploymentStatus) error {
+	_, err := r.service.Transition(ctx, deploymentID, status)
+	if errors.Is(err, ErrInvalidTransition) {
+		return nil
+	}
+	return err
+}
+
+func containerName(deploymentID string) string {
+	return "deployease-" + deploymentID
+}
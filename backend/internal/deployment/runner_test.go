@@ -0,0 +1,485 @@
+package deployment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Jesuloba-world/deployease/backend/internal/build"
+	"github.com/Jesuloba-world/deployease/backend/internal/build/buildtest"
+	"github.com/Jesuloba-world/deployease/backend/internal/config"
+	"github.com/Jesuloba-world/deployease/backend/internal/container"
+	"github.com/Jesuloba-world/deployease/backend/internal/container/containertest"
+	database "github.com/Jesuloba-world/deployease/backend/internal/infrastructure/database/postgres"
+	"github.com/Jesuloba-world/deployease/backend/internal/infrastructure/database/postgres/sqlc"
+	"github.com/Jesuloba-world/deployease/backend/internal/jobs"
+	"github.com/Jesuloba-world/deployease/backend/internal/secrets"
+)
+
+type runnerFixture struct {
+	svc     *Service
+	tc      *database.TestContainer
+	runner  *Runner
+	builder *buildtest.Builder
+	runtime *containertest.Runtime
+	prober  *fakeProber
+	env     *fakeEnv
+	commit  string
+}
+
+// fakeProber reports every address healthy unless it has been marked down.
+type fakeProber struct {
+	mu   sync.Mutex
+	down map[string]bool
+}
+
+func (p *fakeProber) Probe(ctx context.Context, addr string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.down[addr] {
+		return fmt.Errorf("%w: connection refused", ErrUnhealthy)
+	}
+	return nil
+}
+
+func (p *fakeProber) setDown(addr string, down bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.down[addr] = down
+}
+
+// fakeEnv hands every project the same variables, overridden per branch.
+type fakeEnv struct {
+	vars     map[string]string
+	branches map[string]map[string]string
+	err      error
+}
+
+func (e *fakeEnv) Resolve(ctx context.Context, projectID, branch string) (map[string]string, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+	env := make(map[string]string, len(e.vars))
+	for k, v := range e.vars {
+		env[k] = v
+	}
+	for k, v := range e.branches[branch] {
+		env[k] = v
+	}
+	return env, nil
+}
+
+func setupRunner(t *testing.T) *runnerFixture {
+	t.Helper()
+	svc, tc := setupService(t)
+
+	repo, hashes := buildtest.BareRepo(t, map[string]string{"Dockerfile": "FROM scratch\n"})
+	_, err := tc.Pool.Exec(context.Background(), `UPDATE projects SET repository_url = $1 WHERE id = 'p1'`, repo)
+	require.NoError(t, err)
+
+	builder := &buildtest.Builder{Output: "building\n"}
+	runtime := containertest.NewRuntime()
+	pipeline := build.NewPipeline(builder, config.BuildConfig{WorkDir: t.TempDir(), Timeout: time.Minute})
+
+	prober := &fakeProber{down: make(map[string]bool)}
+	env := &fakeEnv{vars: make(map[string]string)}
+	rollout := config.RolloutConfig{
+		HealthPath:     "/",
+		HealthTimeout:  50 * time.Millisecond,
+		ProbeInterval:  10 * time.Millisecond,
+		RollbackWindow: time.Minute,
+		VerifyInterval: 10 * time.Millisecond,
+	}
+
+	return &runnerFixture{
+		svc:     svc,
+		tc:      tc,
+		runner:  NewRunner(svc, pipeline, runtime, prober, env, config.RuntimeConfig{ContainerPort: 8080, StopTimeout: time.Second}, rollout, config.LogsConfig{CollectInterval: time.Minute}),
+		builder: builder,
+		runtime: runtime,
+		prober:  prober,
+		env:     env,
+		commit:  hashes[0],
+	}
+}
+
+// addr is where the fake prober sees a container's application.
+func (f *runnerFixture) addr(t *testing.T, containerID string) string {
+	t.Helper()
+	info, err := f.runtime.Inspect(context.Background(), containerID)
+	require.NoError(t, err)
+	return info.IPAddress + ":8080"
+}
+
+// runJobs runs and removes the queued jobs of the given kind, returning how
+// many there were.
+func (f *runnerFixture) runJobs(t *testing.T, kind string, handler jobs.Handler) int {
+	t.Helper()
+	ctx := context.Background()
+
+	rows, err := f.tc.Pool.Query(ctx, `DELETE FROM jobs WHERE kind = $1 RETURNING kind, payload`, kind)
+	require.NoError(t, err)
+	var queued []sqlc.Job
+	for rows.Next() {
+		var job sqlc.Job
+		require.NoError(t, rows.Scan(&job.Kind, &job.Payload))
+		queued = append(queued, job)
+	}
+	require.NoError(t, rows.Err())
+
+	for _, job := range queued {
+		require.NoError(t, handler(ctx, job))
+	}
+	return len(queued)
+}
+
+func (f *runnerFixture) activeDeployment(t *testing.T) string {
+	t.Helper()
+	p, err := f.svc.queries.GetProject(context.Background(), "p1")
+	require.NoError(t, err)
+	return p.ActiveDeploymentID.String
+}
+
+func (f *runnerFixture) deploy(t *testing.T) sqlc.Deployment {
+	t.Helper()
+	ctx := context.Background()
+
+	d, err := f.svc.Create(ctx, "p1", f.commit)
+	require.NoError(t, err)
+	require.NoError(t, f.runner.Handle(ctx, sqlc.Job{
+		Kind:    RunJobKind,
+		Payload: []byte(`{"deployment_id":"` + d.ID + `"}`),
+	}))
+
+	d, err = f.svc.Get(ctx, "p1", d.ID)
+	require.NoError(t, err)
+	return d
+}
+
+func TestRunnerDeploysAndRetiresPrevious(t *testing.T) {
+	ctx := context.Background()
+	f := setupRunner(t)
+
+	first := f.deploy(t)
+	assert.Equal(t, sqlc.DeploymentStatusSuccess, first.Status)
+	assert.Equal(t, f.builder.Builds()[0].Spec.Tag, first.ImageRef.String)
+	require.True(t, first.ContainerID.Valid)
+	assert.Equal(t, []string{first.ContainerID.String}, f.runtime.Running(container.LabelProject, "p1"))
+
+	spec, ok := f.runtime.Spec(first.ContainerID.String)
+	require.True(t, ok)
+	assert.Equal(t, first.ImageRef.String, spec.Image)
+	assert.Equal(t, "8080", spec.Env["PORT"])
+	assert.Equal(t, first.ID, spec.Labels[container.LabelDeployment])
+
+	logs, err := f.svc.queries.ListDeploymentLogs(ctx, sqlc.ListDeploymentLogsParams{
+		DeploymentID: first.ID,
+		PageLimit:    100,
+	})
+	require.NoError(t, err)
+	var lines []string
+	for _, l := range logs {
+		lines = append(lines, l.Line)
+	}
+	assert.Contains(t, lines, "building")
+
+	assert.Equal(t, first.ID, f.activeDeployment(t))
+
+	second := f.deploy(t)
+	assert.Equal(t, sqlc.DeploymentStatusSuccess, second.Status)
+	assert.Equal(t, second.ID, f.activeDeployment(t))
+	assert.ElementsMatch(t, []string{first.ContainerID.String, second.ContainerID.String}, f.runtime.Running(container.LabelProject, "p1"),
+		"the previous container drains before it is retired")
+
+	assert.Equal(t, 1, f.runJobs(t, RetireJobKind, f.runner.Retire))
+	assert.Equal(t, []string{second.ContainerID.String}, f.runtime.Running(container.LabelProject, "p1"))
+
+	first, err = f.svc.Get(ctx, "p1", first.ID)
+	require.NoError(t, err)
+	assert.False(t, first.ContainerID.Valid, "the previous container is retired")
+	_, err = f.runtime.Inspect(ctx, "deployease-"+first.ID)
+	assert.ErrorIs(t, err, container.ErrNotFound)
+
+	// Running the job again once the deployment finished is a no-op.
+	require.NoError(t, f.runner.Handle(ctx, sqlc.Job{
+		Kind:    RunJobKind,
+		Payload: []byte(`{"deployment_id":"` + second.ID + `"}`),
+	}))
+	assert.Len(t, f.builder.Builds(), 2)
+}
+
+func TestRunnerFailures(t *testing.T) {
+	f := setupRunner(t)
+
+	live := f.deploy(t)
+	require.Equal(t, sqlc.DeploymentStatusSuccess, live.Status)
+
+	f.runtime.StartErr = errors.New("exec format error")
+	d := f.deploy(t)
+	assert.Equal(t, sqlc.DeploymentStatusFailed, d.Status)
+	assert.False(t, d.ContainerID.Valid)
+	assert.Equal(t, []string{live.ContainerID.String}, f.runtime.Running(container.LabelProject, "p1"),
+		"a failed rollout leaves the live container alone")
+
+	f.runtime.StartErr = nil
+	f.prober.setDown("10.0.0.3:8080", true)
+	d = f.deploy(t)
+	assert.Equal(t, sqlc.DeploymentStatusFailed, d.Status, "a container that never gets healthy fails the deployment")
+	assert.False(t, d.ContainerID.Valid)
+	assert.Equal(t, live.ID, f.activeDeployment(t))
+
+	f.builder.Err = errors.New("compile error")
+	d = f.deploy(t)
+	assert.Equal(t, sqlc.DeploymentStatusFailed, d.Status)
+	assert.Equal(t, pgtype.Text{}, d.ImageRef)
+}
+
+func TestRunnerRollsBackUnhealthyDeployment(t *testing.T) {
+	ctx := context.Background()
+	f := setupRunner(t)
+
+	first := f.deploy(t)
+	second := f.deploy(t)
+	require.Equal(t, sqlc.DeploymentStatusSuccess, second.Status)
+	f.runJobs(t, RetireJobKind, f.runner.Retire)
+	require.Equal(t, []string{second.ContainerID.String}, f.runtime.Running(container.LabelProject, "p1"))
+
+	// The superseded deployment's check lapses; the live one is healthy and
+	// keeps being verified until the window closes.
+	assert.Equal(t, 2, f.runJobs(t, VerifyJobKind, f.runner.Verify))
+	assert.Equal(t, 1, f.runJobs(t, VerifyJobKind, f.runner.Verify))
+	assert.Equal(t, second.ID, f.activeDeployment(t))
+
+	f.prober.setDown(f.addr(t, second.ContainerID.String), true)
+	assert.Equal(t, 1, f.runJobs(t, VerifyJobKind, f.runner.Verify))
+
+	second, err := f.svc.Get(ctx, "p1", second.ID)
+	require.NoError(t, err)
+	assert.Equal(t, sqlc.DeploymentStatusRolledBack, second.Status)
+	assert.False(t, second.ContainerID.Valid)
+	assert.Equal(t, first.ID, f.activeDeployment(t))
+
+	first, err = f.svc.Get(ctx, "p1", first.ID)
+	require.NoError(t, err)
+	require.True(t, first.ContainerID.Valid, "the previous deployment is recreated from its image")
+	assert.Equal(t, []string{first.ContainerID.String}, f.runtime.Running(container.LabelProject, "p1"))
+	spec, ok := f.runtime.Spec(first.ContainerID.String)
+	require.True(t, ok)
+	assert.Equal(t, first.ImageRef.String, spec.Image)
+
+	assert.Zero(t, f.runJobs(t, VerifyJobKind, f.runner.Verify), "verification stops after a rollback")
+}
+
+func TestRunnerKeepsOnlyDeploymentWhenUnhealthy(t *testing.T) {
+	f := setupRunner(t)
+
+	d := f.deploy(t)
+	f.prober.setDown(f.addr(t, d.ContainerID.String), true)
+	f.runJobs(t, VerifyJobKind, f.runner.Verify)
+
+	d, err := f.svc.Get(context.Background(), "p1", d.ID)
+	require.NoError(t, err)
+	assert.Equal(t, sqlc.DeploymentStatusSuccess, d.Status, "there is nothing to roll back to")
+	assert.Equal(t, d.ID, f.activeDeployment(t))
+}
+
+func TestRunnerRollbackReusesImage(t *testing.T) {
+	ctx := context.Background()
+	f := setupRunner(t)
+
+	first := f.deploy(t)
+	second := f.deploy(t)
+	f.runJobs(t, RetireJobKind, f.runner.Retire)
+
+	_, err := f.svc.Rollback(ctx, "p1", second.ID)
+	assert.ErrorIs(t, err, ErrRollbackTarget, "the active deployment cannot be rolled back to")
+
+	f.builder.Err = errors.New("compile error")
+	failed := f.deploy(t)
+	require.Equal(t, sqlc.DeploymentStatusFailed, failed.Status)
+	_, err = f.svc.Rollback(ctx, "p1", failed.ID)
+	assert.ErrorIs(t, err, ErrRollbackTarget)
+
+	_, err = f.svc.Rollback(ctx, "p2", first.ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	d, err := f.svc.Rollback(ctx, "p1", first.ID)
+	require.NoError(t, err)
+	assert.Equal(t, sqlc.DeploymentStatusPending, d.Status)
+	assert.Equal(t, first.ID, d.RollbackTargetID.String)
+	assert.Equal(t, first.ImageRef, d.ImageRef)
+	assert.Equal(t, first.CommitHash, d.CommitHash)
+
+	require.NoError(t, f.runner.Handle(ctx, sqlc.Job{
+		Kind:    RunJobKind,
+		Payload: []byte(`{"deployment_id":"` + d.ID + `"}`),
+	}))
+	assert.Len(t, f.builder.Builds(), 3, "a rollback does not rebuild")
+
+	d, err = f.svc.Get(ctx, "p1", d.ID)
+	require.NoError(t, err)
+	assert.Equal(t, sqlc.DeploymentStatusSuccess, d.Status)
+	assert.Equal(t, d.ID, f.activeDeployment(t))
+	spec, ok := f.runtime.Spec(d.ContainerID.String)
+	require.True(t, ok)
+	assert.Equal(t, first.ImageRef.String, spec.Image)
+}
+
+func TestRunnerRedeployInjectsEnvironment(t *testing.T) {
+	ctx := context.Background()
+	f := setupRunner(t)
+
+	_, err := f.svc.Redeploy(ctx, "p1", "")
+	assert.ErrorIs(t, err, ErrNoActiveDeployment)
+
+	f.env.vars["LOG_LEVEL"] = "debug"
+	first := f.deploy(t)
+	spec, ok := f.runtime.Spec(first.ContainerID.String)
+	require.True(t, ok)
+	assert.Equal(t, map[string]string{"LOG_LEVEL": "debug", "PORT": "8080"}, spec.Env)
+
+	f.env.vars["LOG_LEVEL"] = "info"
+	d, err := f.svc.Redeploy(ctx, "p1", "")
+	require.NoError(t, err)
+	assert.Equal(t, sqlc.DeploymentStatusPending, d.Status)
+	assert.Equal(t, first.ImageRef, d.ImageRef)
+	assert.False(t, d.RollbackTargetID.Valid)
+
+	require.NoError(t, f.runner.Handle(ctx, sqlc.Job{
+		Kind:    RunJobKind,
+		Payload: []byte(`{"deployment_id":"` + d.ID + `"}`),
+	}))
+	assert.Len(t, f.builder.Builds(), 1, "a redeploy does not rebuild")
+
+	d, err = f.svc.Get(ctx, "p1", d.ID)
+	require.NoError(t, err)
+	assert.Equal(t, d.ID, f.activeDeployment(t))
+	spec, ok = f.runtime.Spec(d.ContainerID.String)
+	require.True(t, ok)
+	assert.Equal(t, "info", spec.Env["LOG_LEVEL"])
+
+	f.env.err = fmt.Errorf("failed to decrypt API_TOKEN: %w", secrets.ErrDecrypt)
+	failed := f.deploy(t)
+	assert.Equal(t, sqlc.DeploymentStatusFailed, failed.Status, "an unreadable secret fails the deployment")
+	assert.Equal(t, d.ID, f.activeDeployment(t))
+}
+
+func TestMergeEnvLaterSourcesWin(t *testing.T) {
+	ctx := context.Background()
+	databases := &fakeEnv{vars: map[string]string{"DATABASE_URL": "postgres://db", "REDIS_URL": "redis://cache"}}
+	project := &fakeEnv{vars: map[string]string{"DATABASE_URL": "postgres://elsewhere", "LOG_LEVEL": "debug"}}
+
+	env, err := MergeEnv(databases, project).Resolve(ctx, "p1", "main")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"DATABASE_URL": "postgres://elsewhere",
+		"REDIS_URL":    "redis://cache",
+		"LOG_LEVEL":    "debug",
+	}, env)
+
+	databases.err = errors.New("boom")
+	_, err = MergeEnv(databases, project).Resolve(ctx, "p1", "main")
+	assert.Error(t, err)
+}
+
+func TestRunnerCollectsRuntimeLogs(t *testing.T) {
+	ctx := context.Background()
+	f := setupRunner(t)
+
+	d := f.deploy(t)
+	runtimeLines := func() []string {
+		t.Helper()
+		logs, err := f.svc.queries.ListDeploymentLogsSince(ctx, sqlc.ListDeploymentLogsSinceParams{
+			DeploymentID: d.ID,
+			Stream:       LogStreamRuntime,
+			Since:        pgtype.Timestamptz{Valid: true},
+			PageLimit:    100,
+		})
+		require.NoError(t, err)
+		lines := []string{}
+		for _, l := range logs {
+			lines = append(lines, l.Line)
+		}
+		return lines
+	}
+
+	f.runtime.WriteLog(d.ContainerID.String, "ERROR first request failed")
+	assert.Equal(t, 1, f.runJobs(t, CollectLogsJobKind, f.runner.CollectLogs))
+	f.runtime.WriteLog(d.ContainerID.String, "second request")
+	assert.Equal(t, 1, f.runJobs(t, CollectLogsJobKind, f.runner.CollectLogs), "collection continues while the container runs")
+
+	started := "started " + d.ImageRef.String
+	assert.Equal(t, []string{started, "ERROR first request failed", "second request"}, runtimeLines())
+
+	// A retired container's last output is kept.
+	f.runtime.WriteLog(d.ContainerID.String, "shutting down")
+	f.deploy(t)
+	f.runJobs(t, RetireJobKind, f.runner.Retire)
+	assert.Equal(t, []string{started, "ERROR first request failed", "second request", "shutting down"}, runtimeLines())
+
+	assert.Equal(t, 2, f.runJobs(t, CollectLogsJobKind, f.runner.CollectLogs))
+	assert.Equal(t, 1, f.runJobs(t, CollectLogsJobKind, f.runner.CollectLogs), "only the live container is still collected")
+}
+
+func TestRunnerDeploysPreviewEnvironment(t *testing.T) {
+	ctx := context.Background()
+	f := setupRunner(t)
+
+	production := f.deploy(t)
+
+	_, err := f.svc.CreateForBranch(ctx, "p1", "feature/login", f.commit)
+	assert.ErrorIs(t, err, ErrPreviewsDisabled)
+
+	_, err = f.tc.Pool.Exec(ctx, `UPDATE projects SET previews_enabled = TRUE WHERE id = 'p1'`)
+	require.NoError(t, err)
+	f.env.branches = map[string]map[string]string{"feature/login": {"LOG_LEVEL": "debug"}}
+
+	d, err := f.svc.CreateForBranch(ctx, "p1", "feature/login", f.commit)
+	require.NoError(t, err)
+	assert.Equal(t, "feature/login", d.Branch)
+	require.NoError(t, f.runner.Handle(ctx, sqlc.Job{
+		Kind:    RunJobKind,
+		Payload: []byte(`{"deployment_id":"` + d.ID + `"}`),
+	}))
+
+	d, err = f.svc.Get(ctx, "p1", d.ID)
+	require.NoError(t, err)
+	assert.Equal(t, sqlc.DeploymentStatusSuccess, d.Status)
+	assert.Equal(t, production.ID, f.activeDeployment(t), "a preview leaves production alone")
+	assert.Zero(t, f.runJobs(t, RetireJobKind, f.runner.Retire))
+
+	spec, ok := f.runtime.Spec(d.ContainerID.String)
+	require.True(t, ok)
+	assert.Equal(t, "debug", spec.Env["LOG_LEVEL"])
+
+	previews, err := f.svc.ListPreviews(ctx, "p1")
+	require.NoError(t, err)
+	require.Len(t, previews, 1)
+	assert.Equal(t, d.ID, previews[0].ActiveDeploymentID.String)
+
+	history, err := f.svc.List(ctx, ListParams{ProjectID: "p1", Branch: "feature/login", Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, history.Deployments, 1)
+	assert.Equal(t, d.ID, history.Deployments[0].ID)
+
+	_, err = f.svc.Redeploy(ctx, "p1", "feature/login")
+	require.NoError(t, err)
+
+	torn, err := f.svc.TeardownPreview(ctx, "p1", "feature/login")
+	require.NoError(t, err)
+	assert.Equal(t, previews[0].ID, torn.ID)
+	assert.Equal(t, 1, f.runJobs(t, RetireJobKind, f.runner.Retire))
+	assert.Equal(t, []string{production.ContainerID.String}, f.runtime.Running(container.LabelProject, "p1"))
+
+	_, err = f.svc.TeardownPreview(ctx, "p1", "feature/login")
+	assert.ErrorIs(t, err, ErrPreviewNotFound)
+	_, err = f.svc.TeardownPreview(ctx, "p1", "main")
+	assert.ErrorIs(t, err, ErrPreviewNotFound)
+}
@@ -0,0 +1,96 @@
+package deployment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/Jesuloba-world/deployease/backend/internal/infrastructure/database/postgres/sqlc"
+	"github.com/Jesuloba-world/deployease/backend/internal/pagination"
+)
+
+var ErrInvalidTimeRange = errors.New("since must be before until")
+
+type LogSearchParams struct {
+	ProjectID string
+	// DeploymentID and Stream narrow the search when set.
+	DeploymentID string
+	Stream       string
+	// Query matches lines containing it, ignoring case.
+	Query string
+	// Level matches lines of that level or more severe.
+	Level  string
+	Since  time.Time
+	Until  time.Time
+	Cursor string
+	Limit  int
+}
+
+type LogPage struct {
+	Logs       []sqlc.DeploymentLog
+	NextCursor string
+}
+
+// SearchLogs returns the stored log lines of a project's deployments that
+// match params, newest first.
+func (s *Service) SearchLogs(ctx context.Context, params LogSearchParams) (*LogPage, error) {
+	if !params.Since.IsZero() && !params.Until.IsZero() && !params.Since.Before(params.Until) {
+		return nil, ErrInvalidTimeRange
+	}
+
+	limit := pagination.Limit(params.Limit)
+	arg := sqlc.SearchDeploymentLogsParams{
+		ProjectID:    params.ProjectID,
+		DeploymentID: pgtype.Text{String: params.DeploymentID, Valid: params.DeploymentID != ""},
+		Levels:       []string{},
+		Stream:       pgtype.Text{String: params.Stream, Valid: params.Stream != ""},
+		Since:        pgtype.Timestamptz{Time: params.Since, Valid: !params.Since.IsZero()},
+		Until:        pgtype.Timestamptz{Time: params.Until, Valid: !params.Until.IsZero()},
+		PageLimit:    int32(limit + 1),
+	}
+	if params.Level != "" {
+		arg.Levels = LogLevelsFrom(params.Level)
+	}
+	if params.Query != "" {
+		arg.Pattern = pgtype.Text{String: "%" + escapeLike(params.Query) + "%", Valid: true}
+	}
+
+	if params.Cursor != "" {
+		createdAt, rawID, err := pagination.DecodeCursor(params.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		id, err := strconv.ParseInt(rawID, 10, 64)
+		if err != nil {
+			return nil, pagination.ErrInvalidCursor
+		}
+		arg.CursorCreatedAt = pgtype.Timestamptz{Time: createdAt, Valid: true}
+		arg.CursorID = pgtype.Int8{Int64: id, Valid: true}
+	}
+
+	logs, err := s.queries.SearchDeploymentLogs(ctx, arg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search deployment logs: %w", err)
+	}
+
+	page := &LogPage{Logs: logs}
+	if len(logs) > limit {
+		page.Logs = logs[:limit]
+		last := page.Logs[limit-1]
+		page.NextCursor = pagination.EncodeCursor(last.CreatedAt.Time, strconv.FormatInt(last.ID, 10))
+	}
+
+	return page, nil
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// escapeLike makes s match literally in a LIKE pattern.
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
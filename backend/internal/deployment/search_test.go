@@ -0,0 +1,83 @@
+package deployment
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	database "github.com/Jesuloba-world/deployease/backend/internal/infrastructure/database/postgres"
+)
+
+func insertLog(t *testing.T, tc *database.TestContainer, deploymentID, stream, level, line string, at time.Time) {
+	t.Helper()
+	_, err := tc.Pool.Exec(context.Background(),
+		`INSERT INTO deployment_logs (deployment_id, stream, level, line, created_at) VALUES ($1, $2, $3, $4, $5)`,
+		deploymentID, stream, level, line, at)
+	require.NoError(t, err)
+}
+
+func TestSearchLogs(t *testing.T) {
+	ctx := context.Background()
+	svc, tc := setupService(t)
+	insertDeployment(t, tc, "d1")
+	insertDeployment(t, tc, "d2")
+
+	// Another project's logs never match.
+	_, err := tc.Pool.Exec(ctx, `INSERT INTO projects (id, name, repository_url, user_id) VALUES ('p2', 'other', 'https://github.com/user/other.git', 'u1')`)
+	require.NoError(t, err)
+	_, err = tc.Pool.Exec(ctx, `INSERT INTO deployments (id, project_id, commit_hash, branch) VALUES ('d3', 'p2', 'abc123', 'main')`)
+	require.NoError(t, err)
+
+	base := time.Date(2025, 6, 30, 12, 0, 0, 0, time.UTC)
+	insertLog(t, tc, "d1", LogStreamBuild, LogLevelInfo, "Step 1/3 : FROM golang", base)
+	insertLog(t, tc, "d1", LogStreamRuntime, LogLevelError, "ERROR connection refused", base.Add(time.Minute))
+	insertLog(t, tc, "d2", LogStreamRuntime, LogLevelWarn, "WARN disk 90% full", base.Add(2*time.Minute))
+	insertLog(t, tc, "d2", LogStreamRuntime, LogLevelInfo, "listening on :8080", base.Add(3*time.Minute))
+	insertLog(t, tc, "d3", LogStreamRuntime, LogLevelError, "ERROR connection refused", base.Add(time.Minute))
+
+	search := func(params LogSearchParams) []string {
+		t.Helper()
+		params.ProjectID = "p1"
+		page, err := svc.SearchLogs(ctx, params)
+		require.NoError(t, err)
+		lines := []string{}
+		for _, l := range page.Logs {
+			lines = append(lines, l.Line)
+		}
+		return lines
+	}
+
+	assert.Equal(t, []string{
+		"listening on :8080",
+		"WARN disk 90% full",
+		"ERROR connection refused",
+		"Step 1/3 : FROM golang",
+	}, search(LogSearchParams{}))
+	assert.Equal(t, []string{"ERROR connection refused"}, search(LogSearchParams{Query: "CONNECTION"}))
+	assert.Equal(t, []string{"WARN disk 90% full"}, search(LogSearchParams{Query: "90%"}))
+	assert.Empty(t, search(LogSearchParams{Query: "9_%"}), "wildcards match literally")
+	assert.Equal(t, []string{"WARN disk 90% full", "ERROR connection refused"}, search(LogSearchParams{Level: LogLevelWarn}))
+	assert.Equal(t, []string{"listening on :8080", "WARN disk 90% full"}, search(LogSearchParams{DeploymentID: "d2"}))
+	assert.Equal(t, []string{"Step 1/3 : FROM golang"}, search(LogSearchParams{Stream: LogStreamBuild}))
+	assert.Equal(t, []string{"WARN disk 90% full", "ERROR connection refused"}, search(LogSearchParams{
+		Since: base.Add(time.Minute),
+		Until: base.Add(3 * time.Minute),
+	}))
+
+	page, err := svc.SearchLogs(ctx, LogSearchParams{ProjectID: "p1", Limit: 3})
+	require.NoError(t, err)
+	require.Len(t, page.Logs, 3)
+	require.NotEmpty(t, page.NextCursor)
+
+	page, err = svc.SearchLogs(ctx, LogSearchParams{ProjectID: "p1", Limit: 3, Cursor: page.NextCursor})
+	require.NoError(t, err)
+	require.Len(t, page.Logs, 1)
+	assert.Equal(t, "Step 1/3 : FROM golang", page.Logs[0].Line)
+	assert.Empty(t, page.NextCursor)
+
+	_, err = svc.SearchLogs(ctx, LogSearchParams{ProjectID: "p1", Since: base, Until: base})
+	assert.ErrorIs(t, err, ErrInvalidTimeRange)
+}
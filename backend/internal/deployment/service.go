@@ -0,0 +1,258 @@
+package deployment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/Jesuloba-world/deployease/backend/internal/events"
+	"github.com/Jesuloba-world/deployease/backend/internal/infrastructure/database/postgres/sqlc"
+	"github.com/Jesuloba-world/deployease/backend/internal/jobs"
+	"github.com/Jesuloba-world/deployease/backend/internal/proxy"
+)
+
+var (
+	ErrNotFound  = errors.New("deployment not found")
+	ErrNotActive = errors.New("deployment is not the active deployment of its environment")
+	// ErrRollbackTarget is returned when asked to roll back to a deployment
+	// that never went live, such as a failed or cancelled one.
+	ErrRollbackTarget = errors.New("cannot roll back to deployment")
+	// ErrNoActiveDeployment is returned when asked to redeploy a project
+	// that has nothing live.
+	ErrNoActiveDeployment = errors.New("project has no active deployment")
+)
+
+// TxBeginner is satisfied by *pgxpool.Pool.
+type TxBeginner interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// Service owns deployment status changes. Every transition locks the
+// deployment row so concurrent workers and API calls cannot race each other
+// into an illegal state.
+type Service struct {
+	db      TxBeginner
+	queries *sqlc.Queries
+	queue   *jobs.Queue
+	events  events.Publisher
+	routes  proxy.Invalidator
+}
+
+// NewService creates the deployment service. Status changes and log output
+// are published to publisher, and changes of what an environment serves are
+// announced to routes; either may be nil.
+func NewService(db TxBeginner, queries *sqlc.Queries, queue *jobs.Queue, publisher events.Publisher, routes proxy.Invalidator) *Service {
+	return &Service{
+		db:      db,
+		queries: queries,
+		queue:   queue,
+		events:  publisher,
+		routes:  routes,
+	}
+}
+
+// Transition moves a deployment to the given status, returning a
+// *TransitionError if the move is not allowed from its current status.
+// deployed_at is recorded when the deployment reaches success.
+func (s *Service) Transition(ctx context.Context, deploymentID string, to sqlc.DeploymentStatus) (sqlc.Deployment, error) {
+	var updated sqlc.Deployment
+
+	err := s.withTx(ctx, func(q *sqlc.Queries) error {
+		var err error
+		updated, err = transition(ctx, q, deploymentID, to)
+		return err
+	})
+	if err != nil {
+		return sqlc.Deployment{}, err
+	}
+
+	s.publishStatus(ctx, updated)
+	return updated, nil
+}
+
+// Activate marks a healthy deployment successful and makes it the one the
+// environment of its branch serves, returning the ID of the deployment it
+// replaced, if any. The project row is locked so concurrent cutovers are
+// serialised. It returns ErrPreviewNotFound if the deployment's preview
+// environment has been torn down.
+func (s *Service) Activate(ctx context.Context, deploymentID string) (sqlc.Deployment, string, error) {
+	var (
+		updated  sqlc.Deployment
+		previous string
+	)
+
+	err := s.withTx(ctx, func(q *sqlc.Queries) error {
+		d, err := q.GetDeployment(ctx, deploymentID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrNotFound
+			}
+			return fmt.Errorf("failed to get deployment: %w", err)
+		}
+
+		env, err := lockEnvironment(ctx, q, d.ProjectID, d.Branch)
+		if err != nil {
+			return err
+		}
+
+		updated, err = transition(ctx, q, deploymentID, sqlc.DeploymentStatusSuccess)
+		if err != nil {
+			return err
+		}
+		if env.activeDeploymentID() != deploymentID {
+			previous = env.activeDeploymentID()
+		}
+
+		return env.setActiveDeployment(ctx, q, deploymentID)
+	})
+	if err != nil {
+		return sqlc.Deployment{}, "", err
+	}
+
+	s.publishStatus(ctx, updated)
+	s.invalidateRoutes(ctx)
+	return updated, previous, nil
+}
+
+// RollBack marks the active deployment rolled back and hands its
+// environment back to target. It returns ErrNotActive if deploymentID is no
+// longer its environment's active deployment, for example because a newer
+// one replaced it.
+func (s *Service) RollBack(ctx context.Context, deploymentID, targetID string) (sqlc.Deployment, error) {
+	var updated sqlc.Deployment
+
+	err := s.withTx(ctx, func(q *sqlc.Queries) error {
+		d, err := q.GetDeployment(ctx, deploymentID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrNotFound
+			}
+			return fmt.Errorf("failed to get deployment: %w", err)
+		}
+
+		env, err := lockEnvironment(ctx, q, d.ProjectID, d.Branch)
+		if errors.Is(err, ErrPreviewNotFound) {
+			return ErrNotActive
+		}
+		if err != nil {
+			return err
+		}
+		if env.activeDeploymentID() != deploymentID {
+			return ErrNotActive
+		}
+
+		updated, err = transition(ctx, q, deploymentID, sqlc.DeploymentStatusRolledBack)
+		if err != nil {
+			return err
+		}
+
+		return env.setActiveDeployment(ctx, q, targetID)
+	})
+	if err != nil {
+		return sqlc.Deployment{}, err
+	}
+
+	s.publishStatus(ctx, updated)
+	s.invalidateRoutes(ctx)
+	return updated, nil
+}
+
+func (s *Service) Start(ctx context.Context, deploymentID string) (sqlc.Deployment, error) {
+	return s.Transition(ctx, deploymentID, sqlc.DeploymentStatusInProgress)
+}
+
+func (s *Service) Succeed(ctx context.Context, deploymentID string) (sqlc.Deployment, error) {
+	return s.Transition(ctx, deploymentID, sqlc.DeploymentStatusSuccess)
+}
+
+func (s *Service) Fail(ctx context.Context, deploymentID string) (sqlc.Deployment, error) {
+	return s.Transition(ctx, deploymentID, sqlc.DeploymentStatusFailed)
+}
+
+func (s *Service) Cancel(ctx context.Context, deploymentID string) (sqlc.Deployment, error) {
+	return s.Transition(ctx, deploymentID, sqlc.DeploymentStatusCancelled)
+}
+
+// transition locks the deployment row inside q's transaction and moves it to
+// the given status.
+func transition(ctx context.Context, q *sqlc.Queries, deploymentID string, to sqlc.DeploymentStatus) (sqlc.Deployment, error) {
+	current, err := q.GetDeploymentForUpdate(ctx, deploymentID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return sqlc.Deployment{}, ErrNotFound
+		}
+		return sqlc.Deployment{}, fmt.Errorf("failed to lock deployment: %w", err)
+	}
+
+	if !CanTransition(current.Status, to) {
+		return sqlc.Deployment{}, &TransitionError{
+			DeploymentID: deploymentID,
+			From:         current.Status,
+			To:           to,
+		}
+	}
+
+	updated, err := q.UpdateDeploymentStatus(ctx, sqlc.UpdateDeploymentStatusParams{
+		Status: to,
+		ID:     deploymentID,
+	})
+	if err != nil {
+		return sqlc.Deployment{}, fmt.Errorf("failed to update deployment status: %w", err)
+	}
+
+	return updated, nil
+}
+
+// publishStatus announces a deployment's new status. Failing to do so is not
+// worth failing the change over; clients can always re-fetch.
+func (s *Service) publishStatus(ctx context.Context, d sqlc.Deployment) {
+	if s.events == nil {
+		return
+	}
+
+	err := s.events.Publish(ctx, events.Event{
+		Type: events.TypeDeploymentStatus,
+		Data: events.EventData{
+			ProjectID:    d.ProjectID,
+			DeploymentID: d.ID,
+			Status:       string(d.Status),
+			Timestamp:    d.UpdatedAt.Time,
+		},
+	})
+	if err != nil {
+		log.Printf("Failed to publish status of deployment %s: %v", d.ID, err)
+	}
+}
+
+// invalidateRoutes tells the edge proxies an environment now serves another
+// deployment, or nothing. If that fails they notice on their next periodic
+// refresh.
+func (s *Service) invalidateRoutes(ctx context.Context) {
+	if s.routes == nil {
+		return
+	}
+	if err := s.routes.Invalidate(ctx); err != nil {
+		log.Printf("Failed to invalidate proxy routes: %v", err)
+	}
+}
+
+func (s *Service) withTx(ctx context.Context, fn func(q *sqlc.Queries) error) error {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := fn(s.queries.WithTx(tx)); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,130 @@
+package deployment
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
+
+	database "github.com/Jesuloba-world/deployease/backend/internal/infrastructure/database/postgres"
+	"github.com/Jesuloba-world/deployease/backend/internal/infrastructure/database/postgres/sqlc"
+	"github.com/Jesuloba-world/deployease/backend/internal/jobs"
+)
+
+func setupService(t *testing.T) (*Service, *database.TestContainer) {
+	t.Helper()
+	testcontainers.SkipIfProviderIsNotHealthy(t)
+
+	tc := database.SetupTestContainer(t)
+	t.Cleanup(func() { tc.Cleanup(t) })
+	tc.ApplyMigrations(t, "../../sql/migrations")
+
+	ctx := context.Background()
+	_, err := tc.Pool.Exec(ctx, `INSERT INTO users (id, username, email, password_hash) VALUES ('u1', 'user', 'user@example.com', 'x')`)
+	require.NoError(t, err)
+	_, err = tc.Pool.Exec(ctx, `INSERT INTO projects (id, name, repository_url, user_id) VALUES ('p1', 'app', 'https://github.com/user/repo.git', 'u1')`)
+	require.NoError(t, err)
+
+	queries := sqlc.New(tc.Pool)
+	return NewService(tc.Pool, queries, jobs.NewQueue(queries, 3), nil, nil), tc
+}
+
+func insertDeployment(t *testing.T, tc *database.TestContainer, id string) {
+	t.Helper()
+	_, err := tc.Pool.Exec(context.Background(), `INSERT INTO deployments (id, project_id, commit_hash, branch) VALUES ($1, 'p1', 'abc123', 'main')`, id)
+	require.NoError(t, err)
+}
+
+func TestServiceLifecycle(t *testing.T) {
+	ctx := context.Background()
+	svc, tc := setupService(t)
+	insertDeployment(t, tc, "d1")
+
+	d, err := svc.Start(ctx, "d1")
+	require.NoError(t, err)
+	assert.Equal(t, sqlc.DeploymentStatusInProgress, d.Status)
+	assert.False(t, d.DeployedAt.Valid)
+
+	d, err = svc.Succeed(ctx, "d1")
+	require.NoError(t, err)
+	assert.Equal(t, sqlc.DeploymentStatusSuccess, d.Status)
+	assert.True(t, d.DeployedAt.Valid)
+
+	_, err = svc.Cancel(ctx, "d1")
+	assert.ErrorIs(t, err, ErrInvalidTransition)
+
+	_, err = svc.Start(ctx, "missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestServiceConcurrentTransitions(t *testing.T) {
+	ctx := context.Background()
+	svc, tc := setupService(t)
+	insertDeployment(t, tc, "d2")
+
+	results := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			_, err := svc.Start(ctx, "d2")
+			results <- err
+		}()
+	}
+
+	var succeeded, rejected int
+	for i := 0; i < 2; i++ {
+		if err := <-results; err == nil {
+			succeeded++
+		} else {
+			assert.ErrorIs(t, err, ErrInvalidTransition)
+			rejected++
+		}
+	}
+
+	assert.Equal(t, 1, succeeded)
+	assert.Equal(t, 1, rejected)
+}
+
+func TestServiceCreateAndList(t *testing.T) {
+	ctx := context.Background()
+	svc, tc := setupService(t)
+
+	var created []sqlc.Deployment
+	for i := 0; i < 3; i++ {
+		d, err := svc.Create(ctx, "p1", "ABC1234")
+		require.NoError(t, err)
+		assert.Equal(t, sqlc.DeploymentStatusPending, d.Status)
+		assert.Equal(t, "abc1234", d.CommitHash.String)
+		created = append(created, d)
+	}
+
+	var queued int
+	err := tc.Pool.QueryRow(ctx, `SELECT count(*) FROM jobs WHERE kind = $1`, RunJobKind).Scan(&queued)
+	require.NoError(t, err)
+	assert.Equal(t, 3, queued)
+
+	_, err = svc.Cancel(ctx, created[0].ID)
+	require.NoError(t, err)
+
+	page, err := svc.List(ctx, ListParams{ProjectID: "p1", Limit: 2})
+	require.NoError(t, err)
+	assert.Len(t, page.Deployments, 2)
+	assert.NotEmpty(t, page.NextCursor)
+
+	page, err = svc.List(ctx, ListParams{ProjectID: "p1", Cursor: page.NextCursor, Limit: 2})
+	require.NoError(t, err)
+	assert.Len(t, page.Deployments, 1)
+	assert.Empty(t, page.NextCursor)
+
+	page, err = svc.List(ctx, ListParams{
+		ProjectID: "p1",
+		Statuses:  []sqlc.DeploymentStatus{sqlc.DeploymentStatusCancelled},
+	})
+	require.NoError(t, err)
+	require.Len(t, page.Deployments, 1)
+	assert.Equal(t, created[0].ID, page.Deployments[0].ID)
+
+	_, err = svc.Get(ctx, "other-project", created[1].ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
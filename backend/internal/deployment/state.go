@@ -0,0 +1,58 @@
+package deployment
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/Jesuloba-world/deployease/backend/internal/infrastructure/database/postgres/sqlc"
+)
+
+var ErrInvalidTransition = errors.New("invalid deployment status transition")
+
+// transitions lists the statuses each status may move to. Statuses without
+// an entry are terminal.
+var transitions = map[sqlc.DeploymentStatus][]sqlc.DeploymentStatus{
+	sqlc.DeploymentStatusPending: {
+		sqlc.DeploymentStatusInProgress,
+		sqlc.DeploymentStatusCancelled,
+	},
+	sqlc.DeploymentStatusInProgress: {
+		sqlc.DeploymentStatusSuccess,
+		sqlc.DeploymentStatusFailed,
+		sqlc.DeploymentStatusCancelled,
+	},
+	// A live deployment that fails its health checks is replaced by the
+	// one it superseded.
+	sqlc.DeploymentStatusSuccess: {
+		sqlc.DeploymentStatusRolledBack,
+	},
+}
+
+// TransitionError reports an attempt to move a deployment between two
+// statuses that are not connected in the state machine.
+type TransitionError struct {
+	DeploymentID string
+	From         sqlc.DeploymentStatus
+	To           sqlc.DeploymentStatus
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("deployment %s cannot move from %s to %s", e.DeploymentID, e.From, e.To)
+}
+
+func (e *TransitionError) Is(target error) bool {
+	return target == ErrInvalidTransition
+}
+
+func CanTransition(from, to sqlc.DeploymentStatus) bool {
+	for _, allowed := range transitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+func IsTerminal(status sqlc.DeploymentStatus) bool {
+	return len(transitions[status]) == 0
+}
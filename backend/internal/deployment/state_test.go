@@ -0,0 +1,56 @@
+package deployment
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Jesuloba-world/deployease/backend/internal/infrastructure/database/postgres/sqlc"
+)
+
+func TestCanTransition(t *testing.T) {
+	tests := []struct {
+		from    sqlc.DeploymentStatus
+		to      sqlc.DeploymentStatus
+		allowed bool
+	}{
+		{sqlc.DeploymentStatusPending, sqlc.DeploymentStatusInProgress, true},
+		{sqlc.DeploymentStatusPending, sqlc.DeploymentStatusCancelled, true},
+		{sqlc.DeploymentStatusPending, sqlc.DeploymentStatusSuccess, false},
+		{sqlc.DeploymentStatusPending, sqlc.DeploymentStatusFailed, false},
+		{sqlc.DeploymentStatusInProgress, sqlc.DeploymentStatusSuccess, true},
+		{sqlc.DeploymentStatusInProgress, sqlc.DeploymentStatusFailed, true},
+		{sqlc.DeploymentStatusInProgress, sqlc.DeploymentStatusCancelled, true},
+		{sqlc.DeploymentStatusInProgress, sqlc.DeploymentStatusPending, false},
+		{sqlc.DeploymentStatusSuccess, sqlc.DeploymentStatusFailed, false},
+		{sqlc.DeploymentStatusSuccess, sqlc.DeploymentStatusRolledBack, true},
+		{sqlc.DeploymentStatusRolledBack, sqlc.DeploymentStatusSuccess, false},
+		{sqlc.DeploymentStatusFailed, sqlc.DeploymentStatusInProgress, false},
+		{sqlc.DeploymentStatusCancelled, sqlc.DeploymentStatusInProgress, false},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.allowed, CanTransition(tt.from, tt.to), "%s -> %s", tt.from, tt.to)
+	}
+}
+
+func TestIsTerminal(t *testing.T) {
+	assert.False(t, IsTerminal(sqlc.DeploymentStatusPending))
+	assert.False(t, IsTerminal(sqlc.DeploymentStatusInProgress))
+	assert.False(t, IsTerminal(sqlc.DeploymentStatusSuccess))
+	assert.True(t, IsTerminal(sqlc.DeploymentStatusRolledBack))
+	assert.True(t, IsTerminal(sqlc.DeploymentStatusFailed))
+	assert.True(t, IsTerminal(sqlc.DeploymentStatusCancelled))
+}
+
+func TestTransitionErrorIs(t *testing.T) {
+	var err error = &TransitionError{
+		DeploymentID: "dep-1",
+		From:         sqlc.DeploymentStatusSuccess,
+		To:           sqlc.DeploymentStatusInProgress,
+	}
+
+	assert.True(t, errors.Is(err, ErrInvalidTransition))
+	assert.Equal(t, "deployment dep-1 cannot move from success to in_progress", err.Error())
+}
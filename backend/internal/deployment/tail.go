@@ -0,0 +1,291 @@
+package deployment
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/Jesuloba-world/deployease/backend/internal/container"
+	"github.com/Jesuloba-world/deployease/backend/internal/infrastructure/database/postgres/sqlc"
+)
+
+// LogStreamRuntime is the output of a deployment's running container. It is
+// read from the container runtime rather than stored.
+const LogStreamRuntime = "runtime"
+
+const (
+	tailPageSize     = 500
+	tailPollInterval = time.Second
+	maxLogLineSize   = 1 << 20
+)
+
+var ErrInvalidLogCursor = errors.New("invalid log cursor")
+
+// LogEntry is a line of deployment output.
+type LogEntry struct {
+	// Cursor resumes a stream right after this entry.
+	Cursor LogCursor
+	Stream string
+	Level  string
+	Line   string
+	Time   time.Time
+}
+
+// LogCursor marks a position in a deployment's output: after a stored line,
+// or, once those are exhausted, after a point in its container's output.
+type LogCursor struct {
+	AfterID      int64
+	RuntimeSince time.Time
+}
+
+const runtimeCursorPrefix = "r"
+
+func (c LogCursor) String() string {
+	if !c.RuntimeSince.IsZero() {
+		return runtimeCursorPrefix + strconv.FormatInt(c.RuntimeSince.UnixNano(), 10)
+	}
+	return strconv.FormatInt(c.AfterID, 10)
+}
+
+func ParseLogCursor(s string) (LogCursor, error) {
+	if s == "" {
+		return LogCursor{}, nil
+	}
+
+	if nanos, ok := strings.CutPrefix(s, runtimeCursorPrefix); ok {
+		n, err := strconv.ParseInt(nanos, 10, 64)
+		if err != nil || n <= 0 {
+			return LogCursor{}, ErrInvalidLogCursor
+		}
+		return LogCursor{RuntimeSince: time.Unix(0, n).UTC()}, nil
+	}
+
+	id, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || id < 0 {
+		return LogCursor{}, ErrInvalidLogCursor
+	}
+	return LogCursor{AfterID: id}, nil
+}
+
+// LogTailer reads a deployment's build and rollout logs followed by the
+// output of its container.
+type LogTailer struct {
+	queries      sqlc.Querier
+	runtime      container.Runtime
+	pollInterval time.Duration
+}
+
+func NewLogTailer(queries sqlc.Querier, runtime container.Runtime) *LogTailer {
+	return &LogTailer{
+		queries:      queries,
+		runtime:      runtime,
+		pollInterval: tailPollInterval,
+	}
+}
+
+// Tail passes the deployment's output after cursor to emit, stopping at the
+// first error emit returns. Without follow it returns once the output so far
+// has been sent. With follow it waits for the deployment to finish and, if it
+// went live, keeps sending its container's output until the container stops
+// or ctx is done.
+func (t *LogTailer) Tail(ctx context.Context, deploymentID string, cursor LogCursor, follow bool, emit func(LogEntry) error) error {
+	var since time.Time
+	var err error
+	if cursor.RuntimeSince.IsZero() {
+		since, err = t.tailStored(ctx, deploymentID, cursor.AfterID, follow, emit)
+	} else {
+		since, err = t.tailCollected(ctx, deploymentID, cursor.RuntimeSince, emit)
+	}
+	if err != nil {
+		return err
+	}
+
+	d, err := t.getDeployment(ctx, deploymentID)
+	if err != nil {
+		return err
+	}
+	if !d.ContainerID.Valid {
+		return nil
+	}
+
+	return t.tailRuntime(ctx, d.ContainerID.String, since, follow, emit)
+}
+
+// tailStored sends the stored log after afterID, returning the time of the
+// last collected container line it covers so the container's own output can
+// pick up from there.
+func (t *LogTailer) tailStored(ctx context.Context, deploymentID string, afterID int64, follow bool, emit func(LogEntry) error) (time.Time, error) {
+	var since time.Time
+	for {
+		// Read the status and the last collected line before the logs so
+		// lines stored just before are not missed.
+		d, err := t.getDeployment(ctx, deploymentID)
+		if err != nil {
+			return time.Time{}, err
+		}
+		finished := d.Status != sqlc.DeploymentStatusPending && d.Status != sqlc.DeploymentStatusInProgress
+
+		latest, err := t.queries.GetLatestDeploymentLogTime(ctx, sqlc.GetLatestDeploymentLogTimeParams{
+			DeploymentID: deploymentID,
+			Stream:       LogStreamRuntime,
+		})
+		if err != nil {
+			return time.Time{}, fmt.Errorf("failed to get latest runtime log: %w", err)
+		}
+		if latest.Valid && latest.Time.After(since) {
+			since = latest.Time
+		}
+
+		for {
+			logs, err := t.queries.ListDeploymentLogs(ctx, sqlc.ListDeploymentLogsParams{
+				DeploymentID: deploymentID,
+				AfterID:      afterID,
+				PageLimit:    tailPageSize,
+			})
+			if err != nil {
+				return time.Time{}, fmt.Errorf("failed to list deployment logs: %w", err)
+			}
+
+			for _, l := range logs {
+				afterID = l.ID
+				if l.Stream == LogStreamRuntime && l.CreatedAt.Time.After(since) {
+					since = l.CreatedAt.Time
+				}
+				err := emit(LogEntry{
+					Cursor: LogCursor{AfterID: l.ID},
+					Stream: l.Stream,
+					Level:  l.Level,
+					Line:   l.Line,
+					Time:   l.CreatedAt.Time,
+				})
+				if err != nil {
+					return time.Time{}, err
+				}
+			}
+			if len(logs) < tailPageSize {
+				break
+			}
+		}
+
+		if finished || !follow {
+			return since, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return time.Time{}, ctx.Err()
+		case <-time.After(t.pollInterval):
+		}
+	}
+}
+
+// tailCollected sends the container output collected after since, which
+// covers a container that has gone away since the stream was interrupted.
+func (t *LogTailer) tailCollected(ctx context.Context, deploymentID string, since time.Time, emit func(LogEntry) error) (time.Time, error) {
+	for {
+		logs, err := t.queries.ListDeploymentLogsSince(ctx, sqlc.ListDeploymentLogsSinceParams{
+			DeploymentID: deploymentID,
+			Stream:       LogStreamRuntime,
+			Since:        pgtype.Timestamptz{Time: since, Valid: true},
+			PageLimit:    tailPageSize,
+		})
+		if err != nil {
+			return time.Time{}, fmt.Errorf("failed to list deployment logs: %w", err)
+		}
+
+		for _, l := range logs {
+			since = l.CreatedAt.Time
+			err := emit(LogEntry{
+				Cursor: LogCursor{RuntimeSince: since},
+				Stream: l.Stream,
+				Level:  l.Level,
+				Line:   l.Line,
+				Time:   since,
+			})
+			if err != nil {
+				return time.Time{}, err
+			}
+		}
+		if len(logs) < tailPageSize {
+			return since, nil
+		}
+	}
+}
+
+func (t *LogTailer) tailRuntime(ctx context.Context, containerID string, since time.Time, follow bool, emit func(LogEntry) error) error {
+	rc, err := t.runtime.Logs(ctx, containerID, container.LogsOptions{
+		Follow:     follow,
+		Timestamps: true,
+		Since:      since,
+	})
+	if err != nil {
+		if errors.Is(err, container.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+	defer rc.Close()
+
+	err = scanRuntimeLogs(rc, since, func(ts time.Time, line string) error {
+		return emit(LogEntry{
+			Cursor: LogCursor{RuntimeSince: ts},
+			Stream: LogStreamRuntime,
+			Level:  DetectLogLevel(line),
+			Line:   line,
+			Time:   ts,
+		})
+	})
+	if err != nil && ctx.Err() == nil {
+		return err
+	}
+	return ctx.Err()
+}
+
+// scanRuntimeLogs reads timestamped container output, passing each line
+// written after since to fn. Timestamps are truncated to the microsecond
+// precision of stored logs so lines read from the container and from the
+// store compare equal.
+func scanRuntimeLogs(r io.Reader, since time.Time, fn func(ts time.Time, line string) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLogLineSize)
+	for scanner.Scan() {
+		stamp, line, _ := strings.Cut(scanner.Text(), " ")
+		ts, err := time.Parse(time.RFC3339Nano, stamp)
+		if err != nil {
+			continue
+		}
+		ts = ts.Truncate(time.Microsecond)
+		// The runtime's since filter is inclusive.
+		if !ts.After(since) {
+			continue
+		}
+
+		if err := fn(ts, strings.TrimRight(line, "\r")); err != nil {
+			return err
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read container logs: %w", err)
+	}
+	return nil
+}
+
+func (t *LogTailer) getDeployment(ctx context.Context, deploymentID string) (sqlc.Deployment, error) {
+	d, err := t.queries.GetDeployment(ctx, deploymentID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return sqlc.Deployment{}, ErrNotFound
+		}
+		return sqlc.Deployment{}, fmt.Errorf("failed to get deployment: %w", err)
+	}
+	return d, nil
+}
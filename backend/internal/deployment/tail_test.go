@@ -0,0 +1,289 @@
+package deployment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Jesuloba-world/deployease/backend/internal/container"
+	"github.com/Jesuloba-world/deployease/backend/internal/container/containertest"
+	"github.com/Jesuloba-world/deployease/backend/internal/infrastructure/database/postgres/sqlc"
+)
+
+func TestParseLogCursor(t *testing.T) {
+	cursor, err := ParseLogCursor("")
+	require.NoError(t, err)
+	assert.Equal(t, LogCursor{}, cursor)
+
+	cursor, err = ParseLogCursor("42")
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), cursor.AfterID)
+	assert.Equal(t, "42", cursor.String())
+
+	ts := time.Date(2025, 6, 30, 12, 0, 0, 123456789, time.UTC)
+	cursor, err = ParseLogCursor(LogCursor{RuntimeSince: ts}.String())
+	require.NoError(t, err)
+	assert.True(t, ts.Equal(cursor.RuntimeSince))
+
+	for _, s := range []string{"abc", "-1", "r", "rabc", "r-5"} {
+		_, err := ParseLogCursor(s)
+		assert.ErrorIs(t, err, ErrInvalidLogCursor, s)
+	}
+}
+
+// tailQuerier serves a deployment and its stored logs from memory.
+type tailQuerier struct {
+	sqlc.Querier
+
+	mu         sync.Mutex
+	deployment sqlc.Deployment
+	logs       []sqlc.DeploymentLog
+}
+
+func (q *tailQuerier) GetDeployment(ctx context.Context, id string) (sqlc.Deployment, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.deployment, nil
+}
+
+func (q *tailQuerier) ListDeploymentLogs(ctx context.Context, arg sqlc.ListDeploymentLogsParams) ([]sqlc.DeploymentLog, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var logs []sqlc.DeploymentLog
+	for _, l := range q.logs {
+		if l.ID > arg.AfterID && len(logs) < int(arg.PageLimit) {
+			logs = append(logs, l)
+		}
+	}
+	return logs, nil
+}
+
+func (q *tailQuerier) ListDeploymentLogsSince(ctx context.Context, arg sqlc.ListDeploymentLogsSinceParams) ([]sqlc.DeploymentLog, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var logs []sqlc.DeploymentLog
+	for _, l := range q.logs {
+		if l.Stream == arg.Stream && l.CreatedAt.Time.After(arg.Since.Time) && len(logs) < int(arg.PageLimit) {
+			logs = append(logs, l)
+		}
+	}
+	return logs, nil
+}
+
+func (q *tailQuerier) GetLatestDeploymentLogTime(ctx context.Context, arg sqlc.GetLatestDeploymentLogTimeParams) (pgtype.Timestamptz, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var latest pgtype.Timestamptz
+	for _, l := range q.logs {
+		if l.Stream == arg.Stream && (!latest.Valid || l.CreatedAt.Time.After(latest.Time)) {
+			latest = l.CreatedAt
+		}
+	}
+	return latest, nil
+}
+
+func (q *tailQuerier) AppendDeploymentLogs(ctx context.Context, arg sqlc.AppendDeploymentLogsParams) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for i, line := range arg.Lines {
+		q.logs = append(q.logs, sqlc.DeploymentLog{
+			ID:        int64(len(q.logs) + 1),
+			Stream:    arg.Stream,
+			Level:     arg.Levels[i],
+			Line:      line,
+			CreatedAt: arg.CreatedAts[i],
+		})
+	}
+	return nil
+}
+
+func (q *tailQuerier) appendLogs(stream string, lines ...string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, line := range lines {
+		q.logs = append(q.logs, sqlc.DeploymentLog{
+			ID:        int64(len(q.logs) + 1),
+			Stream:    stream,
+			Level:     DetectLogLevel(line),
+			Line:      line,
+			CreatedAt: pgtype.Timestamptz{Time: time.Now(), Valid: true},
+		})
+	}
+}
+
+func (q *tailQuerier) update(fn func(d *sqlc.Deployment)) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	fn(&q.deployment)
+}
+
+func collect(entries *[]LogEntry) func(LogEntry) error {
+	return func(e LogEntry) error {
+		*entries = append(*entries, e)
+		return nil
+	}
+}
+
+func lines(entries []LogEntry) []string {
+	out := make([]string, len(entries))
+	for i, e := range entries {
+		out[i] = e.Stream + ": " + e.Line
+	}
+	return out
+}
+
+func startContainer(t *testing.T, rt *containertest.Runtime) string {
+	t.Helper()
+	id, err := rt.Create(context.Background(), container.Spec{Image: "app:1"})
+	require.NoError(t, err)
+	require.NoError(t, rt.Start(context.Background(), id))
+	return id
+}
+
+func TestTailStoredThenRuntime(t *testing.T) {
+	rt := containertest.NewRuntime()
+	containerID := startContainer(t, rt)
+	rt.WriteLog(containerID, "listening on :8080")
+
+	q := &tailQuerier{deployment: sqlc.Deployment{
+		ID:          "d1",
+		Status:      sqlc.DeploymentStatusSuccess,
+		ContainerID: pgtype.Text{String: containerID, Valid: true},
+	}}
+	q.appendLogs(LogStreamBuild, "step 1", "step 2")
+	q.appendLogs(LogStreamRollout, "healthy")
+	tailer := NewLogTailer(q, rt)
+
+	var entries []LogEntry
+	require.NoError(t, tailer.Tail(context.Background(), "d1", LogCursor{}, false, collect(&entries)))
+	assert.Equal(t, []string{
+		"build: step 1",
+		"build: step 2",
+		"rollout: healthy",
+		"runtime: started app:1",
+		"runtime: listening on :8080",
+	}, lines(entries))
+
+	t.Run("resumes after a stored line", func(t *testing.T) {
+		var resumed []LogEntry
+		require.NoError(t, tailer.Tail(context.Background(), "d1", entries[1].Cursor, false, collect(&resumed)))
+		assert.Equal(t, lines(entries[2:]), lines(resumed))
+	})
+
+	t.Run("resumes after a runtime line", func(t *testing.T) {
+		cursor, err := ParseLogCursor(entries[3].Cursor.String())
+		require.NoError(t, err)
+
+		var resumed []LogEntry
+		require.NoError(t, tailer.Tail(context.Background(), "d1", cursor, false, collect(&resumed)))
+		assert.Equal(t, []string{"runtime: listening on :8080"}, lines(resumed))
+	})
+}
+
+func TestTailCollectedRuntimeLogs(t *testing.T) {
+	rt := containertest.NewRuntime()
+	containerID := startContainer(t, rt)
+	rt.WriteLog(containerID, "first request")
+
+	q := &tailQuerier{deployment: sqlc.Deployment{
+		ID:          "d1",
+		Status:      sqlc.DeploymentStatusSuccess,
+		ContainerID: pgtype.Text{String: containerID, Valid: true},
+	}}
+	q.appendLogs(LogStreamBuild, "built")
+	tailer := NewLogTailer(q, rt)
+
+	// Collect what the container has written so far, as the collector job
+	// does, then let it write more.
+	var collected []LogEntry
+	require.NoError(t, tailer.Tail(context.Background(), "d1", LogCursor{}, false, collect(&collected)))
+	arg := sqlc.AppendDeploymentLogsParams{DeploymentID: "d1", Stream: LogStreamRuntime}
+	for _, e := range collected[1:] {
+		arg.Levels = append(arg.Levels, e.Level)
+		arg.Lines = append(arg.Lines, e.Line)
+		arg.CreatedAts = append(arg.CreatedAts, pgtype.Timestamptz{Time: e.Time, Valid: true})
+	}
+	require.NoError(t, q.AppendDeploymentLogs(context.Background(), arg))
+	rt.WriteLog(containerID, "ERROR second request failed")
+
+	var entries []LogEntry
+	require.NoError(t, tailer.Tail(context.Background(), "d1", LogCursor{}, false, collect(&entries)))
+	assert.Equal(t, []string{
+		"build: built",
+		"runtime: started app:1",
+		"runtime: first request",
+		"runtime: ERROR second request failed",
+	}, lines(entries), "collected lines are not repeated from the container")
+	assert.Equal(t, LogLevelError, entries[3].Level)
+
+	t.Run("after the container is gone", func(t *testing.T) {
+		require.NoError(t, rt.Remove(context.Background(), containerID))
+
+		var resumed []LogEntry
+		require.NoError(t, tailer.Tail(context.Background(), "d1", entries[1].Cursor, false, collect(&resumed)))
+		assert.Equal(t, []string{"runtime: first request"}, lines(resumed))
+
+		resumed = nil
+		cursor := LogCursor{RuntimeSince: entries[1].Time}
+		require.NoError(t, tailer.Tail(context.Background(), "d1", cursor, false, collect(&resumed)))
+		assert.Equal(t, []string{"runtime: first request"}, lines(resumed))
+	})
+}
+
+func TestTailPages(t *testing.T) {
+	q := &tailQuerier{deployment: sqlc.Deployment{ID: "d1", Status: sqlc.DeploymentStatusFailed}}
+	for i := 0; i < tailPageSize+10; i++ {
+		q.appendLogs(LogStreamBuild, fmt.Sprintf("line %d", i))
+	}
+
+	var entries []LogEntry
+	require.NoError(t, NewLogTailer(q, containertest.NewRuntime()).Tail(context.Background(), "d1", LogCursor{}, false, collect(&entries)))
+	require.Len(t, entries, tailPageSize+10)
+	assert.Equal(t, int64(tailPageSize+10), entries[len(entries)-1].Cursor.AfterID)
+}
+
+func TestTailFollowsUntilFinished(t *testing.T) {
+	q := &tailQuerier{deployment: sqlc.Deployment{ID: "d1", Status: sqlc.DeploymentStatusInProgress}}
+	q.appendLogs(LogStreamBuild, "cloning")
+	tailer := NewLogTailer(q, containertest.NewRuntime())
+	tailer.pollInterval = time.Millisecond
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var entries []LogEntry
+	err := tailer.Tail(ctx, "d1", LogCursor{}, true, func(e LogEntry) error {
+		entries = append(entries, e)
+		if e.Line == "cloning" {
+			q.appendLogs(LogStreamBuild, "building")
+			q.update(func(d *sqlc.Deployment) { d.Status = sqlc.DeploymentStatusFailed })
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"build: cloning", "build: building"}, lines(entries))
+}
+
+func TestTailStopsOnEmitError(t *testing.T) {
+	q := &tailQuerier{deployment: sqlc.Deployment{ID: "d1", Status: sqlc.DeploymentStatusSuccess}}
+	q.appendLogs(LogStreamBuild, "one", "two")
+
+	errGone := errors.New("client went away")
+	calls := 0
+	err := NewLogTailer(q, containertest.NewRuntime()).Tail(context.Background(), "d1", LogCursor{}, false, func(LogEntry) error {
+		calls++
+		return errGone
+	})
+	assert.ErrorIs(t, err, errGone)
+	assert.Equal(t, 1, calls)
+}
@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"errors"
+	"net"
+	"regexp"
+	"strings"
+)
+
+var ErrInvalidName = errors.New("domain must be a fully qualified host name such as app.example.com")
+
+var labelPattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+// NormalizeName lowercases name and strips a trailing dot, returning
+// ErrInvalidName unless the result is a host name of at least two labels.
+// Wildcards and IP addresses are rejected.
+func NormalizeName(name string) (string, error) {
+	name = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(name)), ".")
+	if len(name) > 253 || net.ParseIP(name) != nil {
+		return "", ErrInvalidName
+	}
+
+	labels := strings.Split(name, ".")
+	if len(labels) < 2 {
+		return "", ErrInvalidName
+	}
+	for _, label := range labels {
+		if !labelPattern.MatchString(label) {
+			return "", ErrInvalidName
+		}
+	}
+	if strings.Trim(labels[len(labels)-1], "0123456789") == "" {
+		return "", ErrInvalidName
+	}
+
+	return name, nil
+}
@@ -0,0 +1,46 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeName(t *testing.T) {
+	valid := map[string]string{
+		"example.com":                    "example.com",
+		"App.Example.COM.":               "app.example.com",
+		" shop.example.co.uk ":           "shop.example.co.uk",
+		"xn--bcher-kva.example":          "xn--bcher-kva.example",
+		"a-b.c0.example.io":              "a-b.c0.example.io",
+		strings.Repeat("a", 63) + ".com": strings.Repeat("a", 63) + ".com",
+	}
+	for in, want := range valid {
+		got, err := NormalizeName(in)
+		require.NoError(t, err, in)
+		assert.Equal(t, want, got)
+	}
+
+	invalid := []string{
+		"",
+		"localhost",
+		"*.example.com",
+		"-app.example.com",
+		"app-.example.com",
+		"app..example.com",
+		"app_1.example.com",
+		"https://example.com",
+		"example.com/path",
+		"192.168.0.1",
+		"::1",
+		"example.123",
+		strings.Repeat("a", 64) + ".com",
+		strings.Repeat("a.", 127) + "com",
+	}
+	for _, in := range invalid {
+		_, err := NormalizeName(in)
+		assert.ErrorIs(t, err, ErrInvalidName, in)
+	}
+}
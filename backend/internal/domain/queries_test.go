@@ -0,0 +1,126 @@
+package domain
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
+
+	database "github.com/Jesuloba-world/deployease/backend/internal/infrastructure/database/postgres"
+	"github.com/Jesuloba-world/deployease/backend/internal/infrastructure/database/postgres/sqlc"
+)
+
+// setupQueries runs the domain queries against a migrated database with
+// the projects p1 and p2.
+func setupQueries(t *testing.T) (*sqlc.Queries, *database.TestContainer) {
+	t.Helper()
+	testcontainers.SkipIfProviderIsNotHealthy(t)
+
+	tc := database.SetupTestContainer(t)
+	t.Cleanup(func() { tc.Cleanup(t) })
+	tc.ApplyMigrations(t, "../../sql/migrations")
+
+	ctx := context.Background()
+	_, err := tc.Pool.Exec(ctx, `INSERT INTO users (id, username, email, password_hash) VALUES ('u1', 'user', 'user@example.com', 'x')`)
+	require.NoError(t, err)
+	_, err = tc.Pool.Exec(ctx, `INSERT INTO projects (id, name, repository_url, user_id) VALUES
+		('p1', 'app', 'https://github.com/user/app.git', 'u1'),
+		('p2', 'other', 'https://github.com/user/other.git', 'u1')`)
+	require.NoError(t, err)
+
+	return sqlc.New(tc.Pool), tc
+}
+
+func createDomain(t *testing.T, q *sqlc.Queries, id, projectID, name string, nextCheckAt time.Time) sqlc.Domain {
+	t.Helper()
+	d, err := q.CreateDomain(context.Background(), sqlc.CreateDomainParams{
+		ID:                id,
+		ProjectID:         projectID,
+		Name:              name,
+		VerificationToken: "token-" + id,
+		NextCheckAt:       timestamp(nextCheckAt),
+	})
+	require.NoError(t, err)
+	return d
+}
+
+func TestClaimDueDomainChecksSkipsLockedDomains(t *testing.T) {
+	ctx := context.Background()
+	q, tc := setupQueries(t)
+
+	now := time.Now()
+	createDomain(t, q, "d1", "p1", "a.example.com", now.Add(-2*time.Minute))
+	createDomain(t, q, "d2", "p1", "b.example.com", now.Add(-time.Minute))
+	createDomain(t, q, "d3", "p1", "c.example.com", now.Add(time.Hour))
+
+	claim := func(q *sqlc.Queries) []string {
+		t.Helper()
+		ids, err := q.ClaimDueDomainChecks(ctx, sqlc.ClaimDueDomainChecksParams{
+			LeaseUntil: timestamp(now.Add(10 * time.Minute)),
+			Now:        timestamp(now),
+			BatchSize:  5,
+		})
+		require.NoError(t, err)
+		return ids
+	}
+
+	// A scheduler that has claimed d1 but not committed yet holds its row;
+	// a second one skips it rather than waiting or claiming it too.
+	tx, err := tc.Pool.Begin(ctx)
+	require.NoError(t, err)
+	defer tx.Rollback(ctx)
+	held, err := q.WithTx(tx).ClaimDueDomainChecks(ctx, sqlc.ClaimDueDomainChecksParams{
+		LeaseUntil: timestamp(now.Add(10 * time.Minute)),
+		Now:        timestamp(now),
+		BatchSize:  1,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"d1"}, held, "the most overdue check is claimed first")
+
+	assert.Equal(t, []string{"d2"}, claim(q), "locked and not yet due domains are skipped")
+	require.NoError(t, tx.Commit(ctx))
+
+	assert.Empty(t, claim(q), "claimed checks are leased until they come due again")
+
+	d, err := q.GetDomain(ctx, "d1")
+	require.NoError(t, err)
+	assert.WithinDuration(t, now.Add(10*time.Minute), d.NextCheckAt.Time, time.Millisecond)
+}
+
+func TestDomainNameIsUniqueOnceVerified(t *testing.T) {
+	ctx := context.Background()
+	q, _ := setupQueries(t)
+
+	now := time.Now()
+	first := createDomain(t, q, "d1", "p1", "app.example.com", now)
+	second := createDomain(t, q, "d2", "p2", "app.example.com", now)
+
+	_, err := q.CreateDomain(ctx, sqlc.CreateDomainParams{
+		ID:                "d3",
+		ProjectID:         "p1",
+		Name:              "app.example.com",
+		VerificationToken: "token-d3",
+	})
+	assert.True(t, isUniqueViolation(err), "a project claims a name once, got %v", err)
+
+	verify := func(d sqlc.Domain, status sqlc.DomainStatus) error {
+		_, err := q.UpdateDomainVerification(ctx, sqlc.UpdateDomainVerificationParams{
+			ID:                    d.ID,
+			Status:                status,
+			VerificationStartedAt: d.VerificationStartedAt,
+			VerifiedAt:            timestamp(now),
+			LastCheckedAt:         timestamp(now),
+		})
+		return err
+	}
+
+	require.NoError(t, verify(first, sqlc.DomainStatusVerified))
+	err = verify(second, sqlc.DomainStatusVerified)
+	assert.True(t, isUniqueViolation(err), "only one project has a name verified, got %v", err)
+
+	require.NoError(t, verify(first, sqlc.DomainStatusFailed))
+	require.NoError(t, verify(second, sqlc.DomainStatusVerified), "a failed claim frees the name")
+}
@@ -0,0 +1,39 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"net"
+)
+
+const (
+	// recordPrefix is prepended to a domain to name the TXT record that
+	// proves control of it, so the record does not clash with the
+	// domain's own TXT records.
+	recordPrefix = "_deployease-challenge."
+	// valuePrefix is prepended to the verification token in the record.
+	valuePrefix = "deployease-verification="
+)
+
+// Resolver looks up DNS TXT records. *net.Resolver implements it.
+type Resolver interface {
+	LookupTXT(ctx context.Context, name string) ([]string, error)
+}
+
+// RecordName is the name of the TXT record that verifies domain.
+func RecordName(domain string) string {
+	return recordPrefix + domain
+}
+
+// RecordValue is the content of the TXT record that verifies the domain
+// with the given token.
+func RecordValue(token string) string {
+	return valuePrefix + token
+}
+
+// isNotFound reports whether err is the resolver saying there are no
+// records, as opposed to failing to find out.
+func isNotFound(err error) bool {
+	var dnsErr *net.DNSError
+	return errors.As(err, &dnsErr) && dnsErr.IsNotFound
+}
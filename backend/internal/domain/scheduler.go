@@ -0,0 +1,109 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/Jesuloba-world/deployease/backend/internal/infrastructure/database/postgres/sqlc"
+	"github.com/Jesuloba-world/deployease/backend/internal/jobs"
+)
+
+// CheckJobKind checks a domain's verification record.
+const CheckJobKind = "domain.check"
+
+// checkBatchSize caps how many checks are queued per tick.
+const checkBatchSize = 100
+
+type CheckPayload struct {
+	DomainID string `json:"domain_id"`
+}
+
+// HandleCheck runs a queued check. Domains deleted in the meantime are
+// skipped.
+func (s *Service) HandleCheck(ctx context.Context, job sqlc.Job) error {
+	var payload CheckPayload
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return jobs.Permanent(fmt.Errorf("invalid domain check payload: %w", err))
+	}
+
+	d, err := s.queries.GetDomain(ctx, payload.DomainID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		return fmt.Errorf("failed to get domain: %w", err)
+	}
+
+	if _, err := s.check(ctx, d); err != nil && !errors.Is(err, ErrNotFound) {
+		return err
+	}
+	return nil
+}
+
+// Scheduler queues the checks of domains that have come due.
+type Scheduler struct {
+	service *Service
+	queue   *jobs.Queue
+}
+
+func NewScheduler(service *Service, queue *jobs.Queue) *Scheduler {
+	return &Scheduler{
+		service: service,
+		queue:   queue,
+	}
+}
+
+// Run queues due checks right away and then every check interval until ctx
+// is done.
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.service.cfg.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.Schedule(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("Failed to schedule domain checks: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Schedule queues a check for every domain that is due, returning how many
+// were queued. Claimed domains are not due again for a retry interval, so
+// a check whose job is lost is still retried.
+func (s *Scheduler) Schedule(ctx context.Context) (int, error) {
+	now := s.service.now()
+	queued := 0
+
+	for {
+		ids, err := s.service.queries.ClaimDueDomainChecks(ctx, sqlc.ClaimDueDomainChecksParams{
+			LeaseUntil: timestamp(now.Add(s.service.cfg.RetryInterval)),
+			Now:        timestamp(now),
+			BatchSize:  checkBatchSize,
+		})
+		if err != nil {
+			return queued, fmt.Errorf("failed to claim domain checks: %w", err)
+		}
+
+		for _, id := range ids {
+			if _, err := s.queue.Enqueue(ctx, CheckJobKind, CheckPayload{DomainID: id}); err != nil {
+				return queued, err
+			}
+			queued++
+		}
+
+		if len(ids) < checkBatchSize {
+			return queued, nil
+		}
+	}
+}
@@ -0,0 +1,249 @@
+// Package domain attaches custom domains to projects. A project proves it
+// controls a domain by publishing a TXT record with the domain's
+// verification token; pending domains are checked until the record shows
+// up or verification times out, and verified ones are checked again
+// periodically in case it is taken down.
+package domain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	gonanoid "github.com/matoous/go-nanoid/v2"
+
+	"github.com/Jesuloba-world/deployease/backend/internal/config"
+	"github.com/Jesuloba-world/deployease/backend/internal/infrastructure/database/postgres/sqlc"
+	"github.com/Jesuloba-world/deployease/backend/internal/proxy"
+)
+
+var (
+	ErrNotFound = errors.New("domain not found")
+	ErrTaken    = errors.New("domain is already attached to the project")
+)
+
+const (
+	pgUniqueViolation = "23505"
+
+	tokenAlphabet = "0123456789abcdefghijklmnopqrstuvwxyz"
+	tokenLength   = 32
+)
+
+type Service struct {
+	queries  sqlc.Querier
+	resolver Resolver
+	cfg      config.DomainsConfig
+	routes   proxy.Invalidator
+	now      func() time.Time
+}
+
+// NewService creates the domain service. Domains starting or ceasing to be
+// verified are announced to routes, which may be nil.
+func NewService(queries sqlc.Querier, resolver Resolver, cfg config.DomainsConfig, routes proxy.Invalidator) *Service {
+	return &Service{
+		queries:  queries,
+		resolver: resolver,
+		cfg:      cfg,
+		routes:   routes,
+		now:      time.Now,
+	}
+}
+
+// Add attaches name to the project as a pending domain with a fresh
+// verification token. Its first check is due right away. Other projects may
+// claim the same name, but only one of them can have it verified.
+func (s *Service) Add(ctx context.Context, projectID, name string) (sqlc.Domain, error) {
+	name, err := NormalizeName(name)
+	if err != nil {
+		return sqlc.Domain{}, err
+	}
+
+	d, err := s.queries.CreateDomain(ctx, sqlc.CreateDomainParams{
+		ID:                gonanoid.Must(),
+		ProjectID:         projectID,
+		Name:              name,
+		VerificationToken: gonanoid.MustGenerate(tokenAlphabet, tokenLength),
+		NextCheckAt:       timestamp(s.now()),
+	})
+	if err != nil {
+		if isUniqueViolation(err) {
+			return sqlc.Domain{}, ErrTaken
+		}
+		return sqlc.Domain{}, fmt.Errorf("failed to create domain: %w", err)
+	}
+	return d, nil
+}
+
+// List returns the project's domains, oldest first.
+func (s *Service) List(ctx context.Context, projectID string) ([]sqlc.Domain, error) {
+	domains, err := s.queries.ListDomains(ctx, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list domains: %w", err)
+	}
+	return domains, nil
+}
+
+func (s *Service) Get(ctx context.Context, projectID, domainID string) (sqlc.Domain, error) {
+	d, err := s.queries.GetDomainForProject(ctx, sqlc.GetDomainForProjectParams{
+		ID:        domainID,
+		ProjectID: projectID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return sqlc.Domain{}, ErrNotFound
+		}
+		return sqlc.Domain{}, fmt.Errorf("failed to get domain: %w", err)
+	}
+	return d, nil
+}
+
+func (s *Service) Delete(ctx context.Context, projectID, domainID string) error {
+	n, err := s.queries.DeleteDomainForProject(ctx, sqlc.DeleteDomainForProjectParams{
+		ID:        domainID,
+		ProjectID: projectID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete domain: %w", err)
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	s.invalidateRoutes(ctx)
+	return nil
+}
+
+// Verify checks the domain's TXT record right away. A failed domain gets a
+// new verification window first, so fixing the record and verifying again
+// brings it back.
+func (s *Service) Verify(ctx context.Context, projectID, domainID string) (sqlc.Domain, error) {
+	d, err := s.Get(ctx, projectID, domainID)
+	if err != nil {
+		return sqlc.Domain{}, err
+	}
+
+	if d.Status == sqlc.DomainStatusFailed {
+		d.Status = sqlc.DomainStatusPending
+		d.VerificationStartedAt = timestamp(s.now())
+	}
+	return s.check(ctx, d)
+}
+
+// check looks up the domain's TXT record and stores the outcome: a domain
+// with the record is verified, a pending one without it fails once its
+// verification window is over and a verified one without it fails right
+// away. Lookups that fail for other reasons than the record missing are
+// retried without changing the status. A domain whose name another project
+// has verified stays pending until that one is removed or fails.
+func (s *Service) check(ctx context.Context, d sqlc.Domain) (sqlc.Domain, error) {
+	found, lookupErr := s.lookup(ctx, d)
+	now := s.now()
+
+	arg := sqlc.UpdateDomainVerificationParams{
+		ID:                    d.ID,
+		Status:                d.Status,
+		VerificationStartedAt: d.VerificationStartedAt,
+		VerifiedAt:            d.VerifiedAt,
+		LastCheckedAt:         timestamp(now),
+	}
+
+	switch {
+	case found:
+		if d.Status != sqlc.DomainStatusVerified {
+			arg.VerifiedAt = timestamp(now)
+		}
+		arg.Status = sqlc.DomainStatusVerified
+		arg.NextCheckAt = timestamp(now.Add(s.cfg.RecheckInterval))
+
+	case d.Status == sqlc.DomainStatusVerified && lookupErr == nil:
+		arg.Status = sqlc.DomainStatusFailed
+		arg.LastError = text(fmt.Sprintf("TXT record %s no longer contains the verification token", RecordName(d.Name)))
+
+	case d.Status == sqlc.DomainStatusPending && now.Sub(d.VerificationStartedAt.Time) >= s.cfg.VerificationTimeout:
+		arg.Status = sqlc.DomainStatusFailed
+		arg.LastError = text(fmt.Sprintf("TXT record %s was not found within %s", RecordName(d.Name), s.cfg.VerificationTimeout))
+
+	case d.Status == sqlc.DomainStatusFailed:
+		// Failed domains are only checked again when asked to.
+
+	default:
+		if lookupErr != nil {
+			arg.LastError = text(fmt.Sprintf("failed to look up TXT record %s: %v", RecordName(d.Name), lookupErr))
+		} else {
+			arg.LastError = text(fmt.Sprintf("TXT record %s does not contain the verification token yet", RecordName(d.Name)))
+		}
+		arg.NextCheckAt = timestamp(now.Add(s.cfg.RetryInterval))
+	}
+
+	updated, err := s.queries.UpdateDomainVerification(ctx, arg)
+	if err != nil && arg.Status == sqlc.DomainStatusVerified && d.Status != sqlc.DomainStatusVerified && isUniqueViolation(err) {
+		arg.Status = d.Status
+		arg.VerifiedAt = d.VerifiedAt
+		arg.LastError = text(fmt.Sprintf("%s is already verified by another project", d.Name))
+		arg.NextCheckAt = timestamp(now.Add(s.cfg.RetryInterval))
+		if now.Sub(d.VerificationStartedAt.Time) >= s.cfg.VerificationTimeout {
+			arg.Status = sqlc.DomainStatusFailed
+			arg.NextCheckAt = pgtype.Timestamptz{}
+		}
+		updated, err = s.queries.UpdateDomainVerification(ctx, arg)
+	}
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return sqlc.Domain{}, ErrNotFound
+		}
+		return sqlc.Domain{}, fmt.Errorf("failed to update domain: %w", err)
+	}
+	if (updated.Status == sqlc.DomainStatusVerified) != (d.Status == sqlc.DomainStatusVerified) {
+		s.invalidateRoutes(ctx)
+	}
+	return updated, nil
+}
+
+// invalidateRoutes tells the edge proxies which domains they serve has
+// changed. If that fails they notice on their next periodic refresh.
+func (s *Service) invalidateRoutes(ctx context.Context) {
+	if s.routes == nil {
+		return
+	}
+	if err := s.routes.Invalidate(ctx); err != nil {
+		log.Printf("Failed to invalidate proxy routes: %v", err)
+	}
+}
+
+// lookup reports whether the domain's TXT record holds its token. The error
+// is only set when the lookup itself failed; a missing record is not one.
+func (s *Service) lookup(ctx context.Context, d sqlc.Domain) (bool, error) {
+	records, err := s.resolver.LookupTXT(ctx, RecordName(d.Name))
+	if err != nil {
+		if isNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	want := RecordValue(d.VerificationToken)
+	for _, record := range records {
+		if strings.TrimSpace(record) == want {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation
+}
+
+func timestamp(t time.Time) pgtype.Timestamptz {
+	return pgtype.Timestamptz{Time: t, Valid: true}
+}
+
+func text(s string) pgtype.Text {
+	return pgtype.Text{String: s, Valid: true}
+}
@@ -0,0 +1,392 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Jesuloba-world/deployease/backend/internal/config"
+	"github.com/Jesuloba-world/deployease/backend/internal/infrastructure/database/postgres/sqlc"
+	"github.com/Jesuloba-world/deployease/backend/internal/jobs"
+)
+
+type fakeQuerier struct {
+	sqlc.Querier
+	domains map[string]sqlc.Domain
+	jobs    []sqlc.Job
+}
+
+func (f *fakeQuerier) CreateDomain(ctx context.Context, arg sqlc.CreateDomainParams) (sqlc.Domain, error) {
+	for _, d := range f.domains {
+		if d.ProjectID == arg.ProjectID && d.Name == arg.Name {
+			return sqlc.Domain{}, &pgconn.PgError{Code: "23505", ConstraintName: "idx_domains_project_name"}
+		}
+	}
+	d := sqlc.Domain{
+		ID:                    arg.ID,
+		ProjectID:             arg.ProjectID,
+		Name:                  arg.Name,
+		Status:                sqlc.DomainStatusPending,
+		VerificationToken:     arg.VerificationToken,
+		VerificationStartedAt: arg.NextCheckAt,
+		NextCheckAt:           arg.NextCheckAt,
+		CreatedAt:             arg.NextCheckAt,
+	}
+	f.domains[d.ID] = d
+	return d, nil
+}
+
+func (f *fakeQuerier) GetDomain(ctx context.Context, id string) (sqlc.Domain, error) {
+	d, ok := f.domains[id]
+	if !ok {
+		return sqlc.Domain{}, pgx.ErrNoRows
+	}
+	return d, nil
+}
+
+func (f *fakeQuerier) GetDomainForProject(ctx context.Context, arg sqlc.GetDomainForProjectParams) (sqlc.Domain, error) {
+	d, ok := f.domains[arg.ID]
+	if !ok || d.ProjectID != arg.ProjectID {
+		return sqlc.Domain{}, pgx.ErrNoRows
+	}
+	return d, nil
+}
+
+func (f *fakeQuerier) ListDomains(ctx context.Context, projectID string) ([]sqlc.Domain, error) {
+	out := []sqlc.Domain{}
+	for _, d := range f.domains {
+		if d.ProjectID == projectID {
+			out = append(out, d)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
+}
+
+func (f *fakeQuerier) UpdateDomainVerification(ctx context.Context, arg sqlc.UpdateDomainVerificationParams) (sqlc.Domain, error) {
+	d, ok := f.domains[arg.ID]
+	if !ok {
+		return sqlc.Domain{}, pgx.ErrNoRows
+	}
+	if arg.Status == sqlc.DomainStatusVerified {
+		for _, other := range f.domains {
+			if other.ID != d.ID && other.Name == d.Name && other.Status == sqlc.DomainStatusVerified {
+				return sqlc.Domain{}, &pgconn.PgError{Code: "23505", ConstraintName: "idx_domains_name_verified"}
+			}
+		}
+	}
+	d.Status = arg.Status
+	d.VerificationStartedAt = arg.VerificationStartedAt
+	d.VerifiedAt = arg.VerifiedAt
+	d.LastCheckedAt = arg.LastCheckedAt
+	d.LastError = arg.LastError
+	d.NextCheckAt = arg.NextCheckAt
+	f.domains[d.ID] = d
+	return d, nil
+}
+
+func (f *fakeQuerier) ClaimDueDomainChecks(ctx context.Context, arg sqlc.ClaimDueDomainChecksParams) ([]string, error) {
+	ids := []string{}
+	for id, d := range f.domains {
+		if len(ids) == int(arg.BatchSize) {
+			break
+		}
+		if d.NextCheckAt.Valid && !d.NextCheckAt.Time.After(arg.Now.Time) {
+			d.NextCheckAt = arg.LeaseUntil
+			f.domains[id] = d
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+func (f *fakeQuerier) DeleteDomainForProject(ctx context.Context, arg sqlc.DeleteDomainForProjectParams) (int64, error) {
+	d, ok := f.domains[arg.ID]
+	if !ok || d.ProjectID != arg.ProjectID {
+		return 0, nil
+	}
+	delete(f.domains, arg.ID)
+	return 1, nil
+}
+
+func (f *fakeQuerier) EnqueueJob(ctx context.Context, arg sqlc.EnqueueJobParams) (sqlc.Job, error) {
+	job := sqlc.Job{ID: arg.ID, Kind: arg.Kind, Payload: arg.Payload}
+	f.jobs = append(f.jobs, job)
+	return job, nil
+}
+
+// fakeResolver serves TXT records from a map; names without records are
+// reported as not found.
+type fakeResolver struct {
+	records map[string][]string
+	err     error
+}
+
+func (r *fakeResolver) LookupTXT(ctx context.Context, name string) ([]string, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	records, ok := r.records[name]
+	if !ok {
+		return nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+	}
+	return records, nil
+}
+
+// fakeInvalidator counts routing change announcements.
+type fakeInvalidator struct {
+	calls int
+}
+
+func (i *fakeInvalidator) Invalidate(ctx context.Context) error {
+	i.calls++
+	return nil
+}
+
+type fixture struct {
+	svc      *Service
+	queries  *fakeQuerier
+	resolver *fakeResolver
+	routes   *fakeInvalidator
+	now      time.Time
+}
+
+func setupService(t *testing.T) *fixture {
+	t.Helper()
+	f := &fixture{
+		queries:  &fakeQuerier{domains: make(map[string]sqlc.Domain)},
+		resolver: &fakeResolver{records: make(map[string][]string)},
+		routes:   &fakeInvalidator{},
+		now:      time.Date(2025, 7, 4, 12, 0, 0, 0, time.UTC),
+	}
+	f.svc = NewService(f.queries, f.resolver, config.DomainsConfig{
+		CheckInterval:       time.Minute,
+		RetryInterval:       5 * time.Minute,
+		RecheckInterval:     24 * time.Hour,
+		VerificationTimeout: 72 * time.Hour,
+	}, f.routes)
+	f.svc.now = func() time.Time { return f.now }
+	return f
+}
+
+func (f *fixture) publish(d sqlc.Domain) {
+	f.resolver.records[RecordName(d.Name)] = []string{"v=spf1 -all", RecordValue(d.VerificationToken)}
+}
+
+func TestServiceAdd(t *testing.T) {
+	ctx := context.Background()
+	f := setupService(t)
+
+	d, err := f.svc.Add(ctx, "p1", "App.Example.com.")
+	require.NoError(t, err)
+	assert.Equal(t, "app.example.com", d.Name)
+	assert.Equal(t, sqlc.DomainStatusPending, d.Status)
+	assert.Len(t, d.VerificationToken, tokenLength)
+	assert.Equal(t, f.now, d.NextCheckAt.Time, "the first check is due right away")
+
+	_, err = f.svc.Add(ctx, "p1", "app.example.com")
+	assert.ErrorIs(t, err, ErrTaken)
+
+	_, err = f.svc.Add(ctx, "p1", "*.example.com")
+	assert.ErrorIs(t, err, ErrInvalidName)
+
+	domains, err := f.svc.List(ctx, "p1")
+	require.NoError(t, err)
+	assert.Len(t, domains, 1)
+
+	_, err = f.svc.Get(ctx, "p2", d.ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.ErrorIs(t, f.svc.Delete(ctx, "p2", d.ID), ErrNotFound)
+	require.NoError(t, f.svc.Delete(ctx, "p1", d.ID))
+	_, err = f.svc.Get(ctx, "p1", d.ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestServiceVerify(t *testing.T) {
+	ctx := context.Background()
+	f := setupService(t)
+
+	d, err := f.svc.Add(ctx, "p1", "app.example.com")
+	require.NoError(t, err)
+
+	d, err = f.svc.Verify(ctx, "p1", d.ID)
+	require.NoError(t, err)
+	assert.Equal(t, sqlc.DomainStatusPending, d.Status)
+	assert.Contains(t, d.LastError.String, "_deployease-challenge.app.example.com")
+	assert.Equal(t, f.now.Add(5*time.Minute), d.NextCheckAt.Time)
+
+	f.resolver.records[RecordName(d.Name)] = []string{RecordValue("someone-elses-token")}
+	d, err = f.svc.Verify(ctx, "p1", d.ID)
+	require.NoError(t, err)
+	assert.Equal(t, sqlc.DomainStatusPending, d.Status)
+
+	f.now = f.now.Add(time.Hour)
+	f.publish(d)
+	d, err = f.svc.Verify(ctx, "p1", d.ID)
+	require.NoError(t, err)
+	assert.Equal(t, sqlc.DomainStatusVerified, d.Status)
+	assert.Equal(t, f.now, d.VerifiedAt.Time)
+	assert.False(t, d.LastError.Valid)
+	assert.Equal(t, f.now.Add(24*time.Hour), d.NextCheckAt.Time)
+	assert.Equal(t, 1, f.routes.calls, "the proxies start serving the domain")
+
+	// Rechecks keep the original verification time.
+	verifiedAt := d.VerifiedAt.Time
+	f.now = f.now.Add(24 * time.Hour)
+	d, err = f.svc.Verify(ctx, "p1", d.ID)
+	require.NoError(t, err)
+	assert.Equal(t, verifiedAt, d.VerifiedAt.Time)
+
+	// A DNS outage leaves a verified domain alone and retries.
+	f.resolver.err = errors.New("i/o timeout")
+	d, err = f.svc.Verify(ctx, "p1", d.ID)
+	require.NoError(t, err)
+	assert.Equal(t, sqlc.DomainStatusVerified, d.Status)
+	assert.Contains(t, d.LastError.String, "i/o timeout")
+	assert.Equal(t, f.now.Add(5*time.Minute), d.NextCheckAt.Time)
+	assert.Equal(t, 1, f.routes.calls)
+	f.resolver.err = nil
+
+	// Taking the record down fails the domain for good.
+	delete(f.resolver.records, RecordName(d.Name))
+	d, err = f.svc.Verify(ctx, "p1", d.ID)
+	require.NoError(t, err)
+	assert.Equal(t, sqlc.DomainStatusFailed, d.Status)
+	assert.Contains(t, d.LastError.String, "no longer")
+	assert.False(t, d.NextCheckAt.Valid)
+	assert.Equal(t, 2, f.routes.calls, "the proxies stop serving the domain")
+
+	// Verifying a failed domain starts a new verification window.
+	f.now = f.now.Add(100 * time.Hour)
+	d, err = f.svc.Verify(ctx, "p1", d.ID)
+	require.NoError(t, err)
+	assert.Equal(t, sqlc.DomainStatusPending, d.Status)
+	assert.Equal(t, f.now, d.VerificationStartedAt.Time)
+	assert.True(t, d.NextCheckAt.Valid)
+
+	f.publish(d)
+	d, err = f.svc.Verify(ctx, "p1", d.ID)
+	require.NoError(t, err)
+	assert.Equal(t, sqlc.DomainStatusVerified, d.Status)
+	assert.Equal(t, f.now, d.VerifiedAt.Time)
+
+	_, err = f.svc.Verify(ctx, "p2", d.ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestServiceSameNameInTwoProjects(t *testing.T) {
+	ctx := context.Background()
+	f := setupService(t)
+
+	first, err := f.svc.Add(ctx, "p1", "app.example.com")
+	require.NoError(t, err)
+	second, err := f.svc.Add(ctx, "p2", "app.example.com")
+	require.NoError(t, err, "a pending claim does not hold the name")
+
+	f.publish(second)
+	second, err = f.svc.Verify(ctx, "p2", second.ID)
+	require.NoError(t, err)
+	assert.Equal(t, sqlc.DomainStatusVerified, second.Status)
+
+	// Even with its record published, the other claim cannot take a name
+	// that is verified already.
+	f.resolver.records[RecordName(second.Name)] = []string{RecordValue(second.VerificationToken), RecordValue(first.VerificationToken)}
+	first, err = f.svc.Verify(ctx, "p1", first.ID)
+	require.NoError(t, err)
+	assert.Equal(t, sqlc.DomainStatusPending, first.Status)
+	assert.Contains(t, first.LastError.String, "verified by another project")
+	assert.Equal(t, f.now.Add(5*time.Minute), first.NextCheckAt.Time)
+	assert.Equal(t, 1, f.routes.calls)
+
+	// Once the second loses the name, the other claim can verify it.
+	f.resolver.records[RecordName(second.Name)] = []string{RecordValue(first.VerificationToken)}
+	second, err = f.svc.Verify(ctx, "p2", second.ID)
+	require.NoError(t, err)
+	assert.Equal(t, sqlc.DomainStatusFailed, second.Status)
+
+	first, err = f.svc.Verify(ctx, "p1", first.ID)
+	require.NoError(t, err)
+	assert.Equal(t, sqlc.DomainStatusVerified, first.Status)
+	assert.False(t, first.LastError.Valid)
+}
+
+func TestServiceVerificationTimesOut(t *testing.T) {
+	ctx := context.Background()
+	f := setupService(t)
+
+	d, err := f.svc.Add(ctx, "p1", "app.example.com")
+	require.NoError(t, err)
+
+	f.now = f.now.Add(72*time.Hour - time.Second)
+	d, err = f.svc.Verify(ctx, "p1", d.ID)
+	require.NoError(t, err)
+	assert.Equal(t, sqlc.DomainStatusPending, d.Status)
+
+	f.now = f.now.Add(time.Second)
+	d, err = f.svc.Verify(ctx, "p1", d.ID)
+	require.NoError(t, err)
+	assert.Equal(t, sqlc.DomainStatusFailed, d.Status)
+	assert.Contains(t, d.LastError.String, "not found within 72h0m0s")
+	assert.False(t, d.NextCheckAt.Valid)
+}
+
+func TestSchedulerQueuesDueChecks(t *testing.T) {
+	ctx := context.Background()
+	f := setupService(t)
+	scheduler := NewScheduler(f.svc, jobs.NewQueue(f.queries, 3))
+
+	first, err := f.svc.Add(ctx, "p1", "app.example.com")
+	require.NoError(t, err)
+	second, err := f.svc.Add(ctx, "p1", "www.example.com")
+	require.NoError(t, err)
+	f.publish(first)
+
+	n, err := scheduler.Schedule(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	n, err = scheduler.Schedule(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, n, "claimed checks are not queued twice")
+
+	require.Len(t, f.queries.jobs, 2)
+	for _, job := range f.queries.jobs {
+		assert.Equal(t, CheckJobKind, job.Kind)
+		require.NoError(t, f.svc.HandleCheck(ctx, job))
+	}
+
+	d, err := f.svc.Get(ctx, "p1", first.ID)
+	require.NoError(t, err)
+	assert.Equal(t, sqlc.DomainStatusVerified, d.Status)
+	d, err = f.svc.Get(ctx, "p1", second.ID)
+	require.NoError(t, err)
+	assert.Equal(t, sqlc.DomainStatusPending, d.Status)
+
+	// The pending domain comes due again after the retry interval, the
+	// verified one only after the recheck interval.
+	f.queries.jobs = nil
+	f.now = f.now.Add(5 * time.Minute)
+	n, err = scheduler.Schedule(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	var payload CheckPayload
+	require.NoError(t, json.Unmarshal(f.queries.jobs[0].Payload, &payload))
+	assert.Equal(t, second.ID, payload.DomainID)
+
+	require.NoError(t, f.svc.Delete(ctx, "p1", second.ID))
+	assert.NoError(t, f.svc.HandleCheck(ctx, f.queries.jobs[0]), "deleted domains are skipped")
+
+	f.queries.jobs = nil
+	f.now = f.now.Add(24 * time.Hour)
+	n, err = scheduler.Schedule(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+}
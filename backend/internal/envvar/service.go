@@ -0,0 +1,179 @@
+// Package envvar stores the environment variables a project's containers
+// run with. Variables can be overridden for the deployments of a single
+// branch. Secret values are encrypted at rest and only decrypted when a
+// container is started.
+package envvar
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/Jesuloba-world/deployease/backend/internal/infrastructure/database/postgres/sqlc"
+	"github.com/Jesuloba-world/deployease/backend/internal/project"
+	"github.com/Jesuloba-world/deployease/backend/internal/secrets"
+)
+
+var (
+	ErrNotFound    = errors.New("environment variable not found")
+	ErrInvalidKey  = errors.New("environment variable names must start with a letter or underscore and contain only letters, digits and underscores")
+	ErrReservedKey = errors.New("environment variable is set by DeployEase")
+)
+
+var keyPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// reserved are set on every container by the deployment runner.
+var reserved = map[string]bool{
+	"PORT": true,
+}
+
+type Service struct {
+	queries sqlc.Querier
+	cipher  *secrets.Cipher
+}
+
+func NewService(queries sqlc.Querier, cipher *secrets.Cipher) *Service {
+	return &Service{
+		queries: queries,
+		cipher:  cipher,
+	}
+}
+
+// ValidateKey checks that key can be used as an environment variable name.
+func ValidateKey(key string) error {
+	if len(key) > 256 || !keyPattern.MatchString(key) {
+		return ErrInvalidKey
+	}
+	if reserved[key] {
+		return fmt.Errorf("%w: %s", ErrReservedKey, key)
+	}
+	return nil
+}
+
+// validateBranch checks the branch variables are scoped to; the empty
+// branch holds the project's own variables.
+func validateBranch(branch string) error {
+	if branch == "" {
+		return nil
+	}
+	return project.ValidateBranch(branch)
+}
+
+// List returns the variables set for branch, or the project's own when
+// branch is empty, ordered by name. Secret values stay encrypted.
+func (s *Service) List(ctx context.Context, projectID, branch string) ([]sqlc.ProjectEnvVar, error) {
+	if err := validateBranch(branch); err != nil {
+		return nil, err
+	}
+
+	vars, err := s.queries.ListProjectEnvVars(ctx, sqlc.ListProjectEnvVarsParams{
+		ProjectID: projectID,
+		Branch:    branch,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list environment variables: %w", err)
+	}
+	return vars, nil
+}
+
+type SetParams struct {
+	ProjectID string
+	// Branch scopes the variable to the deployments of one branch, where it
+	// overrides the project's variable of the same name.
+	Branch string
+	Key    string
+	Value  string
+	Secret bool
+}
+
+// Set creates or replaces a variable. A variable can be turned into a
+// secret and back; its value is re-stored accordingly.
+func (s *Service) Set(ctx context.Context, params SetParams) (sqlc.ProjectEnvVar, error) {
+	if err := ValidateKey(params.Key); err != nil {
+		return sqlc.ProjectEnvVar{}, err
+	}
+	if err := validateBranch(params.Branch); err != nil {
+		return sqlc.ProjectEnvVar{}, err
+	}
+
+	arg := sqlc.UpsertProjectEnvVarParams{
+		ProjectID: params.ProjectID,
+		Branch:    params.Branch,
+		Key:       params.Key,
+		Secret:    params.Secret,
+	}
+	if params.Secret {
+		sealed, err := s.cipher.SealString(params.Value, associatedData(params.ProjectID, params.Branch, params.Key))
+		if err != nil {
+			return sqlc.ProjectEnvVar{}, fmt.Errorf("failed to encrypt environment variable: %w", err)
+		}
+		arg.EncryptedValue = sealed
+	} else {
+		arg.Value = pgtype.Text{String: params.Value, Valid: true}
+	}
+
+	v, err := s.queries.UpsertProjectEnvVar(ctx, arg)
+	if err != nil {
+		return sqlc.ProjectEnvVar{}, fmt.Errorf("failed to set environment variable: %w", err)
+	}
+	return v, nil
+}
+
+func (s *Service) Delete(ctx context.Context, projectID, branch, key string) error {
+	if err := validateBranch(branch); err != nil {
+		return err
+	}
+
+	rows, err := s.queries.DeleteProjectEnvVar(ctx, sqlc.DeleteProjectEnvVarParams{
+		ProjectID: projectID,
+		Branch:    branch,
+		Key:       key,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete environment variable: %w", err)
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Resolve returns the variables a deployment of branch runs with, the
+// branch's overrides applied over the project's variables, with secrets
+// decrypted, ready to be passed to a container.
+func (s *Service) Resolve(ctx context.Context, projectID, branch string) (map[string]string, error) {
+	vars, err := s.queries.ResolveProjectEnvVars(ctx, sqlc.ResolveProjectEnvVarsParams{
+		ProjectID: projectID,
+		Branch:    branch,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list environment variables: %w", err)
+	}
+
+	env := make(map[string]string, len(vars))
+	for _, v := range vars {
+		if !v.Secret {
+			env[v.Key] = v.Value.String
+			continue
+		}
+		value, err := s.cipher.OpenString(v.EncryptedValue, associatedData(v.ProjectID, v.Branch, v.Key))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt %s: %w", v.Key, err)
+		}
+		env[v.Key] = value
+	}
+	return env, nil
+}
+
+// associatedData binds a sealed value to its variable, so a value copied
+// to another row, branch or project fails to decrypt. Keys cannot contain a
+// slash, so a branch's variables never share the project's data.
+func associatedData(projectID, branch, key string) string {
+	if branch == "" {
+		return "project_env_vars/" + projectID + "/" + key
+	}
+	return "project_env_vars/" + projectID + "/" + branch + "/" + key
+}
@@ -0,0 +1,193 @@
+package envvar
+
+import (
+	"bytes"
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Jesuloba-world/deployease/backend/internal/config"
+	"github.com/Jesuloba-world/deployease/backend/internal/infrastructure/database/postgres/sqlc"
+	"github.com/Jesuloba-world/deployease/backend/internal/project"
+	"github.com/Jesuloba-world/deployease/backend/internal/secrets"
+)
+
+type fakeQuerier struct {
+	sqlc.Querier
+	vars map[string]sqlc.ProjectEnvVar
+}
+
+func (f *fakeQuerier) ListProjectEnvVars(ctx context.Context, arg sqlc.ListProjectEnvVarsParams) ([]sqlc.ProjectEnvVar, error) {
+	out := []sqlc.ProjectEnvVar{}
+	for _, v := range f.vars {
+		if v.ProjectID == arg.ProjectID && v.Branch == arg.Branch {
+			out = append(out, v)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Key < out[j].Key })
+	return out, nil
+}
+
+func (f *fakeQuerier) ResolveProjectEnvVars(ctx context.Context, arg sqlc.ResolveProjectEnvVarsParams) ([]sqlc.ProjectEnvVar, error) {
+	byKey := make(map[string]sqlc.ProjectEnvVar)
+	for _, v := range f.vars {
+		if v.ProjectID != arg.ProjectID || (v.Branch != "" && v.Branch != arg.Branch) {
+			continue
+		}
+		if _, ok := byKey[v.Key]; !ok || v.Branch != "" {
+			byKey[v.Key] = v
+		}
+	}
+	out := []sqlc.ProjectEnvVar{}
+	for _, v := range byKey {
+		out = append(out, v)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Key < out[j].Key })
+	return out, nil
+}
+
+func (f *fakeQuerier) UpsertProjectEnvVar(ctx context.Context, arg sqlc.UpsertProjectEnvVarParams) (sqlc.ProjectEnvVar, error) {
+	v := sqlc.ProjectEnvVar{
+		ProjectID:      arg.ProjectID,
+		Branch:         arg.Branch,
+		Key:            arg.Key,
+		Value:          arg.Value,
+		EncryptedValue: arg.EncryptedValue,
+		Secret:         arg.Secret,
+	}
+	f.vars[arg.ProjectID+"/"+arg.Branch+"/"+arg.Key] = v
+	return v, nil
+}
+
+func (f *fakeQuerier) DeleteProjectEnvVar(ctx context.Context, arg sqlc.DeleteProjectEnvVarParams) (int64, error) {
+	id := arg.ProjectID + "/" + arg.Branch + "/" + arg.Key
+	if _, ok := f.vars[id]; !ok {
+		return 0, nil
+	}
+	delete(f.vars, id)
+	return 1, nil
+}
+
+func setupService(t *testing.T) (*Service, *fakeQuerier) {
+	t.Helper()
+	cipher, err := secrets.NewCipher(config.SecretsConfig{MasterKey: "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY="})
+	require.NoError(t, err)
+	q := &fakeQuerier{vars: make(map[string]sqlc.ProjectEnvVar)}
+	return NewService(q, cipher), q
+}
+
+func TestSetAndResolve(t *testing.T) {
+	ctx := context.Background()
+	svc, q := setupService(t)
+
+	plain, err := svc.Set(ctx, SetParams{ProjectID: "p1", Key: "LOG_LEVEL", Value: "debug"})
+	require.NoError(t, err)
+	assert.Equal(t, "debug", plain.Value.String)
+	assert.Nil(t, plain.EncryptedValue)
+
+	secret, err := svc.Set(ctx, SetParams{ProjectID: "p1", Key: "API_TOKEN", Value: "s3cr3t", Secret: true})
+	require.NoError(t, err)
+	assert.False(t, secret.Value.Valid)
+	assert.False(t, bytes.Contains(secret.EncryptedValue, []byte("s3cr3t")))
+
+	_, err = svc.Set(ctx, SetParams{ProjectID: "p2", Key: "LOG_LEVEL", Value: "info"})
+	require.NoError(t, err)
+
+	env, err := svc.Resolve(ctx, "p1", "main")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"LOG_LEVEL": "debug", "API_TOKEN": "s3cr3t"}, env)
+
+	// A sealed value moved to another variable does not decrypt.
+	moved := q.vars["p1//API_TOKEN"]
+	moved.Key = "OTHER"
+	q.vars["p1//OTHER"] = moved
+	_, err = svc.Resolve(ctx, "p1", "main")
+	assert.ErrorIs(t, err, secrets.ErrDecrypt)
+}
+
+func TestSetReplacesSecretness(t *testing.T) {
+	ctx := context.Background()
+	svc, _ := setupService(t)
+
+	_, err := svc.Set(ctx, SetParams{ProjectID: "p1", Key: "TOKEN", Value: "one", Secret: true})
+	require.NoError(t, err)
+	v, err := svc.Set(ctx, SetParams{ProjectID: "p1", Key: "TOKEN", Value: "two"})
+	require.NoError(t, err)
+	assert.False(t, v.Secret)
+	assert.Nil(t, v.EncryptedValue)
+
+	env, err := svc.Resolve(ctx, "p1", "main")
+	require.NoError(t, err)
+	assert.Equal(t, "two", env["TOKEN"])
+}
+
+func TestBranchOverrides(t *testing.T) {
+	ctx := context.Background()
+	svc, q := setupService(t)
+
+	_, err := svc.Set(ctx, SetParams{ProjectID: "p1", Key: "API_URL", Value: "https://api.example.com"})
+	require.NoError(t, err)
+	_, err = svc.Set(ctx, SetParams{ProjectID: "p1", Key: "API_TOKEN", Value: "prod", Secret: true})
+	require.NoError(t, err)
+	_, err = svc.Set(ctx, SetParams{ProjectID: "p1", Branch: "feature/login", Key: "API_URL", Value: "https://staging.example.com"})
+	require.NoError(t, err)
+	_, err = svc.Set(ctx, SetParams{ProjectID: "p1", Branch: "feature/login", Key: "API_TOKEN", Value: "staging", Secret: true})
+	require.NoError(t, err)
+
+	env, err := svc.Resolve(ctx, "p1", "feature/login")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"API_URL": "https://staging.example.com", "API_TOKEN": "staging"}, env)
+
+	env, err = svc.Resolve(ctx, "p1", "main")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"API_URL": "https://api.example.com", "API_TOKEN": "prod"}, env)
+
+	overrides, err := svc.List(ctx, "p1", "feature/login")
+	require.NoError(t, err)
+	assert.Len(t, overrides, 2)
+
+	// An override's sealed value does not decrypt as the project's.
+	stolen := q.vars["p1/feature/login/API_TOKEN"]
+	stolen.Branch = ""
+	q.vars["p1//API_TOKEN"] = stolen
+	_, err = svc.Resolve(ctx, "p1", "main")
+	assert.ErrorIs(t, err, secrets.ErrDecrypt)
+
+	require.NoError(t, svc.Delete(ctx, "p1", "feature/login", "API_URL"))
+	assert.ErrorIs(t, svc.Delete(ctx, "p1", "feature/login", "API_URL"), ErrNotFound)
+
+	_, err = svc.Set(ctx, SetParams{ProjectID: "p1", Branch: "bad..branch", Key: "API_URL", Value: "x"})
+	assert.ErrorIs(t, err, project.ErrInvalidBranch)
+}
+
+func TestSetValidatesKey(t *testing.T) {
+	ctx := context.Background()
+	svc, _ := setupService(t)
+
+	for _, key := range []string{"", "1ST", "MY-VAR", "A B"} {
+		_, err := svc.Set(ctx, SetParams{ProjectID: "p1", Key: key, Value: "x"})
+		assert.ErrorIs(t, err, ErrInvalidKey, key)
+	}
+
+	_, err := svc.Set(ctx, SetParams{ProjectID: "p1", Key: "PORT", Value: "3000"})
+	assert.ErrorIs(t, err, ErrReservedKey)
+}
+
+func TestDelete(t *testing.T) {
+	ctx := context.Background()
+	svc, _ := setupService(t)
+
+	_, err := svc.Set(ctx, SetParams{ProjectID: "p1", Key: "LOG_LEVEL", Value: "debug"})
+	require.NoError(t, err)
+
+	assert.ErrorIs(t, svc.Delete(ctx, "p2", "", "LOG_LEVEL"), ErrNotFound)
+	require.NoError(t, svc.Delete(ctx, "p1", "", "LOG_LEVEL"))
+	assert.ErrorIs(t, svc.Delete(ctx, "p1", "", "LOG_LEVEL"), ErrNotFound)
+
+	vars, err := svc.List(ctx, "p1", "")
+	require.NoError(t, err)
+	assert.Empty(t, vars)
+}
@@ -0,0 +1,156 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/Jesuloba-world/deployease/backend/internal/config"
+	"github.com/Jesuloba-world/deployease/backend/internal/infrastructure/database/dragonfly"
+)
+
+var ErrClientNotInitialized = errors.New("Dragonfly client not initialized")
+
+// Broker publishes events to Dragonfly and delivers the ones it receives to
+// local subscribers. It holds a single pub/sub connection and only listens
+// on the channels of projects that have a subscriber on this instance.
+type Broker struct {
+	client *redis.Client
+	pubsub *redis.PubSub
+	hub    *hub
+	buffer int
+
+	// mu serialises channel (un)subscriptions so they always converge on
+	// whether the hub still has subscribers for the project.
+	mu        sync.Mutex
+	listening map[string]bool
+
+	done chan struct{}
+}
+
+func NewBroker(cfg config.EventsConfig) (*Broker, error) {
+	client := dragonfly.GetClient()
+	if client == nil {
+		return nil, ErrClientNotInitialized
+	}
+	return newBroker(client, cfg.SendBuffer), nil
+}
+
+func newBroker(client *redis.Client, buffer int) *Broker {
+	b := &Broker{
+		client:    client,
+		pubsub:    client.Subscribe(context.Background()),
+		hub:       newHub(),
+		buffer:    buffer,
+		listening: make(map[string]bool),
+		done:      make(chan struct{}),
+	}
+	go b.run()
+	return b
+}
+
+func (b *Broker) Publish(ctx context.Context, event Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+	if err := b.client.Publish(ctx, channel(event.Data.ProjectID), data).Err(); err != nil {
+		return fmt.Errorf("failed to publish event: %w", err)
+	}
+	return nil
+}
+
+// NewSubscriber registers a subscriber with room for buffer undelivered
+// events. It must be released with Remove.
+func (b *Broker) NewSubscriber() (*Subscriber, error) {
+	s := newSubscriber(b.buffer)
+	if err := b.hub.register(s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (b *Broker) Subscribe(ctx context.Context, s *Subscriber, projectID string) error {
+	if err := b.hub.add(s, projectID); err != nil {
+		return err
+	}
+	if err := b.sync(ctx, projectID); err != nil {
+		b.hub.remove(s, projectID)
+		return err
+	}
+	return nil
+}
+
+func (b *Broker) Unsubscribe(ctx context.Context, s *Subscriber, projectID string) error {
+	b.hub.remove(s, projectID)
+	return b.sync(ctx, projectID)
+}
+
+// Remove unsubscribes s from everything and releases it.
+func (b *Broker) Remove(s *Subscriber) {
+	for _, projectID := range b.hub.unregister(s) {
+		if err := b.sync(context.Background(), projectID); err != nil {
+			log.Printf("Failed to stop listening for events of project %s: %v", projectID, err)
+		}
+	}
+}
+
+// Close drops every subscriber and closes the pub/sub connection.
+func (b *Broker) Close() error {
+	b.hub.close()
+	err := b.pubsub.Close()
+	<-b.done
+	return err
+}
+
+// sync listens on the project's channel if and only if it has local
+// subscribers.
+func (b *Broker) sync(ctx context.Context, projectID string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	want := b.hub.has(projectID)
+	if want == b.listening[projectID] {
+		return nil
+	}
+
+	var err error
+	if want {
+		err = b.pubsub.Subscribe(ctx, channel(projectID))
+	} else {
+		err = b.pubsub.Unsubscribe(ctx, channel(projectID))
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update event subscription: %w", err)
+	}
+
+	if want {
+		b.listening[projectID] = true
+	} else {
+		delete(b.listening, projectID)
+	}
+	return nil
+}
+
+func (b *Broker) run() {
+	defer close(b.done)
+
+	for msg := range b.pubsub.Channel() {
+		projectID := strings.TrimPrefix(msg.Channel, channelPrefix)
+		for _, emptied := range b.hub.dispatch(projectID, []byte(msg.Payload)) {
+			// Not from this goroutine: sync waits on the pub/sub connection,
+			// which may be waiting for us to drain its channel.
+			go func() {
+				if err := b.sync(context.Background(), emptied); err != nil {
+					log.Printf("Failed to stop listening for events of project %s: %v", emptied, err)
+				}
+			}()
+		}
+	}
+}
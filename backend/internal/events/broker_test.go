@@ -0,0 +1,91 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
+
+	"github.com/Jesuloba-world/deployease/backend/internal/infrastructure/database/dragonfly"
+)
+
+func setupBrokers(t *testing.T) (*Broker, *Broker) {
+	t.Helper()
+	testcontainers.SkipIfProviderIsNotHealthy(t)
+
+	ctx := context.Background()
+	dc, err := dragonfly.StartDragonflyContainer(ctx)
+	require.NoError(t, err)
+	t.Cleanup(func() { dc.Cleanup(ctx) })
+
+	// Two clients stand in for two server instances.
+	newClient := func() *redis.Client {
+		client := redis.NewClient(&redis.Options{Addr: dc.Address})
+		t.Cleanup(func() { client.Close() })
+		return client
+	}
+	a, b := newBroker(newClient(), 8), newBroker(newClient(), 8)
+	t.Cleanup(func() { a.Close(); b.Close() })
+	return a, b
+}
+
+func receive(t *testing.T, s *Subscriber) Event {
+	t.Helper()
+	select {
+	case msg := <-s.Messages():
+		var event Event
+		require.NoError(t, json.Unmarshal(msg, &event))
+		return event
+	case <-time.After(5 * time.Second):
+		t.Fatal("no event received")
+		return Event{}
+	}
+}
+
+func TestBrokerFansOutAcrossInstances(t *testing.T) {
+	ctx := context.Background()
+	a, b := setupBrokers(t)
+
+	sub, err := b.NewSubscriber()
+	require.NoError(t, err)
+	defer b.Remove(sub)
+	require.NoError(t, b.Subscribe(ctx, sub, "p1"))
+
+	event := Event{
+		Type: TypeDeploymentStatus,
+		Data: EventData{ProjectID: "p1", DeploymentID: "d1", Status: "success", Timestamp: time.Now().UTC()},
+	}
+	require.NoError(t, a.Publish(ctx, Event{Type: TypeDeploymentStatus, Data: EventData{ProjectID: "p2"}}))
+	require.NoError(t, a.Publish(ctx, event))
+
+	got := receive(t, sub)
+	assert.Equal(t, event.Type, got.Type)
+	assert.Equal(t, "d1", got.Data.DeploymentID)
+	assert.Equal(t, "success", got.Data.Status)
+
+	require.NoError(t, b.Unsubscribe(ctx, sub, "p1"))
+	require.NoError(t, a.Publish(ctx, event))
+	select {
+	case <-sub.Messages():
+		t.Fatal("event delivered after unsubscribing")
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+func TestBrokerCloseDropsSubscribers(t *testing.T) {
+	a, _ := setupBrokers(t)
+
+	sub, err := a.NewSubscriber()
+	require.NoError(t, err)
+	require.NoError(t, a.Close())
+
+	<-sub.Dropped()
+	assert.ErrorIs(t, sub.Err(), ErrBrokerClosed)
+	_, err = a.NewSubscriber()
+	assert.ErrorIs(t, err, ErrBrokerClosed)
+}
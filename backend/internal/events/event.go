@@ -0,0 +1,42 @@
+// Package events carries deployment status changes and log output to
+// WebSocket clients. Events are published to Dragonfly so every server
+// instance can deliver them to its own connections, whichever instance (or
+// worker) produced them.
+package events
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	TypeDeploymentStatus = "deployment.status"
+	TypeDeploymentLog    = "deployment.log"
+)
+
+type Event struct {
+	Type string    `json:"type"`
+	Data EventData `json:"data"`
+}
+
+type EventData struct {
+	ProjectID    string `json:"project_id"`
+	DeploymentID string `json:"deployment_id"`
+	// Status is set on status events.
+	Status string `json:"status,omitempty"`
+	// Stream and Lines are set on log events.
+	Stream    string    `json:"stream,omitempty"`
+	Lines     []string  `json:"lines,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// Publisher delivers an event to the subscribers of its project.
+type Publisher interface {
+	Publish(ctx context.Context, event Event) error
+}
+
+const channelPrefix = "deployease:events:"
+
+func channel(projectID string) string {
+	return channelPrefix + projectID
+}
@@ -0,0 +1,184 @@
+package events
+
+import (
+	"errors"
+	"sync"
+)
+
+var (
+	ErrSlowSubscriber = errors.New("subscriber fell too far behind")
+	ErrBrokerClosed   = errors.New("event broker closed")
+)
+
+// Subscriber receives the events of the projects it is subscribed to, already
+// encoded as JSON. A subscriber that lets its buffer fill up is dropped rather
+// than allowed to hold up delivery to everyone else.
+type Subscriber struct {
+	messages chan []byte
+	dropped  chan struct{}
+	err      error
+
+	// projects is guarded by the hub's mutex.
+	projects map[string]struct{}
+}
+
+func newSubscriber(buffer int) *Subscriber {
+	return &Subscriber{
+		messages: make(chan []byte, buffer),
+		dropped:  make(chan struct{}),
+		projects: make(map[string]struct{}),
+	}
+}
+
+func (s *Subscriber) Messages() <-chan []byte {
+	return s.messages
+}
+
+// Dropped is closed when the subscriber stops receiving events; Err then
+// says why.
+func (s *Subscriber) Dropped() <-chan struct{} {
+	return s.dropped
+}
+
+func (s *Subscriber) Err() error {
+	select {
+	case <-s.dropped:
+		return s.err
+	default:
+		return nil
+	}
+}
+
+// hub fans messages out to the local subscribers of each project.
+type hub struct {
+	mu          sync.Mutex
+	subscribers map[*Subscriber]struct{}
+	projects    map[string]map[*Subscriber]struct{}
+	closed      bool
+}
+
+func newHub() *hub {
+	return &hub{
+		subscribers: make(map[*Subscriber]struct{}),
+		projects:    make(map[string]map[*Subscriber]struct{}),
+	}
+}
+
+func (h *hub) register(s *Subscriber) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		return ErrBrokerClosed
+	}
+	h.subscribers[s] = struct{}{}
+	return nil
+}
+
+func (h *hub) add(s *Subscriber, projectID string) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, ok := h.subscribers[s]; !ok {
+		if err := s.Err(); err != nil {
+			return err
+		}
+		return ErrBrokerClosed
+	}
+
+	subs, ok := h.projects[projectID]
+	if !ok {
+		subs = make(map[*Subscriber]struct{})
+		h.projects[projectID] = subs
+	}
+	subs[s] = struct{}{}
+	s.projects[projectID] = struct{}{}
+	return nil
+}
+
+func (h *hub) remove(s *Subscriber, projectID string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.removeLocked(s, projectID)
+}
+
+// unregister unsubscribes s from every project, returning the projects it
+// was subscribed to.
+func (h *hub) unregister(s *Subscriber) []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	delete(h.subscribers, s)
+	projects := make([]string, 0, len(s.projects))
+	for projectID := range s.projects {
+		projects = append(projects, projectID)
+		h.removeLocked(s, projectID)
+	}
+	return projects
+}
+
+func (h *hub) removeLocked(s *Subscriber, projectID string) {
+	delete(s.projects, projectID)
+	if subs, ok := h.projects[projectID]; ok {
+		delete(subs, s)
+		if len(subs) == 0 {
+			delete(h.projects, projectID)
+		}
+	}
+}
+
+func (h *hub) has(projectID string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.projects[projectID]) > 0
+}
+
+// dispatch hands msg to every subscriber of the project without blocking,
+// dropping those whose buffers are full. It returns the projects that were
+// left without subscribers as a result.
+func (h *hub) dispatch(projectID string, msg []byte) []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var slow []*Subscriber
+	for s := range h.projects[projectID] {
+		select {
+		case s.messages <- msg:
+		default:
+			slow = append(slow, s)
+		}
+	}
+
+	var emptied []string
+	for _, s := range slow {
+		for p := range s.projects {
+			h.removeLocked(s, p)
+			if len(h.projects[p]) == 0 {
+				emptied = append(emptied, p)
+			}
+		}
+		delete(h.subscribers, s)
+		s.err = ErrSlowSubscriber
+		close(s.dropped)
+	}
+	return emptied
+}
+
+// close drops every subscriber and refuses new ones.
+func (h *hub) close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		return
+	}
+	h.closed = true
+
+	for s := range h.subscribers {
+		s.projects = make(map[string]struct{})
+		s.err = ErrBrokerClosed
+		close(s.dropped)
+	}
+	h.subscribers = make(map[*Subscriber]struct{})
+	h.projects = make(map[string]map[*Subscriber]struct{})
+}
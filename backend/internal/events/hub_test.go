@@ -0,0 +1,74 @@
+package events
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestSubscriber(t *testing.T, h *hub, buffer int) *Subscriber {
+	t.Helper()
+	s := newSubscriber(buffer)
+	require.NoError(t, h.register(s))
+	return s
+}
+
+func TestHubDispatchesToProjectSubscribers(t *testing.T) {
+	h := newHub()
+	a := newTestSubscriber(t, h, 4)
+	b := newTestSubscriber(t, h, 4)
+	require.NoError(t, h.add(a, "p1"))
+	require.NoError(t, h.add(b, "p1"))
+	require.NoError(t, h.add(b, "p2"))
+
+	assert.Empty(t, h.dispatch("p1", []byte("one")))
+	assert.Empty(t, h.dispatch("p2", []byte("two")))
+	assert.Empty(t, h.dispatch("p3", []byte("nobody")))
+
+	assert.Equal(t, []byte("one"), <-a.Messages())
+	assert.Empty(t, a.Messages())
+	assert.Equal(t, []byte("one"), <-b.Messages())
+	assert.Equal(t, []byte("two"), <-b.Messages())
+
+	h.remove(a, "p1")
+	assert.True(t, h.has("p1"))
+	assert.ElementsMatch(t, []string{"p1", "p2"}, h.unregister(b))
+	assert.False(t, h.has("p1"))
+	assert.False(t, h.has("p2"))
+}
+
+func TestHubDropsSlowSubscribers(t *testing.T) {
+	h := newHub()
+	slow := newTestSubscriber(t, h, 1)
+	fast := newTestSubscriber(t, h, 8)
+	require.NoError(t, h.add(slow, "p1"))
+	require.NoError(t, h.add(slow, "p2"))
+	require.NoError(t, h.add(fast, "p1"))
+
+	assert.Empty(t, h.dispatch("p1", []byte("one")))
+	assert.Equal(t, []string{"p2"}, h.dispatch("p1", []byte("two")),
+		"p2 loses its only subscriber")
+
+	<-slow.Dropped()
+	assert.ErrorIs(t, slow.Err(), ErrSlowSubscriber)
+	assert.Len(t, fast.Messages(), 2, "others keep receiving")
+	assert.NoError(t, fast.Err())
+	assert.ErrorIs(t, h.add(slow, "p1"), ErrSlowSubscriber)
+}
+
+func TestHubCloseDropsEveryone(t *testing.T) {
+	h := newHub()
+	idle := newTestSubscriber(t, h, 1)
+	busy := newTestSubscriber(t, h, 1)
+	require.NoError(t, h.add(busy, "p1"))
+
+	h.close()
+
+	<-idle.Dropped()
+	<-busy.Dropped()
+	assert.ErrorIs(t, idle.Err(), ErrBrokerClosed)
+	assert.ErrorIs(t, busy.Err(), ErrBrokerClosed)
+	assert.ErrorIs(t, h.register(newSubscriber(1)), ErrBrokerClosed)
+	assert.False(t, h.has("p1"))
+}
@@ -11,6 +11,137 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type CertificateStatus string
+
+const (
+	CertificateStatusPending CertificateStatus = "pending"
+	CertificateStatusIssued  CertificateStatus = "issued"
+	CertificateStatusFailed  CertificateStatus = "failed"
+)
+
+func (e *CertificateStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = CertificateStatus(s)
+	case string:
+		*e = CertificateStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for CertificateStatus: %T", src)
+	}
+	return nil
+}
+
+type NullCertificateStatus struct {
+	CertificateStatus CertificateStatus `json:"certificate_status"`
+	Valid             bool              `json:"valid"` // Valid is true if CertificateStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullCertificateStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.CertificateStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.CertificateStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullCertificateStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.CertificateStatus), nil
+}
+
+type DatabaseBackupStatus string
+
+const (
+	DatabaseBackupStatusPending   DatabaseBackupStatus = "pending"
+	DatabaseBackupStatusRunning   DatabaseBackupStatus = "running"
+	DatabaseBackupStatusSucceeded DatabaseBackupStatus = "succeeded"
+	DatabaseBackupStatusFailed    DatabaseBackupStatus = "failed"
+)
+
+func (e *DatabaseBackupStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = DatabaseBackupStatus(s)
+	case string:
+		*e = DatabaseBackupStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for DatabaseBackupStatus: %T", src)
+	}
+	return nil
+}
+
+type NullDatabaseBackupStatus struct {
+	DatabaseBackupStatus DatabaseBackupStatus `json:"database_backup_status"`
+	Valid                bool                 `json:"valid"` // Valid is true if DatabaseBackupStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullDatabaseBackupStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.DatabaseBackupStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.DatabaseBackupStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullDatabaseBackupStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.DatabaseBackupStatus), nil
+}
+
+type DatabaseRestoreStatus string
+
+const (
+	DatabaseRestoreStatusPending   DatabaseRestoreStatus = "pending"
+	DatabaseRestoreStatusRunning   DatabaseRestoreStatus = "running"
+	DatabaseRestoreStatusSucceeded DatabaseRestoreStatus = "succeeded"
+	DatabaseRestoreStatusFailed    DatabaseRestoreStatus = "failed"
+)
+
+func (e *DatabaseRestoreStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = DatabaseRestoreStatus(s)
+	case string:
+		*e = DatabaseRestoreStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for DatabaseRestoreStatus: %T", src)
+	}
+	return nil
+}
+
+type NullDatabaseRestoreStatus struct {
+	DatabaseRestoreStatus DatabaseRestoreStatus `json:"database_restore_status"`
+	Valid                 bool                  `json:"valid"` // Valid is true if DatabaseRestoreStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullDatabaseRestoreStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.DatabaseRestoreStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.DatabaseRestoreStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullDatabaseRestoreStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.DatabaseRestoreStatus), nil
+}
+
 type DeploymentStatus string
 
 const (
@@ -19,6 +150,7 @@ const (
 	DeploymentStatusSuccess    DeploymentStatus = "success"
 	DeploymentStatusFailed     DeploymentStatus = "failed"
 	DeploymentStatusCancelled  DeploymentStatus = "cancelled"
+	DeploymentStatusRolledBack DeploymentStatus = "rolled_back"
 )
 
 func (e *DeploymentStatus) Scan(src interface{}) error {
@@ -56,24 +188,340 @@ func (ns NullDeploymentStatus) Value() (driver.Value, error) {
 	return string(ns.DeploymentStatus), nil
 }
 
+type DomainStatus string
+
+const (
+	DomainStatusPending  DomainStatus = "pending"
+	DomainStatusVerified DomainStatus = "verified"
+	DomainStatusFailed   DomainStatus = "failed"
+)
+
+func (e *DomainStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = DomainStatus(s)
+	case string:
+		*e = DomainStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for DomainStatus: %T", src)
+	}
+	return nil
+}
+
+type NullDomainStatus struct {
+	DomainStatus DomainStatus `json:"domain_status"`
+	Valid        bool         `json:"valid"` // Valid is true if DomainStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullDomainStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.DomainStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.DomainStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullDomainStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.DomainStatus), nil
+}
+
+type JobStatus string
+
+const (
+	JobStatusPending   JobStatus = "pending"
+	JobStatusRunning   JobStatus = "running"
+	JobStatusCompleted JobStatus = "completed"
+	JobStatusDead      JobStatus = "dead"
+)
+
+func (e *JobStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = JobStatus(s)
+	case string:
+		*e = JobStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for JobStatus: %T", src)
+	}
+	return nil
+}
+
+type NullJobStatus struct {
+	JobStatus JobStatus `json:"job_status"`
+	Valid     bool      `json:"valid"` // Valid is true if JobStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullJobStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.JobStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.JobStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullJobStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.JobStatus), nil
+}
+
+type ManagedDatabaseStatus string
+
+const (
+	ManagedDatabaseStatusProvisioning ManagedDatabaseStatus = "provisioning"
+	ManagedDatabaseStatusRunning      ManagedDatabaseStatus = "running"
+	ManagedDatabaseStatusFailed       ManagedDatabaseStatus = "failed"
+	ManagedDatabaseStatusDeleting     ManagedDatabaseStatus = "deleting"
+)
+
+func (e *ManagedDatabaseStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = ManagedDatabaseStatus(s)
+	case string:
+		*e = ManagedDatabaseStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for ManagedDatabaseStatus: %T", src)
+	}
+	return nil
+}
+
+type NullManagedDatabaseStatus struct {
+	ManagedDatabaseStatus ManagedDatabaseStatus `json:"managed_database_status"`
+	Valid                 bool                  `json:"valid"` // Valid is true if ManagedDatabaseStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullManagedDatabaseStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.ManagedDatabaseStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.ManagedDatabaseStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullManagedDatabaseStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.ManagedDatabaseStatus), nil
+}
+
+type ManagedDatabaseType string
+
+const (
+	ManagedDatabaseTypePostgresql ManagedDatabaseType = "postgresql"
+	ManagedDatabaseTypeRedis      ManagedDatabaseType = "redis"
+)
+
+func (e *ManagedDatabaseType) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = ManagedDatabaseType(s)
+	case string:
+		*e = ManagedDatabaseType(s)
+	default:
+		return fmt.Errorf("unsupported scan type for ManagedDatabaseType: %T", src)
+	}
+	return nil
+}
+
+type NullManagedDatabaseType struct {
+	ManagedDatabaseType ManagedDatabaseType `json:"managed_database_type"`
+	Valid               bool                `json:"valid"` // Valid is true if ManagedDatabaseType is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullManagedDatabaseType) Scan(value interface{}) error {
+	if value == nil {
+		ns.ManagedDatabaseType, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.ManagedDatabaseType.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullManagedDatabaseType) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.ManagedDatabaseType), nil
+}
+
+type AcmeAccount struct {
+	DirectoryUrl string             `json:"directory_url"`
+	Uri          string             `json:"uri"`
+	Email        string             `json:"email"`
+	EncryptedKey []byte             `json:"encrypted_key"`
+	CreatedAt    pgtype.Timestamptz `json:"created_at"`
+}
+
+type AcmeChallenge struct {
+	Token            string             `json:"token"`
+	KeyAuthorization string             `json:"key_authorization"`
+	ExpiresAt        pgtype.Timestamptz `json:"expires_at"`
+}
+
+type Certificate struct {
+	DomainID       string             `json:"domain_id"`
+	Status         CertificateStatus  `json:"status"`
+	CertificatePem pgtype.Text        `json:"certificate_pem"`
+	EncryptedKey   []byte             `json:"encrypted_key"`
+	NotBefore      pgtype.Timestamptz `json:"not_before"`
+	NotAfter       pgtype.Timestamptz `json:"not_after"`
+	IssuedAt       pgtype.Timestamptz `json:"issued_at"`
+	LastError      pgtype.Text        `json:"last_error"`
+	NextAttemptAt  pgtype.Timestamptz `json:"next_attempt_at"`
+	CreatedAt      pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt      pgtype.Timestamptz `json:"updated_at"`
+}
+
+type DatabaseBackup struct {
+	ID          string               `json:"id"`
+	DatabaseID  string               `json:"database_id"`
+	Status      DatabaseBackupStatus `json:"status"`
+	ObjectKey   string               `json:"object_key"`
+	SizeBytes   int64                `json:"size_bytes"`
+	LastError   pgtype.Text          `json:"last_error"`
+	StartedAt   pgtype.Timestamptz   `json:"started_at"`
+	CompletedAt pgtype.Timestamptz   `json:"completed_at"`
+	CreatedAt   pgtype.Timestamptz   `json:"created_at"`
+	UpdatedAt   pgtype.Timestamptz   `json:"updated_at"`
+}
+
+type DatabaseRestore struct {
+	ID            string                `json:"id"`
+	DatabaseID    string                `json:"database_id"`
+	BackupID      pgtype.Text           `json:"backup_id"`
+	Status        DatabaseRestoreStatus `json:"status"`
+	BytesTotal    int64                 `json:"bytes_total"`
+	BytesRestored int64                 `json:"bytes_restored"`
+	LastError     pgtype.Text           `json:"last_error"`
+	StartedAt     pgtype.Timestamptz    `json:"started_at"`
+	CompletedAt   pgtype.Timestamptz    `json:"completed_at"`
+	CreatedAt     pgtype.Timestamptz    `json:"created_at"`
+	UpdatedAt     pgtype.Timestamptz    `json:"updated_at"`
+}
+
 type Deployment struct {
-	ID         string             `json:"id"`
-	ProjectID  string             `json:"project_id"`
-	Status     DeploymentStatus   `json:"status"`
-	CommitHash pgtype.Text        `json:"commit_hash"`
-	DeployedAt pgtype.Timestamptz `json:"deployed_at"`
-	CreatedAt  pgtype.Timestamptz `json:"created_at"`
-	UpdatedAt  pgtype.Timestamptz `json:"updated_at"`
+	ID               string             `json:"id"`
+	ProjectID        string             `json:"project_id"`
+	Status           DeploymentStatus   `json:"status"`
+	CommitHash       pgtype.Text        `json:"commit_hash"`
+	DeployedAt       pgtype.Timestamptz `json:"deployed_at"`
+	CreatedAt        pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt        pgtype.Timestamptz `json:"updated_at"`
+	ImageRef         pgtype.Text        `json:"image_ref"`
+	ContainerID      pgtype.Text        `json:"container_id"`
+	RollbackTargetID pgtype.Text        `json:"rollback_target_id"`
+	Branch           string             `json:"branch"`
+}
+
+type DeploymentLog struct {
+	ID           int64              `json:"id"`
+	DeploymentID string             `json:"deployment_id"`
+	Stream       string             `json:"stream"`
+	Level        string             `json:"level"`
+	Line         string             `json:"line"`
+	CreatedAt    pgtype.Timestamptz `json:"created_at"`
+}
+
+type Domain struct {
+	ID                    string             `json:"id"`
+	ProjectID             string             `json:"project_id"`
+	Name                  string             `json:"name"`
+	Status                DomainStatus       `json:"status"`
+	VerificationToken     string             `json:"verification_token"`
+	VerificationStartedAt pgtype.Timestamptz `json:"verification_started_at"`
+	VerifiedAt            pgtype.Timestamptz `json:"verified_at"`
+	LastCheckedAt         pgtype.Timestamptz `json:"last_checked_at"`
+	LastError             pgtype.Text        `json:"last_error"`
+	NextCheckAt           pgtype.Timestamptz `json:"next_check_at"`
+	CreatedAt             pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt             pgtype.Timestamptz `json:"updated_at"`
+}
+
+type Job struct {
+	ID          string             `json:"id"`
+	Queue       string             `json:"queue"`
+	Kind        string             `json:"kind"`
+	Payload     []byte             `json:"payload"`
+	Status      JobStatus          `json:"status"`
+	Attempts    int32              `json:"attempts"`
+	MaxAttempts int32              `json:"max_attempts"`
+	RunAt       pgtype.Timestamptz `json:"run_at"`
+	LockedBy    pgtype.Text        `json:"locked_by"`
+	LockedUntil pgtype.Timestamptz `json:"locked_until"`
+	LastError   pgtype.Text        `json:"last_error"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
+}
+
+type ManagedDatabase struct {
+	ID                        string                `json:"id"`
+	ProjectID                 string                `json:"project_id"`
+	Name                      string                `json:"name"`
+	Type                      ManagedDatabaseType   `json:"type"`
+	Version                   string                `json:"version"`
+	Size                      string                `json:"size"`
+	Status                    ManagedDatabaseStatus `json:"status"`
+	ContainerID               pgtype.Text           `json:"container_id"`
+	EncryptedConnectionString []byte                `json:"encrypted_connection_string"`
+	LastError                 pgtype.Text           `json:"last_error"`
+	CreatedAt                 pgtype.Timestamptz    `json:"created_at"`
+	UpdatedAt                 pgtype.Timestamptz    `json:"updated_at"`
+	BackupSchedule            pgtype.Text           `json:"backup_schedule"`
+	BackupRetention           int32                 `json:"backup_retention"`
+	NextBackupAt              pgtype.Timestamptz    `json:"next_backup_at"`
+}
+
+type PreviewEnvironment struct {
+	ID                 string             `json:"id"`
+	ProjectID          string             `json:"project_id"`
+	Branch             string             `json:"branch"`
+	Subdomain          string             `json:"subdomain"`
+	PullRequest        pgtype.Int4        `json:"pull_request"`
+	ActiveDeploymentID pgtype.Text        `json:"active_deployment_id"`
+	CreatedAt          pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt          pgtype.Timestamptz `json:"updated_at"`
 }
 
 type Project struct {
-	ID            string             `json:"id"`
-	Name          string             `json:"name"`
-	Description   pgtype.Text        `json:"description"`
-	RepositoryUrl string             `json:"repository_url"`
-	UserID        string             `json:"user_id"`
-	CreatedAt     pgtype.Timestamptz `json:"created_at"`
-	UpdatedAt     pgtype.Timestamptz `json:"updated_at"`
+	ID                     string             `json:"id"`
+	Name                   string             `json:"name"`
+	Description            pgtype.Text        `json:"description"`
+	RepositoryUrl          string             `json:"repository_url"`
+	UserID                 string             `json:"user_id"`
+	CreatedAt              pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt              pgtype.Timestamptz `json:"updated_at"`
+	ActiveDeploymentID     pgtype.Text        `json:"active_deployment_id"`
+	Branch                 string             `json:"branch"`
+	PreviewsEnabled        bool               `json:"previews_enabled"`
+	EncryptedWebhookSecret []byte             `json:"encrypted_webhook_secret"`
+}
+
+type ProjectEnvVar struct {
+	ProjectID      string             `json:"project_id"`
+	Key            string             `json:"key"`
+	Value          pgtype.Text        `json:"value"`
+	EncryptedValue []byte             `json:"encrypted_value"`
+	Secret         bool               `json:"secret"`
+	CreatedAt      pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt      pgtype.Timestamptz `json:"updated_at"`
+	Branch         string             `json:"branch"`
 }
 
 type User struct {
@@ -83,4 +531,5 @@ type User struct {
 	PasswordHash string             `json:"password_hash"`
 	CreatedAt    pgtype.Timestamptz `json:"created_at"`
 	UpdatedAt    pgtype.Timestamptz `json:"updated_at"`
+	IsAdmin      bool               `json:"is_admin"`
 }
@@ -6,10 +6,116 @@ package sqlc
 
 import (
 	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 type Querier interface {
+	AppendDeploymentLogs(ctx context.Context, arg AppendDeploymentLogsParams) error
+	CancelBranchDeployments(ctx context.Context, arg CancelBranchDeploymentsParams) ([]Deployment, error)
+	ClaimDueCertificates(ctx context.Context, arg ClaimDueCertificatesParams) ([]string, error)
+	ClaimDueDatabaseBackups(ctx context.Context, arg ClaimDueDatabaseBackupsParams) ([]ManagedDatabase, error)
+	ClaimDueDomainChecks(ctx context.Context, arg ClaimDueDomainChecksParams) ([]string, error)
+	ClaimJobs(ctx context.Context, arg ClaimJobsParams) ([]Job, error)
+	CompleteJob(ctx context.Context, arg CompleteJobParams) (int64, error)
+	CreateACMEAccount(ctx context.Context, arg CreateACMEAccountParams) (AcmeAccount, error)
+	CreateDatabaseBackup(ctx context.Context, arg CreateDatabaseBackupParams) (DatabaseBackup, error)
+	CreateDatabaseRestore(ctx context.Context, arg CreateDatabaseRestoreParams) (DatabaseRestore, error)
+	CreateDeployment(ctx context.Context, arg CreateDeploymentParams) (Deployment, error)
+	CreateDomain(ctx context.Context, arg CreateDomainParams) (Domain, error)
+	CreateManagedDatabase(ctx context.Context, arg CreateManagedDatabaseParams) (ManagedDatabase, error)
+	CreateMissingCertificates(ctx context.Context, nextAttemptAt pgtype.Timestamptz) (int64, error)
+	CreateProject(ctx context.Context, arg CreateProjectParams) (Project, error)
+	CreateRollbackDeployment(ctx context.Context, arg CreateRollbackDeploymentParams) (Deployment, error)
+	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
+	DeadLetterExpiredJobs(ctx context.Context, queue string) (int64, error)
+	DeleteACMEChallenge(ctx context.Context, token string) error
+	DeleteDatabaseBackup(ctx context.Context, id string) error
+	DeleteDefaultPartitionLogsBefore(ctx context.Context, before pgtype.Timestamptz) (int64, error)
+	DeleteDomainForProject(ctx context.Context, arg DeleteDomainForProjectParams) (int64, error)
+	DeleteManagedDatabase(ctx context.Context, id string) error
+	DeletePreviewEnvironment(ctx context.Context, id string) error
+	DeleteProject(ctx context.Context, arg DeleteProjectParams) (int64, error)
+	DeleteProjectEnvVar(ctx context.Context, arg DeleteProjectEnvVarParams) (int64, error)
+	EnqueueJob(ctx context.Context, arg EnqueueJobParams) (Job, error)
+	ExtendJobLock(ctx context.Context, arg ExtendJobLockParams) (int64, error)
+	GetACMEAccount(ctx context.Context, directoryUrl string) (AcmeAccount, error)
+	GetACMEChallenge(ctx context.Context, token string) (string, error)
+	GetCertificate(ctx context.Context, domainID string) (Certificate, error)
+	GetDatabaseBackup(ctx context.Context, id string) (DatabaseBackup, error)
+	GetDatabaseBackupForDatabase(ctx context.Context, arg GetDatabaseBackupForDatabaseParams) (DatabaseBackup, error)
+	GetDatabaseRestore(ctx context.Context, id string) (DatabaseRestore, error)
+	GetDatabaseRestoreForDatabase(ctx context.Context, arg GetDatabaseRestoreForDatabaseParams) (DatabaseRestore, error)
+	GetDeployment(ctx context.Context, id string) (Deployment, error)
+	GetDeploymentForProject(ctx context.Context, arg GetDeploymentForProjectParams) (Deployment, error)
+	GetDeploymentForUpdate(ctx context.Context, id string) (Deployment, error)
+	GetDomain(ctx context.Context, id string) (Domain, error)
+	GetDomainForProject(ctx context.Context, arg GetDomainForProjectParams) (Domain, error)
 	GetGreeting(ctx context.Context) (string, error)
+	GetIssuedCertificateByName(ctx context.Context, name string) (Certificate, error)
+	GetLatestDatabaseBackupBefore(ctx context.Context, arg GetLatestDatabaseBackupBeforeParams) (DatabaseBackup, error)
+	GetLatestDeploymentLogTime(ctx context.Context, arg GetLatestDeploymentLogTimeParams) (pgtype.Timestamptz, error)
+	GetManagedDatabase(ctx context.Context, id string) (ManagedDatabase, error)
+	GetManagedDatabaseForProject(ctx context.Context, arg GetManagedDatabaseForProjectParams) (ManagedDatabase, error)
+	GetPreviewEnvironmentByBranchForUpdate(ctx context.Context, arg GetPreviewEnvironmentByBranchForUpdateParams) (PreviewEnvironment, error)
+	GetPreviewEnvironmentForProject(ctx context.Context, arg GetPreviewEnvironmentForProjectParams) (PreviewEnvironment, error)
+	GetProject(ctx context.Context, id string) (Project, error)
+	GetProjectForUpdate(ctx context.Context, id string) (Project, error)
+	GetProjectForUser(ctx context.Context, arg GetProjectForUserParams) (Project, error)
+	GetUserByEmail(ctx context.Context, email string) (User, error)
+	GetUserByID(ctx context.Context, id string) (User, error)
+	GetUserByUsername(ctx context.Context, username string) (User, error)
+	IsDeploymentActive(ctx context.Context, id string) (bool, error)
+	ListDatabaseBackups(ctx context.Context, databaseID string) ([]DatabaseBackup, error)
+	ListDatabaseRestores(ctx context.Context, databaseID string) ([]DatabaseRestore, error)
+	ListDeadJobs(ctx context.Context, arg ListDeadJobsParams) ([]Job, error)
+	ListDeploymentLogPartitions(ctx context.Context) ([]string, error)
+	ListDeploymentLogs(ctx context.Context, arg ListDeploymentLogsParams) ([]DeploymentLog, error)
+	ListDeploymentLogsSince(ctx context.Context, arg ListDeploymentLogsSinceParams) ([]DeploymentLog, error)
+	ListDeploymentsForProject(ctx context.Context, arg ListDeploymentsForProjectParams) ([]Deployment, error)
+	ListDeploymentsWithContainers(ctx context.Context, arg ListDeploymentsWithContainersParams) ([]Deployment, error)
+	ListDomains(ctx context.Context, projectID string) ([]Domain, error)
+	ListExpiredDatabaseBackups(ctx context.Context, arg ListExpiredDatabaseBackupsParams) ([]DatabaseBackup, error)
+	ListManagedDatabases(ctx context.Context, projectID string) ([]ManagedDatabase, error)
+	ListPreviewEnvironments(ctx context.Context, projectID string) ([]PreviewEnvironment, error)
+	ListProjectEnvVars(ctx context.Context, arg ListProjectEnvVarsParams) ([]ProjectEnvVar, error)
+	ListProjectsByRepository(ctx context.Context, repositoryUrls []string) ([]Project, error)
+	ListProjectsForUser(ctx context.Context, arg ListProjectsForUserParams) ([]Project, error)
+	ListRouteUpstreams(ctx context.Context, previewDomain string) ([]ListRouteUpstreamsRow, error)
+	ListRunningManagedDatabases(ctx context.Context, projectID string) ([]ManagedDatabase, error)
+	MarkJobDead(ctx context.Context, arg MarkJobDeadParams) (int64, error)
+	MarkManagedDatabaseDeleting(ctx context.Context, arg MarkManagedDatabaseDeletingParams) (ManagedDatabase, error)
+	ReleaseJob(ctx context.Context, arg ReleaseJobParams) (int64, error)
+	RequeueDeadJob(ctx context.Context, id string) (int64, error)
+	ResolveProjectEnvVars(ctx context.Context, arg ResolveProjectEnvVarsParams) ([]ProjectEnvVar, error)
+	RetryJob(ctx context.Context, arg RetryJobParams) (int64, error)
+	SearchDeploymentLogs(ctx context.Context, arg SearchDeploymentLogsParams) ([]DeploymentLog, error)
+	SetDeploymentContainer(ctx context.Context, arg SetDeploymentContainerParams) (Deployment, error)
+	SetDeploymentImage(ctx context.Context, arg SetDeploymentImageParams) (Deployment, error)
+	SetManagedDatabaseContainer(ctx context.Context, arg SetManagedDatabaseContainerParams) error
+	SetManagedDatabaseNextBackup(ctx context.Context, arg SetManagedDatabaseNextBackupParams) error
+	SetPreviewEnvironmentActiveDeployment(ctx context.Context, arg SetPreviewEnvironmentActiveDeploymentParams) error
+	SetPreviewEnvironmentPullRequest(ctx context.Context, arg SetPreviewEnvironmentPullRequestParams) (PreviewEnvironment, error)
+	SetProjectActiveDeployment(ctx context.Context, arg SetProjectActiveDeploymentParams) error
+	SetProjectWebhookSecret(ctx context.Context, arg SetProjectWebhookSecretParams) (Project, error)
+	UpdateCertificateFailure(ctx context.Context, arg UpdateCertificateFailureParams) (Certificate, error)
+	UpdateCertificateIssued(ctx context.Context, arg UpdateCertificateIssuedParams) (Certificate, error)
+	UpdateDatabaseBackupFailed(ctx context.Context, arg UpdateDatabaseBackupFailedParams) (DatabaseBackup, error)
+	UpdateDatabaseBackupRunning(ctx context.Context, arg UpdateDatabaseBackupRunningParams) (DatabaseBackup, error)
+	UpdateDatabaseBackupSucceeded(ctx context.Context, arg UpdateDatabaseBackupSucceededParams) (DatabaseBackup, error)
+	UpdateDatabaseRestoreFailed(ctx context.Context, arg UpdateDatabaseRestoreFailedParams) (DatabaseRestore, error)
+	UpdateDatabaseRestoreProgress(ctx context.Context, arg UpdateDatabaseRestoreProgressParams) error
+	UpdateDatabaseRestoreRunning(ctx context.Context, arg UpdateDatabaseRestoreRunningParams) (DatabaseRestore, error)
+	UpdateDatabaseRestoreSucceeded(ctx context.Context, arg UpdateDatabaseRestoreSucceededParams) (DatabaseRestore, error)
+	UpdateDeploymentStatus(ctx context.Context, arg UpdateDeploymentStatusParams) (Deployment, error)
+	UpdateDomainVerification(ctx context.Context, arg UpdateDomainVerificationParams) (Domain, error)
+	UpdateManagedDatabaseBackupSchedule(ctx context.Context, arg UpdateManagedDatabaseBackupScheduleParams) (ManagedDatabase, error)
+	UpdateManagedDatabaseFailed(ctx context.Context, arg UpdateManagedDatabaseFailedParams) (ManagedDatabase, error)
+	UpdateManagedDatabaseRunning(ctx context.Context, arg UpdateManagedDatabaseRunningParams) (ManagedDatabase, error)
+	UpdateProject(ctx context.Context, arg UpdateProjectParams) (Project, error)
+	UpsertACMEChallenge(ctx context.Context, arg UpsertACMEChallengeParams) error
+	UpsertPreviewEnvironment(ctx context.Context, arg UpsertPreviewEnvironmentParams) (PreviewEnvironment, error)
+	UpsertProjectEnvVar(ctx context.Context, arg UpsertProjectEnvVarParams) (ProjectEnvVar, error)
 }
 
 var _ Querier = (*Queries)(nil)
@@ -3,6 +3,10 @@ package database
 import (
 	"context"
 	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 	"testing"
 	"time"
 
@@ -120,3 +124,29 @@ func setupTestDB(t *testing.T) *pgxpool.Pool {
 	})
 	return tc.Pool
 }
+
+// ApplyMigrations runs the "-- +goose Up" section of every migration in dir
+// against the test database, in filename order.
+func (tc *TestContainer) ApplyMigrations(t *testing.T, dir string) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.sql"))
+	if err != nil {
+		t.Fatalf("Failed to list migrations: %v", err)
+	}
+	sort.Strings(files)
+
+	for _, file := range files {
+		contents, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatalf("Failed to read migration %s: %v", file, err)
+		}
+
+		up := string(contents)
+		if idx := strings.Index(up, "-- +goose Down"); idx >= 0 {
+			up = up[:idx]
+		}
+
+		if _, err := tc.Pool.Exec(context.Background(), up); err != nil {
+			t.Fatalf("Failed to apply migration %s: %v", file, err)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package jobs
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+// Backoff returns how long to wait before the next try of a job that has
+// failed attempt times. The delay doubles with every attempt, is capped at max
+// and carries up to 50% jitter so jobs that failed together do not retry
+// together.
+func Backoff(attempt int, base, max time.Duration) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+
+	d := base
+	for i := 1; i < attempt && d < max; i++ {
+		d *= 2
+	}
+	if d > max {
+		d = max
+	}
+
+	half := d / 2
+	if half <= 0 {
+		return d
+	}
+	return half + rand.N(half+1)
+}
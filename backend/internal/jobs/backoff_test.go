@@ -0,0 +1,34 @@
+package jobs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBackoff(t *testing.T) {
+	base := time.Second
+	max := 30 * time.Second
+
+	tests := []struct {
+		attempt int
+		ceiling time.Duration
+	}{
+		{attempt: 0, ceiling: time.Second},
+		{attempt: 1, ceiling: time.Second},
+		{attempt: 2, ceiling: 2 * time.Second},
+		{attempt: 3, ceiling: 4 * time.Second},
+		{attempt: 5, ceiling: 16 * time.Second},
+		{attempt: 6, ceiling: 30 * time.Second},
+		{attempt: 50, ceiling: 30 * time.Second},
+	}
+
+	for _, tt := range tests {
+		for i := 0; i < 20; i++ {
+			d := Backoff(tt.attempt, base, max)
+			assert.GreaterOrEqual(t, d, tt.ceiling/2, "attempt %d", tt.attempt)
+			assert.LessOrEqual(t, d, tt.ceiling, "attempt %d", tt.attempt)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	gonanoid "github.com/matoous/go-nanoid/v2"
+
+	"github.com/Jesuloba-world/deployease/backend/internal/infrastructure/database/postgres/sqlc"
+)
+
+// DefaultQueue is the queue jobs are enqueued on and claimed from unless
+// another one is given.
+const DefaultQueue = "default"
+
+// Queue enqueues jobs for the worker pool. Jobs are rows in the jobs table, so
+// enqueueing inside a transaction only makes the job visible once the
+// transaction commits.
+type Queue struct {
+	queries     sqlc.Querier
+	maxAttempts int32
+	now         func() time.Time
+}
+
+func NewQueue(queries sqlc.Querier, maxAttempts int) *Queue {
+	return &Queue{
+		queries:     queries,
+		maxAttempts: int32(maxAttempts),
+		now:         time.Now,
+	}
+}
+
+// WithQuerier returns a copy of the queue that enqueues through the given
+// querier, typically one bound to a transaction.
+func (q *Queue) WithQuerier(queries sqlc.Querier) *Queue {
+	clone := *q
+	clone.queries = queries
+	return &clone
+}
+
+type enqueueOptions struct {
+	queue       string
+	runAt       time.Time
+	maxAttempts int32
+}
+
+type EnqueueOption func(*enqueueOptions)
+
+// OnQueue enqueues the job on a named queue instead of DefaultQueue.
+func OnQueue(name string) EnqueueOption {
+	return func(o *enqueueOptions) { o.queue = name }
+}
+
+// RunAt delays the job until the given time.
+func RunAt(t time.Time) EnqueueOption {
+	return func(o *enqueueOptions) { o.runAt = t }
+}
+
+// MaxAttempts overrides how many times the job is tried before it is
+// dead-lettered.
+func MaxAttempts(n int) EnqueueOption {
+	return func(o *enqueueOptions) { o.maxAttempts = int32(n) }
+}
+
+// Enqueue stores a job of the given kind. The payload is marshalled to JSON
+// and handed back to the handler registered for kind.
+func (q *Queue) Enqueue(ctx context.Context, kind string, payload any, opts ...EnqueueOption) (sqlc.Job, error) {
+	o := enqueueOptions{
+		queue:       DefaultQueue,
+		runAt:       q.now(),
+		maxAttempts: q.maxAttempts,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return sqlc.Job{}, fmt.Errorf("failed to encode job payload: %w", err)
+	}
+
+	job, err := q.queries.EnqueueJob(ctx, sqlc.EnqueueJobParams{
+		ID:          gonanoid.Must(),
+		Queue:       o.queue,
+		Kind:        kind,
+		Payload:     data,
+		MaxAttempts: o.maxAttempts,
+		RunAt:       pgtype.Timestamptz{Time: o.runAt, Valid: true},
+	})
+	if err != nil {
+		return sqlc.Job{}, fmt.Errorf("failed to enqueue job: %w", err)
+	}
+
+	return job, nil
+}
+
+// ListDead returns the most recently dead-lettered jobs on a queue.
+func (q *Queue) ListDead(ctx context.Context, queue string, limit int) ([]sqlc.Job, error) {
+	jobs, err := q.queries.ListDeadJobs(ctx, sqlc.ListDeadJobsParams{
+		Queue: queue,
+		Limit: int32(limit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list dead jobs: %w", err)
+	}
+	return jobs, nil
+}
+
+// Requeue moves a dead job back to pending with its attempts reset.
+func (q *Queue) Requeue(ctx context.Context, jobID string) error {
+	n, err := q.queries.RequeueDeadJob(ctx, jobID)
+	if err != nil {
+		return fmt.Errorf("failed to requeue job: %w", err)
+	}
+	if n == 0 {
+		return ErrJobNotFound
+	}
+	return nil
+}
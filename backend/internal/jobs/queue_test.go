@@ -0,0 +1,159 @@
+package jobs
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
+
+	database "github.com/Jesuloba-world/deployease/backend/internal/infrastructure/database/postgres"
+	"github.com/Jesuloba-world/deployease/backend/internal/infrastructure/database/postgres/sqlc"
+)
+
+func setupQueue(t *testing.T) (*Queue, *sqlc.Queries) {
+	t.Helper()
+	testcontainers.SkipIfProviderIsNotHealthy(t)
+
+	tc := database.SetupTestContainer(t)
+	t.Cleanup(func() { tc.Cleanup(t) })
+	tc.ApplyMigrations(t, "../../sql/migrations")
+
+	queries := sqlc.New(tc.Pool)
+	return NewQueue(queries, 3), queries
+}
+
+func claim(t *testing.T, queries *sqlc.Queries, worker string, until time.Time, n int32) []sqlc.Job {
+	t.Helper()
+	jobs, err := queries.ClaimJobs(context.Background(), sqlc.ClaimJobsParams{
+		WorkerID:    pgtype.Text{String: worker, Valid: true},
+		LockedUntil: pgtype.Timestamptz{Time: until, Valid: true},
+		Queue:       DefaultQueue,
+		BatchSize:   n,
+	})
+	require.NoError(t, err)
+	return jobs
+}
+
+func TestQueueClaimSkipsLockedJobs(t *testing.T) {
+	ctx := context.Background()
+	queue, queries := setupQueue(t)
+
+	for i := 0; i < 3; i++ {
+		_, err := queue.Enqueue(ctx, "test", map[string]int{"n": i})
+		require.NoError(t, err)
+	}
+	_, err := queue.Enqueue(ctx, "test", nil, RunAt(time.Now().Add(time.Hour)))
+	require.NoError(t, err)
+
+	later := time.Now().Add(time.Minute)
+	first := claim(t, queries, "w1", later, 2)
+	second := claim(t, queries, "w2", later, 5)
+
+	require.Len(t, first, 2)
+	require.Len(t, second, 1, "delayed and already-claimed jobs must not be handed out")
+	for _, j := range first {
+		assert.NotEqual(t, second[0].ID, j.ID)
+		assert.Equal(t, sqlc.JobStatusRunning, j.Status)
+		assert.Equal(t, int32(1), j.Attempts)
+	}
+}
+
+func TestQueueReclaimsExpiredLocks(t *testing.T) {
+	ctx := context.Background()
+	queue, queries := setupQueue(t)
+
+	job, err := queue.Enqueue(ctx, "test", nil)
+	require.NoError(t, err)
+
+	claimed := claim(t, queries, "w1", time.Now().Add(-time.Second), 1)
+	require.Len(t, claimed, 1)
+
+	reclaimed := claim(t, queries, "w2", time.Now().Add(time.Minute), 1)
+	require.Len(t, reclaimed, 1)
+	assert.Equal(t, job.ID, reclaimed[0].ID)
+	assert.Equal(t, int32(2), reclaimed[0].Attempts)
+
+	// The first worker no longer owns the job and cannot finish it.
+	n, err := queries.CompleteJob(ctx, sqlc.CompleteJobParams{
+		ID:       job.ID,
+		WorkerID: pgtype.Text{String: "w1", Valid: true},
+	})
+	require.NoError(t, err)
+	assert.Zero(t, n)
+}
+
+func TestQueueDeadLettersExpiredFinalAttempt(t *testing.T) {
+	ctx := context.Background()
+	queue, queries := setupQueue(t)
+
+	job, err := queue.Enqueue(ctx, "test", nil, MaxAttempts(1))
+	require.NoError(t, err)
+	require.Len(t, claim(t, queries, "w1", time.Now().Add(-time.Second), 1), 1)
+
+	// The worker died on the only attempt, so nobody may claim the job again.
+	assert.Empty(t, claim(t, queries, "w2", time.Now().Add(time.Minute), 1))
+
+	n, err := queries.DeadLetterExpiredJobs(ctx, DefaultQueue)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+
+	dead, err := queue.ListDead(ctx, DefaultQueue, 10)
+	require.NoError(t, err)
+	require.Len(t, dead, 1)
+	assert.Equal(t, job.ID, dead[0].ID)
+}
+
+func TestQueueReleaseGivesBackAttempt(t *testing.T) {
+	ctx := context.Background()
+	queue, queries := setupQueue(t)
+
+	job, err := queue.Enqueue(ctx, "test", nil)
+	require.NoError(t, err)
+	claim(t, queries, "w1", time.Now().Add(time.Minute), 1)
+
+	n, err := queries.ReleaseJob(ctx, sqlc.ReleaseJobParams{
+		RunAt:     pgtype.Timestamptz{Time: time.Now(), Valid: true},
+		LastError: pgtype.Text{String: "shutting down", Valid: true},
+		ID:        job.ID,
+		WorkerID:  pgtype.Text{String: "w1", Valid: true},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+
+	claimed := claim(t, queries, "w2", time.Now().Add(time.Minute), 1)
+	require.Len(t, claimed, 1)
+	assert.Equal(t, int32(1), claimed[0].Attempts)
+}
+
+func TestQueueDeadLetterAndRequeue(t *testing.T) {
+	ctx := context.Background()
+	queue, queries := setupQueue(t)
+
+	job, err := queue.Enqueue(ctx, "test", nil)
+	require.NoError(t, err)
+	claim(t, queries, "w1", time.Now().Add(time.Minute), 1)
+
+	n, err := queries.MarkJobDead(ctx, sqlc.MarkJobDeadParams{
+		LastError: pgtype.Text{String: "boom", Valid: true},
+		ID:        job.ID,
+		WorkerID:  pgtype.Text{String: "w1", Valid: true},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+
+	dead, err := queue.ListDead(ctx, DefaultQueue, 10)
+	require.NoError(t, err)
+	require.Len(t, dead, 1)
+	assert.Equal(t, "boom", dead[0].LastError.String)
+
+	require.NoError(t, queue.Requeue(ctx, job.ID))
+	assert.ErrorIs(t, queue.Requeue(ctx, job.ID), ErrJobNotFound)
+
+	claimed := claim(t, queries, "w2", time.Now().Add(time.Minute), 1)
+	require.Len(t, claimed, 1)
+	assert.Equal(t, int32(1), claimed[0].Attempts)
+}
@@ -0,0 +1,381 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	gonanoid "github.com/matoous/go-nanoid/v2"
+
+	"github.com/Jesuloba-world/deployease/backend/internal/config"
+	"github.com/Jesuloba-world/deployease/backend/internal/infrastructure/database/postgres/sqlc"
+)
+
+var (
+	ErrJobNotFound      = errors.New("job not found")
+	ErrWorkerStarted    = errors.New("worker already started")
+	ErrNoHandler        = errors.New("no handler registered for job kind")
+	ErrLockLost         = errors.New("job lock lost to another worker")
+	errPermanentFailure = errors.New("permanent job failure")
+)
+
+// Handler processes a single job. Returning an error schedules a retry unless
+// the error is wrapped with Permanent or the job is out of attempts. The
+// context is cancelled if the worker loses the job's lock or shutdown runs
+// out of time.
+type Handler func(ctx context.Context, job sqlc.Job) error
+
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+func (e *permanentError) Is(target error) bool {
+	return target == errPermanentFailure
+}
+
+// Permanent marks err as not worth retrying, so the job is dead-lettered
+// straight away.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// Worker claims jobs from a single queue and runs them on a bounded pool of
+// goroutines. A claimed job is locked until its visibility timeout; the
+// worker keeps extending the lock while the handler runs, so a job whose
+// worker crashed becomes claimable again once the lock expires.
+type Worker struct {
+	queries  sqlc.Querier
+	cfg      config.JobsConfig
+	queue    string
+	id       string
+	handlers map[string]Handler
+	now      func() time.Time
+
+	slots   chan struct{}
+	wg      sync.WaitGroup
+	mu      sync.Mutex
+	started bool
+
+	stopPolling context.CancelFunc
+	pollerDone  chan struct{}
+	cancelJobs  context.CancelFunc
+}
+
+func NewWorker(queries sqlc.Querier, cfg config.JobsConfig) *Worker {
+	return &Worker{
+		queries:  queries,
+		cfg:      cfg,
+		queue:    DefaultQueue,
+		id:       workerID(),
+		handlers: make(map[string]Handler),
+		now:      time.Now,
+		slots:    make(chan struct{}, cfg.Concurrency),
+	}
+}
+
+// Register sets the handler for a job kind. It must be called before Start.
+func (w *Worker) Register(kind string, handler Handler) {
+	w.handlers[kind] = handler
+}
+
+// Start launches the polling loop. Jobs keep running until Shutdown is called;
+// ctx only carries values to the handlers.
+func (w *Worker) Start(ctx context.Context) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.started {
+		return ErrWorkerStarted
+	}
+	w.started = true
+
+	pollCtx, stopPolling := context.WithCancel(ctx)
+	jobCtx, cancelJobs := context.WithCancel(context.WithoutCancel(ctx))
+	w.stopPolling = stopPolling
+	w.cancelJobs = cancelJobs
+	w.pollerDone = make(chan struct{})
+
+	go w.poll(pollCtx, jobCtx)
+
+	log.Printf("Job worker %s started with concurrency %d", w.id, w.cfg.Concurrency)
+	return nil
+}
+
+// Shutdown stops claiming new jobs and waits for running ones to finish. If
+// ctx expires first, running handlers are cancelled and their jobs are put
+// back on the queue.
+func (w *Worker) Shutdown(ctx context.Context) error {
+	w.mu.Lock()
+	started := w.started
+	w.mu.Unlock()
+	if !started {
+		return nil
+	}
+
+	w.stopPolling()
+	<-w.pollerDone
+
+	done := make(chan struct{})
+	go func() {
+		w.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		w.cancelJobs()
+		return nil
+	case <-ctx.Done():
+		w.cancelJobs()
+		<-done
+		return fmt.Errorf("job worker did not drain in time: %w", ctx.Err())
+	}
+}
+
+func (w *Worker) poll(ctx, jobCtx context.Context) {
+	defer close(w.pollerDone)
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		w.sweepExpired(ctx)
+		full, err := w.claimAndRun(ctx, jobCtx)
+		if err != nil && ctx.Err() == nil {
+			log.Printf("Job worker %s failed to claim jobs: %v", w.id, err)
+		}
+
+		// A full batch means there is probably more work waiting, so poll
+		// again as soon as a slot frees up instead of sleeping.
+		if full {
+			timer.Reset(0)
+		} else {
+			timer.Reset(w.cfg.PollInterval)
+		}
+	}
+}
+
+// sweepExpired dead-letters jobs whose worker let the lock expire on their
+// final attempt. Claiming skips them, so without this they would sit in
+// running forever.
+func (w *Worker) sweepExpired(ctx context.Context) {
+	n, err := w.queries.DeadLetterExpiredJobs(ctx, w.queue)
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Printf("Job worker %s failed to dead-letter expired jobs: %v", w.id, err)
+		}
+		return
+	}
+	if n > 0 {
+		log.Printf("Job worker %s dead-lettered %d job(s) whose lock expired on their final attempt", w.id, n)
+	}
+}
+
+// claimAndRun waits for at least one free slot, claims up to as many jobs as
+// there are free slots and runs them. It reports whether every slot it asked
+// for was filled.
+func (w *Worker) claimAndRun(ctx, jobCtx context.Context) (bool, error) {
+	select {
+	case w.slots <- struct{}{}:
+	case <-ctx.Done():
+		return false, ctx.Err()
+	}
+	free := 1
+fill:
+	for free < cap(w.slots) {
+		select {
+		case w.slots <- struct{}{}:
+			free++
+		default:
+			break fill
+		}
+	}
+
+	claimed, err := w.queries.ClaimJobs(ctx, sqlc.ClaimJobsParams{
+		WorkerID:    pgtype.Text{String: w.id, Valid: true},
+		LockedUntil: w.lockDeadline(),
+		Queue:       w.queue,
+		BatchSize:   int32(free),
+	})
+	if err != nil {
+		claimed = nil
+	}
+
+	for i := len(claimed); i < free; i++ {
+		<-w.slots
+	}
+
+	for _, job := range claimed {
+		w.wg.Add(1)
+		go func(job sqlc.Job) {
+			defer w.wg.Done()
+			defer func() { <-w.slots }()
+			w.process(jobCtx, job)
+		}(job)
+	}
+
+	return err == nil && len(claimed) == free, err
+}
+
+// process runs the job's handler while keeping its lock alive, then records
+// the outcome.
+func (w *Worker) process(ctx context.Context, job sqlc.Job) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	heartbeatDone := make(chan struct{})
+	go func() {
+		defer close(heartbeatDone)
+		w.heartbeat(ctx, cancel, job.ID)
+	}()
+
+	err := w.run(ctx, job)
+	lockLost := errors.Is(context.Cause(ctx), ErrLockLost)
+	interrupted := ctx.Err() != nil && !lockLost
+	cancel(nil)
+	<-heartbeatDone
+
+	if lockLost {
+		// Another worker has already reclaimed the job; whatever we record
+		// now would clobber its state.
+		log.Printf("Job %s (%s) lost its lock on worker %s", job.ID, job.Kind, w.id)
+		return
+	}
+
+	// The outcome must be recorded even when shutdown cancelled the handler.
+	ctx = context.WithoutCancel(ctx)
+	if err != nil && interrupted {
+		// Shutdown cut the handler short; that is not the job's fault, so
+		// hand it straight back without backing off or dead-lettering it.
+		err = w.release(ctx, job, err)
+	} else {
+		err = w.finish(ctx, job, err)
+	}
+	if err != nil {
+		log.Printf("Failed to record result of job %s (%s): %v", job.ID, job.Kind, err)
+	}
+}
+
+func (w *Worker) run(ctx context.Context, job sqlc.Job) (err error) {
+	handler, ok := w.handlers[job.Kind]
+	if !ok {
+		return Permanent(fmt.Errorf("%w: %s", ErrNoHandler, job.Kind))
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+
+	return handler(ctx, job)
+}
+
+func (w *Worker) finish(ctx context.Context, job sqlc.Job, err error) error {
+	workerID := pgtype.Text{String: w.id, Valid: true}
+
+	if err == nil {
+		_, err := w.queries.CompleteJob(ctx, sqlc.CompleteJobParams{
+			ID:       job.ID,
+			WorkerID: workerID,
+		})
+		return err
+	}
+
+	lastError := pgtype.Text{String: err.Error(), Valid: true}
+
+	if errors.Is(err, errPermanentFailure) || job.Attempts >= job.MaxAttempts {
+		log.Printf("Job %s (%s) dead-lettered after %d attempts: %v", job.ID, job.Kind, job.Attempts, err)
+		_, err := w.queries.MarkJobDead(ctx, sqlc.MarkJobDeadParams{
+			LastError: lastError,
+			ID:        job.ID,
+			WorkerID:  workerID,
+		})
+		return err
+	}
+
+	runAt := w.now().Add(Backoff(int(job.Attempts), w.cfg.BaseBackoff, w.cfg.MaxBackoff))
+	log.Printf("Job %s (%s) failed attempt %d/%d, retrying at %s: %v",
+		job.ID, job.Kind, job.Attempts, job.MaxAttempts, runAt.Format(time.RFC3339), err)
+	_, err = w.queries.RetryJob(ctx, sqlc.RetryJobParams{
+		RunAt:     pgtype.Timestamptz{Time: runAt, Valid: true},
+		LastError: lastError,
+		ID:        job.ID,
+		WorkerID:  workerID,
+	})
+	return err
+}
+
+// release puts an interrupted job back on the queue to run again right away,
+// giving back the attempt its claim used up.
+func (w *Worker) release(ctx context.Context, job sqlc.Job, cause error) error {
+	_, err := w.queries.ReleaseJob(ctx, sqlc.ReleaseJobParams{
+		RunAt:     pgtype.Timestamptz{Time: w.now(), Valid: true},
+		LastError: pgtype.Text{String: cause.Error(), Valid: true},
+		ID:        job.ID,
+		WorkerID:  pgtype.Text{String: w.id, Valid: true},
+	})
+	return err
+}
+
+// heartbeat extends the job's lock at half the visibility timeout until ctx is
+// done, cancelling the job if the lock has been taken over.
+func (w *Worker) heartbeat(ctx context.Context, cancel context.CancelCauseFunc, jobID string) {
+	ticker := time.NewTicker(w.cfg.VisibilityTimeout / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		n, err := w.queries.ExtendJobLock(ctx, sqlc.ExtendJobLockParams{
+			LockedUntil: w.lockDeadline(),
+			ID:          jobID,
+			WorkerID:    pgtype.Text{String: w.id, Valid: true},
+		})
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Printf("Failed to extend lock on job %s: %v", jobID, err)
+			}
+			continue
+		}
+		if n == 0 {
+			cancel(ErrLockLost)
+			return
+		}
+	}
+}
+
+func (w *Worker) lockDeadline() pgtype.Timestamptz {
+	return pgtype.Timestamptz{Time: w.now().Add(w.cfg.VisibilityTimeout), Valid: true}
+}
+
+func workerID() string {
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		host = "worker"
+	}
+	if len(host) > 40 {
+		host = host[:40]
+	}
+	return fmt.Sprintf("%s-%s", host, gonanoid.Must(12))
+}
@@ -0,0 +1,242 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Jesuloba-world/deployease/backend/internal/config"
+	"github.com/Jesuloba-world/deployease/backend/internal/infrastructure/database/postgres/sqlc"
+)
+
+// fakeQuerier hands out queued jobs once and records how each one ended.
+type fakeQuerier struct {
+	sqlc.Querier
+
+	mu        sync.Mutex
+	pending   []sqlc.Job
+	completed []string
+	retried   map[string]sqlc.RetryJobParams
+	released  map[string]sqlc.ReleaseJobParams
+	dead      map[string]string
+	extended  int
+	swept     int
+}
+
+func newFakeQuerier(jobs ...sqlc.Job) *fakeQuerier {
+	return &fakeQuerier{
+		pending:  jobs,
+		retried:  make(map[string]sqlc.RetryJobParams),
+		released: make(map[string]sqlc.ReleaseJobParams),
+		dead:     make(map[string]string),
+	}
+}
+
+func (f *fakeQuerier) ClaimJobs(ctx context.Context, arg sqlc.ClaimJobsParams) ([]sqlc.Job, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	n := min(int(arg.BatchSize), len(f.pending))
+	claimed := f.pending[:n]
+	f.pending = f.pending[n:]
+	for i := range claimed {
+		claimed[i].Attempts++
+		claimed[i].LockedBy = arg.WorkerID
+	}
+	return claimed, nil
+}
+
+func (f *fakeQuerier) CompleteJob(ctx context.Context, arg sqlc.CompleteJobParams) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.completed = append(f.completed, arg.ID)
+	return 1, nil
+}
+
+func (f *fakeQuerier) RetryJob(ctx context.Context, arg sqlc.RetryJobParams) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.retried[arg.ID] = arg
+	return 1, nil
+}
+
+func (f *fakeQuerier) ReleaseJob(ctx context.Context, arg sqlc.ReleaseJobParams) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.released[arg.ID] = arg
+	return 1, nil
+}
+
+func (f *fakeQuerier) DeadLetterExpiredJobs(ctx context.Context, queue string) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.swept++
+	return 0, nil
+}
+
+func (f *fakeQuerier) MarkJobDead(ctx context.Context, arg sqlc.MarkJobDeadParams) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.dead[arg.ID] = arg.LastError.String
+	return 1, nil
+}
+
+func (f *fakeQuerier) ExtendJobLock(ctx context.Context, arg sqlc.ExtendJobLockParams) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.extended++
+	return 1, nil
+}
+
+func testConfig() config.JobsConfig {
+	return config.JobsConfig{
+		Concurrency:       2,
+		PollInterval:      10 * time.Millisecond,
+		VisibilityTimeout: time.Minute,
+		MaxAttempts:       3,
+		BaseBackoff:       time.Second,
+		MaxBackoff:        time.Minute,
+	}
+}
+
+func job(id, kind string, attempts, maxAttempts int32) sqlc.Job {
+	return sqlc.Job{ID: id, Kind: kind, Attempts: attempts, MaxAttempts: maxAttempts}
+}
+
+func runUntilDrained(t *testing.T, w *Worker, q *fakeQuerier) {
+	t.Helper()
+	require.NoError(t, w.Start(context.Background()))
+	require.Eventually(t, func() bool {
+		q.mu.Lock()
+		defer q.mu.Unlock()
+		return len(q.pending) == 0
+	}, time.Second, 5*time.Millisecond)
+	require.NoError(t, w.Shutdown(context.Background()))
+}
+
+func TestWorkerOutcomes(t *testing.T) {
+	q := newFakeQuerier(
+		job("ok", "succeed", 0, 3),
+		job("retry", "fail", 0, 3),
+		job("last", "fail", 2, 3),
+		job("permanent", "permanent", 0, 3),
+		job("panic", "panic", 0, 3),
+		job("unknown", "missing", 0, 3),
+	)
+
+	w := NewWorker(q, testConfig())
+	now := time.Date(2025, 6, 24, 12, 0, 0, 0, time.UTC)
+	w.now = func() time.Time { return now }
+	w.Register("succeed", func(ctx context.Context, job sqlc.Job) error { return nil })
+	w.Register("fail", func(ctx context.Context, job sqlc.Job) error { return errors.New("boom") })
+	w.Register("permanent", func(ctx context.Context, job sqlc.Job) error {
+		return Permanent(errors.New("bad payload"))
+	})
+	w.Register("panic", func(ctx context.Context, job sqlc.Job) error { panic("oops") })
+
+	runUntilDrained(t, w, q)
+
+	assert.Equal(t, []string{"ok"}, q.completed)
+
+	require.Contains(t, q.retried, "retry")
+	retry := q.retried["retry"]
+	assert.Equal(t, "boom", retry.LastError.String)
+	assert.False(t, retry.RunAt.Time.Before(now.Add(500*time.Millisecond)))
+	assert.False(t, retry.RunAt.Time.After(now.Add(time.Second)))
+	assert.Contains(t, q.retried, "panic")
+
+	assert.Equal(t, "boom", q.dead["last"])
+	assert.Equal(t, "bad payload", q.dead["permanent"])
+	assert.Contains(t, q.dead["unknown"], ErrNoHandler.Error())
+	assert.NotContains(t, q.dead, "retry")
+	assert.Positive(t, q.swept, "each poll dead-letters expired final attempts")
+}
+
+func TestWorkerRespectsConcurrency(t *testing.T) {
+	var jobs []sqlc.Job
+	for _, id := range []string{"a", "b", "c", "d", "e"} {
+		jobs = append(jobs, job(id, "slow", 0, 3))
+	}
+	q := newFakeQuerier(jobs...)
+
+	var mu sync.Mutex
+	running, peak := 0, 0
+	w := NewWorker(q, testConfig())
+	w.Register("slow", func(ctx context.Context, job sqlc.Job) error {
+		mu.Lock()
+		running++
+		peak = max(peak, running)
+		mu.Unlock()
+
+		time.Sleep(20 * time.Millisecond)
+
+		mu.Lock()
+		running--
+		mu.Unlock()
+		return nil
+	})
+
+	runUntilDrained(t, w, q)
+
+	assert.Len(t, q.completed, 5)
+	assert.LessOrEqual(t, peak, 2)
+}
+
+func TestWorkerShutdownDrainsRunningJobs(t *testing.T) {
+	q := newFakeQuerier(job("long", "long", 0, 3))
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	w := NewWorker(q, testConfig())
+	w.Register("long", func(ctx context.Context, job sqlc.Job) error {
+		close(started)
+		<-release
+		return nil
+	})
+
+	require.NoError(t, w.Start(context.Background()))
+	<-started
+
+	shutdownErr := make(chan error, 1)
+	go func() { shutdownErr <- w.Shutdown(context.Background()) }()
+
+	select {
+	case <-shutdownErr:
+		t.Fatal("shutdown returned before the running job finished")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(release)
+	require.NoError(t, <-shutdownErr)
+	assert.Equal(t, []string{"long"}, q.completed)
+}
+
+func TestWorkerShutdownTimeoutReleasesJobs(t *testing.T) {
+	q := newFakeQuerier(job("stuck", "stuck", 3, 3))
+
+	started := make(chan struct{})
+	w := NewWorker(q, testConfig())
+	w.Register("stuck", func(ctx context.Context, job sqlc.Job) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	require.NoError(t, w.Start(context.Background()))
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err := w.Shutdown(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// Interrupted jobs go back to the queue even on their last attempt.
+	assert.Contains(t, q.released, "stuck")
+	assert.NotContains(t, q.retried, "stuck")
+	assert.NotContains(t, q.dead, "stuck")
+}
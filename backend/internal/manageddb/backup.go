@@ -0,0 +1,375 @@
+package manageddb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	gonanoid "github.com/matoous/go-nanoid/v2"
+
+	"github.com/Jesuloba-world/deployease/backend/internal/infrastructure/database/postgres/sqlc"
+	"github.com/Jesuloba-world/deployease/backend/internal/jobs"
+)
+
+// BackupJobKind dumps a database into the object store.
+const BackupJobKind = "database.backup"
+
+const (
+	// backupBatchSize caps how many databases are claimed per query.
+	backupBatchSize = 100
+
+	maxBackupRetention = 365
+)
+
+var (
+	ErrBackupsUnsupported = errors.New("backups are only available for postgresql databases")
+	ErrNotRunning         = errors.New("database is not running")
+	ErrInvalidRetention   = errors.New("backup retention must be between 1 and 365")
+	ErrBackupNotFound     = errors.New("backup not found")
+)
+
+type BackupPayload struct {
+	BackupID string `json:"backup_id"`
+}
+
+// BackupSettings changes a database's backup schedule. Nil fields are left
+// as they are; an empty schedule turns scheduled backups off.
+type BackupSettings struct {
+	Schedule  *string
+	Retention *int32
+}
+
+// UpdateBackupSettings sets when a database is backed up and how many of
+// its successful backups are kept.
+func (s *Service) UpdateBackupSettings(ctx context.Context, projectID, databaseID string, in BackupSettings) (sqlc.ManagedDatabase, error) {
+	db, err := s.Get(ctx, projectID, databaseID)
+	if err != nil {
+		return sqlc.ManagedDatabase{}, err
+	}
+	if db.Type != sqlc.ManagedDatabaseTypePostgresql {
+		return sqlc.ManagedDatabase{}, ErrBackupsUnsupported
+	}
+
+	params := sqlc.UpdateManagedDatabaseBackupScheduleParams{
+		BackupSchedule:  db.BackupSchedule,
+		BackupRetention: db.BackupRetention,
+		ID:              db.ID,
+		ProjectID:       projectID,
+	}
+	if in.Schedule != nil {
+		params.BackupSchedule = pgtype.Text{String: *in.Schedule, Valid: *in.Schedule != ""}
+	}
+	if in.Retention != nil {
+		if *in.Retention < 1 || *in.Retention > maxBackupRetention {
+			return sqlc.ManagedDatabase{}, ErrInvalidRetention
+		}
+		params.BackupRetention = *in.Retention
+	}
+	if params.BackupSchedule.Valid {
+		schedule, err := ParseSchedule(params.BackupSchedule.String)
+		if err != nil {
+			return sqlc.ManagedDatabase{}, err
+		}
+		if next := schedule.Next(s.now()); !next.IsZero() {
+			params.NextBackupAt = timestamp(next)
+		}
+	}
+
+	db, err = s.queries.UpdateManagedDatabaseBackupSchedule(ctx, params)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return sqlc.ManagedDatabase{}, ErrNotFound
+		}
+		return sqlc.ManagedDatabase{}, fmt.Errorf("failed to update backup schedule: %w", err)
+	}
+	return db, nil
+}
+
+// CreateBackup queues a backup of a running database outside its schedule.
+func (s *Service) CreateBackup(ctx context.Context, projectID, databaseID string) (sqlc.DatabaseBackup, error) {
+	db, err := s.Get(ctx, projectID, databaseID)
+	if err != nil {
+		return sqlc.DatabaseBackup{}, err
+	}
+	if db.Type != sqlc.ManagedDatabaseTypePostgresql {
+		return sqlc.DatabaseBackup{}, ErrBackupsUnsupported
+	}
+	if db.Status != sqlc.ManagedDatabaseStatusRunning {
+		return sqlc.DatabaseBackup{}, ErrNotRunning
+	}
+	return s.startBackup(ctx, db)
+}
+
+// ListBackups returns a database's backups, newest first.
+func (s *Service) ListBackups(ctx context.Context, projectID, databaseID string) ([]sqlc.DatabaseBackup, error) {
+	db, err := s.Get(ctx, projectID, databaseID)
+	if err != nil {
+		return nil, err
+	}
+	backups, err := s.queries.ListDatabaseBackups(ctx, db.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list backups: %w", err)
+	}
+	return backups, nil
+}
+
+// startBackup records a pending backup and queues the job taking it.
+func (s *Service) startBackup(ctx context.Context, db sqlc.ManagedDatabase) (sqlc.DatabaseBackup, error) {
+	var backup sqlc.DatabaseBackup
+	err := s.withTx(ctx, func(q sqlc.Querier) error {
+		id := gonanoid.Must()
+		var err error
+		backup, err = q.CreateDatabaseBackup(ctx, sqlc.CreateDatabaseBackupParams{
+			ID:         id,
+			DatabaseID: db.ID,
+			ObjectKey:  backupKey(db.ID, id),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create backup: %w", err)
+		}
+
+		_, err = s.queue.WithQuerier(q).Enqueue(ctx, BackupJobKind, BackupPayload{BackupID: backup.ID})
+		return err
+	})
+	if err != nil {
+		return sqlc.DatabaseBackup{}, err
+	}
+	return backup, nil
+}
+
+// HandleBackup dumps the database of the backup named in the job payload
+// and uploads the dump. A dump or upload that fails fails the backup rather
+// than the job. Once it has succeeded, backups beyond the database's
+// retention are deleted.
+func (s *Service) HandleBackup(ctx context.Context, job sqlc.Job) error {
+	var payload BackupPayload
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return jobs.Permanent(fmt.Errorf("invalid database backup payload: %w", err))
+	}
+
+	backup, err := s.queries.GetDatabaseBackup(ctx, payload.BackupID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		return fmt.Errorf("failed to get backup: %w", err)
+	}
+	if backup.Status == sqlc.DatabaseBackupStatusSucceeded || backup.Status == sqlc.DatabaseBackupStatusFailed {
+		return nil
+	}
+
+	db, err := s.queries.GetManagedDatabase(ctx, backup.DatabaseID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		return fmt.Errorf("failed to get database: %w", err)
+	}
+	if db.Status != sqlc.ManagedDatabaseStatusRunning {
+		return s.failBackup(ctx, backup.ID, ErrNotRunning)
+	}
+
+	_, err = s.queries.UpdateDatabaseBackupRunning(ctx, sqlc.UpdateDatabaseBackupRunningParams{
+		StartedAt: timestamp(s.now()),
+		ID:        backup.ID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		return fmt.Errorf("failed to update backup: %w", err)
+	}
+
+	size, err := s.backup(ctx, db, backup.ObjectKey)
+	if err != nil {
+		log.Printf("Failed to back up database %s: %v", db.ID, err)
+		return s.failBackup(ctx, backup.ID, err)
+	}
+
+	_, err = s.queries.UpdateDatabaseBackupSucceeded(ctx, sqlc.UpdateDatabaseBackupSucceededParams{
+		SizeBytes:   size,
+		CompletedAt: timestamp(s.now()),
+		ID:          backup.ID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update backup: %w", err)
+	}
+
+	// The backup is done either way; retention catches up after the next.
+	if err := s.enforceRetention(ctx, db); err != nil {
+		log.Printf("Failed to delete expired backups of database %s: %v", db.ID, err)
+	}
+	return nil
+}
+
+// backup dumps the database to a temporary file, whose size the object
+// store needs up front, and uploads it under key.
+func (s *Service) backup(ctx context.Context, db sqlc.ManagedDatabase, key string) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.backupsCfg.Timeout)
+	defer cancel()
+
+	f, err := os.CreateTemp("", "deployease-backup-*")
+	if err != nil {
+		return 0, fmt.Errorf("failed to create dump file: %w", err)
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	if err := s.dumper.Dump(ctx, db, f); err != nil {
+		return 0, err
+	}
+	size, err := f.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read dump file: %w", err)
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return 0, fmt.Errorf("failed to read dump file: %w", err)
+	}
+
+	if err := s.store.Put(ctx, key, f, size); err != nil {
+		return 0, err
+	}
+	return size, nil
+}
+
+func (s *Service) failBackup(ctx context.Context, backupID string, cause error) error {
+	_, err := s.queries.UpdateDatabaseBackupFailed(context.WithoutCancel(ctx), sqlc.UpdateDatabaseBackupFailedParams{
+		LastError:   pgtype.Text{String: cause.Error(), Valid: true},
+		CompletedAt: timestamp(s.now()),
+		ID:          backupID,
+	})
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("failed to update backup: %w", err)
+	}
+	return nil
+}
+
+// enforceRetention deletes the database's backups older than its newest
+// backup_retention successful ones, object first so no object outlives its
+// row. Backups a restore is reading are kept until it is done.
+func (s *Service) enforceRetention(ctx context.Context, db sqlc.ManagedDatabase) error {
+	expired, err := s.queries.ListExpiredDatabaseBackups(ctx, sqlc.ListExpiredDatabaseBackupsParams{
+		DatabaseID: db.ID,
+		Keep:       db.BackupRetention,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list expired backups: %w", err)
+	}
+
+	for _, backup := range expired {
+		if err := s.store.Delete(ctx, backup.ObjectKey); err != nil {
+			return err
+		}
+		if err := s.queries.DeleteDatabaseBackup(ctx, backup.ID); err != nil {
+			return fmt.Errorf("failed to delete backup: %w", err)
+		}
+	}
+	return nil
+}
+
+// deleteBackups deletes the objects of all of a database's backups. Their
+// rows go with the database.
+func (s *Service) deleteBackups(ctx context.Context, databaseID string) error {
+	backups, err := s.queries.ListDatabaseBackups(ctx, databaseID)
+	if err != nil {
+		return fmt.Errorf("failed to list backups: %w", err)
+	}
+	for _, backup := range backups {
+		if err := s.store.Delete(ctx, backup.ObjectKey); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// BackupScheduler queues the backups of databases whose schedule has come
+// due.
+type BackupScheduler struct {
+	service *Service
+}
+
+func NewBackupScheduler(service *Service) *BackupScheduler {
+	return &BackupScheduler{service: service}
+}
+
+// Run queues due backups right away and then every check interval until
+// ctx is done.
+func (s *BackupScheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.service.backupsCfg.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.Schedule(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("Failed to schedule database backups: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Schedule starts a backup of every database that is due and moves it on
+// to its next scheduled time, returning how many were started. Claimed
+// databases are not due again for a backup timeout, so a database whose
+// backup could not be queued is retried.
+func (s *BackupScheduler) Schedule(ctx context.Context) (int, error) {
+	now := s.service.now()
+	queued := 0
+
+	for {
+		dbs, err := s.service.queries.ClaimDueDatabaseBackups(ctx, sqlc.ClaimDueDatabaseBackupsParams{
+			LeaseUntil: timestamp(now.Add(s.service.backupsCfg.Timeout)),
+			Now:        timestamp(now),
+			BatchSize:  backupBatchSize,
+		})
+		if err != nil {
+			return queued, fmt.Errorf("failed to claim database backups: %w", err)
+		}
+
+		for _, db := range dbs {
+			if _, err := s.service.startBackup(ctx, db); err != nil {
+				return queued, err
+			}
+			queued++
+
+			var next pgtype.Timestamptz
+			if schedule, err := ParseSchedule(db.BackupSchedule.String); err == nil {
+				if t := schedule.Next(now); !t.IsZero() {
+					next = timestamp(t)
+				}
+			}
+			// The schedule may have changed since the claim; the new one
+			// has already set its own next time.
+			err = s.service.queries.SetManagedDatabaseNextBackup(ctx, sqlc.SetManagedDatabaseNextBackupParams{
+				NextBackupAt:   next,
+				ID:             db.ID,
+				BackupSchedule: db.BackupSchedule,
+			})
+			if err != nil {
+				return queued, fmt.Errorf("failed to schedule next backup: %w", err)
+			}
+		}
+
+		if len(dbs) < backupBatchSize {
+			return queued, nil
+		}
+	}
+}
+
+func backupKey(databaseID, backupID string) string {
+	return "databases/" + databaseID + "/" + backupID + ".dump"
+}
+
+func timestamp(t time.Time) pgtype.Timestamptz {
+	return pgtype.Timestamptz{Time: t, Valid: true}
+}
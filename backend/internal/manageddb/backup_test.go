@@ -0,0 +1,371 @@
+package manageddb
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Jesuloba-world/deployease/backend/internal/infrastructure/database/postgres/sqlc"
+	"github.com/Jesuloba-world/deployease/backend/internal/objectstore"
+)
+
+func (f *fakeQuerier) UpdateManagedDatabaseBackupSchedule(ctx context.Context, arg sqlc.UpdateManagedDatabaseBackupScheduleParams) (sqlc.ManagedDatabase, error) {
+	db, ok := f.databases[arg.ID]
+	if !ok || db.ProjectID != arg.ProjectID {
+		return sqlc.ManagedDatabase{}, pgx.ErrNoRows
+	}
+	db.BackupSchedule = arg.BackupSchedule
+	db.BackupRetention = arg.BackupRetention
+	db.NextBackupAt = arg.NextBackupAt
+	f.databases[db.ID] = db
+	return db, nil
+}
+
+func (f *fakeQuerier) ClaimDueDatabaseBackups(ctx context.Context, arg sqlc.ClaimDueDatabaseBackupsParams) ([]sqlc.ManagedDatabase, error) {
+	dbs := []sqlc.ManagedDatabase{}
+	for id, db := range f.databases {
+		if db.BackupSchedule.Valid && db.Status == sqlc.ManagedDatabaseStatusRunning &&
+			db.NextBackupAt.Valid && !db.NextBackupAt.Time.After(arg.Now.Time) {
+			db.NextBackupAt = arg.LeaseUntil
+			f.databases[id] = db
+			dbs = append(dbs, db)
+		}
+	}
+	return dbs, nil
+}
+
+func (f *fakeQuerier) SetManagedDatabaseNextBackup(ctx context.Context, arg sqlc.SetManagedDatabaseNextBackupParams) error {
+	if db, ok := f.databases[arg.ID]; ok && db.BackupSchedule == arg.BackupSchedule {
+		db.NextBackupAt = arg.NextBackupAt
+		f.databases[arg.ID] = db
+	}
+	return nil
+}
+
+func (f *fakeQuerier) createdAt() pgtype.Timestamptz {
+	f.created++
+	return pgtype.Timestamptz{Time: time.Unix(int64(f.created), 0), Valid: true}
+}
+
+func (f *fakeQuerier) CreateDatabaseBackup(ctx context.Context, arg sqlc.CreateDatabaseBackupParams) (sqlc.DatabaseBackup, error) {
+	backup := sqlc.DatabaseBackup{
+		ID:         arg.ID,
+		DatabaseID: arg.DatabaseID,
+		Status:     sqlc.DatabaseBackupStatusPending,
+		ObjectKey:  arg.ObjectKey,
+		CreatedAt:  f.createdAt(),
+	}
+	f.backups[backup.ID] = backup
+	return backup, nil
+}
+
+func (f *fakeQuerier) GetDatabaseBackup(ctx context.Context, id string) (sqlc.DatabaseBackup, error) {
+	backup, ok := f.backups[id]
+	if !ok {
+		return sqlc.DatabaseBackup{}, pgx.ErrNoRows
+	}
+	return backup, nil
+}
+
+func (f *fakeQuerier) GetDatabaseBackupForDatabase(ctx context.Context, arg sqlc.GetDatabaseBackupForDatabaseParams) (sqlc.DatabaseBackup, error) {
+	backup, ok := f.backups[arg.ID]
+	if !ok || backup.DatabaseID != arg.DatabaseID {
+		return sqlc.DatabaseBackup{}, pgx.ErrNoRows
+	}
+	return backup, nil
+}
+
+func (f *fakeQuerier) GetLatestDatabaseBackupBefore(ctx context.Context, arg sqlc.GetLatestDatabaseBackupBeforeParams) (sqlc.DatabaseBackup, error) {
+	for _, backup := range f.listBackups(arg.DatabaseID) {
+		if backup.Status == sqlc.DatabaseBackupStatusSucceeded && !backup.CompletedAt.Time.After(arg.CompletedAt.Time) {
+			return backup, nil
+		}
+	}
+	return sqlc.DatabaseBackup{}, pgx.ErrNoRows
+}
+
+func (f *fakeQuerier) ListDatabaseBackups(ctx context.Context, databaseID string) ([]sqlc.DatabaseBackup, error) {
+	return f.listBackups(databaseID), nil
+}
+
+// listBackups returns a database's backups, newest first.
+func (f *fakeQuerier) listBackups(databaseID string) []sqlc.DatabaseBackup {
+	backups := []sqlc.DatabaseBackup{}
+	for _, backup := range f.backups {
+		if backup.DatabaseID == databaseID {
+			backups = append(backups, backup)
+		}
+	}
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].CreatedAt.Time.After(backups[j].CreatedAt.Time)
+	})
+	return backups
+}
+
+func (f *fakeQuerier) ListExpiredDatabaseBackups(ctx context.Context, arg sqlc.ListExpiredDatabaseBackupsParams) ([]sqlc.DatabaseBackup, error) {
+	backups := f.listBackups(arg.DatabaseID)
+	kept := 0
+	expired := []sqlc.DatabaseBackup{}
+	for _, backup := range backups {
+		if kept < int(arg.Keep) {
+			if backup.Status == sqlc.DatabaseBackupStatusSucceeded {
+				kept++
+			}
+			continue
+		}
+		if backup.Status == sqlc.DatabaseBackupStatusPending || backup.Status == sqlc.DatabaseBackupStatusRunning || f.restoring(backup.ID) {
+			continue
+		}
+		expired = append(expired, backup)
+	}
+	return expired, nil
+}
+
+func (f *fakeQuerier) restoring(backupID string) bool {
+	for _, restore := range f.restores {
+		if restore.BackupID.String == backupID &&
+			(restore.Status == sqlc.DatabaseRestoreStatusPending || restore.Status == sqlc.DatabaseRestoreStatusRunning) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *fakeQuerier) UpdateDatabaseBackupRunning(ctx context.Context, arg sqlc.UpdateDatabaseBackupRunningParams) (sqlc.DatabaseBackup, error) {
+	backup, ok := f.backups[arg.ID]
+	if !ok || (backup.Status != sqlc.DatabaseBackupStatusPending && backup.Status != sqlc.DatabaseBackupStatusRunning) {
+		return sqlc.DatabaseBackup{}, pgx.ErrNoRows
+	}
+	backup.Status = sqlc.DatabaseBackupStatusRunning
+	backup.StartedAt = arg.StartedAt
+	f.backups[backup.ID] = backup
+	return backup, nil
+}
+
+func (f *fakeQuerier) UpdateDatabaseBackupSucceeded(ctx context.Context, arg sqlc.UpdateDatabaseBackupSucceededParams) (sqlc.DatabaseBackup, error) {
+	backup, ok := f.backups[arg.ID]
+	if !ok {
+		return sqlc.DatabaseBackup{}, pgx.ErrNoRows
+	}
+	backup.Status = sqlc.DatabaseBackupStatusSucceeded
+	backup.SizeBytes = arg.SizeBytes
+	backup.CompletedAt = arg.CompletedAt
+	f.backups[backup.ID] = backup
+	return backup, nil
+}
+
+func (f *fakeQuerier) UpdateDatabaseBackupFailed(ctx context.Context, arg sqlc.UpdateDatabaseBackupFailedParams) (sqlc.DatabaseBackup, error) {
+	backup, ok := f.backups[arg.ID]
+	if !ok {
+		return sqlc.DatabaseBackup{}, pgx.ErrNoRows
+	}
+	backup.Status = sqlc.DatabaseBackupStatusFailed
+	backup.LastError = arg.LastError
+	backup.CompletedAt = arg.CompletedAt
+	f.backups[backup.ID] = backup
+	return backup, nil
+}
+
+func (f *fakeQuerier) DeleteDatabaseBackup(ctx context.Context, id string) error {
+	delete(f.backups, id)
+	return nil
+}
+
+// fakeDumper "dumps" a database as a numbered line of text and records
+// what it was asked to restore.
+type fakeDumper struct {
+	dumps      int
+	dumpErr    error
+	restored   []byte
+	restoreErr error
+}
+
+func (d *fakeDumper) Dump(ctx context.Context, db sqlc.ManagedDatabase, w io.Writer) error {
+	if d.dumpErr != nil {
+		return d.dumpErr
+	}
+	d.dumps++
+	_, err := fmt.Fprintf(w, "dump %d of %s\n", d.dumps, db.Name)
+	return err
+}
+
+func (d *fakeDumper) Restore(ctx context.Context, db sqlc.ManagedDatabase, r io.Reader) error {
+	body, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	if d.restoreErr != nil {
+		return d.restoreErr
+	}
+	d.restored = body
+	return nil
+}
+
+// runningDatabase creates a database and runs its provisioning.
+func (f *fixture) runningDatabase(t *testing.T, typ sqlc.ManagedDatabaseType) sqlc.ManagedDatabase {
+	t.Helper()
+	db, err := f.svc.Create(context.Background(), "p1", CreateInput{Name: "app", Type: typ})
+	require.NoError(t, err)
+	f.runJobs(t)
+	db, err = f.svc.Get(context.Background(), "p1", db.ID)
+	require.NoError(t, err)
+	require.Equal(t, sqlc.ManagedDatabaseStatusRunning, db.Status)
+	return db
+}
+
+func (f *fixture) object(t *testing.T, key string) []byte {
+	t.Helper()
+	r, err := f.store.Get(context.Background(), key)
+	require.NoError(t, err)
+	defer r.Close()
+	body, err := io.ReadAll(r)
+	require.NoError(t, err)
+	return body
+}
+
+func ptr[T any](v T) *T { return &v }
+
+func TestUpdateBackupSettings(t *testing.T) {
+	ctx := context.Background()
+	f := setupService(t, "")
+	db := f.runningDatabase(t, sqlc.ManagedDatabaseTypePostgresql)
+
+	_, err := f.svc.UpdateBackupSettings(ctx, "p1", db.ID, BackupSettings{Schedule: ptr("every day")})
+	assert.ErrorIs(t, err, ErrInvalidSchedule)
+	_, err = f.svc.UpdateBackupSettings(ctx, "p1", db.ID, BackupSettings{Retention: ptr[int32](0)})
+	assert.ErrorIs(t, err, ErrInvalidRetention)
+	_, err = f.svc.UpdateBackupSettings(ctx, "p2", db.ID, BackupSettings{Schedule: ptr("@daily")})
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	db, err = f.svc.UpdateBackupSettings(ctx, "p1", db.ID, BackupSettings{Schedule: ptr("0 3 * * *"), Retention: ptr[int32](3)})
+	require.NoError(t, err)
+	assert.Equal(t, "0 3 * * *", db.BackupSchedule.String)
+	assert.Equal(t, int32(3), db.BackupRetention)
+	assert.Equal(t, time.Date(2025, 7, 2, 3, 0, 0, 0, time.UTC), db.NextBackupAt.Time)
+
+	db, err = f.svc.UpdateBackupSettings(ctx, "p1", db.ID, BackupSettings{Retention: ptr[int32](5)})
+	require.NoError(t, err)
+	assert.Equal(t, "0 3 * * *", db.BackupSchedule.String, "fields left out are kept")
+	assert.Equal(t, int32(5), db.BackupRetention)
+
+	db, err = f.svc.UpdateBackupSettings(ctx, "p1", db.ID, BackupSettings{Schedule: ptr("")})
+	require.NoError(t, err)
+	assert.False(t, db.BackupSchedule.Valid)
+	assert.False(t, db.NextBackupAt.Valid, "an empty schedule turns scheduled backups off")
+
+	f.queries.databases = map[string]sqlc.ManagedDatabase{}
+	cache := f.runningDatabase(t, sqlc.ManagedDatabaseTypeRedis)
+	_, err = f.svc.UpdateBackupSettings(ctx, "p1", cache.ID, BackupSettings{Schedule: ptr("@daily")})
+	assert.ErrorIs(t, err, ErrBackupsUnsupported)
+	_, err = f.svc.CreateBackup(ctx, "p1", cache.ID)
+	assert.ErrorIs(t, err, ErrBackupsUnsupported)
+}
+
+func TestScheduledBackupsEnforceRetention(t *testing.T) {
+	ctx := context.Background()
+	f := setupService(t, "")
+	db := f.runningDatabase(t, sqlc.ManagedDatabaseTypePostgresql)
+	scheduler := NewBackupScheduler(f.svc)
+
+	_, err := f.svc.UpdateBackupSettings(ctx, "p1", db.ID, BackupSettings{Schedule: ptr("@hourly"), Retention: ptr[int32](2)})
+	require.NoError(t, err)
+
+	queued, err := scheduler.Schedule(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, queued, "nothing is due before the first scheduled time")
+
+	for i := 0; i < 3; i++ {
+		f.now = f.now.Add(time.Hour)
+		queued, err := scheduler.Schedule(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 1, queued)
+
+		queued, err = scheduler.Schedule(ctx)
+		require.NoError(t, err)
+		assert.Zero(t, queued, "a database is backed up once per scheduled time")
+
+		f.runJobs(t)
+	}
+
+	db, err = f.svc.Get(ctx, "p1", db.ID)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2025, 7, 1, 16, 0, 0, 0, time.UTC), db.NextBackupAt.Time)
+
+	backups, err := f.svc.ListBackups(ctx, "p1", db.ID)
+	require.NoError(t, err)
+	require.Len(t, backups, 2, "only the newest backups are kept")
+	for _, backup := range backups {
+		assert.Equal(t, sqlc.DatabaseBackupStatusSucceeded, backup.Status)
+		assert.Equal(t, backupKey(db.ID, backup.ID), backup.ObjectKey)
+	}
+	assert.Equal(t, "dump 3 of app\n", string(f.object(t, backups[0].ObjectKey)))
+	assert.Equal(t, int64(len("dump 3 of app\n")), backups[0].SizeBytes)
+	assert.Equal(t, "dump 2 of app\n", string(f.object(t, backups[1].ObjectKey)))
+}
+
+func TestBackupFailure(t *testing.T) {
+	ctx := context.Background()
+	f := setupService(t, "")
+	db := f.runningDatabase(t, sqlc.ManagedDatabaseTypePostgresql)
+
+	f.queries.enqueueErr = errors.New("queue is down")
+	_, err := f.svc.CreateBackup(ctx, "p1", db.ID)
+	assert.Error(t, err)
+	assert.Empty(t, f.queries.backups, "a backup whose job was not queued is rolled back")
+	f.queries.enqueueErr = nil
+
+	f.dumper.dumpErr = errors.New("pg_dump failed: exit status 1")
+
+	backup, err := f.svc.CreateBackup(ctx, "p1", db.ID)
+	require.NoError(t, err)
+	assert.Equal(t, sqlc.DatabaseBackupStatusPending, backup.Status)
+	f.runJobs(t)
+
+	backup = f.queries.backups[backup.ID]
+	assert.Equal(t, sqlc.DatabaseBackupStatusFailed, backup.Status)
+	assert.Contains(t, backup.LastError.String, "pg_dump failed")
+	_, err = f.store.Get(ctx, backup.ObjectKey)
+	assert.ErrorIs(t, err, objectstore.ErrNotFound)
+}
+
+func TestDeleteRemovesBackups(t *testing.T) {
+	ctx := context.Background()
+	f := setupService(t, "")
+	db := f.runningDatabase(t, sqlc.ManagedDatabaseTypePostgresql)
+
+	backup, err := f.svc.CreateBackup(ctx, "p1", db.ID)
+	require.NoError(t, err)
+	f.runJobs(t)
+	assert.NotEmpty(t, f.object(t, backup.ObjectKey))
+
+	_, err = f.svc.Delete(ctx, "p1", db.ID)
+	require.NoError(t, err)
+	_, err = f.svc.CreateBackup(ctx, "p1", db.ID)
+	assert.ErrorIs(t, err, ErrNotRunning)
+
+	f.runJobs(t)
+	_, err = f.store.Get(ctx, backup.ObjectKey)
+	assert.ErrorIs(t, err, objectstore.ErrNotFound)
+}
+
+func TestLimitedBufferKeepsTheStart(t *testing.T) {
+	var b limitedBuffer
+	n, err := b.Write(bytes.Repeat([]byte("a"), stderrLimit-1))
+	require.NoError(t, err)
+	assert.Equal(t, stderrLimit-1, n)
+	n, err = b.Write([]byte("bcd"))
+	require.NoError(t, err)
+	assert.Equal(t, 3, n, "writes never fail")
+	assert.Equal(t, stderrLimit, b.Len())
+	assert.Equal(t, byte('b'), b.Bytes()[stderrLimit-1])
+}
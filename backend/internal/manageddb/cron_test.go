@@ -0,0 +1,54 @@
+package manageddb
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduleNext(t *testing.T) {
+	// A Wednesday.
+	from := time.Date(2025, 7, 2, 10, 30, 15, 0, time.UTC)
+
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2025, 7, 2, 10, 31, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2025, 7, 2, 10, 45, 0, 0, time.UTC)},
+		{"0 3 * * *", time.Date(2025, 7, 3, 3, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2025, 7, 3, 0, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2025, 7, 2, 11, 0, 0, 0, time.UTC)},
+		{"@weekly", time.Date(2025, 7, 6, 0, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC)},
+		{"@yearly", time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 9-17/4 * * 1-5", time.Date(2025, 7, 2, 13, 0, 0, 0, time.UTC)},
+		{"30 2 * * 7", time.Date(2025, 7, 6, 2, 30, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"0,45 10 * * *", time.Date(2025, 7, 2, 10, 45, 0, 0, time.UTC)},
+		// Both day fields restricted: the 15th or any Monday.
+		{"0 0 15 * 1", time.Date(2025, 7, 7, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			s, err := ParseSchedule(tt.expr)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, s.Next(from))
+		})
+	}
+}
+
+func TestScheduleNeverDue(t *testing.T) {
+	s, err := ParseSchedule("0 0 30 2 *")
+	require.NoError(t, err)
+	assert.True(t, s.Next(time.Now()).IsZero())
+}
+
+func TestParseScheduleRejectsInvalidExpressions(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "* * * 13 *", "* * * * 8", "5-1 * * * *", "*/0 * * * *", "a * * * *", "@every 5m"} {
+		_, err := ParseSchedule(expr)
+		assert.ErrorIs(t, err, ErrInvalidSchedule, expr)
+	}
+}
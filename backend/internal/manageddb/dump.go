@@ -0,0 +1,80 @@
+package manageddb
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/Jesuloba-world/deployease/backend/internal/container"
+	"github.com/Jesuloba-world/deployease/backend/internal/infrastructure/database/postgres/sqlc"
+)
+
+// stderrLimit caps how much of a failed tool's output ends up in errors.
+const stderrLimit = 4 << 10
+
+// Dumper takes logical backups of PostgreSQL databases and restores them.
+type Dumper interface {
+	// Dump writes a backup of the database to w.
+	Dump(ctx context.Context, db sqlc.ManagedDatabase, w io.Writer) error
+	// Restore replaces the database's contents with the backup read from r.
+	Restore(ctx context.Context, db sqlc.ManagedDatabase, r io.Reader) error
+}
+
+// RuntimeDumper runs pg_dump and pg_restore inside the database's own
+// container through the container runtime, so the tools always match the
+// server's version.
+type RuntimeDumper struct {
+	runtime container.Runtime
+}
+
+func NewRuntimeDumper(runtime container.Runtime) *RuntimeDumper {
+	return &RuntimeDumper{runtime: runtime}
+}
+
+func (d *RuntimeDumper) Dump(ctx context.Context, db sqlc.ManagedDatabase, w io.Writer) error {
+	return d.exec(ctx, db, nil, w,
+		"pg_dump", "--format=custom", "--username", postgresUser, "--dbname", db.Name)
+}
+
+// Restore drops the objects in the backup before recreating them, in a
+// single transaction so a failed restore leaves the database as it was.
+func (d *RuntimeDumper) Restore(ctx context.Context, db sqlc.ManagedDatabase, r io.Reader) error {
+	return d.exec(ctx, db, r, nil,
+		"pg_restore", "--clean", "--if-exists", "--no-owner", "--single-transaction",
+		"--username", postgresUser, "--dbname", db.Name)
+}
+
+func (d *RuntimeDumper) exec(ctx context.Context, db sqlc.ManagedDatabase, stdin io.Reader, stdout io.Writer, cmd ...string) error {
+	var stderr limitedBuffer
+	code, err := d.runtime.Exec(ctx, containerName(db.ID), container.ExecOptions{
+		Cmd:    cmd,
+		Stdin:  stdin,
+		Stdout: stdout,
+		Stderr: &stderr,
+	})
+	if err != nil {
+		return fmt.Errorf("%s failed: %w", cmd[0], err)
+	}
+	if code != 0 {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("%s failed with exit status %d: %s", cmd[0], code, msg)
+		}
+		return fmt.Errorf("%s failed with exit status %d", cmd[0], code)
+	}
+	return nil
+}
+
+// limitedBuffer keeps the first stderrLimit bytes written to it and drops
+// the rest.
+type limitedBuffer struct {
+	bytes.Buffer
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if room := stderrLimit - b.Len(); room > 0 {
+		b.Buffer.Write(p[:min(len(p), room)])
+	}
+	return len(p), nil
+}
@@ -0,0 +1,73 @@
+package manageddb
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Jesuloba-world/deployease/backend/internal/container"
+	"github.com/Jesuloba-world/deployease/backend/internal/container/containertest"
+	"github.com/Jesuloba-world/deployease/backend/internal/infrastructure/database/postgres/sqlc"
+)
+
+func runningDatabase(t *testing.T, rt *containertest.Runtime, db sqlc.ManagedDatabase) {
+	t.Helper()
+	ctx := context.Background()
+	id, err := rt.Create(ctx, container.Spec{Name: containerName(db.ID), Image: "postgres:16"})
+	require.NoError(t, err)
+	require.NoError(t, rt.Start(ctx, id))
+}
+
+func TestRuntimeDumperDump(t *testing.T) {
+	rt := containertest.NewRuntime()
+	db := sqlc.ManagedDatabase{ID: "db1", Name: "app"}
+	runningDatabase(t, rt, db)
+
+	var cmd []string
+	rt.ExecFunc = func(id string, opts container.ExecOptions) (int, error) {
+		cmd = opts.Cmd
+		assert.Nil(t, opts.Stdin)
+		io.WriteString(opts.Stdout, "PGDMP")
+		return 0, nil
+	}
+
+	var out bytes.Buffer
+	require.NoError(t, NewRuntimeDumper(rt).Dump(context.Background(), db, &out))
+	assert.Equal(t, "PGDMP", out.String())
+	assert.Equal(t, []string{"pg_dump", "--format=custom", "--username", postgresUser, "--dbname", "app"}, cmd)
+}
+
+func TestRuntimeDumperRestoreReportsFailures(t *testing.T) {
+	rt := containertest.NewRuntime()
+	db := sqlc.ManagedDatabase{ID: "db1", Name: "app"}
+	runningDatabase(t, rt, db)
+
+	var restored []byte
+	rt.ExecFunc = func(id string, opts container.ExecOptions) (int, error) {
+		assert.Equal(t, "pg_restore", opts.Cmd[0])
+		restored, _ = io.ReadAll(opts.Stdin)
+		io.WriteString(opts.Stderr, "pg_restore: error: relation already exists\n")
+		return 1, nil
+	}
+
+	err := NewRuntimeDumper(rt).Restore(context.Background(), db, strings.NewReader("PGDMP"))
+	require.Error(t, err)
+	assert.Equal(t, "PGDMP", string(restored))
+	assert.Contains(t, err.Error(), "exit status 1")
+	assert.Contains(t, err.Error(), "relation already exists")
+}
+
+func TestRuntimeDumperStoppedDatabase(t *testing.T) {
+	rt := containertest.NewRuntime()
+	db := sqlc.ManagedDatabase{ID: "db1", Name: "app"}
+	runningDatabase(t, rt, db)
+	require.NoError(t, rt.Stop(context.Background(), containerName(db.ID), 0))
+
+	err := NewRuntimeDumper(rt).Dump(context.Background(), db, io.Discard)
+	assert.ErrorIs(t, err, container.ErrConflict)
+}
@@ -0,0 +1,270 @@
+package manageddb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	gonanoid "github.com/matoous/go-nanoid/v2"
+
+	"github.com/Jesuloba-world/deployease/backend/internal/container"
+	"github.com/Jesuloba-world/deployease/backend/internal/infrastructure/database/postgres/sqlc"
+	"github.com/Jesuloba-world/deployease/backend/internal/jobs"
+)
+
+const (
+	// ProvisionJobKind starts a new database's container.
+	ProvisionJobKind = "database.provision"
+	// DeprovisionJobKind removes a deleted database's container, backups and
+	// row.
+	DeprovisionJobKind = "database.deprovision"
+)
+
+const (
+	postgresPort = 5432
+	redisPort    = 6379
+
+	// postgresUser owns the database created in every PostgreSQL container.
+	postgresUser = "deployease"
+
+	passwordAlphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+	passwordLength   = 32
+
+	// readyPollInterval is how often a starting database is dialled.
+	readyPollInterval = 500 * time.Millisecond
+)
+
+type ProvisionPayload struct {
+	DatabaseID string `json:"database_id"`
+}
+
+// provisionError marks a failure of the database container itself, such as
+// an image that cannot be pulled, as opposed to an error storing the outcome
+// that is worth retrying the job for.
+type provisionError struct {
+	err error
+}
+
+func (e *provisionError) Error() string { return e.err.Error() }
+func (e *provisionError) Unwrap() error { return e.err }
+
+// HandleProvision starts the container of the database named in the job
+// payload with fresh credentials and, once it accepts connections, stores
+// its connection string and marks it running. A container that fails to
+// come up fails the database rather than the job. Databases deleted in the
+// meantime are skipped.
+func (s *Service) HandleProvision(ctx context.Context, job sqlc.Job) error {
+	var payload ProvisionPayload
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return jobs.Permanent(fmt.Errorf("invalid database provision payload: %w", err))
+	}
+
+	db, err := s.queries.GetManagedDatabase(ctx, payload.DatabaseID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		return fmt.Errorf("failed to get database: %w", err)
+	}
+	if db.Status != sqlc.ManagedDatabaseStatusProvisioning {
+		return nil
+	}
+
+	connString, err := s.provision(ctx, db)
+	if err != nil {
+		var pErr *provisionError
+		if !errors.As(err, &pErr) {
+			return err
+		}
+		log.Printf("Failed to provision database %s: %v", db.ID, err)
+		s.removeContainer(context.WithoutCancel(ctx), db.ID)
+		_, err = s.queries.UpdateManagedDatabaseFailed(ctx, sqlc.UpdateManagedDatabaseFailedParams{
+			LastError: pgtype.Text{String: pErr.Error(), Valid: true},
+			ID:        db.ID,
+		})
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("failed to update database: %w", err)
+		}
+		return nil
+	}
+
+	sealed, err := s.cipher.SealString(connString, associatedData(db.ID))
+	if err != nil {
+		return fmt.Errorf("failed to encrypt connection string: %w", err)
+	}
+
+	_, err = s.queries.UpdateManagedDatabaseRunning(ctx, sqlc.UpdateManagedDatabaseRunningParams{
+		EncryptedConnectionString: sealed,
+		ID:                        db.ID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			// Deleted while it was starting; its deprovision job may have
+			// run before the container existed.
+			s.removeContainer(context.WithoutCancel(ctx), db.ID)
+			return nil
+		}
+		return fmt.Errorf("failed to update database: %w", err)
+	}
+	return nil
+}
+
+// HandleDeprovision removes a deleted database's container and backups,
+// then the database itself.
+func (s *Service) HandleDeprovision(ctx context.Context, job sqlc.Job) error {
+	var payload ProvisionPayload
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return jobs.Permanent(fmt.Errorf("invalid database deprovision payload: %w", err))
+	}
+
+	if err := s.runtime.Remove(ctx, containerName(payload.DatabaseID)); err != nil && !errors.Is(err, container.ErrNotFound) {
+		return fmt.Errorf("failed to remove database container: %w", err)
+	}
+	if err := s.deleteBackups(ctx, payload.DatabaseID); err != nil {
+		return err
+	}
+	if err := s.queries.DeleteManagedDatabase(ctx, payload.DatabaseID); err != nil {
+		return fmt.Errorf("failed to delete database: %w", err)
+	}
+	return nil
+}
+
+// provision runs the database's container and returns the connection
+// string deployments reach it with.
+func (s *Service) provision(ctx context.Context, db sqlc.ManagedDatabase) (string, error) {
+	name := containerName(db.ID)
+
+	// A previous attempt may have got as far as creating the container; its
+	// password is lost, so it is started over.
+	s.removeContainer(ctx, db.ID)
+
+	image := s.image(db)
+	if err := s.runtime.Pull(ctx, image); err != nil {
+		return "", &provisionError{err: err}
+	}
+
+	password := gonanoid.MustGenerate(passwordAlphabet, passwordLength)
+	spec := container.Spec{
+		Name:  name,
+		Image: image,
+		Labels: map[string]string{
+			container.LabelProject:  db.ProjectID,
+			container.LabelDatabase: db.ID,
+		},
+		Network: s.runtimeCfg.Network,
+		Memory:  sizes[db.Size],
+	}
+	switch db.Type {
+	case sqlc.ManagedDatabaseTypeRedis:
+		spec.Port = redisPort
+		spec.Cmd = []string{"redis-server", "--requirepass", password, "--appendonly", "yes"}
+	default:
+		spec.Port = postgresPort
+		spec.Env = map[string]string{
+			"POSTGRES_USER":     postgresUser,
+			"POSTGRES_PASSWORD": password,
+			"POSTGRES_DB":       db.Name,
+		}
+	}
+
+	id, err := s.runtime.Create(ctx, spec)
+	if err != nil {
+		return "", &provisionError{err: err}
+	}
+
+	err = s.queries.SetManagedDatabaseContainer(ctx, sqlc.SetManagedDatabaseContainerParams{
+		ContainerID: pgtype.Text{String: id, Valid: true},
+		ID:          db.ID,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to record database container: %w", err)
+	}
+
+	if err := s.runtime.Start(ctx, id); err != nil {
+		return "", &provisionError{err: err}
+	}
+
+	info, err := s.runtime.Inspect(ctx, id)
+	if err != nil {
+		return "", &provisionError{err: err}
+	}
+	if !info.Running() || info.IPAddress == "" {
+		return "", &provisionError{err: fmt.Errorf("database container is %s", info.State)}
+	}
+
+	readyCtx, cancel := context.WithTimeout(ctx, s.cfg.ReadyTimeout)
+	defer cancel()
+	if err := s.ready(readyCtx, net.JoinHostPort(info.IPAddress, strconv.Itoa(spec.Port))); err != nil {
+		return "", &provisionError{err: fmt.Errorf("database did not accept connections within %s: %w", s.cfg.ReadyTimeout, err)}
+	}
+
+	// On a user-defined network containers reach each other by name, which
+	// survives the database container getting a new address on restart.
+	host := info.IPAddress
+	if s.runtimeCfg.Network != "" {
+		host = name
+	}
+	return connectionString(db, host, spec.Port, password), nil
+}
+
+func (s *Service) image(db sqlc.ManagedDatabase) string {
+	if db.Type == sqlc.ManagedDatabaseTypeRedis {
+		return s.cfg.RedisImage + ":" + db.Version
+	}
+	return s.cfg.PostgresImage + ":" + db.Version
+}
+
+// removeContainer removes the database's container if it has one. Failures
+// are only logged: a leftover container is removed by the next attempt.
+func (s *Service) removeContainer(ctx context.Context, databaseID string) {
+	if err := s.runtime.Remove(ctx, containerName(databaseID)); err != nil && !errors.Is(err, container.ErrNotFound) {
+		log.Printf("Failed to remove container of database %s: %v", databaseID, err)
+	}
+}
+
+func connectionString(db sqlc.ManagedDatabase, host string, port int, password string) string {
+	u := url.URL{Host: net.JoinHostPort(host, strconv.Itoa(port))}
+	if db.Type == sqlc.ManagedDatabaseTypeRedis {
+		u.Scheme = "redis"
+		u.User = url.UserPassword("", password)
+		u.Path = "/0"
+		return u.String()
+	}
+	u.Scheme = "postgres"
+	u.User = url.UserPassword(postgresUser, password)
+	u.Path = "/" + db.Name
+	u.RawQuery = "sslmode=disable"
+	return u.String()
+}
+
+// waitForListener dials addr until a connection succeeds or ctx is done.
+func waitForListener(ctx context.Context, addr string) error {
+	var dialer net.Dialer
+	ticker := time.NewTicker(readyPollInterval)
+	defer ticker.Stop()
+
+	for {
+		conn, err := dialer.DialContext(ctx, "tcp", addr)
+		if err == nil {
+			return conn.Close()
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-ticker.C:
+		}
+	}
+}
+
+func containerName(databaseID string) string {
+	return "deployease-db-" + databaseID
+}
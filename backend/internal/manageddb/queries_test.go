@@ -0,0 +1,228 @@
+package manageddb
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
+
+	database "github.com/Jesuloba-world/deployease/backend/internal/infrastructure/database/postgres"
+	"github.com/Jesuloba-world/deployease/backend/internal/infrastructure/database/postgres/sqlc"
+)
+
+// setupQueries runs the managed database queries against a migrated
+// database with the projects p1, p2 and p3.
+func setupQueries(t *testing.T) (*sqlc.Queries, *database.TestContainer) {
+	t.Helper()
+	testcontainers.SkipIfProviderIsNotHealthy(t)
+
+	tc := database.SetupTestContainer(t)
+	t.Cleanup(func() { tc.Cleanup(t) })
+	tc.ApplyMigrations(t, "../../sql/migrations")
+
+	ctx := context.Background()
+	_, err := tc.Pool.Exec(ctx, `INSERT INTO users (id, username, email, password_hash) VALUES ('u1', 'user', 'user@example.com', 'x')`)
+	require.NoError(t, err)
+	_, err = tc.Pool.Exec(ctx, `INSERT INTO projects (id, name, repository_url, user_id) VALUES
+		('p1', 'one', 'https://github.com/user/one.git', 'u1'),
+		('p2', 'two', 'https://github.com/user/two.git', 'u1'),
+		('p3', 'three', 'https://github.com/user/three.git', 'u1')`)
+	require.NoError(t, err)
+
+	return sqlc.New(tc.Pool), tc
+}
+
+func insertDatabase(t *testing.T, tc *database.TestContainer, id, projectID string, status sqlc.ManagedDatabaseStatus, schedule string, nextBackupAt time.Time) {
+	t.Helper()
+	_, err := tc.Pool.Exec(context.Background(), `INSERT INTO managed_databases
+		(id, project_id, name, type, version, size, status, backup_schedule, next_backup_at)
+		VALUES ($1, $2, $1, 'postgresql', '16', 'small', $3, NULLIF($4, ''), $5)`,
+		id, projectID, status, schedule, pgtype.Timestamptz{Time: nextBackupAt, Valid: !nextBackupAt.IsZero()})
+	require.NoError(t, err)
+}
+
+func insertBackup(t *testing.T, tc *database.TestContainer, id, databaseID string, status sqlc.DatabaseBackupStatus, createdAt time.Time) {
+	t.Helper()
+	_, err := tc.Pool.Exec(context.Background(), `INSERT INTO database_backups
+		(id, database_id, object_key, status, completed_at, created_at)
+		VALUES ($1, $2, $1, $3, $4, $4)`,
+		id, databaseID, status, createdAt)
+	require.NoError(t, err)
+}
+
+func backupIDs(backups []sqlc.DatabaseBackup) []string {
+	ids := make([]string, 0, len(backups))
+	for _, b := range backups {
+		ids = append(ids, b.ID)
+	}
+	return ids
+}
+
+func TestClaimDueDatabaseBackupsSkipsLockedDatabases(t *testing.T) {
+	ctx := context.Background()
+	q, tc := setupQueries(t)
+
+	now := time.Now()
+	insertDatabase(t, tc, "db1", "p1", sqlc.ManagedDatabaseStatusRunning, "0 3 * * *", now.Add(-2*time.Minute))
+	insertDatabase(t, tc, "db2", "p2", sqlc.ManagedDatabaseStatusRunning, "0 3 * * *", now.Add(-time.Minute))
+	insertDatabase(t, tc, "db3", "p3", sqlc.ManagedDatabaseStatusProvisioning, "0 3 * * *", now.Add(-time.Minute))
+
+	params := sqlc.ClaimDueDatabaseBackupsParams{
+		LeaseUntil: timestamp(now.Add(10 * time.Minute)),
+		Now:        timestamp(now),
+		BatchSize:  5,
+	}
+	claim := func(q *sqlc.Queries, batchSize int32) []string {
+		t.Helper()
+		p := params
+		p.BatchSize = batchSize
+		dbs, err := q.ClaimDueDatabaseBackups(ctx, p)
+		require.NoError(t, err)
+		ids := make([]string, 0, len(dbs))
+		for _, db := range dbs {
+			ids = append(ids, db.ID)
+		}
+		return ids
+	}
+
+	tx, err := tc.Pool.Begin(ctx)
+	require.NoError(t, err)
+	defer tx.Rollback(ctx)
+	assert.Equal(t, []string{"db1"}, claim(q.WithTx(tx), 1), "the most overdue backup is claimed first")
+
+	assert.Equal(t, []string{"db2"}, claim(q, 5), "locked and not running databases are skipped")
+	require.NoError(t, tx.Commit(ctx))
+
+	assert.Empty(t, claim(q, 5), "claimed backups are leased until they come due again")
+
+	db, err := q.GetManagedDatabase(ctx, "db1")
+	require.NoError(t, err)
+	assert.WithinDuration(t, now.Add(10*time.Minute), db.NextBackupAt.Time, time.Millisecond)
+}
+
+func TestSetManagedDatabaseNextBackupKeepsChangedSchedules(t *testing.T) {
+	ctx := context.Background()
+	q, tc := setupQueries(t)
+
+	now := time.Now()
+	insertDatabase(t, tc, "db1", "p1", sqlc.ManagedDatabaseStatusRunning, "0 4 * * *", now)
+
+	// A backup scheduled under the old expression must not overwrite the
+	// time computed for the new one.
+	err := q.SetManagedDatabaseNextBackup(ctx, sqlc.SetManagedDatabaseNextBackupParams{
+		NextBackupAt:   timestamp(now.Add(24 * time.Hour)),
+		ID:             "db1",
+		BackupSchedule: pgtype.Text{String: "0 3 * * *", Valid: true},
+	})
+	require.NoError(t, err)
+	db, err := q.GetManagedDatabase(ctx, "db1")
+	require.NoError(t, err)
+	assert.WithinDuration(t, now, db.NextBackupAt.Time, time.Millisecond)
+
+	err = q.SetManagedDatabaseNextBackup(ctx, sqlc.SetManagedDatabaseNextBackupParams{
+		NextBackupAt:   timestamp(now.Add(24 * time.Hour)),
+		ID:             "db1",
+		BackupSchedule: pgtype.Text{String: "0 4 * * *", Valid: true},
+	})
+	require.NoError(t, err)
+	db, err = q.GetManagedDatabase(ctx, "db1")
+	require.NoError(t, err)
+	assert.WithinDuration(t, now.Add(24*time.Hour), db.NextBackupAt.Time, time.Millisecond)
+}
+
+func TestListExpiredDatabaseBackups(t *testing.T) {
+	ctx := context.Background()
+	q, tc := setupQueries(t)
+
+	now := time.Now()
+	insertDatabase(t, tc, "db1", "p1", sqlc.ManagedDatabaseStatusRunning, "", time.Time{})
+	insertDatabase(t, tc, "db2", "p2", sqlc.ManagedDatabaseStatusRunning, "", time.Time{})
+	insertBackup(t, tc, "b1", "db1", sqlc.DatabaseBackupStatusSucceeded, now.Add(-5*time.Hour))
+	insertBackup(t, tc, "b2", "db1", sqlc.DatabaseBackupStatusFailed, now.Add(-4*time.Hour))
+	insertBackup(t, tc, "b3", "db1", sqlc.DatabaseBackupStatusSucceeded, now.Add(-3*time.Hour))
+	insertBackup(t, tc, "b4", "db1", sqlc.DatabaseBackupStatusFailed, now.Add(-2*time.Hour))
+	insertBackup(t, tc, "b5", "db1", sqlc.DatabaseBackupStatusSucceeded, now.Add(-time.Hour))
+	insertBackup(t, tc, "b6", "db1", sqlc.DatabaseBackupStatusRunning, now)
+	insertBackup(t, tc, "other", "db2", sqlc.DatabaseBackupStatusSucceeded, now.Add(-6*time.Hour))
+
+	expired := func(keep int32) []string {
+		t.Helper()
+		backups, err := q.ListExpiredDatabaseBackups(ctx, sqlc.ListExpiredDatabaseBackupsParams{DatabaseID: "db1", Keep: keep})
+		require.NoError(t, err)
+		return backupIDs(backups)
+	}
+
+	assert.Equal(t, []string{"b1", "b2"}, expired(2), "older than the two newest successful backups")
+	assert.Equal(t, []string{"b1", "b2", "b3", "b4"}, expired(1))
+	assert.Empty(t, expired(3))
+	assert.Empty(t, expired(4), "fewer successful backups than are kept")
+
+	_, err := q.CreateDatabaseRestore(ctx, sqlc.CreateDatabaseRestoreParams{
+		ID:         "r1",
+		DatabaseID: "db1",
+		BackupID:   pgtype.Text{String: "b1", Valid: true},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"b2"}, expired(2), "a backup being restored is kept")
+}
+
+func TestGetLatestDatabaseBackupBefore(t *testing.T) {
+	ctx := context.Background()
+	q, tc := setupQueries(t)
+
+	now := time.Now()
+	insertDatabase(t, tc, "db1", "p1", sqlc.ManagedDatabaseStatusRunning, "", time.Time{})
+	insertBackup(t, tc, "b1", "db1", sqlc.DatabaseBackupStatusSucceeded, now.Add(-3*time.Hour))
+	insertBackup(t, tc, "b2", "db1", sqlc.DatabaseBackupStatusSucceeded, now.Add(-2*time.Hour))
+	insertBackup(t, tc, "b3", "db1", sqlc.DatabaseBackupStatusFailed, now.Add(-time.Hour))
+	insertBackup(t, tc, "b4", "db1", sqlc.DatabaseBackupStatusSucceeded, now)
+
+	b, err := q.GetLatestDatabaseBackupBefore(ctx, sqlc.GetLatestDatabaseBackupBeforeParams{
+		DatabaseID:  "db1",
+		CompletedAt: timestamp(now.Add(-30 * time.Minute)),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "b2", b.ID, "failed backups are never restored")
+}
+
+func TestCreateDatabaseRestoreOneActivePerDatabase(t *testing.T) {
+	ctx := context.Background()
+	q, tc := setupQueries(t)
+
+	now := time.Now()
+	insertDatabase(t, tc, "db1", "p1", sqlc.ManagedDatabaseStatusRunning, "", time.Time{})
+	insertDatabase(t, tc, "db2", "p2", sqlc.ManagedDatabaseStatusRunning, "", time.Time{})
+	insertBackup(t, tc, "b1", "db1", sqlc.DatabaseBackupStatusSucceeded, now)
+	insertBackup(t, tc, "b2", "db2", sqlc.DatabaseBackupStatusSucceeded, now)
+
+	restore := func(id, databaseID, backupID string) error {
+		_, err := q.CreateDatabaseRestore(ctx, sqlc.CreateDatabaseRestoreParams{
+			ID:         id,
+			DatabaseID: databaseID,
+			BackupID:   pgtype.Text{String: backupID, Valid: true},
+		})
+		return err
+	}
+
+	require.NoError(t, restore("r1", "db1", "b1"))
+	require.NoError(t, restore("r2", "db2", "b2"), "restores of other databases run alongside")
+
+	err := restore("r3", "db1", "b1")
+	var pgErr *pgconn.PgError
+	require.True(t, errors.As(err, &pgErr), "expected a Postgres error, got %v", err)
+	assert.Equal(t, pgUniqueViolation, pgErr.Code)
+
+	_, err = q.UpdateDatabaseRestoreFailed(ctx, sqlc.UpdateDatabaseRestoreFailedParams{
+		LastError:   pgtype.Text{String: "boom", Valid: true},
+		CompletedAt: timestamp(now),
+		ID:          "r1",
+	})
+	require.NoError(t, err)
+	assert.NoError(t, restore("r3", "db1", "b1"), "a finished restore frees the database")
+}
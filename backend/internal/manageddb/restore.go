@@ -0,0 +1,283 @@
+package manageddb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	gonanoid "github.com/matoous/go-nanoid/v2"
+
+	"github.com/Jesuloba-world/deployease/backend/internal/infrastructure/database/postgres/sqlc"
+	"github.com/Jesuloba-world/deployease/backend/internal/jobs"
+)
+
+// RestoreJobKind restores a backup into its database.
+const RestoreJobKind = "database.restore"
+
+// restoreProgressInterval is how often a running restore records how far
+// it has got.
+const restoreProgressInterval = time.Second
+
+var (
+	ErrRestoreNotFound   = errors.New("restore not found")
+	ErrRestoreInProgress = errors.New("database already has a restore in progress")
+	ErrBackupNotReady    = errors.New("backup has not succeeded")
+)
+
+type RestorePayload struct {
+	RestoreID string `json:"restore_id"`
+}
+
+// RestoreInput picks the backup to restore: the one named by BackupID, or
+// else the newest successful backup completed at or before At, which
+// defaults to now.
+type RestoreInput struct {
+	BackupID string
+	At       time.Time
+}
+
+// Restore queues restoring a backup into a running database. A database
+// has at most one restore pending or running at a time.
+func (s *Service) Restore(ctx context.Context, projectID, databaseID string, in RestoreInput) (sqlc.DatabaseRestore, error) {
+	db, err := s.Get(ctx, projectID, databaseID)
+	if err != nil {
+		return sqlc.DatabaseRestore{}, err
+	}
+	if db.Type != sqlc.ManagedDatabaseTypePostgresql {
+		return sqlc.DatabaseRestore{}, ErrBackupsUnsupported
+	}
+	if db.Status != sqlc.ManagedDatabaseStatusRunning {
+		return sqlc.DatabaseRestore{}, ErrNotRunning
+	}
+
+	backup, err := s.restorePoint(ctx, db.ID, in)
+	if err != nil {
+		return sqlc.DatabaseRestore{}, err
+	}
+
+	var restore sqlc.DatabaseRestore
+	err = s.withTx(ctx, func(q sqlc.Querier) error {
+		var err error
+		restore, err = q.CreateDatabaseRestore(ctx, sqlc.CreateDatabaseRestoreParams{
+			ID:         gonanoid.Must(),
+			DatabaseID: db.ID,
+			BackupID:   pgtype.Text{String: backup.ID, Valid: true},
+			BytesTotal: backup.SizeBytes,
+		})
+		if err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation {
+				return ErrRestoreInProgress
+			}
+			return fmt.Errorf("failed to create restore: %w", err)
+		}
+
+		_, err = s.queue.WithQuerier(q).Enqueue(ctx, RestoreJobKind, RestorePayload{RestoreID: restore.ID})
+		return err
+	})
+	if err != nil {
+		return sqlc.DatabaseRestore{}, err
+	}
+	return restore, nil
+}
+
+func (s *Service) restorePoint(ctx context.Context, databaseID string, in RestoreInput) (sqlc.DatabaseBackup, error) {
+	if in.BackupID != "" {
+		backup, err := s.queries.GetDatabaseBackupForDatabase(ctx, sqlc.GetDatabaseBackupForDatabaseParams{
+			ID:         in.BackupID,
+			DatabaseID: databaseID,
+		})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return sqlc.DatabaseBackup{}, ErrBackupNotFound
+			}
+			return sqlc.DatabaseBackup{}, fmt.Errorf("failed to get backup: %w", err)
+		}
+		if backup.Status != sqlc.DatabaseBackupStatusSucceeded {
+			return sqlc.DatabaseBackup{}, ErrBackupNotReady
+		}
+		return backup, nil
+	}
+
+	at := in.At
+	if at.IsZero() {
+		at = s.now()
+	}
+	backup, err := s.queries.GetLatestDatabaseBackupBefore(ctx, sqlc.GetLatestDatabaseBackupBeforeParams{
+		DatabaseID:  databaseID,
+		CompletedAt: timestamp(at),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return sqlc.DatabaseBackup{}, ErrBackupNotFound
+		}
+		return sqlc.DatabaseBackup{}, fmt.Errorf("failed to get backup: %w", err)
+	}
+	return backup, nil
+}
+
+// ListRestores returns a database's restores, newest first.
+func (s *Service) ListRestores(ctx context.Context, projectID, databaseID string) ([]sqlc.DatabaseRestore, error) {
+	db, err := s.Get(ctx, projectID, databaseID)
+	if err != nil {
+		return nil, err
+	}
+	restores, err := s.queries.ListDatabaseRestores(ctx, db.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list restores: %w", err)
+	}
+	return restores, nil
+}
+
+func (s *Service) GetRestore(ctx context.Context, projectID, databaseID, restoreID string) (sqlc.DatabaseRestore, error) {
+	db, err := s.Get(ctx, projectID, databaseID)
+	if err != nil {
+		return sqlc.DatabaseRestore{}, err
+	}
+	restore, err := s.queries.GetDatabaseRestoreForDatabase(ctx, sqlc.GetDatabaseRestoreForDatabaseParams{
+		ID:         restoreID,
+		DatabaseID: db.ID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return sqlc.DatabaseRestore{}, ErrRestoreNotFound
+		}
+		return sqlc.DatabaseRestore{}, fmt.Errorf("failed to get restore: %w", err)
+	}
+	return restore, nil
+}
+
+// HandleRestore streams the backup of the restore named in the job payload
+// from the object store into its database, recording how many bytes have
+// been read as it goes. A restore that fails fails the restore rather than
+// the job.
+func (s *Service) HandleRestore(ctx context.Context, job sqlc.Job) error {
+	var payload RestorePayload
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return jobs.Permanent(fmt.Errorf("invalid database restore payload: %w", err))
+	}
+
+	restore, err := s.queries.GetDatabaseRestore(ctx, payload.RestoreID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		return fmt.Errorf("failed to get restore: %w", err)
+	}
+	if restore.Status == sqlc.DatabaseRestoreStatusSucceeded || restore.Status == sqlc.DatabaseRestoreStatusFailed {
+		return nil
+	}
+
+	db, err := s.queries.GetManagedDatabase(ctx, restore.DatabaseID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		return fmt.Errorf("failed to get database: %w", err)
+	}
+	if db.Status != sqlc.ManagedDatabaseStatusRunning {
+		return s.failRestore(ctx, restore.ID, ErrNotRunning)
+	}
+
+	if !restore.BackupID.Valid {
+		return s.failRestore(ctx, restore.ID, ErrBackupNotFound)
+	}
+	backup, err := s.queries.GetDatabaseBackup(ctx, restore.BackupID.String)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return s.failRestore(ctx, restore.ID, ErrBackupNotFound)
+		}
+		return fmt.Errorf("failed to get backup: %w", err)
+	}
+
+	_, err = s.queries.UpdateDatabaseRestoreRunning(ctx, sqlc.UpdateDatabaseRestoreRunningParams{
+		StartedAt: timestamp(s.now()),
+		ID:        restore.ID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		return fmt.Errorf("failed to update restore: %w", err)
+	}
+
+	if err := s.restore(ctx, db, backup, restore.ID); err != nil {
+		log.Printf("Failed to restore database %s: %v", db.ID, err)
+		return s.failRestore(ctx, restore.ID, err)
+	}
+
+	_, err = s.queries.UpdateDatabaseRestoreSucceeded(ctx, sqlc.UpdateDatabaseRestoreSucceededParams{
+		CompletedAt: timestamp(s.now()),
+		ID:          restore.ID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update restore: %w", err)
+	}
+	return nil
+}
+
+func (s *Service) restore(ctx context.Context, db sqlc.ManagedDatabase, backup sqlc.DatabaseBackup, restoreID string) error {
+	ctx, cancel := context.WithTimeout(ctx, s.backupsCfg.Timeout)
+	defer cancel()
+
+	body, err := s.store.Get(ctx, backup.ObjectKey)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	progress := &progressReader{
+		r:    body,
+		now:  s.now,
+		last: s.now(),
+		report: func(n int64) {
+			err := s.queries.UpdateDatabaseRestoreProgress(ctx, sqlc.UpdateDatabaseRestoreProgressParams{
+				BytesRestored: n,
+				ID:            restoreID,
+			})
+			if err != nil {
+				log.Printf("Failed to record progress of restore %s: %v", restoreID, err)
+			}
+		},
+	}
+	return s.dumper.Restore(ctx, db, progress)
+}
+
+func (s *Service) failRestore(ctx context.Context, restoreID string, cause error) error {
+	_, err := s.queries.UpdateDatabaseRestoreFailed(context.WithoutCancel(ctx), sqlc.UpdateDatabaseRestoreFailedParams{
+		LastError:   pgtype.Text{String: cause.Error(), Valid: true},
+		CompletedAt: timestamp(s.now()),
+		ID:          restoreID,
+	})
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("failed to update restore: %w", err)
+	}
+	return nil
+}
+
+// progressReader counts the bytes read through it and reports the count at
+// most once per restoreProgressInterval.
+type progressReader struct {
+	r      io.Reader
+	n      int64
+	now    func() time.Time
+	last   time.Time
+	report func(n int64)
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	p.n += int64(n)
+	if now := p.now(); now.Sub(p.last) >= restoreProgressInterval {
+		p.last = now
+		p.report(p.n)
+	}
+	return n, err
+}
@@ -0,0 +1,211 @@
+package manageddb
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Jesuloba-world/deployease/backend/internal/infrastructure/database/postgres/sqlc"
+)
+
+func (f *fakeQuerier) CreateDatabaseRestore(ctx context.Context, arg sqlc.CreateDatabaseRestoreParams) (sqlc.DatabaseRestore, error) {
+	for _, restore := range f.restores {
+		if restore.DatabaseID == arg.DatabaseID &&
+			(restore.Status == sqlc.DatabaseRestoreStatusPending || restore.Status == sqlc.DatabaseRestoreStatusRunning) {
+			return sqlc.DatabaseRestore{}, &pgconn.PgError{Code: pgUniqueViolation}
+		}
+	}
+	restore := sqlc.DatabaseRestore{
+		ID:         arg.ID,
+		DatabaseID: arg.DatabaseID,
+		BackupID:   arg.BackupID,
+		Status:     sqlc.DatabaseRestoreStatusPending,
+		BytesTotal: arg.BytesTotal,
+		CreatedAt:  f.createdAt(),
+	}
+	f.restores[restore.ID] = restore
+	return restore, nil
+}
+
+func (f *fakeQuerier) GetDatabaseRestore(ctx context.Context, id string) (sqlc.DatabaseRestore, error) {
+	restore, ok := f.restores[id]
+	if !ok {
+		return sqlc.DatabaseRestore{}, pgx.ErrNoRows
+	}
+	return restore, nil
+}
+
+func (f *fakeQuerier) GetDatabaseRestoreForDatabase(ctx context.Context, arg sqlc.GetDatabaseRestoreForDatabaseParams) (sqlc.DatabaseRestore, error) {
+	restore, ok := f.restores[arg.ID]
+	if !ok || restore.DatabaseID != arg.DatabaseID {
+		return sqlc.DatabaseRestore{}, pgx.ErrNoRows
+	}
+	return restore, nil
+}
+
+func (f *fakeQuerier) UpdateDatabaseRestoreRunning(ctx context.Context, arg sqlc.UpdateDatabaseRestoreRunningParams) (sqlc.DatabaseRestore, error) {
+	restore, ok := f.restores[arg.ID]
+	if !ok || (restore.Status != sqlc.DatabaseRestoreStatusPending && restore.Status != sqlc.DatabaseRestoreStatusRunning) {
+		return sqlc.DatabaseRestore{}, pgx.ErrNoRows
+	}
+	restore.Status = sqlc.DatabaseRestoreStatusRunning
+	restore.StartedAt = arg.StartedAt
+	restore.BytesRestored = 0
+	f.restores[restore.ID] = restore
+	return restore, nil
+}
+
+func (f *fakeQuerier) UpdateDatabaseRestoreProgress(ctx context.Context, arg sqlc.UpdateDatabaseRestoreProgressParams) error {
+	f.progress = append(f.progress, arg.BytesRestored)
+	if restore, ok := f.restores[arg.ID]; ok && restore.Status == sqlc.DatabaseRestoreStatusRunning {
+		restore.BytesRestored = arg.BytesRestored
+		f.restores[arg.ID] = restore
+	}
+	return nil
+}
+
+func (f *fakeQuerier) UpdateDatabaseRestoreSucceeded(ctx context.Context, arg sqlc.UpdateDatabaseRestoreSucceededParams) (sqlc.DatabaseRestore, error) {
+	restore, ok := f.restores[arg.ID]
+	if !ok {
+		return sqlc.DatabaseRestore{}, pgx.ErrNoRows
+	}
+	restore.Status = sqlc.DatabaseRestoreStatusSucceeded
+	restore.BytesRestored = restore.BytesTotal
+	restore.CompletedAt = arg.CompletedAt
+	f.restores[restore.ID] = restore
+	return restore, nil
+}
+
+func (f *fakeQuerier) UpdateDatabaseRestoreFailed(ctx context.Context, arg sqlc.UpdateDatabaseRestoreFailedParams) (sqlc.DatabaseRestore, error) {
+	restore, ok := f.restores[arg.ID]
+	if !ok {
+		return sqlc.DatabaseRestore{}, pgx.ErrNoRows
+	}
+	restore.Status = sqlc.DatabaseRestoreStatusFailed
+	restore.LastError = arg.LastError
+	restore.CompletedAt = arg.CompletedAt
+	f.restores[restore.ID] = restore
+	return restore, nil
+}
+
+// backupAt takes a backup of db completed at the fixture's current time.
+func (f *fixture) backupAt(t *testing.T, db sqlc.ManagedDatabase, at time.Time) sqlc.DatabaseBackup {
+	t.Helper()
+	f.now = at
+	backup, err := f.svc.CreateBackup(context.Background(), "p1", db.ID)
+	require.NoError(t, err)
+	f.runJobs(t)
+	return f.queries.backups[backup.ID]
+}
+
+func TestRestore(t *testing.T) {
+	ctx := context.Background()
+	f := setupService(t, "")
+	db := f.runningDatabase(t, sqlc.ManagedDatabaseTypePostgresql)
+
+	monday := time.Date(2025, 6, 30, 3, 0, 0, 0, time.UTC)
+	first := f.backupAt(t, db, monday)
+	second := f.backupAt(t, db, monday.Add(24*time.Hour))
+	f.now = monday.Add(48 * time.Hour)
+
+	_, err := f.svc.Restore(ctx, "p1", db.ID, RestoreInput{At: monday.Add(-time.Hour)})
+	assert.ErrorIs(t, err, ErrBackupNotFound, "there is no backup from before the first")
+	_, err = f.svc.Restore(ctx, "p1", db.ID, RestoreInput{BackupID: "missing"})
+	assert.ErrorIs(t, err, ErrBackupNotFound)
+
+	restore, err := f.svc.Restore(ctx, "p1", db.ID, RestoreInput{At: monday.Add(time.Hour)})
+	require.NoError(t, err)
+	assert.Equal(t, first.ID, restore.BackupID.String, "the newest backup before the point in time is restored")
+	assert.Equal(t, first.SizeBytes, restore.BytesTotal)
+	assert.Equal(t, sqlc.DatabaseRestoreStatusPending, restore.Status)
+
+	_, err = f.svc.Restore(ctx, "p1", db.ID, RestoreInput{})
+	assert.ErrorIs(t, err, ErrRestoreInProgress)
+
+	f.runJobs(t)
+	restore, err = f.svc.GetRestore(ctx, "p1", db.ID, restore.ID)
+	require.NoError(t, err)
+	assert.Equal(t, sqlc.DatabaseRestoreStatusSucceeded, restore.Status)
+	assert.Equal(t, restore.BytesTotal, restore.BytesRestored)
+	assert.Equal(t, "dump 1 of app\n", string(f.dumper.restored))
+
+	restore, err = f.svc.Restore(ctx, "p1", db.ID, RestoreInput{})
+	require.NoError(t, err)
+	assert.Equal(t, second.ID, restore.BackupID.String, "without a point in time the newest backup is restored")
+
+	_, err = f.svc.GetRestore(ctx, "p2", db.ID, restore.ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestRestoreFailures(t *testing.T) {
+	ctx := context.Background()
+	f := setupService(t, "")
+	db := f.runningDatabase(t, sqlc.ManagedDatabaseTypePostgresql)
+
+	f.dumper.dumpErr = errors.New("pg_dump failed")
+	failed := f.backupAt(t, db, f.now)
+	_, err := f.svc.Restore(ctx, "p1", db.ID, RestoreInput{BackupID: failed.ID})
+	assert.ErrorIs(t, err, ErrBackupNotReady)
+
+	f.dumper.dumpErr = nil
+	backup := f.backupAt(t, db, f.now.Add(time.Hour))
+
+	f.dumper.restoreErr = errors.New("pg_restore failed: relation already exists")
+	restore, err := f.svc.Restore(ctx, "p1", db.ID, RestoreInput{BackupID: backup.ID})
+	require.NoError(t, err)
+	f.runJobs(t)
+
+	restore = f.queries.restores[restore.ID]
+	assert.Equal(t, sqlc.DatabaseRestoreStatusFailed, restore.Status)
+	assert.Contains(t, restore.LastError.String, "relation already exists")
+
+	f.dumper.restoreErr = nil
+	require.NoError(t, f.store.Delete(ctx, backup.ObjectKey))
+	restore, err = f.svc.Restore(ctx, "p1", db.ID, RestoreInput{BackupID: backup.ID})
+	require.NoError(t, err, "a failed restore does not block the next")
+	f.runJobs(t)
+
+	restore = f.queries.restores[restore.ID]
+	assert.Equal(t, sqlc.DatabaseRestoreStatusFailed, restore.Status)
+	assert.Contains(t, restore.LastError.String, "object not found")
+
+	f.queries.enqueueErr = errors.New("queue is down")
+	restores := len(f.queries.restores)
+	_, err = f.svc.Restore(ctx, "p1", db.ID, RestoreInput{BackupID: backup.ID})
+	assert.Error(t, err)
+	assert.Len(t, f.queries.restores, restores, "a restore whose job was not queued is rolled back")
+}
+
+func TestProgressReaderReportsPeriodically(t *testing.T) {
+	now := time.Unix(0, 0)
+	var reported []int64
+	p := &progressReader{
+		r:      bytes.NewReader(make([]byte, 10)),
+		now:    func() time.Time { return now },
+		last:   now,
+		report: func(n int64) { reported = append(reported, n) },
+	}
+
+	buf := make([]byte, 4)
+	_, err := p.Read(buf)
+	require.NoError(t, err)
+	assert.Empty(t, reported, "progress is not reported more than once per interval")
+
+	now = now.Add(restoreProgressInterval)
+	_, err = p.Read(buf)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{8}, reported)
+
+	now = now.Add(restoreProgressInterval)
+	_, err = io.ReadAll(p)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{8, 10}, reported)
+}
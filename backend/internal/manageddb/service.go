@@ -0,0 +1,277 @@
+// Package manageddb provisions PostgreSQL and Redis databases for projects.
+// Each database runs in its own container, started by a provisioning job
+// with generated credentials. Its connection string is encrypted at rest
+// and handed to the project's deployments as DATABASE_URL or REDIS_URL.
+// PostgreSQL databases are backed up to an object store on a cron schedule
+// and can be restored from those backups.
+package manageddb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	gonanoid "github.com/matoous/go-nanoid/v2"
+
+	"github.com/Jesuloba-world/deployease/backend/internal/config"
+	"github.com/Jesuloba-world/deployease/backend/internal/container"
+	"github.com/Jesuloba-world/deployease/backend/internal/deployment"
+	"github.com/Jesuloba-world/deployease/backend/internal/infrastructure/database/postgres/sqlc"
+	"github.com/Jesuloba-world/deployease/backend/internal/jobs"
+	"github.com/Jesuloba-world/deployease/backend/internal/objectstore"
+	"github.com/Jesuloba-world/deployease/backend/internal/secrets"
+)
+
+var (
+	ErrNotFound       = errors.New("database not found")
+	ErrTaken          = errors.New("project already has a database of this type")
+	ErrInvalidName    = errors.New("database names must start with a lowercase letter and contain only lowercase letters, digits, hyphens and underscores, up to 63 characters")
+	ErrInvalidType    = errors.New("database type must be postgresql or redis")
+	ErrInvalidVersion = errors.New("database version must be an image tag such as 16 or 7.2-alpine")
+	ErrInvalidSize    = errors.New("database size must be small, medium or large")
+)
+
+const pgUniqueViolation = "23505"
+
+var (
+	namePattern    = regexp.MustCompile(`^[a-z][a-z0-9_-]{0,62}$`)
+	versionPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,31}$`)
+)
+
+// defaultVersions are used when a database is created without a version.
+var defaultVersions = map[sqlc.ManagedDatabaseType]string{
+	sqlc.ManagedDatabaseTypePostgresql: "16",
+	sqlc.ManagedDatabaseTypeRedis:      "7",
+}
+
+// sizes are the memory limits of the database containers.
+var sizes = map[string]int64{
+	"small":  256 << 20,
+	"medium": 1 << 30,
+	"large":  4 << 30,
+}
+
+const defaultSize = "small"
+
+type Service struct {
+	queries    sqlc.Querier
+	queue      *jobs.Queue
+	cipher     *secrets.Cipher
+	runtime    container.Runtime
+	runtimeCfg config.RuntimeConfig
+	cfg        config.ManagedDatabasesConfig
+	store      objectstore.Store
+	dumper     Dumper
+	backupsCfg config.BackupsConfig
+	// withTx runs fn in a transaction with a querier bound to it, so a row
+	// and the job acting on it are committed together.
+	withTx func(ctx context.Context, fn func(q sqlc.Querier) error) error
+	// ready waits until something accepts connections at addr.
+	ready func(ctx context.Context, addr string) error
+	now   func() time.Time
+}
+
+func NewService(
+	db deployment.TxBeginner,
+	queries sqlc.Querier,
+	queue *jobs.Queue,
+	cipher *secrets.Cipher,
+	runtime container.Runtime,
+	runtimeCfg config.RuntimeConfig,
+	cfg config.ManagedDatabasesConfig,
+	store objectstore.Store,
+	dumper Dumper,
+	backupsCfg config.BackupsConfig,
+) *Service {
+	return &Service{
+		queries:    queries,
+		withTx:     transactor(db),
+		queue:      queue,
+		cipher:     cipher,
+		runtime:    runtime,
+		runtimeCfg: runtimeCfg,
+		cfg:        cfg,
+		store:      store,
+		dumper:     dumper,
+		backupsCfg: backupsCfg,
+		ready:      waitForListener,
+		now:        time.Now,
+	}
+}
+
+type CreateInput struct {
+	Name    string
+	Type    sqlc.ManagedDatabaseType
+	Version string
+	Size    string
+}
+
+// Create records a database for the project and queues its provisioning.
+// A project has at most one database of each type, so the variable its
+// deployments receive is unambiguous.
+func (s *Service) Create(ctx context.Context, projectID string, in CreateInput) (sqlc.ManagedDatabase, error) {
+	if _, ok := defaultVersions[in.Type]; !ok {
+		return sqlc.ManagedDatabase{}, ErrInvalidType
+	}
+	if !namePattern.MatchString(in.Name) {
+		return sqlc.ManagedDatabase{}, ErrInvalidName
+	}
+	if in.Version == "" {
+		in.Version = defaultVersions[in.Type]
+	}
+	if !versionPattern.MatchString(in.Version) {
+		return sqlc.ManagedDatabase{}, ErrInvalidVersion
+	}
+	if in.Size == "" {
+		in.Size = defaultSize
+	}
+	if _, ok := sizes[in.Size]; !ok {
+		return sqlc.ManagedDatabase{}, ErrInvalidSize
+	}
+
+	var db sqlc.ManagedDatabase
+	err := s.withTx(ctx, func(q sqlc.Querier) error {
+		var err error
+		db, err = q.CreateManagedDatabase(ctx, sqlc.CreateManagedDatabaseParams{
+			ID:        gonanoid.Must(),
+			ProjectID: projectID,
+			Name:      in.Name,
+			Type:      in.Type,
+			Version:   in.Version,
+			Size:      in.Size,
+		})
+		if err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation {
+				return ErrTaken
+			}
+			return fmt.Errorf("failed to create database: %w", err)
+		}
+
+		_, err = s.queue.WithQuerier(q).Enqueue(ctx, ProvisionJobKind, ProvisionPayload{DatabaseID: db.ID})
+		return err
+	})
+	if err != nil {
+		return sqlc.ManagedDatabase{}, err
+	}
+	return db, nil
+}
+
+// List returns the project's databases, oldest first.
+func (s *Service) List(ctx context.Context, projectID string) ([]sqlc.ManagedDatabase, error) {
+	dbs, err := s.queries.ListManagedDatabases(ctx, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list databases: %w", err)
+	}
+	return dbs, nil
+}
+
+func (s *Service) Get(ctx context.Context, projectID, databaseID string) (sqlc.ManagedDatabase, error) {
+	db, err := s.queries.GetManagedDatabaseForProject(ctx, sqlc.GetManagedDatabaseForProjectParams{
+		ID:        databaseID,
+		ProjectID: projectID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return sqlc.ManagedDatabase{}, ErrNotFound
+		}
+		return sqlc.ManagedDatabase{}, fmt.Errorf("failed to get database: %w", err)
+	}
+	return db, nil
+}
+
+// Delete stops handing the database to deployments and queues the removal
+// of its container. The data is gone once the job has run.
+func (s *Service) Delete(ctx context.Context, projectID, databaseID string) (sqlc.ManagedDatabase, error) {
+	var db sqlc.ManagedDatabase
+	err := s.withTx(ctx, func(q sqlc.Querier) error {
+		var err error
+		db, err = q.MarkManagedDatabaseDeleting(ctx, sqlc.MarkManagedDatabaseDeletingParams{
+			ID:        databaseID,
+			ProjectID: projectID,
+		})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrNotFound
+			}
+			return fmt.Errorf("failed to delete database: %w", err)
+		}
+
+		_, err = s.queue.WithQuerier(q).Enqueue(ctx, DeprovisionJobKind, ProvisionPayload{DatabaseID: db.ID})
+		return err
+	})
+	if err != nil {
+		return sqlc.ManagedDatabase{}, err
+	}
+	return db, nil
+}
+
+// transactor runs functions in transactions begun on db.
+func transactor(db deployment.TxBeginner) func(ctx context.Context, fn func(q sqlc.Querier) error) error {
+	return func(ctx context.Context, fn func(q sqlc.Querier) error) error {
+		tx, err := db.Begin(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to begin transaction: %w", err)
+		}
+		defer tx.Rollback(ctx)
+
+		if err := fn(sqlc.New(tx)); err != nil {
+			return err
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			return fmt.Errorf("failed to commit transaction: %w", err)
+		}
+
+		return nil
+	}
+}
+
+// ConnectionString decrypts the connection string of a running database.
+// Other databases have none.
+func (s *Service) ConnectionString(db sqlc.ManagedDatabase) (string, error) {
+	if db.Status != sqlc.ManagedDatabaseStatusRunning || len(db.EncryptedConnectionString) == 0 {
+		return "", nil
+	}
+	url, err := s.cipher.OpenString(db.EncryptedConnectionString, associatedData(db.ID))
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt connection string: %w", err)
+	}
+	return url, nil
+}
+
+// Resolve returns the variables pointing a project's deployments at its
+// running databases. Databases are shared by every branch of the project.
+func (s *Service) Resolve(ctx context.Context, projectID, branch string) (map[string]string, error) {
+	dbs, err := s.queries.ListRunningManagedDatabases(ctx, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list databases: %w", err)
+	}
+
+	env := make(map[string]string, len(dbs))
+	for _, db := range dbs {
+		url, err := s.ConnectionString(db)
+		if err != nil {
+			return nil, fmt.Errorf("database %s: %w", db.Name, err)
+		}
+		env[EnvVar(db.Type)] = url
+	}
+	return env, nil
+}
+
+// EnvVar is the variable a database's connection string is passed in.
+func EnvVar(t sqlc.ManagedDatabaseType) string {
+	if t == sqlc.ManagedDatabaseTypeRedis {
+		return "REDIS_URL"
+	}
+	return "DATABASE_URL"
+}
+
+// associatedData binds a sealed connection string to its database.
+func associatedData(databaseID string) string {
+	return "managed_databases/" + databaseID
+}
@@ -0,0 +1,411 @@
+package manageddb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"maps"
+	"net/url"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Jesuloba-world/deployease/backend/internal/config"
+	"github.com/Jesuloba-world/deployease/backend/internal/container"
+	"github.com/Jesuloba-world/deployease/backend/internal/container/containertest"
+	"github.com/Jesuloba-world/deployease/backend/internal/infrastructure/database/postgres/sqlc"
+	"github.com/Jesuloba-world/deployease/backend/internal/jobs"
+	"github.com/Jesuloba-world/deployease/backend/internal/objectstore"
+	"github.com/Jesuloba-world/deployease/backend/internal/secrets"
+)
+
+type fakeQuerier struct {
+	sqlc.Querier
+	databases  map[string]sqlc.ManagedDatabase
+	backups    map[string]sqlc.DatabaseBackup
+	restores   map[string]sqlc.DatabaseRestore
+	progress   []int64
+	jobs       []sqlc.Job
+	enqueueErr error
+	// created orders backups and restores by creation.
+	created int
+}
+
+// inTx stands in for a transaction: whatever fn changed is undone if it
+// fails.
+func (f *fakeQuerier) inTx(ctx context.Context, fn func(q sqlc.Querier) error) error {
+	databases, backups, restores := maps.Clone(f.databases), maps.Clone(f.backups), maps.Clone(f.restores)
+	queued := slices.Clone(f.jobs)
+	if err := fn(f); err != nil {
+		f.databases, f.backups, f.restores, f.jobs = databases, backups, restores, queued
+		return err
+	}
+	return nil
+}
+
+func (f *fakeQuerier) CreateManagedDatabase(ctx context.Context, arg sqlc.CreateManagedDatabaseParams) (sqlc.ManagedDatabase, error) {
+	for _, db := range f.databases {
+		if db.ProjectID == arg.ProjectID && db.Type == arg.Type {
+			return sqlc.ManagedDatabase{}, &pgconn.PgError{Code: pgUniqueViolation}
+		}
+	}
+	now := pgtype.Timestamptz{Time: time.Now(), Valid: true}
+	db := sqlc.ManagedDatabase{
+		ID:        arg.ID,
+		ProjectID: arg.ProjectID,
+		Name:      arg.Name,
+		Type:      arg.Type,
+		Version:   arg.Version,
+		Size:      arg.Size,
+		Status:    sqlc.ManagedDatabaseStatusProvisioning,
+		// The column default.
+		BackupRetention: 7,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	f.databases[db.ID] = db
+	return db, nil
+}
+
+func (f *fakeQuerier) GetManagedDatabase(ctx context.Context, id string) (sqlc.ManagedDatabase, error) {
+	db, ok := f.databases[id]
+	if !ok {
+		return sqlc.ManagedDatabase{}, pgx.ErrNoRows
+	}
+	return db, nil
+}
+
+func (f *fakeQuerier) GetManagedDatabaseForProject(ctx context.Context, arg sqlc.GetManagedDatabaseForProjectParams) (sqlc.ManagedDatabase, error) {
+	db, ok := f.databases[arg.ID]
+	if !ok || db.ProjectID != arg.ProjectID {
+		return sqlc.ManagedDatabase{}, pgx.ErrNoRows
+	}
+	return db, nil
+}
+
+func (f *fakeQuerier) ListRunningManagedDatabases(ctx context.Context, projectID string) ([]sqlc.ManagedDatabase, error) {
+	dbs := []sqlc.ManagedDatabase{}
+	for _, db := range f.databases {
+		if db.ProjectID == projectID && db.Status == sqlc.ManagedDatabaseStatusRunning {
+			dbs = append(dbs, db)
+		}
+	}
+	return dbs, nil
+}
+
+func (f *fakeQuerier) SetManagedDatabaseContainer(ctx context.Context, arg sqlc.SetManagedDatabaseContainerParams) error {
+	if db, ok := f.databases[arg.ID]; ok {
+		db.ContainerID = arg.ContainerID
+		f.databases[arg.ID] = db
+	}
+	return nil
+}
+
+func (f *fakeQuerier) UpdateManagedDatabaseRunning(ctx context.Context, arg sqlc.UpdateManagedDatabaseRunningParams) (sqlc.ManagedDatabase, error) {
+	db, ok := f.databases[arg.ID]
+	if !ok || db.Status != sqlc.ManagedDatabaseStatusProvisioning {
+		return sqlc.ManagedDatabase{}, pgx.ErrNoRows
+	}
+	db.Status = sqlc.ManagedDatabaseStatusRunning
+	db.EncryptedConnectionString = arg.EncryptedConnectionString
+	db.LastError = pgtype.Text{}
+	f.databases[db.ID] = db
+	return db, nil
+}
+
+func (f *fakeQuerier) UpdateManagedDatabaseFailed(ctx context.Context, arg sqlc.UpdateManagedDatabaseFailedParams) (sqlc.ManagedDatabase, error) {
+	db, ok := f.databases[arg.ID]
+	if !ok || db.Status != sqlc.ManagedDatabaseStatusProvisioning {
+		return sqlc.ManagedDatabase{}, pgx.ErrNoRows
+	}
+	db.Status = sqlc.ManagedDatabaseStatusFailed
+	db.LastError = arg.LastError
+	f.databases[db.ID] = db
+	return db, nil
+}
+
+func (f *fakeQuerier) MarkManagedDatabaseDeleting(ctx context.Context, arg sqlc.MarkManagedDatabaseDeletingParams) (sqlc.ManagedDatabase, error) {
+	db, ok := f.databases[arg.ID]
+	if !ok || db.ProjectID != arg.ProjectID {
+		return sqlc.ManagedDatabase{}, pgx.ErrNoRows
+	}
+	db.Status = sqlc.ManagedDatabaseStatusDeleting
+	f.databases[db.ID] = db
+	return db, nil
+}
+
+func (f *fakeQuerier) DeleteManagedDatabase(ctx context.Context, id string) error {
+	delete(f.databases, id)
+	return nil
+}
+
+func (f *fakeQuerier) EnqueueJob(ctx context.Context, arg sqlc.EnqueueJobParams) (sqlc.Job, error) {
+	if f.enqueueErr != nil {
+		return sqlc.Job{}, f.enqueueErr
+	}
+	job := sqlc.Job{ID: arg.ID, Kind: arg.Kind, Payload: arg.Payload}
+	f.jobs = append(f.jobs, job)
+	return job, nil
+}
+
+type fixture struct {
+	svc     *Service
+	queries *fakeQuerier
+	runtime *containertest.Runtime
+	store   objectstore.Store
+	dumper  *fakeDumper
+	now     time.Time
+	// readyErr makes every database fail to accept connections.
+	readyErr error
+	dialled  []string
+}
+
+func setupService(t *testing.T, network string) *fixture {
+	t.Helper()
+	cipher, err := secrets.NewCipher(config.SecretsConfig{MasterKey: "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY="})
+	require.NoError(t, err)
+
+	f := &fixture{
+		queries: &fakeQuerier{
+			databases: make(map[string]sqlc.ManagedDatabase),
+			backups:   make(map[string]sqlc.DatabaseBackup),
+			restores:  make(map[string]sqlc.DatabaseRestore),
+		},
+		runtime: containertest.NewRuntime(),
+		store:   objectstore.NewFileStore(t.TempDir()),
+		dumper:  &fakeDumper{},
+		now:     time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC),
+	}
+	f.svc = NewService(nil, f.queries, jobs.NewQueue(f.queries, 5), cipher, f.runtime,
+		config.RuntimeConfig{Network: network},
+		config.ManagedDatabasesConfig{PostgresImage: "postgres", RedisImage: "redis", ReadyTimeout: time.Second},
+		f.store, f.dumper,
+		config.BackupsConfig{CheckInterval: time.Minute, Timeout: time.Hour},
+	)
+	f.svc.ready = func(ctx context.Context, addr string) error {
+		f.dialled = append(f.dialled, addr)
+		return f.readyErr
+	}
+	f.svc.now = func() time.Time { return f.now }
+	f.svc.withTx = f.queries.inTx
+	return f
+}
+
+// runJobs hands every queued job to its handler, like the worker would.
+func (f *fixture) runJobs(t *testing.T) {
+	t.Helper()
+	queued := f.queries.jobs
+	f.queries.jobs = nil
+	for _, job := range queued {
+		switch job.Kind {
+		case ProvisionJobKind:
+			require.NoError(t, f.svc.HandleProvision(context.Background(), job))
+		case DeprovisionJobKind:
+			require.NoError(t, f.svc.HandleDeprovision(context.Background(), job))
+		case BackupJobKind:
+			require.NoError(t, f.svc.HandleBackup(context.Background(), job))
+		case RestoreJobKind:
+			require.NoError(t, f.svc.HandleRestore(context.Background(), job))
+		default:
+			t.Fatalf("unexpected job %s", job.Kind)
+		}
+	}
+}
+
+func TestCreateValidatesAndQueuesProvisioning(t *testing.T) {
+	ctx := context.Background()
+	f := setupService(t, "")
+
+	_, err := f.svc.Create(ctx, "p1", CreateInput{Name: "app", Type: "mysql"})
+	assert.ErrorIs(t, err, ErrInvalidType)
+	_, err = f.svc.Create(ctx, "p1", CreateInput{Name: "App DB", Type: sqlc.ManagedDatabaseTypePostgresql})
+	assert.ErrorIs(t, err, ErrInvalidName)
+	_, err = f.svc.Create(ctx, "p1", CreateInput{Name: "app", Type: sqlc.ManagedDatabaseTypePostgresql, Version: "16; rm -rf /"})
+	assert.ErrorIs(t, err, ErrInvalidVersion)
+	_, err = f.svc.Create(ctx, "p1", CreateInput{Name: "app", Type: sqlc.ManagedDatabaseTypePostgresql, Size: "huge"})
+	assert.ErrorIs(t, err, ErrInvalidSize)
+	assert.Empty(t, f.queries.databases)
+
+	db, err := f.svc.Create(ctx, "p1", CreateInput{Name: "app", Type: sqlc.ManagedDatabaseTypePostgresql})
+	require.NoError(t, err)
+	assert.Equal(t, sqlc.ManagedDatabaseStatusProvisioning, db.Status)
+	assert.Equal(t, "16", db.Version, "the default version is used")
+	assert.Equal(t, "small", db.Size, "the default size is used")
+
+	require.Len(t, f.queries.jobs, 1)
+	assert.Equal(t, ProvisionJobKind, f.queries.jobs[0].Kind)
+	var payload ProvisionPayload
+	require.NoError(t, json.Unmarshal(f.queries.jobs[0].Payload, &payload))
+	assert.Equal(t, db.ID, payload.DatabaseID)
+
+	_, err = f.svc.Create(ctx, "p1", CreateInput{Name: "other", Type: sqlc.ManagedDatabaseTypePostgresql})
+	assert.ErrorIs(t, err, ErrTaken, "a project has one database of each type")
+
+	f.queries.enqueueErr = errors.New("queue is down")
+	_, err = f.svc.Create(ctx, "p1", CreateInput{Name: "cache", Type: sqlc.ManagedDatabaseTypeRedis})
+	assert.Error(t, err)
+	assert.Len(t, f.queries.databases, 1, "a database whose provisioning was not queued is rolled back")
+}
+
+func TestProvisionPostgres(t *testing.T) {
+	ctx := context.Background()
+	f := setupService(t, "deployease")
+
+	db, err := f.svc.Create(ctx, "p1", CreateInput{Name: "app", Type: sqlc.ManagedDatabaseTypePostgresql, Version: "15", Size: "medium"})
+	require.NoError(t, err)
+	f.runJobs(t)
+
+	db, err = f.svc.Get(ctx, "p1", db.ID)
+	require.NoError(t, err)
+	assert.Equal(t, sqlc.ManagedDatabaseStatusRunning, db.Status)
+	assert.Equal(t, []string{"postgres:15"}, f.runtime.Pulled())
+
+	spec, ok := f.runtime.Spec(db.ContainerID.String)
+	require.True(t, ok)
+	assert.Equal(t, "deployease-db-"+db.ID, spec.Name)
+	assert.Equal(t, "deployease", spec.Network)
+	assert.Equal(t, int64(1<<30), spec.Memory)
+	assert.Equal(t, "p1", spec.Labels[container.LabelProject])
+	assert.Equal(t, db.ID, spec.Labels[container.LabelDatabase])
+	assert.Equal(t, "app", spec.Env["POSTGRES_DB"])
+	password := spec.Env["POSTGRES_PASSWORD"]
+	assert.Len(t, password, passwordLength)
+	assert.Len(t, f.dialled, 1)
+
+	connString, err := f.svc.ConnectionString(db)
+	require.NoError(t, err)
+	u, err := url.Parse(connString)
+	require.NoError(t, err)
+	assert.Equal(t, "postgres", u.Scheme)
+	assert.Equal(t, spec.Name+":5432", u.Host, "containers on a network are reached by name")
+	assert.Equal(t, "/app", u.Path)
+	assert.Equal(t, postgresUser, u.User.Username())
+	stored, _ := u.User.Password()
+	assert.Equal(t, password, stored)
+	assert.NotContains(t, string(db.EncryptedConnectionString), password, "the connection string is encrypted at rest")
+
+	env, err := f.svc.Resolve(ctx, "p1", "main")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"DATABASE_URL": connString}, env)
+
+	other, err := f.svc.Resolve(ctx, "p2", "main")
+	require.NoError(t, err)
+	assert.Empty(t, other, "databases are only handed to their own project")
+}
+
+func TestProvisionRedis(t *testing.T) {
+	ctx := context.Background()
+	f := setupService(t, "")
+
+	db, err := f.svc.Create(ctx, "p1", CreateInput{Name: "cache", Type: sqlc.ManagedDatabaseTypeRedis})
+	require.NoError(t, err)
+	f.runJobs(t)
+
+	db, err = f.svc.Get(ctx, "p1", db.ID)
+	require.NoError(t, err)
+	assert.Equal(t, sqlc.ManagedDatabaseStatusRunning, db.Status)
+	assert.Equal(t, []string{"redis:7"}, f.runtime.Pulled())
+
+	spec, ok := f.runtime.Spec(db.ContainerID.String)
+	require.True(t, ok)
+	require.Len(t, spec.Cmd, 5)
+	assert.Equal(t, "--requirepass", spec.Cmd[1])
+	password := spec.Cmd[2]
+
+	info, err := f.runtime.Inspect(ctx, db.ContainerID.String)
+	require.NoError(t, err)
+
+	env, err := f.svc.Resolve(ctx, "p1", "main")
+	require.NoError(t, err)
+	assert.Equal(t, "redis://:"+password+"@"+info.IPAddress+":6379/0", env["REDIS_URL"], "without a network the container is reached by address")
+}
+
+func TestProvisionFailures(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("image cannot be pulled", func(t *testing.T) {
+		f := setupService(t, "")
+		f.runtime.PullErr = errors.New("manifest for postgres:99 not found")
+
+		db, err := f.svc.Create(ctx, "p1", CreateInput{Name: "app", Type: sqlc.ManagedDatabaseTypePostgresql, Version: "99"})
+		require.NoError(t, err)
+		f.runJobs(t)
+
+		db, err = f.svc.Get(ctx, "p1", db.ID)
+		require.NoError(t, err)
+		assert.Equal(t, sqlc.ManagedDatabaseStatusFailed, db.Status)
+		assert.Contains(t, db.LastError.String, "manifest for postgres:99 not found")
+	})
+
+	t.Run("database never accepts connections", func(t *testing.T) {
+		f := setupService(t, "")
+		f.readyErr = context.DeadlineExceeded
+
+		db, err := f.svc.Create(ctx, "p1", CreateInput{Name: "app", Type: sqlc.ManagedDatabaseTypePostgresql})
+		require.NoError(t, err)
+		f.runJobs(t)
+
+		db, err = f.svc.Get(ctx, "p1", db.ID)
+		require.NoError(t, err)
+		assert.Equal(t, sqlc.ManagedDatabaseStatusFailed, db.Status)
+		assert.Contains(t, db.LastError.String, "did not accept connections")
+		assert.Empty(t, f.runtime.Running(container.LabelDatabase, db.ID), "the container is removed")
+
+		env, err := f.svc.Resolve(ctx, "p1", "main")
+		require.NoError(t, err)
+		assert.Empty(t, env)
+	})
+}
+
+func TestDeleteRemovesContainer(t *testing.T) {
+	ctx := context.Background()
+	f := setupService(t, "")
+
+	db, err := f.svc.Create(ctx, "p1", CreateInput{Name: "app", Type: sqlc.ManagedDatabaseTypePostgresql})
+	require.NoError(t, err)
+	f.runJobs(t)
+	require.Len(t, f.runtime.Running(container.LabelDatabase, db.ID), 1)
+
+	_, err = f.svc.Delete(ctx, "p2", db.ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	f.queries.enqueueErr = errors.New("queue is down")
+	_, err = f.svc.Delete(ctx, "p1", db.ID)
+	assert.Error(t, err)
+	assert.Equal(t, sqlc.ManagedDatabaseStatusRunning, f.queries.databases[db.ID].Status,
+		"a database whose removal was not queued keeps running")
+	f.queries.enqueueErr = nil
+
+	deleting, err := f.svc.Delete(ctx, "p1", db.ID)
+	require.NoError(t, err)
+	assert.Equal(t, sqlc.ManagedDatabaseStatusDeleting, deleting.Status)
+
+	env, err := f.svc.Resolve(ctx, "p1", "main")
+	require.NoError(t, err)
+	assert.Empty(t, env, "a database being deleted is no longer handed to deployments")
+
+	f.runJobs(t)
+	assert.Empty(t, f.runtime.Running(container.LabelDatabase, db.ID))
+	_, err = f.svc.Get(ctx, "p1", db.ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestDeleteBeforeProvisioning(t *testing.T) {
+	ctx := context.Background()
+	f := setupService(t, "")
+
+	db, err := f.svc.Create(ctx, "p1", CreateInput{Name: "app", Type: sqlc.ManagedDatabaseTypePostgresql})
+	require.NoError(t, err)
+	_, err = f.svc.Delete(ctx, "p1", db.ID)
+	require.NoError(t, err)
+
+	f.runJobs(t)
+	assert.Empty(t, f.runtime.Pulled(), "a database deleted before its provisioning ran is never started")
+	assert.Empty(t, f.queries.databases)
+}
@@ -0,0 +1,51 @@
+// Package migrate applies the goose migrations under sql/migrations, either
+// from the server binary's migrate command or on startup when
+// database.auto_migrate is set.
+package migrate
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/pressly/goose/v3"
+	"github.com/pressly/goose/v3/lock"
+
+	"github.com/Jesuloba-world/deployease/backend/internal/config"
+	"github.com/Jesuloba-world/deployease/backend/sql/migrations"
+)
+
+// Source returns the migrations to apply: those in the configured
+// migrations directory if one is set, otherwise the ones embedded in the
+// binary.
+func Source(cfg config.DatabaseConfig) fs.FS {
+	if cfg.MigrationsDir != "" {
+		return os.DirFS(cfg.MigrationsDir)
+	}
+	return migrations.FS
+}
+
+// New returns a goose provider applying the configured migrations to pool.
+// It holds a Postgres advisory lock while migrating, so replicas starting
+// together apply each migration once. Closing the provider releases its
+// connections back to pool without closing it.
+func New(pool *pgxpool.Pool, cfg config.DatabaseConfig) (*goose.Provider, error) {
+	locker, err := lock.NewPostgresSessionLocker()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create migration lock: %w", err)
+	}
+
+	provider, err := goose.NewProvider(
+		goose.DialectPostgres,
+		stdlib.OpenDBFromPool(pool),
+		Source(cfg),
+		goose.WithSessionLocker(locker),
+		goose.WithDisableGlobalRegistry(true),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load migrations: %w", err)
+	}
+	return provider, nil
+}
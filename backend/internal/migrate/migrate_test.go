@@ -0,0 +1,122 @@
+package migrate
+
+import (
+	"context"
+	"io/fs"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/pressly/goose/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
+
+	"github.com/Jesuloba-world/deployease/backend/internal/config"
+	database "github.com/Jesuloba-world/deployease/backend/internal/infrastructure/database/postgres"
+	"github.com/Jesuloba-world/deployease/backend/sql/migrations"
+)
+
+func TestSourcePrefersMigrationsDir(t *testing.T) {
+	assert.Equal(t, migrations.FS, Source(config.DatabaseConfig{}))
+
+	dir := t.TempDir()
+	names, err := fs.Glob(Source(config.DatabaseConfig{MigrationsDir: dir}), "*.sql")
+	require.NoError(t, err)
+	assert.Empty(t, names)
+}
+
+func TestNewLoadsEmbeddedMigrations(t *testing.T) {
+	// The pool connects lazily, so loading the migrations needs no database.
+	pool, err := pgxpool.New(context.Background(), "postgres://deployease@127.0.0.1:1/deployease")
+	require.NoError(t, err)
+	t.Cleanup(pool.Close)
+
+	provider, err := New(pool, config.DatabaseConfig{})
+	require.NoError(t, err)
+	t.Cleanup(func() { provider.Close() })
+
+	names, err := fs.Glob(migrations.FS, "*.sql")
+	require.NoError(t, err)
+	sources := provider.ListSources()
+	require.Len(t, sources, len(names))
+	for i, s := range sources {
+		assert.Equal(t, goose.TypeSQL, s.Type)
+		if i > 0 {
+			assert.Greater(t, s.Version, sources[i-1].Version)
+		}
+	}
+}
+
+func TestNewRejectsEmptyMigrationsDir(t *testing.T) {
+	pool, err := pgxpool.New(context.Background(), "postgres://deployease@127.0.0.1:1/deployease")
+	require.NoError(t, err)
+	t.Cleanup(pool.Close)
+
+	_, err = New(pool, config.DatabaseConfig{MigrationsDir: t.TempDir()})
+	assert.ErrorIs(t, err, goose.ErrNoMigrations)
+}
+
+func setupProvider(t *testing.T) (*goose.Provider, *database.TestContainer) {
+	t.Helper()
+	testcontainers.SkipIfProviderIsNotHealthy(t)
+
+	tc := database.SetupTestContainer(t)
+	t.Cleanup(func() { tc.Cleanup(t) })
+
+	provider, err := New(tc.Pool, config.DatabaseConfig{})
+	require.NoError(t, err)
+	t.Cleanup(func() { provider.Close() })
+	return provider, tc
+}
+
+func TestProviderUpDownStatus(t *testing.T) {
+	provider, _ := setupProvider(t)
+	ctx := context.Background()
+	sources := provider.ListSources()
+	total := len(sources)
+	latest := sources[total-1]
+
+	applied, err := provider.Up(ctx)
+	require.NoError(t, err)
+	assert.Len(t, applied, total)
+
+	// Running again is a no-op.
+	applied, err = provider.Up(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, applied)
+
+	down, err := provider.Down(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, latest.Version, down.Source.Version)
+
+	statuses, err := provider.Status(ctx)
+	require.NoError(t, err)
+	require.Len(t, statuses, total)
+	assert.Equal(t, goose.StatePending, statuses[total-1].State)
+	assert.Equal(t, goose.StateApplied, statuses[total-2].State)
+
+	applied, err = provider.Up(ctx)
+	require.NoError(t, err)
+	require.Len(t, applied, 1)
+	assert.Equal(t, latest.Version, applied[0].Source.Version)
+}
+
+func TestProviderUpConcurrently(t *testing.T) {
+	provider, tc := setupProvider(t)
+	ctx := context.Background()
+
+	errs := make(chan error, 3)
+	for range 3 {
+		go func() {
+			_, err := provider.Up(ctx)
+			errs <- err
+		}()
+	}
+	for range 3 {
+		require.NoError(t, <-errs)
+	}
+
+	var rows int
+	require.NoError(t, tc.Pool.QueryRow(ctx, "SELECT count(*) FROM goose_db_version WHERE version_id > 0").Scan(&rows))
+	assert.Equal(t, len(provider.ListSources()), rows)
+}
@@ -0,0 +1,38 @@
+// Package objectstore keeps large blobs, such as database backups, outside
+// of Postgres. Objects are written once under a key and read back or
+// deleted by it; the Store interface hides whether they end up on the local
+// filesystem or in an S3-compatible bucket.
+package objectstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/Jesuloba-world/deployease/backend/internal/config"
+)
+
+var ErrNotFound = errors.New("object not found")
+
+// Store holds objects by key. Keys are slash-separated paths. Delete is
+// idempotent.
+type Store interface {
+	// Put stores size bytes read from body under key, replacing any object
+	// already there.
+	Put(ctx context.Context, key string, body io.Reader, size int64) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// New returns the store the configuration selects.
+func New(cfg config.BackupsConfig) (Store, error) {
+	switch cfg.Store {
+	case "filesystem":
+		return NewFileStore(cfg.Dir), nil
+	case "s3":
+		return NewS3Store(cfg.S3)
+	default:
+		return nil, fmt.Errorf("unsupported object store %q", cfg.Store)
+	}
+}
@@ -0,0 +1,33 @@
+package pagination
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	createdAt := time.Date(2025, 6, 12, 20, 29, 32, 123456000, time.UTC)
+
+	cursor := EncodeCursor(createdAt, "V1StGXR8_Z5jdHi6B-myT")
+
+	gotCreatedAt, gotID, err := DecodeCursor(cursor)
+	require.NoError(t, err)
+	assert.True(t, createdAt.Equal(gotCreatedAt))
+	assert.Equal(t, "V1StGXR8_Z5jdHi6B-myT", gotID)
+}
+
+func TestDecodeCursorInvalid(t *testing.T) {
+	for _, cursor := range []string{"%%%", "bm8tc2VwYXJhdG9y", "bm90LWEtdGltZXxpZA"} {
+		_, _, err := DecodeCursor(cursor)
+		assert.ErrorIs(t, err, ErrInvalidCursor, cursor)
+	}
+}
+
+func TestLimit(t *testing.T) {
+	assert.Equal(t, DefaultLimit, Limit(0))
+	assert.Equal(t, 5, Limit(5))
+	assert.Equal(t, MaxLimit, Limit(1000))
+}
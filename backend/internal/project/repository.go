@@ -0,0 +1,80 @@
+package project
+
+import (
+	"errors"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var (
+	ErrInvalidRepositoryURL = errors.New("repository URL must be a valid git URL")
+	ErrInvalidBranch        = errors.New("branch must be a valid git branch name")
+)
+
+// DefaultBranch is deployed when a project does not name one.
+const DefaultBranch = "main"
+
+// scpLikeURL matches the short SSH form used by most git hosts, for example
+// git@github.com:owner/repo.git.
+var scpLikeURL = regexp.MustCompile(`^[A-Za-z0-9._-]+@[A-Za-z0-9.-]+:[A-Za-z0-9._~/-]+$`)
+
+var allowedSchemes = map[string]bool{
+	"https":   true,
+	"http":    true,
+	"ssh":     true,
+	"git":     true,
+	"git+ssh": true,
+}
+
+// ValidateRepositoryURL checks that repoURL looks like something git can
+// clone: an http(s), ssh or git URL with a host and repository path, or an
+// scp-like SSH address.
+func ValidateRepositoryURL(repoURL string) error {
+	repoURL = strings.TrimSpace(repoURL)
+	if repoURL == "" {
+		return ErrInvalidRepositoryURL
+	}
+
+	if scpLikeURL.MatchString(repoURL) {
+		return nil
+	}
+
+	u, err := url.Parse(repoURL)
+	if err != nil {
+		return ErrInvalidRepositoryURL
+	}
+
+	if !allowedSchemes[strings.ToLower(u.Scheme)] || u.Host == "" {
+		return ErrInvalidRepositoryURL
+	}
+
+	path := strings.Trim(u.Path, "/")
+	if path == "" || u.RawQuery != "" || u.Fragment != "" {
+		return ErrInvalidRepositoryURL
+	}
+
+	return nil
+}
+
+// ValidateBranch checks branch against the parts of git's ref name rules
+// that matter for names typed by users: no whitespace, control or glob
+// characters, no empty or dot-led path components and no "..".
+func ValidateBranch(branch string) error {
+	if branch == "" || len(branch) > 255 || strings.HasPrefix(branch, "-") ||
+		strings.HasSuffix(branch, "/") || strings.HasSuffix(branch, ".lock") ||
+		strings.Contains(branch, "..") || strings.Contains(branch, "@{") {
+		return ErrInvalidBranch
+	}
+	for _, r := range branch {
+		if r <= ' ' || r == 0x7f || strings.ContainsRune(`~^:?*[\`, r) {
+			return ErrInvalidBranch
+		}
+	}
+	for _, part := range strings.Split(branch, "/") {
+		if part == "" || strings.HasPrefix(part, ".") {
+			return ErrInvalidBranch
+		}
+	}
+	return nil
+}
@@ -0,0 +1,272 @@
+package project
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	gonanoid "github.com/matoous/go-nanoid/v2"
+
+	"github.com/Jesuloba-world/deployease/backend/internal/infrastructure/database/postgres/sqlc"
+	"github.com/Jesuloba-world/deployease/backend/internal/pagination"
+	"github.com/Jesuloba-world/deployease/backend/internal/secrets"
+)
+
+var (
+	ErrNotFound        = errors.New("project not found")
+	ErrNoWebhookSecret = errors.New("project has no webhook secret yet; rotate it to create one")
+)
+
+const (
+	webhookSecretAlphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+	webhookSecretLength   = 40
+)
+
+type Service struct {
+	queries sqlc.Querier
+	cipher  *secrets.Cipher
+}
+
+func NewService(queries sqlc.Querier, cipher *secrets.Cipher) *Service {
+	return &Service{
+		queries: queries,
+		cipher:  cipher,
+	}
+}
+
+type CreateParams struct {
+	UserID        string
+	Name          string
+	Description   string
+	RepositoryURL string
+	// Branch defaults to DefaultBranch.
+	Branch string
+	// Previews deploys pushes to other branches to preview environments.
+	Previews bool
+}
+
+func (s *Service) Create(ctx context.Context, params CreateParams) (sqlc.Project, error) {
+	if err := ValidateRepositoryURL(params.RepositoryURL); err != nil {
+		return sqlc.Project{}, err
+	}
+
+	branch := strings.TrimSpace(params.Branch)
+	if branch == "" {
+		branch = DefaultBranch
+	}
+	if err := ValidateBranch(branch); err != nil {
+		return sqlc.Project{}, err
+	}
+
+	id := gonanoid.Must()
+	webhookSecret, err := s.newWebhookSecret(id)
+	if err != nil {
+		return sqlc.Project{}, err
+	}
+
+	project, err := s.queries.CreateProject(ctx, sqlc.CreateProjectParams{
+		ID:                     id,
+		Name:                   strings.TrimSpace(params.Name),
+		Description:            optionalText(params.Description),
+		RepositoryUrl:          strings.TrimSpace(params.RepositoryURL),
+		UserID:                 params.UserID,
+		Branch:                 branch,
+		PreviewsEnabled:        params.Previews,
+		EncryptedWebhookSecret: webhookSecret,
+	})
+	if err != nil {
+		return sqlc.Project{}, fmt.Errorf("failed to create project: %w", err)
+	}
+
+	return project, nil
+}
+
+func (s *Service) Get(ctx context.Context, userID, projectID string) (sqlc.Project, error) {
+	project, err := s.queries.GetProjectForUser(ctx, sqlc.GetProjectForUserParams{
+		ID:     projectID,
+		UserID: userID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return sqlc.Project{}, ErrNotFound
+		}
+		return sqlc.Project{}, fmt.Errorf("failed to get project: %w", err)
+	}
+
+	return project, nil
+}
+
+type ListParams struct {
+	UserID string
+	Cursor string
+	Limit  int
+}
+
+type Page struct {
+	Projects   []sqlc.Project
+	NextCursor string
+}
+
+// List returns the user's projects newest first. Pages are keyed on
+// (created_at, id) so results stay stable while projects are being created.
+func (s *Service) List(ctx context.Context, params ListParams) (*Page, error) {
+	limit := pagination.Limit(params.Limit)
+
+	arg := sqlc.ListProjectsForUserParams{
+		UserID: params.UserID,
+		// fetch one extra row to know whether another page exists
+		PageLimit: int32(limit + 1),
+	}
+
+	if params.Cursor != "" {
+		createdAt, id, err := pagination.DecodeCursor(params.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		arg.CursorCreatedAt = pgtype.Timestamptz{Time: createdAt, Valid: true}
+		arg.CursorID = pgtype.Text{String: id, Valid: true}
+	}
+
+	projects, err := s.queries.ListProjectsForUser(ctx, arg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list projects: %w", err)
+	}
+
+	page := &Page{Projects: projects}
+	if len(projects) > limit {
+		page.Projects = projects[:limit]
+		last := page.Projects[limit-1]
+		page.NextCursor = pagination.EncodeCursor(last.CreatedAt.Time, last.ID)
+	}
+
+	return page, nil
+}
+
+type UpdateParams struct {
+	UserID        string
+	ProjectID     string
+	Name          *string
+	Description   *string
+	RepositoryURL *string
+	Branch        *string
+	Previews      *bool
+}
+
+func (s *Service) Update(ctx context.Context, params UpdateParams) (sqlc.Project, error) {
+	arg := sqlc.UpdateProjectParams{
+		ID:     params.ProjectID,
+		UserID: params.UserID,
+	}
+
+	if params.Name != nil {
+		arg.Name = pgtype.Text{String: strings.TrimSpace(*params.Name), Valid: true}
+	}
+	if params.Description != nil {
+		arg.Description = pgtype.Text{String: strings.TrimSpace(*params.Description), Valid: true}
+	}
+	if params.RepositoryURL != nil {
+		if err := ValidateRepositoryURL(*params.RepositoryURL); err != nil {
+			return sqlc.Project{}, err
+		}
+		arg.RepositoryUrl = pgtype.Text{String: strings.TrimSpace(*params.RepositoryURL), Valid: true}
+	}
+	if params.Branch != nil {
+		branch := strings.TrimSpace(*params.Branch)
+		if err := ValidateBranch(branch); err != nil {
+			return sqlc.Project{}, err
+		}
+		arg.Branch = pgtype.Text{String: branch, Valid: true}
+	}
+	if params.Previews != nil {
+		arg.PreviewsEnabled = pgtype.Bool{Bool: *params.Previews, Valid: true}
+	}
+
+	project, err := s.queries.UpdateProject(ctx, arg)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return sqlc.Project{}, ErrNotFound
+		}
+		return sqlc.Project{}, fmt.Errorf("failed to update project: %w", err)
+	}
+
+	return project, nil
+}
+
+func (s *Service) Delete(ctx context.Context, userID, projectID string) error {
+	rows, err := s.queries.DeleteProject(ctx, sqlc.DeleteProjectParams{
+		ID:     projectID,
+		UserID: userID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete project: %w", err)
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// WebhookSecret decrypts the secret the project's webhook deliveries are
+// signed with.
+func (s *Service) WebhookSecret(p sqlc.Project) (string, error) {
+	if len(p.EncryptedWebhookSecret) == 0 {
+		return "", ErrNoWebhookSecret
+	}
+	secret, err := s.cipher.OpenString(p.EncryptedWebhookSecret, webhookSecretAssociatedData(p.ID))
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt webhook secret: %w", err)
+	}
+	return secret, nil
+}
+
+// RotateWebhookSecret replaces the project's webhook secret with a new one.
+// Deliveries signed with the old secret are rejected from then on.
+func (s *Service) RotateWebhookSecret(ctx context.Context, userID, projectID string) (sqlc.Project, error) {
+	webhookSecret, err := s.newWebhookSecret(projectID)
+	if err != nil {
+		return sqlc.Project{}, err
+	}
+
+	project, err := s.queries.SetProjectWebhookSecret(ctx, sqlc.SetProjectWebhookSecretParams{
+		EncryptedWebhookSecret: webhookSecret,
+		ID:                     projectID,
+		UserID:                 userID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return sqlc.Project{}, ErrNotFound
+		}
+		return sqlc.Project{}, fmt.Errorf("failed to rotate webhook secret: %w", err)
+	}
+
+	return project, nil
+}
+
+// newWebhookSecret generates a webhook secret for the project and encrypts
+// it for storage.
+func (s *Service) newWebhookSecret(projectID string) ([]byte, error) {
+	secret := gonanoid.MustGenerate(webhookSecretAlphabet, webhookSecretLength)
+	sealed, err := s.cipher.SealString(secret, webhookSecretAssociatedData(projectID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt webhook secret: %w", err)
+	}
+	return sealed, nil
+}
+
+// webhookSecretAssociatedData binds an encrypted webhook secret to its
+// project.
+func webhookSecretAssociatedData(projectID string) string {
+	return "projects/" + projectID + "/webhook_secret"
+}
+
+func optionalText(value string) pgtype.Text {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return pgtype.Text{}
+	}
+	return pgtype.Text{String: value, Valid: true}
+}
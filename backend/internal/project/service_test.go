@@ -0,0 +1,324 @@
+package project
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Jesuloba-world/deployease/backend/internal/config"
+	"github.com/Jesuloba-world/deployease/backend/internal/infrastructure/database/postgres/sqlc"
+	"github.com/Jesuloba-world/deployease/backend/internal/pagination"
+	"github.com/Jesuloba-world/deployease/backend/internal/secrets"
+)
+
+type fakeQuerier struct {
+	sqlc.Querier
+	projects []sqlc.Project
+	clock    time.Time
+}
+
+func (f *fakeQuerier) CreateProject(ctx context.Context, arg sqlc.CreateProjectParams) (sqlc.Project, error) {
+	f.clock = f.clock.Add(time.Second)
+	p := sqlc.Project{
+		ID:                     arg.ID,
+		Name:                   arg.Name,
+		Description:            arg.Description,
+		RepositoryUrl:          arg.RepositoryUrl,
+		UserID:                 arg.UserID,
+		Branch:                 arg.Branch,
+		PreviewsEnabled:        arg.PreviewsEnabled,
+		EncryptedWebhookSecret: arg.EncryptedWebhookSecret,
+		CreatedAt:              pgtype.Timestamptz{Time: f.clock, Valid: true},
+		UpdatedAt:              pgtype.Timestamptz{Time: f.clock, Valid: true},
+	}
+	f.projects = append(f.projects, p)
+	return p, nil
+}
+
+func (f *fakeQuerier) GetProjectForUser(ctx context.Context, arg sqlc.GetProjectForUserParams) (sqlc.Project, error) {
+	for _, p := range f.projects {
+		if p.ID == arg.ID && p.UserID == arg.UserID {
+			return p, nil
+		}
+	}
+	return sqlc.Project{}, pgx.ErrNoRows
+}
+
+func (f *fakeQuerier) ListProjectsForUser(ctx context.Context, arg sqlc.ListProjectsForUserParams) ([]sqlc.Project, error) {
+	var out []sqlc.Project
+	for _, p := range f.projects {
+		if p.UserID != arg.UserID {
+			continue
+		}
+		if arg.CursorCreatedAt.Valid {
+			c := arg.CursorCreatedAt.Time
+			if p.CreatedAt.Time.After(c) || (p.CreatedAt.Time.Equal(c) && p.ID >= arg.CursorID.String) {
+				continue
+			}
+		}
+		out = append(out, p)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.Time.After(out[j].CreatedAt.Time) })
+	if len(out) > int(arg.PageLimit) {
+		out = out[:arg.PageLimit]
+	}
+	return out, nil
+}
+
+func (f *fakeQuerier) UpdateProject(ctx context.Context, arg sqlc.UpdateProjectParams) (sqlc.Project, error) {
+	for i, p := range f.projects {
+		if p.ID == arg.ID && p.UserID == arg.UserID {
+			if arg.Name.Valid {
+				p.Name = arg.Name.String
+			}
+			if arg.Description.Valid {
+				p.Description = arg.Description
+			}
+			if arg.RepositoryUrl.Valid {
+				p.RepositoryUrl = arg.RepositoryUrl.String
+			}
+			if arg.Branch.Valid {
+				p.Branch = arg.Branch.String
+			}
+			if arg.PreviewsEnabled.Valid {
+				p.PreviewsEnabled = arg.PreviewsEnabled.Bool
+			}
+			f.projects[i] = p
+			return p, nil
+		}
+	}
+	return sqlc.Project{}, pgx.ErrNoRows
+}
+
+func (f *fakeQuerier) SetProjectWebhookSecret(ctx context.Context, arg sqlc.SetProjectWebhookSecretParams) (sqlc.Project, error) {
+	for i, p := range f.projects {
+		if p.ID == arg.ID && p.UserID == arg.UserID {
+			p.EncryptedWebhookSecret = arg.EncryptedWebhookSecret
+			f.projects[i] = p
+			return p, nil
+		}
+	}
+	return sqlc.Project{}, pgx.ErrNoRows
+}
+
+func (f *fakeQuerier) DeleteProject(ctx context.Context, arg sqlc.DeleteProjectParams) (int64, error) {
+	for i, p := range f.projects {
+		if p.ID == arg.ID && p.UserID == arg.UserID {
+			f.projects = append(f.projects[:i], f.projects[i+1:]...)
+			return 1, nil
+		}
+	}
+	return 0, nil
+}
+
+func newTestService(t *testing.T) *Service {
+	t.Helper()
+	cipher, err := secrets.NewCipher(config.SecretsConfig{MasterKey: "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY="})
+	require.NoError(t, err)
+	return NewService(&fakeQuerier{clock: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}, cipher)
+}
+
+func TestServiceOwnership(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestService(t)
+
+	p, err := svc.Create(ctx, CreateParams{
+		UserID:        "owner",
+		Name:          "my-app",
+		RepositoryURL: "https://github.com/user/repo.git",
+	})
+	require.NoError(t, err)
+
+	_, err = svc.Get(ctx, "owner", p.ID)
+	require.NoError(t, err)
+
+	_, err = svc.Get(ctx, "intruder", p.ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	name := "renamed"
+	_, err = svc.Update(ctx, UpdateParams{UserID: "intruder", ProjectID: p.ID, Name: &name})
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	assert.ErrorIs(t, svc.Delete(ctx, "intruder", p.ID), ErrNotFound)
+
+	updated, err := svc.Update(ctx, UpdateParams{UserID: "owner", ProjectID: p.ID, Name: &name})
+	require.NoError(t, err)
+	assert.Equal(t, "renamed", updated.Name)
+
+	require.NoError(t, svc.Delete(ctx, "owner", p.ID))
+}
+
+func TestServiceWebhookSecret(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestService(t)
+
+	p, err := svc.Create(ctx, CreateParams{
+		UserID:        "owner",
+		Name:          "my-app",
+		RepositoryURL: "https://github.com/user/repo.git",
+	})
+	require.NoError(t, err)
+
+	secret, err := svc.WebhookSecret(p)
+	require.NoError(t, err)
+	assert.Len(t, secret, webhookSecretLength)
+	assert.NotContains(t, string(p.EncryptedWebhookSecret), secret, "the secret is stored encrypted")
+
+	_, err = svc.RotateWebhookSecret(ctx, "intruder", p.ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	rotated, err := svc.RotateWebhookSecret(ctx, "owner", p.ID)
+	require.NoError(t, err)
+	newSecret, err := svc.WebhookSecret(rotated)
+	require.NoError(t, err)
+	assert.NotEqual(t, secret, newSecret)
+
+	// A secret sealed for one project does not open as another's.
+	other := rotated
+	other.ID = "other"
+	_, err = svc.WebhookSecret(other)
+	assert.Error(t, err)
+
+	_, err = svc.WebhookSecret(sqlc.Project{ID: "legacy"})
+	assert.ErrorIs(t, err, ErrNoWebhookSecret)
+}
+
+func TestServiceCreateRejectsInvalidRepositoryURL(t *testing.T) {
+	svc := newTestService(t)
+
+	_, err := svc.Create(context.Background(), CreateParams{
+		UserID:        "owner",
+		Name:          "my-app",
+		RepositoryURL: "not a url",
+	})
+	assert.ErrorIs(t, err, ErrInvalidRepositoryURL)
+}
+
+func TestServiceListPagination(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestService(t)
+
+	for i := 0; i < 5; i++ {
+		_, err := svc.Create(ctx, CreateParams{
+			UserID:        "owner",
+			Name:          "app",
+			RepositoryURL: "git@github.com:user/repo.git",
+		})
+		require.NoError(t, err)
+	}
+
+	seen := map[string]bool{}
+	cursor := ""
+	pages := 0
+	for {
+		page, err := svc.List(ctx, ListParams{UserID: "owner", Cursor: cursor, Limit: 2})
+		require.NoError(t, err)
+		pages++
+		for _, p := range page.Projects {
+			assert.False(t, seen[p.ID], "project returned twice")
+			seen[p.ID] = true
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		cursor = page.NextCursor
+	}
+
+	assert.Equal(t, 3, pages)
+	assert.Len(t, seen, 5)
+
+	_, err := svc.List(ctx, ListParams{UserID: "owner", Cursor: "%%%"})
+	assert.ErrorIs(t, err, pagination.ErrInvalidCursor)
+}
+
+func TestValidateRepositoryURL(t *testing.T) {
+	valid := []string{
+		"https://github.com/user/repo.git",
+		"https://gitlab.com/group/subgroup/repo",
+		"ssh://git@bitbucket.org/user/repo.git",
+		"git@github.com:user/repo.git",
+		"git://example.com/repo.git",
+	}
+	for _, u := range valid {
+		assert.NoError(t, ValidateRepositoryURL(u), u)
+	}
+
+	invalid := []string{
+		"",
+		"github.com/user/repo",
+		"ftp://example.com/repo.git",
+		"https://github.com",
+		"https://github.com/user/repo?ref=main",
+		"file:///tmp/repo",
+	}
+	for _, u := range invalid {
+		assert.ErrorIs(t, ValidateRepositoryURL(u), ErrInvalidRepositoryURL, u)
+	}
+}
+
+func TestServiceBranch(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestService(t)
+
+	p, err := svc.Create(ctx, CreateParams{
+		UserID:        "owner",
+		Name:          "my-app",
+		RepositoryURL: "https://github.com/user/repo.git",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, DefaultBranch, p.Branch)
+
+	branch := "release/v2"
+	p, err = svc.Update(ctx, UpdateParams{UserID: "owner", ProjectID: p.ID, Branch: &branch})
+	require.NoError(t, err)
+	assert.Equal(t, "release/v2", p.Branch)
+
+	_, err = svc.Create(ctx, CreateParams{
+		UserID:        "owner",
+		Name:          "my-app",
+		RepositoryURL: "https://github.com/user/repo.git",
+		Branch:        "feature..x",
+	})
+	assert.ErrorIs(t, err, ErrInvalidBranch)
+}
+
+func TestServicePreviews(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestService(t)
+
+	p, err := svc.Create(ctx, CreateParams{
+		UserID:        "owner",
+		Name:          "my-app",
+		RepositoryURL: "https://github.com/user/repo.git",
+		Previews:      true,
+	})
+	require.NoError(t, err)
+	assert.True(t, p.PreviewsEnabled)
+
+	// Leaving Previews out of an update keeps the setting.
+	name := "renamed"
+	p, err = svc.Update(ctx, UpdateParams{UserID: "owner", ProjectID: p.ID, Name: &name})
+	require.NoError(t, err)
+	assert.True(t, p.PreviewsEnabled)
+
+	off := false
+	p, err = svc.Update(ctx, UpdateParams{UserID: "owner", ProjectID: p.ID, Previews: &off})
+	require.NoError(t, err)
+	assert.False(t, p.PreviewsEnabled)
+}
+
+func TestValidateBranch(t *testing.T) {
+	for _, b := range []string{"main", "release/v2.1", "feature/JIRA-42_fix", "v1"} {
+		assert.NoError(t, ValidateBranch(b), b)
+	}
+
+	for _, b := range []string{"", "-main", "feature/", "a..b", "has space", "x.lock", "a//b", ".hidden", "main~1", "ref@{0}", "a:b"} {
+		assert.ErrorIs(t, ValidateBranch(b), ErrInvalidBranch, b)
+	}
+}
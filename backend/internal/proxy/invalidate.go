@@ -0,0 +1,42 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/Jesuloba-world/deployease/backend/internal/infrastructure/database/dragonfly"
+)
+
+// RoutesChannel is the Dragonfly channel routing changes are announced on.
+const RoutesChannel = "deployease:proxy:routes"
+
+var ErrClientNotInitialized = errors.New("Dragonfly client not initialized")
+
+// Invalidator tells every proxy instance that its routing table is out of
+// date, for example because an environment's active deployment changed.
+type Invalidator interface {
+	Invalidate(ctx context.Context) error
+}
+
+// Notifier announces routing changes over Dragonfly.
+type Notifier struct {
+	client *redis.Client
+}
+
+func NewNotifier() (*Notifier, error) {
+	client := dragonfly.GetClient()
+	if client == nil {
+		return nil, ErrClientNotInitialized
+	}
+	return &Notifier{client: client}, nil
+}
+
+func (n *Notifier) Invalidate(ctx context.Context) error {
+	if err := n.client.Publish(ctx, RoutesChannel, "").Err(); err != nil {
+		return fmt.Errorf("failed to announce routing change: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,289 @@
+// Package proxy is the edge proxy that serves deployed applications. Each
+// request is routed by its Host header to the deployment serving that
+// domain. The routing table is loaded from the database, reloaded
+// periodically and whenever a change is announced over Dragonfly, and the
+// upstreams in it are probed so traffic avoids unhealthy containers. TLS
+// certificates are picked by SNI from the ones issued for custom domains.
+package proxy
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/Jesuloba-world/deployease/backend/internal/certificate"
+	"github.com/Jesuloba-world/deployease/backend/internal/config"
+	"github.com/Jesuloba-world/deployease/backend/internal/container"
+	"github.com/Jesuloba-world/deployease/backend/internal/infrastructure/database/dragonfly"
+	"github.com/Jesuloba-world/deployease/backend/internal/infrastructure/database/postgres/sqlc"
+)
+
+var errNoServerName = errors.New("client did not send a server name")
+
+// Prober checks whether the application listening on addr (host:port) is
+// ready to receive traffic.
+type Prober interface {
+	Probe(ctx context.Context, addr string) error
+}
+
+// Certificates loads the certificate issued for a domain.
+type Certificates interface {
+	Load(ctx context.Context, name string) (*tls.Certificate, error)
+}
+
+type upstreamKey struct{}
+
+type Proxy struct {
+	queries       sqlc.Querier
+	runtime       container.Runtime
+	prober        Prober
+	certificates  Certificates
+	challenges    http.Handler
+	client        *redis.Client
+	cfg           config.ProxyConfig
+	port          int
+	previewDomain string
+	resolve       func(ctx context.Context, containerID string) (string, error)
+
+	reverse     *httputil.ReverseProxy
+	table       atomic.Pointer[table]
+	invalidated chan struct{}
+
+	// certs caches loaded certificates by server name until the next
+	// reload, which is how renewed ones are picked up.
+	certsMu sync.Mutex
+	certs   map[string]*tls.Certificate
+}
+
+// NewProxy creates the edge proxy. Requests for HTTP-01 challenges are
+// answered by challenges, whatever their host.
+func NewProxy(
+	queries sqlc.Querier,
+	runtime container.Runtime,
+	prober Prober,
+	certificates Certificates,
+	challenges http.Handler,
+	cfg config.ProxyConfig,
+	runtimeCfg config.RuntimeConfig,
+	previewsCfg config.PreviewsConfig,
+) (*Proxy, error) {
+	client := dragonfly.GetClient()
+	if client == nil {
+		return nil, ErrClientNotInitialized
+	}
+	return newProxy(client, queries, runtime, prober, certificates, challenges, cfg, runtimeCfg.ContainerPort, previewsCfg.Domain), nil
+}
+
+func newProxy(
+	client *redis.Client,
+	queries sqlc.Querier,
+	runtime container.Runtime,
+	prober Prober,
+	certificates Certificates,
+	challenges http.Handler,
+	cfg config.ProxyConfig,
+	port int,
+	previewDomain string,
+) *Proxy {
+	p := &Proxy{
+		queries:       queries,
+		runtime:       runtime,
+		prober:        prober,
+		certificates:  certificates,
+		challenges:    challenges,
+		client:        client,
+		cfg:           cfg,
+		port:          port,
+		previewDomain: strings.ToLower(previewDomain),
+		invalidated:   make(chan struct{}, 1),
+		certs:         make(map[string]*tls.Certificate),
+	}
+	p.resolve = p.containerAddr
+	p.reverse = &httputil.ReverseProxy{
+		Rewrite:      p.rewrite,
+		Transport:    http.DefaultTransport.(*http.Transport).Clone(),
+		ErrorHandler: p.upstreamError,
+	}
+	return p
+}
+
+// Run keeps the routing table current until ctx is done. It is reloaded
+// right away, every refresh interval and whenever a change is announced;
+// its upstreams are probed every health interval.
+func (p *Proxy) Run(ctx context.Context) {
+	if p.client != nil {
+		go p.listen(ctx)
+	}
+
+	refresh := time.NewTicker(p.cfg.RefreshInterval)
+	defer refresh.Stop()
+	health := time.NewTicker(p.cfg.HealthInterval)
+	defer health.Stop()
+
+	p.reload(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-refresh.C:
+			p.reload(ctx)
+		case <-p.invalidated:
+			p.reload(ctx)
+		case <-health.C:
+			p.checkHealth(ctx)
+		}
+	}
+}
+
+// TLSConfig serves the certificate of the domain a client asks for.
+func (p *Proxy) TLSConfig() *tls.Config {
+	return &tls.Config{
+		GetCertificate: p.GetCertificate,
+		MinVersion:     tls.VersionTLS12,
+	}
+}
+
+// GetCertificate returns the certificate issued for the server name of a
+// TLS handshake. Only names the proxy routes are looked up.
+func (p *Proxy) GetCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	name := hostname(hello.ServerName)
+	if name == "" {
+		return nil, errNoServerName
+	}
+
+	p.certsMu.Lock()
+	cert, ok := p.certs[name]
+	p.certsMu.Unlock()
+	if ok {
+		return cert, nil
+	}
+
+	if p.table.Load().lookup(name) == nil {
+		return nil, fmt.Errorf("no route for %s", name)
+	}
+	cert, err := p.certificates.Load(hello.Context(), name)
+	if err != nil {
+		return nil, err
+	}
+
+	p.certsMu.Lock()
+	p.certs[name] = cert
+	p.certsMu.Unlock()
+	return cert, nil
+}
+
+func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if p.challenges != nil && strings.HasPrefix(r.URL.Path, certificate.ChallengePath) {
+		p.challenges.ServeHTTP(w, r)
+		return
+	}
+
+	route := p.table.Load().lookup(hostname(r.Host))
+	if route == nil {
+		http.Error(w, "no application is deployed at this address", http.StatusNotFound)
+		return
+	}
+
+	u := route.pick()
+	p.reverse.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), upstreamKey{}, u)))
+}
+
+// rewrite points a request at the upstream ServeHTTP picked, keeping the
+// Host the client asked for. WebSocket upgrades pass through as is.
+func (p *Proxy) rewrite(pr *httputil.ProxyRequest) {
+	u := pr.In.Context().Value(upstreamKey{}).(*upstream)
+	pr.SetURL(&url.URL{Scheme: "http", Host: u.addr})
+	pr.Out.Host = pr.In.Host
+	pr.SetXForwarded()
+}
+
+// upstreamError answers a request the upstream failed. Unless the client
+// went away first, the upstream is taken out of rotation until its next
+// successful probe.
+func (p *Proxy) upstreamError(w http.ResponseWriter, r *http.Request, err error) {
+	u := r.Context().Value(upstreamKey{}).(*upstream)
+	if r.Context().Err() == nil {
+		log.Printf("Failed to proxy request for %s to deployment %s: %v", r.Host, u.deploymentID, err)
+		u.healthy.Store(false)
+	}
+	w.WriteHeader(http.StatusBadGateway)
+}
+
+// Invalidate reloads the routing table as soon as possible.
+func (p *Proxy) Invalidate() {
+	select {
+	case p.invalidated <- struct{}{}:
+	default:
+		// A reload is pending already.
+	}
+}
+
+func (p *Proxy) reload(ctx context.Context) {
+	next, err := p.load(ctx, p.table.Load())
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Printf("Failed to reload proxy routes: %v", err)
+		}
+		return
+	}
+	p.table.Store(next)
+
+	p.certsMu.Lock()
+	p.certs = make(map[string]*tls.Certificate)
+	p.certsMu.Unlock()
+}
+
+// checkHealth probes every upstream at once.
+func (p *Proxy) checkHealth(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, u := range p.table.Load().upstreams() {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := p.prober.Probe(ctx, u.addr)
+			if ctx.Err() != nil {
+				return
+			}
+			if healthy := err == nil; u.healthy.Swap(healthy) != healthy {
+				if healthy {
+					log.Printf("Deployment %s is healthy again", u.deploymentID)
+				} else {
+					log.Printf("Deployment %s is unhealthy: %v", u.deploymentID, err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// listen reloads the routing table whenever a change is announced.
+// Announcements missed while the connection is down are caught up with by
+// the periodic refresh.
+func (p *Proxy) listen(ctx context.Context) {
+	pubsub := p.client.Subscribe(ctx, RoutesChannel)
+	defer pubsub.Close()
+
+	messages := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case _, ok := <-messages:
+			if !ok {
+				return
+			}
+			p.Invalidate()
+		}
+	}
+}
@@ -0,0 +1,341 @@
+package proxy
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
+
+	"github.com/Jesuloba-world/deployease/backend/internal/config"
+	"github.com/Jesuloba-world/deployease/backend/internal/container"
+	"github.com/Jesuloba-world/deployease/backend/internal/infrastructure/database/dragonfly"
+	"github.com/Jesuloba-world/deployease/backend/internal/infrastructure/database/postgres/sqlc"
+)
+
+type fakeQuerier struct {
+	sqlc.Querier
+
+	mu   sync.Mutex
+	rows []sqlc.ListRouteUpstreamsRow
+}
+
+func (f *fakeQuerier) ListRouteUpstreams(ctx context.Context, previewDomain string) ([]sqlc.ListRouteUpstreamsRow, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]sqlc.ListRouteUpstreamsRow{}, f.rows...), nil
+}
+
+func (f *fakeQuerier) set(rows ...sqlc.ListRouteUpstreamsRow) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.rows = rows
+}
+
+// fakeProber reports the addresses in down as unhealthy.
+type fakeProber struct {
+	mu   sync.Mutex
+	down map[string]bool
+}
+
+func (p *fakeProber) Probe(ctx context.Context, addr string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.down[addr] {
+		return errors.New("connection refused")
+	}
+	return nil
+}
+
+func (p *fakeProber) setDown(addr string, down bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.down[addr] = down
+}
+
+type fakeCertificates struct {
+	loads map[string]int
+}
+
+func (c *fakeCertificates) Load(ctx context.Context, name string) (*tls.Certificate, error) {
+	c.loads[name]++
+	if name != "app.example.com" {
+		return nil, errors.New("certificate not found")
+	}
+	return &tls.Certificate{}, nil
+}
+
+type fixture struct {
+	proxy        *Proxy
+	queries      *fakeQuerier
+	prober       *fakeProber
+	certificates *fakeCertificates
+	// containers maps container IDs to the address of the application in
+	// them; containers missing from it are gone.
+	containers map[string]string
+}
+
+func setupProxy(t *testing.T, client *redis.Client) *fixture {
+	t.Helper()
+	f := &fixture{
+		queries:      &fakeQuerier{},
+		prober:       &fakeProber{down: make(map[string]bool)},
+		certificates: &fakeCertificates{loads: make(map[string]int)},
+		containers:   make(map[string]string),
+	}
+	challenges := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "challenge "+strings.TrimPrefix(r.URL.Path, "/.well-known/acme-challenge/"))
+	})
+	f.proxy = newProxy(client, f.queries, nil, f.prober, f.certificates, challenges, config.ProxyConfig{
+		RefreshInterval: time.Hour,
+		HealthInterval:  time.Hour,
+	}, 8080, "preview.example.com")
+	f.proxy.resolve = func(ctx context.Context, id string) (string, error) {
+		addr, ok := f.containers[id]
+		if !ok {
+			return "", container.ErrNotFound
+		}
+		return addr, nil
+	}
+	return f
+}
+
+// backend starts an application that answers with its name and the
+// request's Host and X-Forwarded-Proto headers.
+func (f *fixture) backend(t *testing.T, containerID, name string) string {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "%s %s %s", name, r.Host, r.Header.Get("X-Forwarded-Proto"))
+	}))
+	t.Cleanup(srv.Close)
+	addr := strings.TrimPrefix(srv.URL, "http://")
+	f.containers[containerID] = addr
+	return addr
+}
+
+func get(t *testing.T, handler http.Handler, host, path string) (int, string) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "http://"+host+path, nil)
+	handler.ServeHTTP(rec, req)
+	return rec.Code, rec.Body.String()
+}
+
+func TestProxyRoutesByHost(t *testing.T) {
+	ctx := context.Background()
+	f := setupProxy(t, nil)
+	f.backend(t, "c1", "app")
+	f.backend(t, "c2", "preview")
+	f.queries.set(
+		sqlc.ListRouteUpstreamsRow{Host: "app.example.com", DeploymentID: "d1", ContainerID: "c1", Active: true},
+		sqlc.ListRouteUpstreamsRow{Host: "feature-x.preview.example.com", DeploymentID: "d2", ContainerID: "c2", Active: true},
+		// Its container is gone, so the host is not served.
+		sqlc.ListRouteUpstreamsRow{Host: "gone.example.com", DeploymentID: "d3", ContainerID: "c3", Active: true},
+	)
+	f.proxy.reload(ctx)
+
+	code, body := get(t, f.proxy, "App.Example.com.:80", "/")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "app App.Example.com.:80 http", body, "the client's Host is passed on")
+
+	_, body = get(t, f.proxy, "feature-x.preview.example.com", "/")
+	assert.True(t, strings.HasPrefix(body, "preview "))
+
+	code, _ = get(t, f.proxy, "gone.example.com", "/")
+	assert.Equal(t, http.StatusNotFound, code)
+	code, _ = get(t, f.proxy, "unknown.example.com", "/")
+	assert.Equal(t, http.StatusNotFound, code)
+
+	// Challenges are answered for any host, routed or not.
+	code, body = get(t, f.proxy, "unknown.example.com", "/.well-known/acme-challenge/tok")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "challenge tok", body)
+}
+
+func TestProxyPrefersHealthyUpstream(t *testing.T) {
+	ctx := context.Background()
+	f := setupProxy(t, nil)
+	active := f.backend(t, "c2", "new")
+	f.backend(t, "c1", "old")
+	f.queries.set(
+		sqlc.ListRouteUpstreamsRow{Host: "app.example.com", DeploymentID: "d2", ContainerID: "c2", Active: true},
+		sqlc.ListRouteUpstreamsRow{Host: "app.example.com", DeploymentID: "d1", ContainerID: "c1"},
+	)
+	f.proxy.reload(ctx)
+
+	_, body := get(t, f.proxy, "app.example.com", "/")
+	assert.True(t, strings.HasPrefix(body, "new "))
+
+	f.prober.setDown(active, true)
+	f.proxy.checkHealth(ctx)
+	_, body = get(t, f.proxy, "app.example.com", "/")
+	assert.True(t, strings.HasPrefix(body, "old "), "traffic fails over to the previous deployment")
+
+	// Health survives a reload of the routing table.
+	f.proxy.reload(ctx)
+	_, body = get(t, f.proxy, "app.example.com", "/")
+	assert.True(t, strings.HasPrefix(body, "old "))
+
+	f.prober.setDown(active, false)
+	f.proxy.checkHealth(ctx)
+	_, body = get(t, f.proxy, "app.example.com", "/")
+	assert.True(t, strings.HasPrefix(body, "new "))
+}
+
+func TestProxyTakesFailingUpstreamOutOfRotation(t *testing.T) {
+	ctx := context.Background()
+	f := setupProxy(t, nil)
+	dead := httptest.NewServer(http.NotFoundHandler())
+	f.containers["c2"] = strings.TrimPrefix(dead.URL, "http://")
+	dead.Close()
+	f.backend(t, "c1", "old")
+	f.queries.set(
+		sqlc.ListRouteUpstreamsRow{Host: "app.example.com", DeploymentID: "d2", ContainerID: "c2", Active: true},
+		sqlc.ListRouteUpstreamsRow{Host: "app.example.com", DeploymentID: "d1", ContainerID: "c1"},
+	)
+	f.proxy.reload(ctx)
+
+	code, _ := get(t, f.proxy, "app.example.com", "/")
+	assert.Equal(t, http.StatusBadGateway, code)
+
+	code, body := get(t, f.proxy, "app.example.com", "/")
+	assert.Equal(t, http.StatusOK, code)
+	assert.True(t, strings.HasPrefix(body, "old "))
+}
+
+func TestProxyKeepsUpstreamWhenContainerCannotBeInspected(t *testing.T) {
+	ctx := context.Background()
+	f := setupProxy(t, nil)
+	f.backend(t, "c1", "app")
+	f.queries.set(sqlc.ListRouteUpstreamsRow{Host: "app.example.com", DeploymentID: "d1", ContainerID: "c1", Active: true})
+	f.proxy.reload(ctx)
+
+	f.proxy.resolve = func(ctx context.Context, id string) (string, error) {
+		return "", errors.New("cannot connect to the Docker daemon")
+	}
+	f.proxy.reload(ctx)
+
+	code, _ := get(t, f.proxy, "app.example.com", "/")
+	assert.Equal(t, http.StatusOK, code)
+}
+
+func TestProxyPassesWebSocketsThrough(t *testing.T) {
+	ctx := context.Background()
+	f := setupProxy(t, nil)
+
+	echo := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.CloseNow()
+		typ, msg, err := conn.Read(r.Context())
+		if err != nil {
+			return
+		}
+		conn.Write(r.Context(), typ, append([]byte("echo "), msg...))
+	}))
+	t.Cleanup(echo.Close)
+	f.containers["c1"] = strings.TrimPrefix(echo.URL, "http://")
+	f.queries.set(sqlc.ListRouteUpstreamsRow{Host: "app.example.com", DeploymentID: "d1", ContainerID: "c1", Active: true})
+	f.proxy.reload(ctx)
+
+	edge := httptest.NewServer(f.proxy)
+	t.Cleanup(edge.Close)
+
+	conn, _, err := websocket.Dial(ctx, "ws"+strings.TrimPrefix(edge.URL, "http"), &websocket.DialOptions{
+		Host: "app.example.com",
+	})
+	require.NoError(t, err)
+	defer conn.CloseNow()
+
+	require.NoError(t, conn.Write(ctx, websocket.MessageText, []byte("hello")))
+	_, msg, err := conn.Read(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "echo hello", string(msg))
+	conn.Close(websocket.StatusNormalClosure, "")
+}
+
+func TestProxySelectsCertificateBySNI(t *testing.T) {
+	ctx := context.Background()
+	f := setupProxy(t, nil)
+	f.backend(t, "c1", "app")
+	f.backend(t, "c2", "other")
+	f.queries.set(
+		sqlc.ListRouteUpstreamsRow{Host: "app.example.com", DeploymentID: "d1", ContainerID: "c1", Active: true},
+		sqlc.ListRouteUpstreamsRow{Host: "other.example.com", DeploymentID: "d2", ContainerID: "c2", Active: true},
+	)
+	f.proxy.reload(ctx)
+
+	hello := func(name string) *tls.ClientHelloInfo {
+		return &tls.ClientHelloInfo{ServerName: name}
+	}
+
+	cert, err := f.proxy.GetCertificate(hello("APP.example.com"))
+	require.NoError(t, err)
+	again, err := f.proxy.GetCertificate(hello("app.example.com"))
+	require.NoError(t, err)
+	assert.Same(t, cert, again)
+	assert.Equal(t, 1, f.certificates.loads["app.example.com"], "certificates are cached")
+
+	// Reloading the routes picks up renewed certificates.
+	f.proxy.reload(ctx)
+	_, err = f.proxy.GetCertificate(hello("app.example.com"))
+	require.NoError(t, err)
+	assert.Equal(t, 2, f.certificates.loads["app.example.com"])
+
+	_, err = f.proxy.GetCertificate(hello("other.example.com"))
+	assert.Error(t, err, "routed without a certificate yet")
+
+	_, err = f.proxy.GetCertificate(hello("unknown.example.com"))
+	assert.Error(t, err)
+	assert.Zero(t, f.certificates.loads["unknown.example.com"], "unrouted names are not looked up")
+
+	_, err = f.proxy.GetCertificate(hello(""))
+	assert.ErrorIs(t, err, errNoServerName)
+}
+
+func TestProxyReloadsWhenRoutesChange(t *testing.T) {
+	testcontainers.SkipIfProviderIsNotHealthy(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	dc, err := dragonfly.StartDragonflyContainer(ctx)
+	require.NoError(t, err)
+	t.Cleanup(func() { dc.Cleanup(context.Background()) })
+	client := redis.NewClient(&redis.Options{Addr: dc.Address})
+	t.Cleanup(func() { client.Close() })
+
+	f := setupProxy(t, client)
+	f.backend(t, "c1", "app")
+	go f.proxy.Run(ctx)
+
+	require.Eventually(t, func() bool {
+		return f.proxy.table.Load() != nil
+	}, 5*time.Second, 10*time.Millisecond)
+	code, _ := get(t, f.proxy, "app.example.com", "/")
+	assert.Equal(t, http.StatusNotFound, code)
+
+	f.queries.set(sqlc.ListRouteUpstreamsRow{Host: "app.example.com", DeploymentID: "d1", ContainerID: "c1", Active: true})
+	notifier := &Notifier{client: client}
+	require.Eventually(t, func() bool {
+		// The subscription may not be in place yet, so keep announcing.
+		if err := notifier.Invalidate(ctx); err != nil {
+			return false
+		}
+		return f.proxy.table.Load().lookup("app.example.com") != nil
+	}, 10*time.Second, 100*time.Millisecond)
+}
@@ -0,0 +1,162 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/Jesuloba-world/deployease/backend/internal/container"
+)
+
+var errNotRunning = errors.New("container is not running")
+
+// upstream is a deployment's container as seen by the proxy. Its health
+// outlives reloads of the routing table as long as its address stays the
+// same.
+type upstream struct {
+	deploymentID string
+	addr         string
+	healthy      atomic.Bool
+}
+
+// route lists the upstreams a host may be served by: the active deployment
+// of its environment first, then earlier live ones that still have a
+// container, newest first.
+type route struct {
+	upstreams []*upstream
+}
+
+// pick returns the first healthy upstream. When none is healthy the active
+// deployment is used anyway, since a failing health check does not mean
+// every request fails, and refusing the host outright helps nobody.
+func (r *route) pick() *upstream {
+	for _, u := range r.upstreams {
+		if u.healthy.Load() {
+			return u
+		}
+	}
+	return r.upstreams[0]
+}
+
+type table struct {
+	routes map[string]*route
+}
+
+func (t *table) lookup(host string) *route {
+	if t == nil {
+		return nil
+	}
+	return t.routes[host]
+}
+
+// upstream returns the deployment's upstream if the table has one.
+func (t *table) upstream(deploymentID string) *upstream {
+	if t == nil {
+		return nil
+	}
+	for _, r := range t.routes {
+		for _, u := range r.upstreams {
+			if u.deploymentID == deploymentID {
+				return u
+			}
+		}
+	}
+	return nil
+}
+
+// upstreams returns every distinct upstream in the table.
+func (t *table) upstreams() []*upstream {
+	if t == nil {
+		return nil
+	}
+	seen := make(map[*upstream]bool)
+	var all []*upstream
+	for _, r := range t.routes {
+		for _, u := range r.upstreams {
+			if !seen[u] {
+				seen[u] = true
+				all = append(all, u)
+			}
+		}
+	}
+	return all
+}
+
+// load builds a routing table from the database. Upstreams whose containers
+// are gone or stopped are left out; one that cannot be inspected keeps the
+// address it had in current, so a hiccup of the container engine does not
+// take its routes down.
+func (p *Proxy) load(ctx context.Context, current *table) (*table, error) {
+	rows, err := p.queries.ListRouteUpstreams(ctx, p.previewDomain)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list routes: %w", err)
+	}
+
+	next := &table{routes: make(map[string]*route)}
+	resolved := make(map[string]*upstream)
+	for _, row := range rows {
+		u, ok := resolved[row.DeploymentID]
+		if !ok {
+			prev := current.upstream(row.DeploymentID)
+
+			addr, err := p.resolve(ctx, row.ContainerID)
+			switch {
+			case err == nil:
+			case errors.Is(err, container.ErrNotFound), errors.Is(err, errNotRunning):
+			case prev != nil:
+				log.Printf("Failed to resolve container of deployment %s: %v", row.DeploymentID, err)
+				addr = prev.addr
+			default:
+				log.Printf("Failed to resolve container of deployment %s: %v", row.DeploymentID, err)
+			}
+
+			if addr != "" {
+				if prev != nil && prev.addr == addr {
+					u = prev
+				} else {
+					u = &upstream{deploymentID: row.DeploymentID, addr: addr}
+					// It passed its health check before going live.
+					u.healthy.Store(true)
+				}
+			}
+			resolved[row.DeploymentID] = u
+		}
+		if u == nil {
+			continue
+		}
+
+		r := next.routes[row.Host]
+		if r == nil {
+			r = &route{}
+			next.routes[row.Host] = r
+		}
+		r.upstreams = append(r.upstreams, u)
+	}
+	return next, nil
+}
+
+// containerAddr is where the application in a running container listens.
+func (p *Proxy) containerAddr(ctx context.Context, id string) (string, error) {
+	info, err := p.runtime.Inspect(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	if !info.Running() || info.IPAddress == "" {
+		return "", errNotRunning
+	}
+	return net.JoinHostPort(info.IPAddress, strconv.Itoa(p.port)), nil
+}
+
+// hostname strips the port and any trailing dot from a Host header or
+// server name, lower-casing what is left.
+func hostname(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.ToLower(strings.TrimSuffix(host, "."))
+}
@@ -0,0 +1,156 @@
+// Package secrets encrypts values stored at rest. Every value is sealed with
+// its own random data key, and the data key is wrapped with a key-encryption
+// key derived from the configured master key, so the master key itself
+// never touches stored data.
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"github.com/Jesuloba-world/deployease/backend/internal/config"
+)
+
+const (
+	keySize = 32
+
+	// formatVersion prefixes every sealed value so the layout can change.
+	formatVersion = 1
+
+	kekInfo = "deployease envelope key v1"
+)
+
+var (
+	ErrInvalidMasterKey = errors.New("secrets master key must be base64 encoding at least 32 bytes")
+	// ErrDecrypt is returned for values that were tampered with, sealed
+	// under another master key or bound to other associated data.
+	ErrDecrypt = errors.New("failed to decrypt secret")
+)
+
+// Cipher seals and opens values with envelope encryption.
+type Cipher struct {
+	kek cipher.AEAD
+}
+
+func NewCipher(cfg config.SecretsConfig) (*Cipher, error) {
+	master, err := decodeMasterKey(cfg.MasterKey)
+	if err != nil {
+		return nil, err
+	}
+
+	kek, err := hkdf.Key(sha256.New, master, nil, kekInfo, keySize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key-encryption key: %w", err)
+	}
+
+	aead, err := newAEAD(kek)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Cipher{kek: aead}, nil
+}
+
+// decodeMasterKey decodes a standard or URL-safe base64 master key.
+func decodeMasterKey(encoded string) ([]byte, error) {
+	for _, enc := range []*base64.Encoding{base64.StdEncoding, base64.URLEncoding, base64.RawStdEncoding, base64.RawURLEncoding} {
+		key, err := enc.DecodeString(encoded)
+		if err == nil && len(key) >= keySize {
+			return key, nil
+		}
+	}
+	return nil, ErrInvalidMasterKey
+}
+
+// Seal encrypts plaintext under a fresh data key. associatedData is not
+// stored but must be passed to Open again, which binds the sealed value to
+// its owner: a value copied to another row does not open.
+//
+// The result is the format version, the wrapped data key and the
+// encrypted value, each AES-GCM output carrying its own nonce.
+func (c *Cipher) Seal(plaintext, associatedData []byte) ([]byte, error) {
+	dataKey := make([]byte, keySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, fmt.Errorf("failed to generate data key: %w", err)
+	}
+
+	dek, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	sealed := []byte{formatVersion}
+	sealed, err = seal(c.kek, sealed, dataKey, nil)
+	if err != nil {
+		return nil, err
+	}
+	return seal(dek, sealed, plaintext, associatedData)
+}
+
+// Open decrypts a value returned by Seal.
+func (c *Cipher) Open(sealed, associatedData []byte) ([]byte, error) {
+	wrappedSize := c.kek.NonceSize() + keySize + c.kek.Overhead()
+	if len(sealed) < 1+wrappedSize || sealed[0] != formatVersion {
+		return nil, ErrDecrypt
+	}
+
+	dataKey, err := open(c.kek, sealed[1:1+wrappedSize], nil)
+	if err != nil {
+		return nil, err
+	}
+
+	dek, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	return open(dek, sealed[1+wrappedSize:], associatedData)
+}
+
+// SealString and OpenString are Seal and Open for text values.
+func (c *Cipher) SealString(plaintext, associatedData string) ([]byte, error) {
+	return c.Seal([]byte(plaintext), []byte(associatedData))
+}
+
+func (c *Cipher) OpenString(sealed []byte, associatedData string) (string, error) {
+	plaintext, err := c.Open(sealed, []byte(associatedData))
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal appends a random nonce and the encryption of plaintext to dst.
+func seal(aead cipher.AEAD, dst, plaintext, associatedData []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	dst = append(dst, nonce...)
+	return aead.Seal(dst, nonce, plaintext, associatedData), nil
+}
+
+func open(aead cipher.AEAD, sealed, associatedData []byte) ([]byte, error) {
+	if len(sealed) < aead.NonceSize()+aead.Overhead() {
+		return nil, ErrDecrypt
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, associatedData)
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	return plaintext, nil
+}
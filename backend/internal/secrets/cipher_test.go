@@ -0,0 +1,68 @@
+package secrets
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Jesuloba-world/deployease/backend/internal/config"
+)
+
+const testMasterKey = "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY="
+
+func newTestCipher(t *testing.T, masterKey string) *Cipher {
+	t.Helper()
+	c, err := NewCipher(config.SecretsConfig{MasterKey: masterKey})
+	require.NoError(t, err)
+	return c
+}
+
+func TestCipherRoundTrip(t *testing.T) {
+	c := newTestCipher(t, testMasterKey)
+
+	sealed, err := c.SealString("postgres://app:hunter2@db/app", "p1/DATABASE_URL")
+	require.NoError(t, err)
+	assert.False(t, bytes.Contains(sealed, []byte("hunter2")))
+
+	plaintext, err := c.OpenString(sealed, "p1/DATABASE_URL")
+	require.NoError(t, err)
+	assert.Equal(t, "postgres://app:hunter2@db/app", plaintext)
+
+	again, err := c.SealString("postgres://app:hunter2@db/app", "p1/DATABASE_URL")
+	require.NoError(t, err)
+	assert.NotEqual(t, sealed, again, "every value gets its own data key and nonce")
+}
+
+func TestCipherRejects(t *testing.T) {
+	c := newTestCipher(t, testMasterKey)
+	sealed, err := c.SealString("value", "p1/KEY")
+	require.NoError(t, err)
+
+	_, err = c.OpenString(sealed, "p2/KEY")
+	assert.ErrorIs(t, err, ErrDecrypt, "associated data must match")
+
+	other := newTestCipher(t, "ZmVkY2JhOTg3NjU0MzIxMGZlZGNiYTk4NzY1NDMyMTA=")
+	_, err = other.OpenString(sealed, "p1/KEY")
+	assert.ErrorIs(t, err, ErrDecrypt, "another master key cannot unwrap the data key")
+
+	tampered := bytes.Clone(sealed)
+	tampered[len(tampered)-1] ^= 1
+	_, err = c.OpenString(tampered, "p1/KEY")
+	assert.ErrorIs(t, err, ErrDecrypt)
+
+	_, err = c.OpenString(sealed[:10], "p1/KEY")
+	assert.ErrorIs(t, err, ErrDecrypt)
+}
+
+func TestNewCipherValidatesMasterKey(t *testing.T) {
+	for _, key := range []string{"", "not base64!", "c2hvcnQ="} {
+		_, err := NewCipher(config.SecretsConfig{MasterKey: key})
+		assert.ErrorIs(t, err, ErrInvalidMasterKey, key)
+	}
+
+	// Unpadded and URL-safe encodings work as well.
+	_, err := NewCipher(config.SecretsConfig{MasterKey: "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY"})
+	assert.NoError(t, err)
+}
@@ -0,0 +1,134 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// Bitbucket verifies Bitbucket Cloud webhooks signed with X-Hub-Signature.
+type Bitbucket struct{}
+
+type bitbucketRef struct {
+	Type   string `json:"type"`
+	Name   string `json:"name"`
+	Target struct {
+		Hash string `json:"hash"`
+	} `json:"target"`
+}
+
+type bitbucketRepository struct {
+	FullName string `json:"full_name"`
+	Links    struct {
+		HTML struct {
+			Href string `json:"href"`
+		} `json:"html"`
+	} `json:"links"`
+}
+
+// urls returns the forms of the repository's URL; payloads only carry its
+// web address and full name.
+func (r bitbucketRepository) urls() []string {
+	forms := []string{r.Links.HTML.Href}
+	if r.FullName != "" {
+		forms = append(forms,
+			"https://bitbucket.org/"+r.FullName,
+			"git@bitbucket.org:"+r.FullName,
+			"ssh://git@bitbucket.org/"+r.FullName,
+		)
+	}
+	return repositoryURLs(forms...)
+}
+
+type bitbucketPush struct {
+	Push struct {
+		Changes []struct {
+			New *bitbucketRef `json:"new"`
+			Old *bitbucketRef `json:"old"`
+		} `json:"changes"`
+	} `json:"push"`
+	Repository bitbucketRepository `json:"repository"`
+}
+
+type bitbucketPullRequest struct {
+	PullRequest struct {
+		ID     int `json:"id"`
+		Source struct {
+			Branch struct {
+				Name string `json:"name"`
+			} `json:"branch"`
+			// Repository is null once a fork the pull request came from is
+			// deleted.
+			Repository *bitbucketRepository `json:"repository"`
+		} `json:"source"`
+	} `json:"pullrequest"`
+}
+
+func (b *Bitbucket) Verify(header http.Header, body []byte, secret string) error {
+	return verifySHA256(secret, header.Get("X-Hub-Signature"), body)
+}
+
+func (b *Bitbucket) DeliveryID(header http.Header) string {
+	return header.Get("X-Request-UUID")
+}
+
+// Pushes returns one push per branch the event updated; a single Bitbucket
+// push event can cover several.
+func (b *Bitbucket) Pushes(header http.Header, body []byte) ([]Push, error) {
+	if header.Get("X-Event-Key") != "repo:push" {
+		return nil, nil
+	}
+
+	var payload bitbucketPush
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPayload, err)
+	}
+
+	urls := payload.Repository.urls()
+
+	var pushes []Push
+	for _, change := range payload.Push.Changes {
+		switch {
+		case change.New != nil && change.New.Type == "branch":
+			pushes = append(pushes, Push{
+				RepositoryURLs: urls,
+				Branch:         change.New.Name,
+				Commit:         change.New.Target.Hash,
+			})
+		case change.New == nil && change.Old != nil && change.Old.Type == "branch":
+			pushes = append(pushes, Push{
+				RepositoryURLs: urls,
+				Branch:         change.Old.Name,
+				Deleted:        true,
+			})
+		}
+	}
+	return pushes, nil
+}
+
+func (b *Bitbucket) PullRequests(header http.Header, body []byte) ([]PullRequest, error) {
+	var closed bool
+	switch header.Get("X-Event-Key") {
+	case "pullrequest:created":
+	case "pullrequest:fulfilled", "pullrequest:rejected":
+		closed = true
+	default:
+		return nil, nil
+	}
+
+	var payload bitbucketPullRequest
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPayload, err)
+	}
+
+	source := payload.PullRequest.Source
+	if source.Repository == nil || source.Branch.Name == "" {
+		return nil, nil
+	}
+	return []PullRequest{{
+		RepositoryURLs: source.Repository.urls(),
+		Branch:         source.Branch.Name,
+		Number:         payload.PullRequest.ID,
+		Closed:         closed,
+	}}, nil
+}
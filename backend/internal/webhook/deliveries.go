@@ -0,0 +1,45 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/Jesuloba-world/deployease/backend/internal/infrastructure/database/dragonfly"
+)
+
+var ErrClientNotInitialized = errors.New("Dragonfly client not initialized")
+
+// Deliveries remembers handled delivery IDs in Dragonfly, shared by every
+// instance, so a redelivered webhook is only deployed once.
+type Deliveries struct {
+	client *redis.Client
+	ttl    time.Duration
+}
+
+func NewDeliveries(ttl time.Duration) (*Deliveries, error) {
+	client := dragonfly.GetClient()
+	if client == nil {
+		return nil, ErrClientNotInitialized
+	}
+	return &Deliveries{
+		client: client,
+		ttl:    ttl,
+	}, nil
+}
+
+// Claim records the delivery and reports whether it is new.
+func (d *Deliveries) Claim(ctx context.Context, id string) (bool, error) {
+	return d.client.SetNX(ctx, deliveryKey(id), 1, d.ttl).Result()
+}
+
+// Release forgets a delivery so a redelivery of it is handled again.
+func (d *Deliveries) Release(ctx context.Context, id string) error {
+	return d.client.Del(ctx, deliveryKey(id)).Err()
+}
+
+func deliveryKey(id string) string {
+	return "webhook:delivery:" + id
+}
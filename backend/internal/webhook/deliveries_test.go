@@ -0,0 +1,42 @@
+package webhook
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
+
+	"github.com/Jesuloba-world/deployease/backend/internal/infrastructure/database/dragonfly"
+)
+
+func TestDeliveries(t *testing.T) {
+	testcontainers.SkipIfProviderIsNotHealthy(t)
+
+	ctx := context.Background()
+	container, err := dragonfly.StartDragonflyContainer(ctx)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		dragonfly.CloseClient()
+		container.Cleanup(ctx)
+	})
+	require.NoError(t, dragonfly.InitClient(container.GetConfig()))
+
+	deliveries, err := NewDeliveries(time.Minute)
+	require.NoError(t, err)
+
+	claimed, err := deliveries.Claim(ctx, "github:1")
+	require.NoError(t, err)
+	assert.True(t, claimed)
+
+	claimed, err = deliveries.Claim(ctx, "github:1")
+	require.NoError(t, err)
+	assert.False(t, claimed)
+
+	require.NoError(t, deliveries.Release(ctx, "github:1"))
+	claimed, err = deliveries.Claim(ctx, "github:1")
+	require.NoError(t, err)
+	assert.True(t, claimed)
+}
@@ -0,0 +1,122 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+)
+
+// GitHub verifies webhooks signed with X-Hub-Signature-256.
+type GitHub struct{}
+
+type githubRepository struct {
+	CloneURL string `json:"clone_url"`
+	SSHURL   string `json:"ssh_url"`
+	GitURL   string `json:"git_url"`
+	HTMLURL  string `json:"html_url"`
+}
+
+func (r githubRepository) urls() []string {
+	return repositoryURLs(r.CloneURL, r.SSHURL, r.GitURL, r.HTMLURL)
+}
+
+type githubPush struct {
+	Ref        string           `json:"ref"`
+	After      string           `json:"after"`
+	Deleted    bool             `json:"deleted"`
+	Repository githubRepository `json:"repository"`
+}
+
+type githubPullRequest struct {
+	Action      string `json:"action"`
+	Number      int    `json:"number"`
+	PullRequest struct {
+		Head struct {
+			Ref string `json:"ref"`
+			// Repo is null once a fork the pull request came from is
+			// deleted.
+			Repo *githubRepository `json:"repo"`
+		} `json:"head"`
+	} `json:"pull_request"`
+}
+
+func (g *GitHub) Verify(header http.Header, body []byte, secret string) error {
+	return verifySHA256(secret, header.Get("X-Hub-Signature-256"), body)
+}
+
+func (g *GitHub) DeliveryID(header http.Header) string {
+	return header.Get("X-GitHub-Delivery")
+}
+
+func (g *GitHub) Pushes(header http.Header, body []byte) ([]Push, error) {
+	if header.Get("X-GitHub-Event") != "push" {
+		return nil, nil
+	}
+
+	var payload githubPush
+	if err := decodeGitHub(header, body, &payload); err != nil {
+		return nil, err
+	}
+
+	branch, ok := branchFromRef(payload.Ref)
+	if !ok {
+		return nil, nil
+	}
+
+	return []Push{{
+		RepositoryURLs: payload.Repository.urls(),
+		Branch:         branch,
+		Commit:         payload.After,
+		Deleted:        payload.Deleted || payload.After == zeroCommit,
+	}}, nil
+}
+
+func (g *GitHub) PullRequests(header http.Header, body []byte) ([]PullRequest, error) {
+	if header.Get("X-GitHub-Event") != "pull_request" {
+		return nil, nil
+	}
+
+	var payload githubPullRequest
+	if err := decodeGitHub(header, body, &payload); err != nil {
+		return nil, err
+	}
+
+	var closed bool
+	switch payload.Action {
+	case "opened", "reopened":
+	case "closed":
+		closed = true
+	default:
+		return nil, nil
+	}
+
+	head := payload.PullRequest.Head
+	if head.Repo == nil || head.Ref == "" {
+		return nil, nil
+	}
+	return []PullRequest{{
+		RepositoryURLs: head.Repo.urls(),
+		Branch:         head.Ref,
+		Number:         payload.Number,
+		Closed:         closed,
+	}}, nil
+}
+
+// decodeGitHub unmarshals a payload into v. Webhooks can be configured to
+// send the payload as a form field.
+func decodeGitHub(header http.Header, body []byte, v any) error {
+	if mediaType, _, _ := mime.ParseMediaType(header.Get("Content-Type")); mediaType == "application/x-www-form-urlencoded" {
+		form, err := url.ParseQuery(string(body))
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidPayload, err)
+		}
+		body = []byte(form.Get("payload"))
+	}
+
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidPayload, err)
+	}
+	return nil
+}
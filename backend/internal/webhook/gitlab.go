@@ -0,0 +1,107 @@
+package webhook
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// GitLab verifies webhooks by the secret token GitLab sends as is in
+// X-Gitlab-Token.
+type GitLab struct{}
+
+type gitlabProject struct {
+	GitHTTPURL string `json:"git_http_url"`
+	GitSSHURL  string `json:"git_ssh_url"`
+	WebURL     string `json:"web_url"`
+}
+
+func (p gitlabProject) urls() []string {
+	return repositoryURLs(p.GitHTTPURL, p.GitSSHURL, p.WebURL)
+}
+
+type gitlabPush struct {
+	ObjectKind string        `json:"object_kind"`
+	Ref        string        `json:"ref"`
+	After      string        `json:"after"`
+	Project    gitlabProject `json:"project"`
+}
+
+type gitlabMergeRequest struct {
+	ObjectKind       string `json:"object_kind"`
+	ObjectAttributes struct {
+		IID          int           `json:"iid"`
+		Action       string        `json:"action"`
+		SourceBranch string        `json:"source_branch"`
+		Source       gitlabProject `json:"source"`
+	} `json:"object_attributes"`
+}
+
+func (g *GitLab) Verify(header http.Header, body []byte, secret string) error {
+	if secret == "" || subtle.ConstantTimeCompare([]byte(header.Get("X-Gitlab-Token")), []byte(secret)) != 1 {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+func (g *GitLab) DeliveryID(header http.Header) string {
+	return header.Get("X-Gitlab-Event-UUID")
+}
+
+func (g *GitLab) Pushes(header http.Header, body []byte) ([]Push, error) {
+	if header.Get("X-Gitlab-Event") != "Push Hook" {
+		return nil, nil
+	}
+
+	var payload gitlabPush
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPayload, err)
+	}
+
+	branch, ok := branchFromRef(payload.Ref)
+	if payload.ObjectKind != "push" || !ok {
+		return nil, nil
+	}
+
+	return []Push{{
+		RepositoryURLs: payload.Project.urls(),
+		Branch:         branch,
+		Commit:         payload.After,
+		Deleted:        payload.After == zeroCommit,
+	}}, nil
+}
+
+func (g *GitLab) PullRequests(header http.Header, body []byte) ([]PullRequest, error) {
+	if header.Get("X-Gitlab-Event") != "Merge Request Hook" {
+		return nil, nil
+	}
+
+	var payload gitlabMergeRequest
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPayload, err)
+	}
+	if payload.ObjectKind != "merge_request" {
+		return nil, nil
+	}
+
+	mr := payload.ObjectAttributes
+	var closed bool
+	switch mr.Action {
+	case "open", "reopen":
+	case "close", "merge":
+		closed = true
+	default:
+		return nil, nil
+	}
+
+	if mr.SourceBranch == "" {
+		return nil, nil
+	}
+	return []PullRequest{{
+		RepositoryURLs: mr.Source.urls(),
+		Branch:         mr.SourceBranch,
+		Number:         mr.IID,
+		Closed:         closed,
+	}}, nil
+}
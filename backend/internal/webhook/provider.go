@@ -0,0 +1,127 @@
+// Package webhook turns push webhooks from git providers into deployments,
+// and branch and pull request events into preview environment changes.
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// Provider names as they appear in webhook URLs.
+const (
+	ProviderGitHub    = "github"
+	ProviderGitLab    = "gitlab"
+	ProviderBitbucket = "bitbucket"
+)
+
+var (
+	ErrUnknownProvider  = errors.New("unknown webhook provider")
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	ErrInvalidPayload   = errors.New("invalid webhook payload")
+)
+
+// zeroCommit is the commit git providers report for a deleted branch.
+const zeroCommit = "0000000000000000000000000000000000000000"
+
+// Push is a branch update reported by a provider.
+type Push struct {
+	// RepositoryURLs are the forms of the repository's URL a project may
+	// have been created with, lowercased.
+	RepositoryURLs []string
+	Branch         string
+	Commit         string
+	// Deleted is set when the push removed the branch.
+	Deleted bool
+}
+
+// PullRequest is a change to a pull request reported by a provider. Pull
+// requests from forks are reported against the fork's repository, which no
+// project deploys.
+type PullRequest struct {
+	// RepositoryURLs are the forms of the URL of the repository the pull
+	// request's branch lives in, lowercased.
+	RepositoryURLs []string
+	// Branch is the pull request's source branch.
+	Branch string
+	Number int
+	// Closed is set when the pull request was closed or merged; otherwise
+	// it was opened or reopened.
+	Closed bool
+}
+
+// Provider verifies and decodes the webhook deliveries of one git host.
+type Provider interface {
+	// Verify checks that the delivery was signed with, or carries, secret.
+	Verify(header http.Header, body []byte, secret string) error
+	// DeliveryID identifies the delivery across redeliveries.
+	DeliveryID(header http.Header) string
+	// Pushes decodes the branch updates of a push event. Other events, such
+	// as pings or tag pushes, have none.
+	Pushes(header http.Header, body []byte) ([]Push, error)
+	// PullRequests decodes the opening and closing of pull requests. Other
+	// pull request events, such as edits, are ignored.
+	PullRequests(header http.Header, body []byte) ([]PullRequest, error)
+}
+
+// NewProviders returns the supported providers by name.
+func NewProviders() map[string]Provider {
+	return map[string]Provider{
+		ProviderGitHub:    &GitHub{},
+		ProviderGitLab:    &GitLab{},
+		ProviderBitbucket: &Bitbucket{},
+	}
+}
+
+// verifySHA256 checks a "sha256=<hex>" HMAC signature of body.
+func verifySHA256(secret, signature string, body []byte) error {
+	if secret == "" {
+		return ErrInvalidSignature
+	}
+	encoded, ok := strings.CutPrefix(signature, "sha256=")
+	if !ok {
+		return ErrInvalidSignature
+	}
+	got, err := hex.DecodeString(encoded)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	if !hmac.Equal(got, mac.Sum(nil)) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// branchFromRef returns the branch a ref names; tags and other refs are
+// not branches.
+func branchFromRef(ref string) (string, bool) {
+	branch, ok := strings.CutPrefix(ref, "refs/heads/")
+	return branch, ok && branch != ""
+}
+
+// repositoryURLs lowercases urls and adds each with and without a ".git"
+// suffix, since projects may be created from either form.
+func repositoryURLs(urls ...string) []string {
+	seen := make(map[string]bool)
+	var out []string
+	for _, u := range urls {
+		u = strings.ToLower(strings.TrimSuffix(strings.TrimSpace(u), "/"))
+		if u == "" {
+			continue
+		}
+		base := strings.TrimSuffix(u, ".git")
+		for _, form := range []string{base, base + ".git"} {
+			if !seen[form] {
+				seen[form] = true
+				out = append(out, form)
+			}
+		}
+	}
+	return out
+}
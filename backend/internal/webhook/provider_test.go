@@ -0,0 +1,290 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testCommit = "9fceb02d0ae598e95dc970b74767f19372d61af8"
+
+func sign(secret, body string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(body))
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+const githubPushBody = `{
+	"ref": "refs/heads/main",
+	"after": "` + testCommit + `",
+	"deleted": false,
+	"repository": {
+		"clone_url": "https://github.com/User/Repo.git",
+		"ssh_url": "git@github.com:User/Repo.git",
+		"git_url": "git://github.com/User/Repo.git",
+		"html_url": "https://github.com/User/Repo"
+	}
+}`
+
+func TestGitHub(t *testing.T) {
+	g := &GitHub{}
+	header := http.Header{}
+	header.Set("X-GitHub-Event", "push")
+	header.Set("X-GitHub-Delivery", "72d3162e-cc78-11e3-81ab-4c9367dc0958")
+	header.Set("X-Hub-Signature-256", sign("s3cr3t", githubPushBody))
+
+	require.NoError(t, g.Verify(header, []byte(githubPushBody), "s3cr3t"))
+	assert.ErrorIs(t, g.Verify(header, []byte(githubPushBody+" "), "s3cr3t"), ErrInvalidSignature)
+	assert.ErrorIs(t, g.Verify(header, []byte(githubPushBody), "other-project"), ErrInvalidSignature)
+	assert.Equal(t, "72d3162e-cc78-11e3-81ab-4c9367dc0958", g.DeliveryID(header))
+
+	pushes, err := g.Pushes(header, []byte(githubPushBody))
+	require.NoError(t, err)
+	require.Len(t, pushes, 1)
+	assert.Equal(t, "main", pushes[0].Branch)
+	assert.Equal(t, testCommit, pushes[0].Commit)
+	assert.False(t, pushes[0].Deleted)
+	assert.Equal(t, []string{
+		"https://github.com/user/repo",
+		"https://github.com/user/repo.git",
+		"git@github.com:user/repo",
+		"git@github.com:user/repo.git",
+		"git://github.com/user/repo",
+		"git://github.com/user/repo.git",
+	}, pushes[0].RepositoryURLs)
+
+	// The payload can also arrive form-encoded, signed as sent.
+	form := "payload=" + url.QueryEscape(githubPushBody)
+	formHeader := header.Clone()
+	formHeader.Set("Content-Type", "application/x-www-form-urlencoded")
+	formHeader.Set("X-Hub-Signature-256", sign("s3cr3t", form))
+	require.NoError(t, g.Verify(formHeader, []byte(form), "s3cr3t"))
+	pushes, err = g.Pushes(formHeader, []byte(form))
+	require.NoError(t, err)
+	require.Len(t, pushes, 1)
+	assert.Equal(t, testCommit, pushes[0].Commit)
+
+	ping := header.Clone()
+	ping.Set("X-GitHub-Event", "ping")
+	pushes, err = g.Pushes(ping, []byte(`{"zen":"Keep it logically awesome."}`))
+	require.NoError(t, err)
+	assert.Empty(t, pushes)
+
+	pushes, err = g.Pushes(header, []byte(`{"ref":"refs/tags/v1.0.0","after":"`+testCommit+`"}`))
+	require.NoError(t, err)
+	assert.Empty(t, pushes, "tag pushes are not deployed")
+
+	pushes, err = g.Pushes(header, []byte(`{"ref":"refs/heads/old","after":"`+zeroCommit+`","deleted":true}`))
+	require.NoError(t, err)
+	require.Len(t, pushes, 1)
+	assert.True(t, pushes[0].Deleted)
+
+	_, err = g.Pushes(header, []byte(`{`))
+	assert.ErrorIs(t, err, ErrInvalidPayload)
+
+	header.Del("X-Hub-Signature-256")
+	assert.ErrorIs(t, g.Verify(header, []byte(githubPushBody), "s3cr3t"), ErrInvalidSignature)
+}
+
+func TestGitLab(t *testing.T) {
+	g := &GitLab{}
+	body := `{
+		"object_kind": "push",
+		"ref": "refs/heads/release/v2",
+		"after": "` + testCommit + `",
+		"project": {
+			"git_http_url": "https://gitlab.com/group/sub/repo.git",
+			"git_ssh_url": "git@gitlab.com:group/sub/repo.git",
+			"web_url": "https://gitlab.com/group/sub/repo"
+		}
+	}`
+	header := http.Header{}
+	header.Set("X-Gitlab-Event", "Push Hook")
+	header.Set("X-Gitlab-Event-UUID", "13792a34-cac6-4fda-95a8-c58e00a3954e")
+
+	assert.ErrorIs(t, g.Verify(header, []byte(body), "t0ken"), ErrInvalidSignature)
+	assert.ErrorIs(t, g.Verify(header, []byte(body), ""), ErrInvalidSignature, "an empty token matches nothing")
+	header.Set("X-Gitlab-Token", "wrong")
+	assert.ErrorIs(t, g.Verify(header, []byte(body), "t0ken"), ErrInvalidSignature)
+	header.Set("X-Gitlab-Token", "t0ken")
+	require.NoError(t, g.Verify(header, []byte(body), "t0ken"))
+	assert.ErrorIs(t, g.Verify(header, []byte(body), "other-project"), ErrInvalidSignature)
+	assert.Equal(t, "13792a34-cac6-4fda-95a8-c58e00a3954e", g.DeliveryID(header))
+
+	pushes, err := g.Pushes(header, []byte(body))
+	require.NoError(t, err)
+	require.Len(t, pushes, 1)
+	assert.Equal(t, "release/v2", pushes[0].Branch)
+	assert.Equal(t, testCommit, pushes[0].Commit)
+	assert.Contains(t, pushes[0].RepositoryURLs, "https://gitlab.com/group/sub/repo.git")
+	assert.Contains(t, pushes[0].RepositoryURLs, "git@gitlab.com:group/sub/repo.git")
+
+	pushes, err = g.Pushes(header, []byte(`{"object_kind":"push","ref":"refs/heads/gone","after":"`+zeroCommit+`"}`))
+	require.NoError(t, err)
+	require.Len(t, pushes, 1)
+	assert.True(t, pushes[0].Deleted)
+
+	header.Set("X-Gitlab-Event", "Tag Push Hook")
+	pushes, err = g.Pushes(header, []byte(body))
+	require.NoError(t, err)
+	assert.Empty(t, pushes)
+}
+
+func TestBitbucket(t *testing.T) {
+	b := &Bitbucket{}
+	body := `{
+		"push": {
+			"changes": [
+				{"new": {"type": "branch", "name": "main", "target": {"hash": "` + testCommit + `"}}, "old": {"type": "branch", "name": "main"}},
+				{"new": {"type": "tag", "name": "v1", "target": {"hash": "` + testCommit + `"}}, "old": null},
+				{"new": null, "old": {"type": "branch", "name": "feature"}}
+			]
+		},
+		"repository": {
+			"full_name": "team/repo",
+			"links": {"html": {"href": "https://bitbucket.org/team/repo"}}
+		}
+	}`
+	header := http.Header{}
+	header.Set("X-Event-Key", "repo:push")
+	header.Set("X-Request-UUID", "a7c1ebbd-6f4b-4a1a-9a4e-f7a1e8f2b3c4")
+	header.Set("X-Hub-Signature", sign("s3cr3t", body))
+
+	require.NoError(t, b.Verify(header, []byte(body), "s3cr3t"))
+	assert.ErrorIs(t, b.Verify(header, []byte(body[1:]), "s3cr3t"), ErrInvalidSignature)
+	assert.Equal(t, "a7c1ebbd-6f4b-4a1a-9a4e-f7a1e8f2b3c4", b.DeliveryID(header))
+
+	pushes, err := b.Pushes(header, []byte(body))
+	require.NoError(t, err)
+	require.Len(t, pushes, 2)
+	assert.Equal(t, "main", pushes[0].Branch)
+	assert.Equal(t, testCommit, pushes[0].Commit)
+	assert.Equal(t, []string{
+		"https://bitbucket.org/team/repo",
+		"https://bitbucket.org/team/repo.git",
+		"git@bitbucket.org:team/repo",
+		"git@bitbucket.org:team/repo.git",
+		"ssh://git@bitbucket.org/team/repo",
+		"ssh://git@bitbucket.org/team/repo.git",
+	}, pushes[0].RepositoryURLs)
+	assert.Equal(t, "feature", pushes[1].Branch)
+	assert.True(t, pushes[1].Deleted)
+}
+
+func TestGitHubPullRequests(t *testing.T) {
+	g := &GitHub{}
+	header := http.Header{}
+	header.Set("X-GitHub-Event", "pull_request")
+
+	body := func(action, repo string) []byte {
+		return []byte(`{
+			"action": "` + action + `",
+			"number": 42,
+			"pull_request": {"head": {"ref": "feature/login", "repo": ` + repo + `}}
+		}`)
+	}
+	repo := `{"clone_url": "https://github.com/User/Repo.git", "html_url": "https://github.com/User/Repo"}`
+
+	pulls, err := g.PullRequests(header, body("opened", repo))
+	require.NoError(t, err)
+	require.Len(t, pulls, 1)
+	assert.Equal(t, "feature/login", pulls[0].Branch)
+	assert.Equal(t, 42, pulls[0].Number)
+	assert.False(t, pulls[0].Closed)
+	assert.Contains(t, pulls[0].RepositoryURLs, "https://github.com/user/repo.git")
+
+	pulls, err = g.PullRequests(header, body("closed", repo))
+	require.NoError(t, err)
+	require.Len(t, pulls, 1)
+	assert.True(t, pulls[0].Closed)
+
+	pulls, err = g.PullRequests(header, body("synchronize", repo))
+	require.NoError(t, err)
+	assert.Empty(t, pulls, "pushes to the branch arrive as push events")
+
+	pulls, err = g.PullRequests(header, body("closed", "null"))
+	require.NoError(t, err)
+	assert.Empty(t, pulls, "a deleted fork has nothing to tear down")
+
+	pushes, err := g.Pushes(header, body("opened", repo))
+	require.NoError(t, err)
+	assert.Empty(t, pushes)
+}
+
+func TestGitLabMergeRequests(t *testing.T) {
+	g := &GitLab{}
+	header := http.Header{}
+	header.Set("X-Gitlab-Event", "Merge Request Hook")
+
+	body := func(action string) []byte {
+		return []byte(`{
+			"object_kind": "merge_request",
+			"object_attributes": {
+				"iid": 7,
+				"action": "` + action + `",
+				"source_branch": "feature/login",
+				"source": {"git_http_url": "https://gitlab.com/group/repo.git"}
+			}
+		}`)
+	}
+
+	pulls, err := g.PullRequests(header, body("open"))
+	require.NoError(t, err)
+	require.Len(t, pulls, 1)
+	assert.Equal(t, 7, pulls[0].Number)
+	assert.False(t, pulls[0].Closed)
+	assert.Contains(t, pulls[0].RepositoryURLs, "https://gitlab.com/group/repo.git")
+
+	for _, action := range []string{"close", "merge"} {
+		pulls, err = g.PullRequests(header, body(action))
+		require.NoError(t, err)
+		require.Len(t, pulls, 1, action)
+		assert.True(t, pulls[0].Closed, action)
+	}
+
+	pulls, err = g.PullRequests(header, body("update"))
+	require.NoError(t, err)
+	assert.Empty(t, pulls)
+}
+
+func TestBitbucketPullRequests(t *testing.T) {
+	b := &Bitbucket{}
+	body := []byte(`{
+		"pullrequest": {
+			"id": 3,
+			"source": {
+				"branch": {"name": "feature/login"},
+				"repository": {"full_name": "team/repo", "links": {"html": {"href": "https://bitbucket.org/team/repo"}}}
+			}
+		}
+	}`)
+	header := http.Header{}
+
+	header.Set("X-Event-Key", "pullrequest:created")
+	pulls, err := b.PullRequests(header, body)
+	require.NoError(t, err)
+	require.Len(t, pulls, 1)
+	assert.Equal(t, 3, pulls[0].Number)
+	assert.Equal(t, "feature/login", pulls[0].Branch)
+	assert.False(t, pulls[0].Closed)
+	assert.Contains(t, pulls[0].RepositoryURLs, "git@bitbucket.org:team/repo.git")
+
+	for _, key := range []string{"pullrequest:fulfilled", "pullrequest:rejected"} {
+		header.Set("X-Event-Key", key)
+		pulls, err = b.PullRequests(header, body)
+		require.NoError(t, err)
+		require.Len(t, pulls, 1, key)
+		assert.True(t, pulls[0].Closed, key)
+	}
+
+	header.Set("X-Event-Key", "pullrequest:updated")
+	pulls, err = b.PullRequests(header, body)
+	require.NoError(t, err)
+	assert.Empty(t, pulls)
+}
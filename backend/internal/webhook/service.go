@@ -0,0 +1,236 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+
+	"github.com/Jesuloba-world/deployease/backend/internal/deployment"
+	"github.com/Jesuloba-world/deployease/backend/internal/infrastructure/database/postgres/sqlc"
+	"github.com/Jesuloba-world/deployease/backend/internal/project"
+)
+
+var commitPattern = regexp.MustCompile(`^[0-9a-fA-F]{40}$`)
+
+// Deployer starts deployments and manages preview environments;
+// *deployment.Service satisfies it.
+type Deployer interface {
+	CreateForBranch(ctx context.Context, projectID, branch, commitHash string) (sqlc.Deployment, error)
+	TeardownPreview(ctx context.Context, projectID, branch string) (sqlc.PreviewEnvironment, error)
+	LinkPullRequest(ctx context.Context, projectID, branch string, number int) (sqlc.PreviewEnvironment, error)
+}
+
+// SecretStore decrypts the secret a project's deliveries are signed with;
+// *project.Service satisfies it.
+type SecretStore interface {
+	WebhookSecret(p sqlc.Project) (string, error)
+}
+
+// DeliveryStore remembers which deliveries have been handled.
+type DeliveryStore interface {
+	Claim(ctx context.Context, id string) (bool, error)
+	Release(ctx context.Context, id string) error
+}
+
+type Service struct {
+	providers  map[string]Provider
+	queries    sqlc.Querier
+	secrets    SecretStore
+	deployer   Deployer
+	deliveries DeliveryStore
+}
+
+func NewService(queries sqlc.Querier, secrets SecretStore, deployer Deployer, deliveries DeliveryStore) *Service {
+	return &Service{
+		providers:  NewProviders(),
+		queries:    queries,
+		secrets:    secrets,
+		deployer:   deployer,
+		deliveries: deliveries,
+	}
+}
+
+type Result struct {
+	Deployments []sqlc.Deployment
+	// TornDown are the preview environments removed because their branch
+	// was deleted or their pull request closed.
+	TornDown []sqlc.PreviewEnvironment
+	// Duplicate is set for redeliveries of a delivery already handled.
+	Duplicate bool
+}
+
+// Handle starts a deployment for every project that deploys the pushed
+// branch of the pushed repository, either as its own branch or, for
+// projects with previews enabled, to the branch's preview environment.
+// Deleting a branch or closing its pull request tears its preview
+// environments down. A delivery only acts on the projects whose webhook
+// secret it was signed with, and is rejected if there are none. Other
+// events are acknowledged without changing anything.
+func (s *Service) Handle(ctx context.Context, provider string, header http.Header, body []byte) (*Result, error) {
+	p, ok := s.providers[provider]
+	if !ok {
+		return nil, ErrUnknownProvider
+	}
+
+	pushes, err := p.Pushes(header, body)
+	if err != nil {
+		return nil, err
+	}
+	pulls, err := p.PullRequests(header, body)
+	if err != nil {
+		return nil, err
+	}
+	if len(pushes) == 0 && len(pulls) == 0 {
+		return &Result{}, nil
+	}
+	for _, push := range pushes {
+		if !push.Deleted && !commitPattern.MatchString(push.Commit) {
+			return nil, fmt.Errorf("%w: %q is not a commit", ErrInvalidPayload, push.Commit)
+		}
+	}
+
+	verified, err := s.verify(ctx, p, header, body, pushes, pulls)
+	if err != nil {
+		return nil, err
+	}
+	if len(verified) == 0 {
+		return nil, ErrInvalidSignature
+	}
+
+	if id := p.DeliveryID(header); id != "" {
+		id = provider + ":" + id
+		claimed, err := s.deliveries.Claim(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to record webhook delivery: %w", err)
+		}
+		if !claimed {
+			return &Result{Duplicate: true}, nil
+		}
+
+		result, err := s.apply(ctx, verified, pushes, pulls)
+		if err != nil {
+			// Let the provider's retry through. Deployments started before
+			// the failure will be started again.
+			if releaseErr := s.deliveries.Release(context.WithoutCancel(ctx), id); releaseErr != nil {
+				log.Printf("Failed to release webhook delivery %s: %v", id, releaseErr)
+			}
+			return nil, err
+		}
+		return result, nil
+	}
+
+	return s.apply(ctx, verified, pushes, pulls)
+}
+
+// verify returns the IDs of the projects of the delivery's repositories
+// whose webhook secret the delivery was signed with. Projects without a
+// secret accept no deliveries.
+func (s *Service) verify(ctx context.Context, p Provider, header http.Header, body []byte, pushes []Push, pulls []PullRequest) (map[string]bool, error) {
+	var urls []string
+	for _, push := range pushes {
+		urls = append(urls, push.RepositoryURLs...)
+	}
+	for _, pull := range pulls {
+		urls = append(urls, pull.RepositoryURLs...)
+	}
+
+	projects, err := s.queries.ListProjectsByRepository(ctx, urls)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find projects: %w", err)
+	}
+
+	verified := make(map[string]bool)
+	for _, proj := range projects {
+		secret, err := s.secrets.WebhookSecret(proj)
+		if errors.Is(err, project.ErrNoWebhookSecret) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("project %s: %w", proj.ID, err)
+		}
+		if p.Verify(header, body, secret) == nil {
+			verified[proj.ID] = true
+		}
+	}
+	return verified, nil
+}
+
+func (s *Service) apply(ctx context.Context, verified map[string]bool, pushes []Push, pulls []PullRequest) (*Result, error) {
+	result := &Result{
+		Deployments: []sqlc.Deployment{},
+		TornDown:    []sqlc.PreviewEnvironment{},
+	}
+
+	for _, push := range pushes {
+		projects, err := s.queries.ListProjectsByRepository(ctx, push.RepositoryURLs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find projects: %w", err)
+		}
+
+		for _, p := range projects {
+			if !verified[p.ID] {
+				continue
+			}
+			switch {
+			case push.Deleted:
+				// Deleting the project's own branch leaves what it last
+				// deployed running.
+				if p.Branch != push.Branch {
+					if err := s.teardown(ctx, result, p.ID, push.Branch); err != nil {
+						return nil, err
+					}
+				}
+			case p.Branch == push.Branch || p.PreviewsEnabled:
+				d, err := s.deployer.CreateForBranch(ctx, p.ID, push.Branch, push.Commit)
+				if err != nil {
+					return nil, err
+				}
+				result.Deployments = append(result.Deployments, d)
+			}
+		}
+	}
+
+	for _, pull := range pulls {
+		projects, err := s.queries.ListProjectsByRepository(ctx, pull.RepositoryURLs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find projects: %w", err)
+		}
+
+		for _, p := range projects {
+			if !verified[p.ID] || p.Branch == pull.Branch {
+				continue
+			}
+			if pull.Closed {
+				if err := s.teardown(ctx, result, p.ID, pull.Branch); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			// Previews are created by pushes, so a pull request opened
+			// before its branch was pushed to goes unlinked.
+			_, err := s.deployer.LinkPullRequest(ctx, p.ID, pull.Branch, pull.Number)
+			if err != nil && !errors.Is(err, deployment.ErrPreviewNotFound) {
+				return nil, err
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// teardown removes the preview environment of branch, if the project has
+// one.
+func (s *Service) teardown(ctx context.Context, result *Result, projectID, branch string) error {
+	preview, err := s.deployer.TeardownPreview(ctx, projectID, branch)
+	if errors.Is(err, deployment.ErrPreviewNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	result.TornDown = append(result.TornDown, preview)
+	return nil
+}
@@ -0,0 +1,265 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Jesuloba-world/deployease/backend/internal/deployment"
+	"github.com/Jesuloba-world/deployease/backend/internal/infrastructure/database/postgres/sqlc"
+	"github.com/Jesuloba-world/deployease/backend/internal/project"
+)
+
+type fakeQuerier struct {
+	sqlc.Querier
+	projects []sqlc.Project
+}
+
+func (f *fakeQuerier) ListProjectsByRepository(ctx context.Context, repositoryUrls []string) ([]sqlc.Project, error) {
+	out := []sqlc.Project{}
+	for _, p := range f.projects {
+		if slices.Contains(repositoryUrls, p.RepositoryUrl) {
+			out = append(out, p)
+		}
+	}
+	return out, nil
+}
+
+// fakeSecrets maps project IDs to their webhook secret.
+type fakeSecrets map[string]string
+
+func (f fakeSecrets) WebhookSecret(p sqlc.Project) (string, error) {
+	secret, ok := f[p.ID]
+	if !ok {
+		return "", project.ErrNoWebhookSecret
+	}
+	return secret, nil
+}
+
+// sharedSecret gives every project of q the same webhook secret.
+func sharedSecret(q *fakeQuerier, secret string) fakeSecrets {
+	secrets := fakeSecrets{}
+	for _, p := range q.projects {
+		secrets[p.ID] = secret
+	}
+	return secrets
+}
+
+type fakeDeployer struct {
+	created []string
+	// previews holds "project/branch" of the preview environments,
+	// mapped to their pull request.
+	previews map[string]int
+	tornDown []string
+	err      error
+}
+
+func (f *fakeDeployer) CreateForBranch(ctx context.Context, projectID, branch, commitHash string) (sqlc.Deployment, error) {
+	if f.err != nil {
+		return sqlc.Deployment{}, f.err
+	}
+	f.created = append(f.created, projectID+"/"+branch+"@"+commitHash)
+	return sqlc.Deployment{ID: "d" + projectID, ProjectID: projectID, Branch: branch}, nil
+}
+
+func (f *fakeDeployer) TeardownPreview(ctx context.Context, projectID, branch string) (sqlc.PreviewEnvironment, error) {
+	id := projectID + "/" + branch
+	if _, ok := f.previews[id]; !ok {
+		return sqlc.PreviewEnvironment{}, deployment.ErrPreviewNotFound
+	}
+	delete(f.previews, id)
+	f.tornDown = append(f.tornDown, id)
+	return sqlc.PreviewEnvironment{ID: id, ProjectID: projectID, Branch: branch}, nil
+}
+
+func (f *fakeDeployer) LinkPullRequest(ctx context.Context, projectID, branch string, number int) (sqlc.PreviewEnvironment, error) {
+	id := projectID + "/" + branch
+	if _, ok := f.previews[id]; !ok {
+		return sqlc.PreviewEnvironment{}, deployment.ErrPreviewNotFound
+	}
+	f.previews[id] = number
+	return sqlc.PreviewEnvironment{ID: id, ProjectID: projectID, Branch: branch}, nil
+}
+
+type fakeDeliveries map[string]bool
+
+func (f fakeDeliveries) Claim(ctx context.Context, id string) (bool, error) {
+	if f[id] {
+		return false, nil
+	}
+	f[id] = true
+	return true, nil
+}
+
+func (f fakeDeliveries) Release(ctx context.Context, id string) error {
+	delete(f, id)
+	return nil
+}
+
+func githubHeader(delivery string) http.Header {
+	header := http.Header{}
+	header.Set("X-GitHub-Event", "push")
+	header.Set("X-GitHub-Delivery", delivery)
+	header.Set("X-Hub-Signature-256", sign("s3cr3t", githubPushBody))
+	return header
+}
+
+func TestServiceDeploysMatchingProjects(t *testing.T) {
+	ctx := context.Background()
+	q := &fakeQuerier{projects: []sqlc.Project{
+		{ID: "p1", RepositoryUrl: "https://github.com/user/repo.git", Branch: "main"},
+		{ID: "p2", RepositoryUrl: "git@github.com:user/repo.git", Branch: "main"},
+		{ID: "p3", RepositoryUrl: "https://github.com/user/repo", Branch: "develop"},
+		{ID: "p4", RepositoryUrl: "https://github.com/user/other.git", Branch: "main"},
+	}}
+	deployer := &fakeDeployer{}
+	deliveries := fakeDeliveries{}
+	svc := NewService(q, sharedSecret(q, "s3cr3t"), deployer, deliveries)
+
+	result, err := svc.Handle(ctx, ProviderGitHub, githubHeader("delivery-1"), []byte(githubPushBody))
+	require.NoError(t, err)
+	assert.False(t, result.Duplicate)
+	require.Len(t, result.Deployments, 2)
+	assert.Equal(t, []string{"p1/main@" + testCommit, "p2/main@" + testCommit}, deployer.created)
+
+	result, err = svc.Handle(ctx, ProviderGitHub, githubHeader("delivery-1"), []byte(githubPushBody))
+	require.NoError(t, err)
+	assert.True(t, result.Duplicate, "redeliveries are dropped")
+	assert.Len(t, deployer.created, 2)
+
+	// A failed delivery is forgotten so the provider's retry goes through.
+	deployer.err = errors.New("database is down")
+	_, err = svc.Handle(ctx, ProviderGitHub, githubHeader("delivery-2"), []byte(githubPushBody))
+	require.Error(t, err)
+	assert.NotContains(t, deliveries, "github:delivery-2")
+
+	deployer.err = nil
+	result, err = svc.Handle(ctx, ProviderGitHub, githubHeader("delivery-2"), []byte(githubPushBody))
+	require.NoError(t, err)
+	assert.Len(t, result.Deployments, 2)
+}
+
+func TestServicePreviews(t *testing.T) {
+	ctx := context.Background()
+	q := &fakeQuerier{projects: []sqlc.Project{
+		{ID: "p1", RepositoryUrl: "https://github.com/user/repo.git", Branch: "main", PreviewsEnabled: true},
+		{ID: "p2", RepositoryUrl: "https://github.com/user/repo.git", Branch: "main"},
+		{ID: "p3", RepositoryUrl: "https://github.com/user/repo.git", Branch: "feature/login"},
+	}}
+	deployer := &fakeDeployer{previews: map[string]int{"p2/feature/login": 0}}
+	svc := NewService(q, sharedSecret(q, "s3cr3t"), deployer, fakeDeliveries{})
+
+	deliver := func(event, body string) *Result {
+		t.Helper()
+		header := http.Header{}
+		header.Set("X-GitHub-Event", event)
+		header.Set("X-Hub-Signature-256", sign("s3cr3t", body))
+		result, err := svc.Handle(ctx, ProviderGitHub, header, []byte(body))
+		require.NoError(t, err)
+		return result
+	}
+	repo := `"repository": {"clone_url": "https://github.com/user/repo.git"}`
+
+	// Only projects with previews enabled deploy other branches; a project
+	// deploying the branch itself does so as usual.
+	result := deliver("push", `{"ref":"refs/heads/feature/login","after":"`+testCommit+`",`+repo+`}`)
+	assert.Len(t, result.Deployments, 2)
+	assert.Equal(t, []string{"p1/feature/login@" + testCommit, "p3/feature/login@" + testCommit}, deployer.created)
+	deployer.previews["p1/feature/login"] = 0
+
+	pull := func(action string) string {
+		return `{"action":"` + action + `","number":42,"pull_request":{"head":{"ref":"feature/login","repo":{"clone_url":"https://github.com/user/repo.git"}}}}`
+	}
+	deliver("pull_request", pull("opened"))
+	assert.Equal(t, 42, deployer.previews["p1/feature/login"])
+
+	// Closing the pull request tears down every project's preview of the
+	// branch, whether or not previews are still enabled, but not p3 which
+	// deploys the branch as its own.
+	result = deliver("pull_request", pull("closed"))
+	assert.ElementsMatch(t, []string{"p1/feature/login", "p2/feature/login"}, deployer.tornDown)
+	assert.Len(t, result.TornDown, 2)
+
+	// Deleting the branch afterwards finds nothing left to tear down.
+	result = deliver("push", `{"ref":"refs/heads/feature/login","after":"`+zeroCommit+`","deleted":true,`+repo+`}`)
+	assert.Empty(t, result.TornDown)
+	assert.Empty(t, result.Deployments)
+	assert.Len(t, deployer.tornDown, 2)
+
+	deployer.previews["p1/fix"] = 0
+	result = deliver("push", `{"ref":"refs/heads/fix","after":"`+zeroCommit+`","deleted":true,`+repo+`}`)
+	require.Len(t, result.TornDown, 1)
+	assert.Equal(t, "p1/fix", result.TornDown[0].ID)
+}
+
+func TestServiceOnlyActsOnProjectsSignedFor(t *testing.T) {
+	ctx := context.Background()
+	q := &fakeQuerier{projects: []sqlc.Project{
+		{ID: "victim", RepositoryUrl: "https://github.com/user/repo.git", Branch: "main", PreviewsEnabled: true},
+		{ID: "tenant", RepositoryUrl: "https://github.com/user/repo.git", Branch: "main"},
+		{ID: "legacy", RepositoryUrl: "https://github.com/user/repo.git", Branch: "main"},
+	}}
+	deployer := &fakeDeployer{previews: map[string]int{"victim/feature": 0}}
+	deliveries := fakeDeliveries{}
+	svc := NewService(q, fakeSecrets{"victim": "victim-secret", "tenant": "s3cr3t"}, deployer, deliveries)
+
+	// A tenant knowing only its own secret deploys only its own project.
+	result, err := svc.Handle(ctx, ProviderGitHub, githubHeader("delivery-1"), []byte(githubPushBody))
+	require.NoError(t, err)
+	require.Len(t, result.Deployments, 1)
+	assert.Equal(t, []string{"tenant/main@" + testCommit}, deployer.created)
+
+	// Nor can it tear down another project's previews.
+	body := `{"ref":"refs/heads/feature","after":"` + zeroCommit + `","deleted":true,"repository":{"clone_url":"https://github.com/user/repo.git"}}`
+	header := githubHeader("delivery-2")
+	header.Set("X-Hub-Signature-256", sign("s3cr3t", body))
+	result, err = svc.Handle(ctx, ProviderGitHub, header, []byte(body))
+	require.NoError(t, err)
+	assert.Empty(t, result.TornDown)
+	assert.Contains(t, deployer.previews, "victim/feature")
+
+	// A delivery no project's secret verifies is rejected before it is
+	// recorded.
+	header = githubHeader("delivery-3")
+	header.Set("X-Hub-Signature-256", sign("", githubPushBody))
+	_, err = svc.Handle(ctx, ProviderGitHub, header, []byte(githubPushBody))
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+	assert.NotContains(t, deliveries, "github:delivery-3")
+	assert.Len(t, deployer.created, 1)
+}
+
+func TestServiceRejects(t *testing.T) {
+	ctx := context.Background()
+	deployer := &fakeDeployer{}
+	q := &fakeQuerier{projects: []sqlc.Project{
+		{ID: "p1", RepositoryUrl: "https://github.com/user/repo.git", Branch: "main"},
+	}}
+	svc := NewService(q, sharedSecret(q, "s3cr3t"), deployer, fakeDeliveries{})
+
+	_, err := svc.Handle(ctx, "gitea", http.Header{}, nil)
+	assert.ErrorIs(t, err, ErrUnknownProvider)
+
+	gitlab := http.Header{}
+	gitlab.Set("X-Gitlab-Event", "Push Hook")
+	gitlab.Set("X-Gitlab-Token", "s3cr3t")
+	body := `{"object_kind":"push","ref":"refs/heads/main","after":"` + testCommit + `","project":{"git_http_url":"https://gitlab.com/user/repo.git"}}`
+	_, err = svc.Handle(ctx, ProviderGitLab, gitlab, []byte(body))
+	assert.ErrorIs(t, err, ErrInvalidSignature, "no project deploys the repository")
+
+	header := githubHeader("delivery-1")
+	header.Set("X-Hub-Signature-256", sign("guess", githubPushBody))
+	_, err = svc.Handle(ctx, ProviderGitHub, header, []byte(githubPushBody))
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+
+	body = `{"ref":"refs/heads/main","after":"HEAD; rm -rf /"}`
+	header.Set("X-Hub-Signature-256", sign("s3cr3t", body))
+	_, err = svc.Handle(ctx, ProviderGitHub, header, []byte(body))
+	assert.ErrorIs(t, err, ErrInvalidPayload)
+
+	assert.Empty(t, deployer.created)
+}
@@ -0,0 +1,108 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+type User struct {
+	ID        string    `json:"id"`
+	Username  string    `json:"username"`
+	Email     string    `json:"email"`
+	IsAdmin   bool      `json:"is_admin"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type tokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"`
+	User         *User  `json:"user"`
+}
+
+func (r tokenResponse) tokens() Tokens {
+	return Tokens{
+		AccessToken:  r.AccessToken,
+		RefreshToken: r.RefreshToken,
+		ExpiresAt:    time.Now().Add(time.Duration(r.ExpiresIn) * time.Second),
+	}
+}
+
+// Login signs in with an email and password. The client uses the tokens
+// it receives for the requests that follow.
+func (c *Client) Login(ctx context.Context, email, password string) (User, error) {
+	var resp tokenResponse
+	err := c.do(ctx, request{
+		method: http.MethodPost,
+		path:   "/auth/login",
+		body:   map[string]string{"email": email, "password": password},
+	}, &resp)
+	if err != nil {
+		return User{}, err
+	}
+	if resp.User == nil {
+		return User{}, fmt.Errorf("login response has no user")
+	}
+
+	c.setTokens(resp.tokens())
+	return *resp.User, nil
+}
+
+// Refresh exchanges the refresh token for new tokens. Requests refresh
+// expired tokens on their own, so this is rarely needed directly.
+func (c *Client) Refresh(ctx context.Context) error {
+	c.refreshMu.Lock()
+	defer c.refreshMu.Unlock()
+	return c.refresh(ctx)
+}
+
+// refreshExpired refreshes the tokens after a request authenticated with
+// expired was rejected, unless another request has refreshed them since.
+func (c *Client) refreshExpired(ctx context.Context, expired string) error {
+	c.refreshMu.Lock()
+	defer c.refreshMu.Unlock()
+	if c.Tokens().AccessToken != expired {
+		return nil
+	}
+	return c.refresh(ctx)
+}
+
+func (c *Client) refresh(ctx context.Context) error {
+	refreshToken := c.Tokens().RefreshToken
+	if refreshToken == "" {
+		return ErrNotSignedIn
+	}
+
+	var resp tokenResponse
+	err := c.do(ctx, request{
+		method: http.MethodPost,
+		path:   "/auth/refresh",
+		body:   map[string]string{"refresh_token": refreshToken},
+	}, &resp)
+	if err != nil {
+		return fmt.Errorf("failed to refresh session, sign in again: %w", err)
+	}
+
+	tokens := resp.tokens()
+	c.setTokens(tokens)
+	if c.onRefresh != nil {
+		c.onRefresh(tokens)
+	}
+	return nil
+}
+
+// Logout revokes the client's tokens and forgets them.
+func (c *Client) Logout(ctx context.Context) error {
+	err := c.do(ctx, request{
+		method:        http.MethodPost,
+		path:          "/auth/logout",
+		authenticated: true,
+	}, nil)
+	if err != nil {
+		return err
+	}
+	c.setTokens(Tokens{})
+	return nil
+}
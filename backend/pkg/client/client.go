@@ -0,0 +1,242 @@
+// Package client is a Go client for the DeployEase REST API. It signs in
+// with an email and password, refreshes expired access tokens on its own
+// and streams deployment logs.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Tokens are the credentials returned by signing in. The access token
+// authenticates requests; the refresh token obtains a new one when it
+// expires, and is rotated every time it is used.
+type Tokens struct {
+	AccessToken  string    `json:"access_token"`
+	RefreshToken string    `json:"refresh_token"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+type Client struct {
+	baseURL    *url.URL
+	httpClient *http.Client
+
+	mu     sync.Mutex
+	tokens Tokens
+	// refreshMu serialises refreshes. The refresh token is rotated on use,
+	// so concurrent requests that find the access token expired must not
+	// each spend it.
+	refreshMu sync.Mutex
+	// onRefresh is called with the new tokens after they were refreshed, so
+	// they can be stored before the rotated refresh token is lost.
+	onRefresh func(Tokens)
+}
+
+type Option func(*Client)
+
+// WithHTTPClient sends requests with hc instead of http.DefaultClient. Its
+// timeout also bounds log streams, so it should usually have none.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) { c.httpClient = hc }
+}
+
+// WithTokens authenticates requests with previously obtained tokens.
+func WithTokens(tokens Tokens) Option {
+	return func(c *Client) { c.tokens = tokens }
+}
+
+// WithRefreshHook calls fn whenever the client has refreshed its tokens.
+func WithRefreshHook(fn func(Tokens)) Option {
+	return func(c *Client) { c.onRefresh = fn }
+}
+
+// New returns a client of the API served at baseURL, such as
+// https://deployease.example.com.
+func New(baseURL string, opts ...Option) (*Client, error) {
+	u, err := url.Parse(strings.TrimSuffix(baseURL, "/"))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid server URL %q", baseURL)
+	}
+
+	c := &Client{baseURL: u, httpClient: http.DefaultClient}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
+}
+
+// Tokens returns the client's current tokens.
+func (c *Client) Tokens() Tokens {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.tokens
+}
+
+func (c *Client) setTokens(tokens Tokens) {
+	c.mu.Lock()
+	c.tokens = tokens
+	c.mu.Unlock()
+}
+
+// APIError is an error response of the API, in the problem details format
+// Huma writes.
+type APIError struct {
+	Status int    `json:"status"`
+	Title  string `json:"title"`
+	Detail string `json:"detail"`
+	Errors []struct {
+		Message  string `json:"message"`
+		Location string `json:"location"`
+	} `json:"errors"`
+}
+
+func (e *APIError) Error() string {
+	msg := e.Detail
+	if msg == "" {
+		msg = e.Title
+	}
+	if msg == "" {
+		msg = http.StatusText(e.Status)
+	}
+	for _, detail := range e.Errors {
+		if detail.Location != "" {
+			msg += fmt.Sprintf("; %s: %s", detail.Location, detail.Message)
+		} else {
+			msg += "; " + detail.Message
+		}
+	}
+	return fmt.Sprintf("%s (%d)", msg, e.Status)
+}
+
+// IsNotFound reports whether err is an API error for something that does
+// not exist, or is not the caller's.
+func IsNotFound(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.Status == http.StatusNotFound
+}
+
+// ErrNotSignedIn is returned by requests needing authentication when the
+// client has no tokens.
+var ErrNotSignedIn = errors.New("not signed in")
+
+// request describes one API call.
+type request struct {
+	method string
+	path   string
+	query  url.Values
+	header http.Header
+	body   any
+	// authenticated requests carry the access token and are retried once
+	// with refreshed tokens if it has expired.
+	authenticated bool
+}
+
+// do sends req and decodes a successful JSON response into out, if not nil.
+func (c *Client) do(ctx context.Context, req request, out any) error {
+	resp, err := c.send(ctx, req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// send sends req and returns the response if it succeeded. Error responses
+// are turned into an *APIError.
+func (c *Client) send(ctx context.Context, req request) (*http.Response, error) {
+	var body []byte
+	if req.body != nil {
+		var err error
+		if body, err = json.Marshal(req.body); err != nil {
+			return nil, fmt.Errorf("failed to encode request: %w", err)
+		}
+	}
+
+	var token string
+	if req.authenticated {
+		token = c.Tokens().AccessToken
+	}
+	resp, err := c.roundTrip(ctx, req, body, token)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusUnauthorized && req.authenticated && c.Tokens().RefreshToken != "" {
+		resp.Body.Close()
+		if err := c.refreshExpired(ctx, token); err != nil {
+			return nil, err
+		}
+		if resp, err = c.roundTrip(ctx, req, body, c.Tokens().AccessToken); err != nil {
+			return nil, err
+		}
+	}
+
+	if resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		return nil, decodeError(resp)
+	}
+	return resp, nil
+}
+
+// roundTrip sends req once, authenticated with token if req needs it.
+func (c *Client) roundTrip(ctx context.Context, req request, body []byte, token string) (*http.Response, error) {
+	u := *c.baseURL
+	u.Path += req.path
+	u.RawQuery = req.query.Encode()
+
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, req.method, u.String(), reader)
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range req.header {
+		httpReq.Header[name] = values
+	}
+	if body != nil {
+		httpReq.Header.Set("Content-Type", "application/json")
+	}
+	if httpReq.Header.Get("Accept") == "" {
+		httpReq.Header.Set("Accept", "application/json")
+	}
+
+	if req.authenticated {
+		if token == "" {
+			return nil, ErrNotSignedIn
+		}
+		httpReq.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("request to %s failed: %w", c.baseURL.Host, err)
+	}
+	return resp, nil
+}
+
+func decodeError(resp *http.Response) error {
+	apiErr := &APIError{}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if err := json.Unmarshal(data, apiErr); err != nil || apiErr.Status == 0 {
+		apiErr = &APIError{Detail: strings.TrimSpace(string(data))}
+	}
+	apiErr.Status = resp.StatusCode
+	return apiErr
+}
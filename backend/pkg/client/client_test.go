@@ -0,0 +1,248 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestClient(t *testing.T, handler http.Handler, opts ...Option) *Client {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	c, err := New(server.URL, opts...)
+	require.NoError(t, err)
+	return c
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeProblem(w http.ResponseWriter, status int, detail string) {
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"title":  http.StatusText(status),
+		"status": status,
+		"detail": detail,
+	})
+}
+
+func TestNewRejectsInvalidURL(t *testing.T) {
+	for _, raw := range []string{"", "localhost:8080", "ftp://example.com", "http://"} {
+		_, err := New(raw)
+		assert.Error(t, err, raw)
+	}
+}
+
+func TestLogin(t *testing.T) {
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/auth/login", r.URL.Path)
+		var body map[string]string
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		if body["password"] != "supersecret" {
+			writeProblem(w, http.StatusUnauthorized, "invalid email or password")
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{
+			"access_token":  "access",
+			"refresh_token": "refresh",
+			"token_type":    "Bearer",
+			"expires_in":    3600,
+			"user":          map[string]any{"id": "u1", "username": "johndoe", "email": body["email"]},
+		})
+	}))
+
+	_, err := c.Login(context.Background(), "john@example.com", "wrong")
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusUnauthorized, apiErr.Status)
+	assert.Equal(t, "invalid email or password (401)", apiErr.Error())
+
+	user, err := c.Login(context.Background(), "john@example.com", "supersecret")
+	require.NoError(t, err)
+	assert.Equal(t, "johndoe", user.Username)
+
+	tokens := c.Tokens()
+	assert.Equal(t, "access", tokens.AccessToken)
+	assert.Equal(t, "refresh", tokens.RefreshToken)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), tokens.ExpiresAt, time.Minute)
+}
+
+func TestRefreshesExpiredAccessToken(t *testing.T) {
+	var refreshed []Tokens
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/auth/refresh":
+			var body map[string]string
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			assert.Equal(t, "old-refresh", body["refresh_token"])
+			writeJSON(w, http.StatusOK, map[string]any{
+				"access_token":  "new-access",
+				"refresh_token": "new-refresh",
+				"expires_in":    3600,
+			})
+		case "/projects/p1":
+			if r.Header.Get("Authorization") != "Bearer new-access" {
+				writeProblem(w, http.StatusUnauthorized, "token expired")
+				return
+			}
+			writeJSON(w, http.StatusOK, map[string]any{"id": "p1", "name": "web"})
+		}
+	}), WithTokens(Tokens{AccessToken: "old-access", RefreshToken: "old-refresh"}), WithRefreshHook(func(tokens Tokens) {
+		refreshed = append(refreshed, tokens)
+	}))
+
+	p, err := c.GetProject(context.Background(), "p1")
+	require.NoError(t, err)
+	assert.Equal(t, "web", p.Name)
+
+	require.Len(t, refreshed, 1)
+	assert.Equal(t, "new-refresh", refreshed[0].RefreshToken)
+	assert.Equal(t, "new-access", c.Tokens().AccessToken)
+}
+
+func TestConcurrentRequestsRefreshOnce(t *testing.T) {
+	var refreshes atomic.Int32
+	// expired holds back the 401s until every request has sent the old
+	// token, so they all find it expired at once.
+	var expired sync.WaitGroup
+	const requests = 5
+	expired.Add(requests)
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/auth/refresh":
+			var body map[string]string
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			if body["refresh_token"] != "old-refresh" {
+				writeProblem(w, http.StatusUnauthorized, "invalid refresh token")
+				return
+			}
+			refreshes.Add(1)
+			writeJSON(w, http.StatusOK, map[string]any{
+				"access_token":  "new-access",
+				"refresh_token": "new-refresh",
+				"expires_in":    3600,
+			})
+		case "/projects/p1":
+			if r.Header.Get("Authorization") != "Bearer new-access" {
+				expired.Done()
+				expired.Wait()
+				writeProblem(w, http.StatusUnauthorized, "token expired")
+				return
+			}
+			writeJSON(w, http.StatusOK, map[string]any{"id": "p1", "name": "web"})
+		}
+	}), WithTokens(Tokens{AccessToken: "old-access", RefreshToken: "old-refresh"}))
+
+	var wg sync.WaitGroup
+	errs := make([]error, requests)
+	for i := range requests {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errs[i] = c.GetProject(context.Background(), "p1")
+		}()
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, int32(1), refreshes.Load())
+	assert.Equal(t, "new-refresh", c.Tokens().RefreshToken)
+}
+
+func TestFailedRefreshAsksToSignInAgain(t *testing.T) {
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeProblem(w, http.StatusUnauthorized, "invalid refresh token")
+	}), WithTokens(Tokens{AccessToken: "old-access", RefreshToken: "old-refresh"}))
+
+	_, err := c.GetProject(context.Background(), "p1")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "sign in again")
+}
+
+func TestNotSignedIn(t *testing.T) {
+	c := newTestClient(t, http.NotFoundHandler())
+
+	_, err := c.ListProjects(context.Background(), ListOptions{})
+	assert.ErrorIs(t, err, ErrNotSignedIn)
+}
+
+func TestIsNotFound(t *testing.T) {
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeProblem(w, http.StatusNotFound, "project not found")
+	}), WithTokens(Tokens{AccessToken: "access"}))
+
+	_, err := c.GetProject(context.Background(), "missing")
+	assert.True(t, IsNotFound(err))
+	assert.EqualError(t, err, "project not found (404)")
+}
+
+func TestAPIErrorDetails(t *testing.T) {
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusUnprocessableEntity, map[string]any{
+			"title":  "Unprocessable Entity",
+			"status": 422,
+			"detail": "validation failed",
+			"errors": []map[string]string{{"message": "expected string to match pattern", "location": "body.commit_hash"}},
+		})
+	}), WithTokens(Tokens{AccessToken: "access"}))
+
+	_, err := c.CreateDeployment(context.Background(), "p1", CreateDeploymentInput{CommitHash: "nope"})
+	assert.EqualError(t, err, "validation failed; body.commit_hash: expected string to match pattern (422)")
+}
+
+func TestListDeployments(t *testing.T) {
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/projects/p1/deployments", r.URL.Path)
+		assert.Equal(t, "Bearer access", r.Header.Get("Authorization"))
+		assert.Equal(t, []string{"success", "rolled_back"}, r.URL.Query()["status"])
+		assert.Equal(t, "main", r.URL.Query().Get("branch"))
+		assert.Equal(t, "5", r.URL.Query().Get("limit"))
+		writeJSON(w, http.StatusOK, map[string]any{
+			"deployments": []map[string]any{{"id": "d1", "status": "success"}},
+			"next_cursor": "next",
+		})
+	}), WithTokens(Tokens{AccessToken: "access"}))
+
+	page, err := c.ListDeployments(context.Background(), "p1", ListDeploymentsOptions{
+		ListOptions: ListOptions{Limit: 5},
+		Branch:      "main",
+		Statuses:    []string{StatusSuccess, StatusRolledBack},
+	})
+	require.NoError(t, err)
+	require.Len(t, page.Deployments, 1)
+	assert.True(t, page.Deployments[0].Finished())
+	assert.Equal(t, "next", page.NextCursor)
+}
+
+func TestAllProjectsFollowsCursors(t *testing.T) {
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("cursor") == "" {
+			writeJSON(w, http.StatusOK, map[string]any{"projects": []map[string]any{{"id": "p2"}}, "next_cursor": "c1"})
+			return
+		}
+		assert.Equal(t, "c1", r.URL.Query().Get("cursor"))
+		writeJSON(w, http.StatusOK, map[string]any{"projects": []map[string]any{{"id": "p1"}}})
+	}), WithTokens(Tokens{AccessToken: "access"}))
+
+	projects, err := c.AllProjects(context.Background())
+	require.NoError(t, err)
+	require.Len(t, projects, 2)
+	assert.Equal(t, "p2", projects[0].ID)
+	assert.Equal(t, "p1", projects[1].ID)
+}
@@ -0,0 +1,113 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Deployment statuses.
+const (
+	StatusPending    = "pending"
+	StatusInProgress = "in_progress"
+	StatusSuccess    = "success"
+	StatusFailed     = "failed"
+	StatusCancelled  = "cancelled"
+	StatusRolledBack = "rolled_back"
+)
+
+type Deployment struct {
+	ID               string     `json:"id"`
+	ProjectID        string     `json:"project_id"`
+	Branch           string     `json:"branch"`
+	Status           string     `json:"status"`
+	CommitHash       string     `json:"commit_hash,omitempty"`
+	ImageRef         string     `json:"image_ref,omitempty"`
+	RollbackTargetID string     `json:"rollback_target_id,omitempty"`
+	DeployedAt       *time.Time `json:"deployed_at,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+}
+
+// Finished reports whether the deployment will not change status again
+// on its own.
+func (d Deployment) Finished() bool {
+	return d.Status != StatusPending && d.Status != StatusInProgress
+}
+
+type CreateDeploymentInput struct {
+	CommitHash string `json:"commit_hash"`
+	// Branch defaults to the project's branch. Any other branch is deployed
+	// to its preview environment.
+	Branch string `json:"branch,omitempty"`
+}
+
+// CreateDeployment queues a deployment of a commit.
+func (c *Client) CreateDeployment(ctx context.Context, projectID string, in CreateDeploymentInput) (Deployment, error) {
+	var d Deployment
+	err := c.do(ctx, request{
+		method:        http.MethodPost,
+		path:          "/projects/" + url.PathEscape(projectID) + "/deployments",
+		body:          in,
+		authenticated: true,
+	}, &d)
+	return d, err
+}
+
+type ListDeploymentsOptions struct {
+	ListOptions
+	// Branch only lists the deployments of one environment.
+	Branch string
+	// Statuses only lists deployments in one of these statuses.
+	Statuses []string
+}
+
+type DeploymentPage struct {
+	Deployments []Deployment `json:"deployments"`
+	// NextCursor is empty on the last page.
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// ListDeployments returns a page of the project's deployments, newest
+// first.
+func (c *Client) ListDeployments(ctx context.Context, projectID string, opts ListDeploymentsOptions) (DeploymentPage, error) {
+	q := opts.query()
+	if opts.Branch != "" {
+		q.Set("branch", opts.Branch)
+	}
+	for _, status := range opts.Statuses {
+		q.Add("status", status)
+	}
+
+	var page DeploymentPage
+	err := c.do(ctx, request{
+		method:        http.MethodGet,
+		path:          "/projects/" + url.PathEscape(projectID) + "/deployments",
+		query:         q,
+		authenticated: true,
+	}, &page)
+	return page, err
+}
+
+func (c *Client) GetDeployment(ctx context.Context, projectID, deploymentID string) (Deployment, error) {
+	var d Deployment
+	err := c.do(ctx, request{
+		method:        http.MethodGet,
+		path:          "/projects/" + url.PathEscape(projectID) + "/deployments/" + url.PathEscape(deploymentID),
+		authenticated: true,
+	}, &d)
+	return d, err
+}
+
+// Rollback creates a deployment re-running the image of an earlier
+// successful deployment.
+func (c *Client) Rollback(ctx context.Context, projectID, deploymentID string) (Deployment, error) {
+	var d Deployment
+	err := c.do(ctx, request{
+		method:        http.MethodPost,
+		path:          "/projects/" + url.PathEscape(projectID) + "/deployments/" + url.PathEscape(deploymentID) + "/rollback",
+		authenticated: true,
+	}, &d)
+	return d, err
+}
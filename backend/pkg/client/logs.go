@@ -0,0 +1,210 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// reconnectDelay is how long a broken log stream waits before resuming.
+	reconnectDelay = time.Second
+	// maxReconnects is how many times in a row a log stream is resumed
+	// without receiving anything before giving up.
+	maxReconnects = 5
+)
+
+type LogLine struct {
+	// ID resumes the stream after this line when passed as LastEventID.
+	ID        string    `json:"-"`
+	Stream    string    `json:"stream"`
+	Level     string    `json:"level"`
+	Line      string    `json:"line"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+type StreamLogsOptions struct {
+	// Follow keeps the stream open for new output until the deployment
+	// finishes or, once live, its container stops.
+	Follow bool
+	// LastEventID resumes a stream after the line with this ID.
+	LastEventID string
+}
+
+// StreamLogs calls fn with each line of the deployment's build, rollout and
+// runtime output, in order, and returns the deployment's status once the
+// stream ends. A stream broken off by the network is resumed where it left
+// off. An error returned by fn stops the stream and is returned.
+func (c *Client) StreamLogs(ctx context.Context, projectID, deploymentID string, opts StreamLogsOptions, fn func(LogLine) error) (string, error) {
+	lastID := opts.LastEventID
+	failures := 0
+	for {
+		status, progressed, err := c.streamLogs(ctx, projectID, deploymentID, opts.Follow, lastID, func(line LogLine) error {
+			lastID = line.ID
+			return fn(line)
+		})
+		if err == nil {
+			return status, nil
+		}
+
+		var streamErr *streamBrokenError
+		if !errors.As(err, &streamErr) || ctx.Err() != nil {
+			return "", err
+		}
+		if progressed {
+			failures = 0
+		}
+		if failures++; failures > maxReconnects {
+			return "", err
+		}
+
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(reconnectDelay):
+		}
+	}
+}
+
+// streamBrokenError is a log stream ending before the server said it was
+// done, which is worth resuming.
+type streamBrokenError struct {
+	err error
+}
+
+func (e *streamBrokenError) Error() string { return "log stream broken: " + e.err.Error() }
+func (e *streamBrokenError) Unwrap() error { return e.err }
+
+// streamLogs reads one connection's worth of the stream. It reports
+// whether any event was received, so repeated failures can be told apart
+// from a long stream that broke once.
+func (c *Client) streamLogs(ctx context.Context, projectID, deploymentID string, follow bool, lastID string, fn func(LogLine) error) (status string, progressed bool, err error) {
+	q := url.Values{}
+	if follow {
+		q.Set("follow", "true")
+	}
+	header := http.Header{"Accept": {"text/event-stream"}}
+	if lastID != "" {
+		header.Set("Last-Event-ID", lastID)
+	}
+
+	resp, err := c.send(ctx, request{
+		method:        http.MethodGet,
+		path:          "/projects/" + url.PathEscape(projectID) + "/deployments/" + url.PathEscape(deploymentID) + "/logs",
+		query:         q,
+		header:        header,
+		authenticated: true,
+	})
+	if err != nil {
+		var apiErr *APIError
+		if errors.As(err, &apiErr) || errors.Is(err, ErrNotSignedIn) || ctx.Err() != nil {
+			return "", false, err
+		}
+		return "", false, &streamBrokenError{err: err}
+	}
+	defer resp.Body.Close()
+
+	events := newEventReader(resp.Body)
+	for {
+		e, err := events.next()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				err = io.ErrUnexpectedEOF
+			}
+			return "", progressed, &streamBrokenError{err: err}
+		}
+		progressed = true
+
+		switch e.name {
+		case "log":
+			line := LogLine{ID: e.id}
+			if err := json.Unmarshal([]byte(e.data), &line); err != nil {
+				return "", progressed, fmt.Errorf("invalid log event: %w", err)
+			}
+			if err := fn(line); err != nil {
+				return "", progressed, err
+			}
+		case "end":
+			var end struct {
+				Status string `json:"status"`
+			}
+			if err := json.Unmarshal([]byte(e.data), &end); err != nil {
+				return "", progressed, fmt.Errorf("invalid end event: %w", err)
+			}
+			return end.Status, progressed, nil
+		case "error":
+			var body struct {
+				Error string `json:"error"`
+			}
+			_ = json.Unmarshal([]byte(e.data), &body)
+			return "", progressed, fmt.Errorf("server failed to stream logs: %s", body.Error)
+		}
+	}
+}
+
+type event struct {
+	id   string
+	name string
+	data string
+}
+
+// eventReader parses a Server-Sent Events stream.
+type eventReader struct {
+	r *bufio.Reader
+}
+
+func newEventReader(r io.Reader) *eventReader {
+	return &eventReader{r: bufio.NewReader(r)}
+}
+
+// next returns the next event, skipping comments. Events without a name
+// are called message, as in browsers.
+func (er *eventReader) next() (event, error) {
+	var (
+		e    event
+		data []string
+		seen bool
+	)
+	for {
+		line, err := er.r.ReadString('\n')
+		if err != nil {
+			return event{}, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+
+		if line == "" {
+			if !seen {
+				continue
+			}
+			if e.name == "" {
+				e.name = "message"
+			}
+			e.data = strings.Join(data, "\n")
+			return e, nil
+		}
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "id":
+			e.id = value
+		case "event":
+			e.name = value
+		case "data":
+			data = append(data, value)
+		default:
+			continue
+		}
+		seen = true
+	}
+}
@@ -0,0 +1,120 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeEvents(w http.ResponseWriter, frames ...string) {
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.WriteHeader(http.StatusOK)
+	for _, frame := range frames {
+		fmt.Fprint(w, frame)
+	}
+}
+
+func logFrame(id, line string) string {
+	return fmt.Sprintf("id: %s\nevent: log\ndata: {\"stream\":\"build\",\"level\":\"info\",\"line\":%q,\"timestamp\":\"2025-07-01T10:00:00Z\"}\n\n", id, line)
+}
+
+func collect(lines *[]string) func(LogLine) error {
+	return func(l LogLine) error {
+		*lines = append(*lines, l.Line)
+		return nil
+	}
+}
+
+func TestStreamLogs(t *testing.T) {
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/projects/p1/deployments/d1/logs", r.URL.Path)
+		assert.Equal(t, "true", r.URL.Query().Get("follow"))
+		assert.Equal(t, "text/event-stream", r.Header.Get("Accept"))
+		writeEvents(w,
+			": connected\n\n",
+			logFrame("1-1", "Step 1/2"),
+			": keep-alive\n\n",
+			logFrame("1-2", "Step 2/2"),
+			"event: end\ndata: {\"status\":\"success\"}\n\n",
+		)
+	}), WithTokens(Tokens{AccessToken: "access"}))
+
+	var lines []string
+	status, err := c.StreamLogs(context.Background(), "p1", "d1", StreamLogsOptions{Follow: true}, collect(&lines))
+	require.NoError(t, err)
+	assert.Equal(t, StatusSuccess, status)
+	assert.Equal(t, []string{"Step 1/2", "Step 2/2"}, lines)
+}
+
+func TestStreamLogsResumesBrokenStream(t *testing.T) {
+	var calls atomic.Int32
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			assert.Empty(t, r.Header.Get("Last-Event-ID"))
+			// The connection drops without an end event.
+			writeEvents(w, logFrame("1-1", "first"))
+			return
+		}
+		assert.Equal(t, "1-1", r.Header.Get("Last-Event-ID"))
+		writeEvents(w, logFrame("1-2", "second"), "event: end\ndata: {\"status\":\"failed\"}\n\n")
+	}), WithTokens(Tokens{AccessToken: "access"}))
+
+	var lines []string
+	status, err := c.StreamLogs(context.Background(), "p1", "d1", StreamLogsOptions{}, collect(&lines))
+	require.NoError(t, err)
+	assert.Equal(t, StatusFailed, status)
+	assert.Equal(t, []string{"first", "second"}, lines)
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestStreamLogsErrorEvent(t *testing.T) {
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeEvents(w, "event: error\ndata: {\"error\":\"failed to read deployment logs\"}\n\n")
+	}), WithTokens(Tokens{AccessToken: "access"}))
+
+	_, err := c.StreamLogs(context.Background(), "p1", "d1", StreamLogsOptions{}, func(LogLine) error { return nil })
+	assert.EqualError(t, err, "server failed to stream logs: failed to read deployment logs")
+}
+
+func TestStreamLogsDoesNotRetryAPIErrors(t *testing.T) {
+	var calls atomic.Int32
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		writeProblem(w, http.StatusNotFound, "deployment not found")
+	}), WithTokens(Tokens{AccessToken: "access"}))
+
+	_, err := c.StreamLogs(context.Background(), "p1", "d1", StreamLogsOptions{}, func(LogLine) error { return nil })
+	assert.True(t, IsNotFound(err))
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestStreamLogsStopsOnCallbackError(t *testing.T) {
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeEvents(w, logFrame("1-1", "first"), logFrame("1-2", "second"))
+	}), WithTokens(Tokens{AccessToken: "access"}))
+
+	stop := fmt.Errorf("stop")
+	_, err := c.StreamLogs(context.Background(), "p1", "d1", StreamLogsOptions{}, func(LogLine) error { return stop })
+	assert.ErrorIs(t, err, stop)
+}
+
+func TestEventReader(t *testing.T) {
+	events := newEventReader(strings.NewReader("data: one\ndata: two\r\n\r\n: comment\n\nid: 7\nevent: custom\ndata:{}\n\n"))
+
+	e, err := events.next()
+	require.NoError(t, err)
+	assert.Equal(t, event{name: "message", data: "one\ntwo"}, e)
+
+	e, err = events.next()
+	require.NoError(t, err)
+	assert.Equal(t, event{id: "7", name: "custom", data: "{}"}, e)
+
+	_, err = events.next()
+	assert.Error(t, err)
+}
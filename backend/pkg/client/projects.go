@@ -0,0 +1,86 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+type Project struct {
+	ID                 string    `json:"id"`
+	Name               string    `json:"name"`
+	Description        string    `json:"description,omitempty"`
+	RepositoryURL      string    `json:"repository_url"`
+	Branch             string    `json:"branch"`
+	Previews           bool      `json:"previews"`
+	ActiveDeploymentID string    `json:"active_deployment_id,omitempty"`
+	CreatedAt          time.Time `json:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at"`
+}
+
+// ListOptions pages through a list. The zero value asks for the first page
+// at the server's default size.
+type ListOptions struct {
+	Cursor string
+	Limit  int
+}
+
+func (o ListOptions) query() url.Values {
+	q := url.Values{}
+	if o.Cursor != "" {
+		q.Set("cursor", o.Cursor)
+	}
+	if o.Limit > 0 {
+		q.Set("limit", strconv.Itoa(o.Limit))
+	}
+	return q
+}
+
+type ProjectPage struct {
+	Projects []Project `json:"projects"`
+	// NextCursor is empty on the last page.
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// ListProjects returns a page of the caller's projects, newest first.
+func (c *Client) ListProjects(ctx context.Context, opts ListOptions) (ProjectPage, error) {
+	var page ProjectPage
+	err := c.do(ctx, request{
+		method:        http.MethodGet,
+		path:          "/projects",
+		query:         opts.query(),
+		authenticated: true,
+	}, &page)
+	return page, err
+}
+
+// AllProjects returns every one of the caller's projects, newest first.
+func (c *Client) AllProjects(ctx context.Context) ([]Project, error) {
+	var (
+		projects []Project
+		opts     = ListOptions{Limit: 100}
+	)
+	for {
+		page, err := c.ListProjects(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		projects = append(projects, page.Projects...)
+		if page.NextCursor == "" {
+			return projects, nil
+		}
+		opts.Cursor = page.NextCursor
+	}
+}
+
+func (c *Client) GetProject(ctx context.Context, projectID string) (Project, error) {
+	var p Project
+	err := c.do(ctx, request{
+		method:        http.MethodGet,
+		path:          "/projects/" + url.PathEscape(projectID),
+		authenticated: true,
+	}, &p)
+	return p, err
+}
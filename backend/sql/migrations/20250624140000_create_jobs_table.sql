@@ -0,0 +1,31 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TYPE job_status AS ENUM ('pending', 'running', 'completed', 'dead');
+
+CREATE TABLE jobs (
+    id VARCHAR(32) PRIMARY KEY,
+    queue VARCHAR(64) NOT NULL DEFAULT 'default',
+    kind VARCHAR(128) NOT NULL,
+    payload JSONB NOT NULL DEFAULT '{}',
+    status job_status NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    max_attempts INTEGER NOT NULL DEFAULT 5,
+    run_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    locked_by VARCHAR(64),
+    locked_until TIMESTAMPTZ,
+    last_error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_jobs_claimable ON jobs (queue, run_at) WHERE status IN ('pending', 'running');
+
+CREATE INDEX idx_jobs_dead ON jobs (queue, updated_at) WHERE status = 'dead';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS jobs;
+
+DROP TYPE IF EXISTS job_status;
+-- +goose StatementEnd
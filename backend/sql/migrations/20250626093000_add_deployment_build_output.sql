@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE deployments ADD COLUMN image_ref VARCHAR(512);
+
+CREATE TABLE deployment_logs (
+    id BIGSERIAL PRIMARY KEY,
+    deployment_id VARCHAR(32) NOT NULL REFERENCES deployments (id) ON DELETE CASCADE,
+    stream VARCHAR(16) NOT NULL DEFAULT 'build',
+    line TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_deployment_logs_deployment_id ON deployment_logs (deployment_id, id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS deployment_logs;
+
+ALTER TABLE deployments DROP COLUMN IF EXISTS image_ref;
+-- +goose StatementEnd
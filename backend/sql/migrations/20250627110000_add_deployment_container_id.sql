@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE deployments ADD COLUMN container_id VARCHAR(128);
+
+CREATE INDEX idx_deployments_project_id_container ON deployments (project_id) WHERE container_id IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_deployments_project_id_container;
+
+ALTER TABLE deployments DROP COLUMN IF EXISTS container_id;
+-- +goose StatementEnd
@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TYPE deployment_status ADD VALUE IF NOT EXISTS 'rolled_back';
+
+ALTER TABLE projects
+    ADD COLUMN active_deployment_id VARCHAR(32) REFERENCES deployments (id) ON DELETE SET NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE projects DROP COLUMN IF EXISTS active_deployment_id;
+
+-- Enum values cannot be dropped, so rebuild the type without rolled_back.
+UPDATE deployments SET status = 'failed' WHERE status = 'rolled_back';
+
+ALTER TYPE deployment_status RENAME TO deployment_status_old;
+
+CREATE TYPE deployment_status AS ENUM ('pending', 'in_progress', 'success', 'failed', 'cancelled');
+
+ALTER TABLE deployments ALTER COLUMN status DROP DEFAULT;
+
+ALTER TABLE deployments
+    ALTER COLUMN status TYPE deployment_status USING status::text::deployment_status;
+
+ALTER TABLE deployments ALTER COLUMN status SET DEFAULT 'pending';
+
+DROP TYPE deployment_status_old;
+-- +goose StatementEnd
@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE deployments
+    ADD COLUMN rollback_target_id VARCHAR(32) REFERENCES deployments (id) ON DELETE SET NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE deployments DROP COLUMN IF EXISTS rollback_target_id;
+-- +goose StatementEnd
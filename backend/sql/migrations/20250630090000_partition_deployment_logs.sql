@@ -0,0 +1,52 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Logs are partitioned by day so retention can drop a whole day at once.
+-- Daily partitions are created ahead of time by the log maintainer; the
+-- default partition catches anything written before they exist.
+CREATE TABLE deployment_logs_partitioned (
+    id BIGINT NOT NULL DEFAULT nextval('deployment_logs_id_seq'),
+    deployment_id VARCHAR(32) NOT NULL REFERENCES deployments (id) ON DELETE CASCADE,
+    stream VARCHAR(16) NOT NULL DEFAULT 'build',
+    level VARCHAR(8) NOT NULL DEFAULT 'info' CHECK (level IN ('debug', 'info', 'warn', 'error')),
+    line TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (id, created_at)
+) PARTITION BY RANGE (created_at);
+
+CREATE TABLE deployment_logs_default PARTITION OF deployment_logs_partitioned DEFAULT;
+
+INSERT INTO deployment_logs_partitioned (id, deployment_id, stream, line, created_at)
+SELECT id, deployment_id, stream, line, created_at FROM deployment_logs;
+
+ALTER SEQUENCE deployment_logs_id_seq OWNED BY deployment_logs_partitioned.id;
+
+DROP TABLE deployment_logs;
+
+ALTER TABLE deployment_logs_partitioned RENAME TO deployment_logs;
+
+CREATE INDEX idx_deployment_logs_deployment_id ON deployment_logs (deployment_id, id);
+
+CREATE INDEX idx_deployment_logs_deployment_created_at ON deployment_logs (deployment_id, created_at DESC, id DESC);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+CREATE TABLE deployment_logs_unpartitioned (
+    id BIGINT PRIMARY KEY DEFAULT nextval('deployment_logs_id_seq'),
+    deployment_id VARCHAR(32) NOT NULL REFERENCES deployments (id) ON DELETE CASCADE,
+    stream VARCHAR(16) NOT NULL DEFAULT 'build',
+    line TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO deployment_logs_unpartitioned (id, deployment_id, stream, line, created_at)
+SELECT id, deployment_id, stream, line, created_at FROM deployment_logs;
+
+ALTER SEQUENCE deployment_logs_id_seq OWNED BY deployment_logs_unpartitioned.id;
+
+DROP TABLE deployment_logs;
+
+ALTER TABLE deployment_logs_unpartitioned RENAME TO deployment_logs;
+
+CREATE INDEX idx_deployment_logs_deployment_id ON deployment_logs (deployment_id, id);
+-- +goose StatementEnd
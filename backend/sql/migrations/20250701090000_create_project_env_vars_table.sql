@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Secret values are only stored encrypted, plain values only in the clear.
+CREATE TABLE project_env_vars (
+    project_id VARCHAR(32) NOT NULL REFERENCES projects (id) ON DELETE CASCADE,
+    key VARCHAR(256) NOT NULL,
+    value TEXT,
+    encrypted_value BYTEA,
+    secret BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (project_id, key),
+    CHECK (
+        (secret AND value IS NULL AND encrypted_value IS NOT NULL)
+        OR (NOT secret AND value IS NOT NULL AND encrypted_value IS NULL)
+    )
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS project_env_vars;
+-- +goose StatementEnd
@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Pushes to this branch are deployed automatically by the git webhooks.
+ALTER TABLE projects ADD COLUMN branch VARCHAR(255) NOT NULL DEFAULT 'main';
+
+-- Webhooks look projects up by repository, ignoring case as git hosts do.
+CREATE INDEX idx_projects_repository_url ON projects (lower(repository_url));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_projects_repository_url;
+
+ALTER TABLE projects DROP COLUMN IF EXISTS branch;
+-- +goose StatementEnd
@@ -0,0 +1,55 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Pushes to other branches than the project's are deployed to preview
+-- environments when the project opts in.
+ALTER TABLE projects ADD COLUMN previews_enabled BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Every deployment belongs to the environment of the branch it was made
+-- from. Deployments made so far were of their project's branch.
+ALTER TABLE deployments ADD COLUMN branch VARCHAR(255);
+
+UPDATE deployments d SET branch = p.branch FROM projects p WHERE p.id = d.project_id;
+
+ALTER TABLE deployments ALTER COLUMN branch SET NOT NULL;
+
+CREATE INDEX idx_deployments_project_branch_created_at ON deployments (project_id, branch, created_at DESC, id DESC);
+
+CREATE TABLE preview_environments (
+    id VARCHAR(32) PRIMARY KEY,
+    project_id VARCHAR(32) NOT NULL REFERENCES projects (id) ON DELETE CASCADE,
+    branch VARCHAR(255) NOT NULL,
+    subdomain VARCHAR(63) NOT NULL UNIQUE,
+    pull_request INTEGER,
+    active_deployment_id VARCHAR(32) REFERENCES deployments (id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (project_id, branch)
+);
+
+-- Variables set for a branch override the project's own, which have an
+-- empty branch, in deployments of that branch.
+ALTER TABLE project_env_vars ADD COLUMN branch VARCHAR(255) NOT NULL DEFAULT '';
+
+ALTER TABLE project_env_vars DROP CONSTRAINT project_env_vars_pkey;
+
+ALTER TABLE project_env_vars ADD PRIMARY KEY (project_id, branch, key);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM project_env_vars WHERE branch <> '';
+
+ALTER TABLE project_env_vars DROP CONSTRAINT project_env_vars_pkey;
+
+ALTER TABLE project_env_vars ADD PRIMARY KEY (project_id, key);
+
+ALTER TABLE project_env_vars DROP COLUMN IF EXISTS branch;
+
+DROP TABLE IF EXISTS preview_environments;
+
+DROP INDEX IF EXISTS idx_deployments_project_branch_created_at;
+
+ALTER TABLE deployments DROP COLUMN IF EXISTS branch;
+
+ALTER TABLE projects DROP COLUMN IF EXISTS previews_enabled;
+-- +goose StatementEnd
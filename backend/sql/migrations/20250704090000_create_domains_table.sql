@@ -0,0 +1,33 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TYPE domain_status AS ENUM ('pending', 'verified', 'failed');
+
+-- A domain is attached to one project at a time. It serves the project once
+-- the project has proven control of it with a DNS TXT record, and is checked
+-- again periodically to make sure it still does.
+CREATE TABLE domains (
+    id VARCHAR(32) PRIMARY KEY,
+    project_id VARCHAR(32) NOT NULL REFERENCES projects (id) ON DELETE CASCADE,
+    name VARCHAR(253) NOT NULL UNIQUE,
+    status domain_status NOT NULL DEFAULT 'pending',
+    verification_token VARCHAR(64) NOT NULL,
+    verification_started_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    verified_at TIMESTAMPTZ,
+    last_checked_at TIMESTAMPTZ,
+    last_error TEXT,
+    next_check_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_domains_project_created_at ON domains (project_id, created_at, id);
+
+CREATE INDEX idx_domains_next_check_at ON domains (next_check_at) WHERE next_check_at IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS domains;
+
+DROP TYPE IF EXISTS domain_status;
+-- +goose StatementEnd
@@ -0,0 +1,51 @@
+-- +goose Up
+-- +goose StatementBegin
+-- One ACME account is registered per certificate authority directory. Its
+-- private key is encrypted with the secrets master key.
+CREATE TABLE acme_accounts (
+    directory_url TEXT PRIMARY KEY,
+    uri TEXT NOT NULL,
+    email VARCHAR(254) NOT NULL DEFAULT '',
+    encrypted_key BYTEA NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Key authorizations of the HTTP-01 challenges being validated, served under
+-- /.well-known/acme-challenge/ by whichever instance the CA reaches.
+CREATE TABLE acme_challenges (
+    token VARCHAR(255) PRIMARY KEY,
+    key_authorization TEXT NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE TYPE certificate_status AS ENUM ('pending', 'issued', 'failed');
+
+-- The TLS certificate of a verified domain. A failed renewal leaves the
+-- issued certificate in place until it expires.
+CREATE TABLE certificates (
+    domain_id VARCHAR(32) PRIMARY KEY REFERENCES domains (id) ON DELETE CASCADE,
+    status certificate_status NOT NULL DEFAULT 'pending',
+    certificate_pem TEXT,
+    encrypted_key BYTEA,
+    not_before TIMESTAMPTZ,
+    not_after TIMESTAMPTZ,
+    issued_at TIMESTAMPTZ,
+    last_error TEXT,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_certificates_next_attempt_at ON certificates (next_attempt_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS certificates;
+
+DROP TYPE IF EXISTS certificate_status;
+
+DROP TABLE IF EXISTS acme_challenges;
+
+DROP TABLE IF EXISTS acme_accounts;
+-- +goose StatementEnd
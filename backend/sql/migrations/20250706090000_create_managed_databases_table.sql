@@ -0,0 +1,37 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TYPE managed_database_type AS ENUM ('postgresql', 'redis');
+
+CREATE TYPE managed_database_status AS ENUM ('provisioning', 'running', 'failed', 'deleting');
+
+-- A database server run for a project in its own container. A project has
+-- at most one of each type, whose connection string its deployments receive
+-- as DATABASE_URL or REDIS_URL. The connection string holds the generated
+-- credentials, so it is encrypted at rest.
+CREATE TABLE managed_databases (
+    id VARCHAR(32) PRIMARY KEY,
+    project_id VARCHAR(32) NOT NULL REFERENCES projects (id) ON DELETE CASCADE,
+    name VARCHAR(63) NOT NULL,
+    type managed_database_type NOT NULL,
+    version VARCHAR(32) NOT NULL,
+    size VARCHAR(16) NOT NULL,
+    status managed_database_status NOT NULL DEFAULT 'provisioning',
+    container_id VARCHAR(128),
+    encrypted_connection_string BYTEA,
+    last_error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (project_id, type)
+);
+
+CREATE INDEX idx_managed_databases_project_created_at ON managed_databases (project_id, created_at, id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS managed_databases;
+
+DROP TYPE IF EXISTS managed_database_status;
+
+DROP TYPE IF EXISTS managed_database_type;
+-- +goose StatementEnd
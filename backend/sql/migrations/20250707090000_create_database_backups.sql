@@ -0,0 +1,74 @@
+-- +goose Up
+-- +goose StatementBegin
+-- A database with a backup schedule is backed up whenever its cron
+-- expression comes due; the newest backup_retention successful backups are
+-- kept.
+ALTER TABLE managed_databases
+    ADD COLUMN backup_schedule VARCHAR(128),
+    ADD COLUMN backup_retention INTEGER NOT NULL DEFAULT 7,
+    ADD COLUMN next_backup_at TIMESTAMPTZ;
+
+CREATE INDEX idx_managed_databases_next_backup_at ON managed_databases (next_backup_at)
+    WHERE backup_schedule IS NOT NULL;
+
+CREATE TYPE database_backup_status AS ENUM ('pending', 'running', 'succeeded', 'failed');
+
+-- A logical backup of a managed database. The dump itself lives in the
+-- object store under object_key.
+CREATE TABLE database_backups (
+    id VARCHAR(32) PRIMARY KEY,
+    database_id VARCHAR(32) NOT NULL REFERENCES managed_databases (id) ON DELETE CASCADE,
+    status database_backup_status NOT NULL DEFAULT 'pending',
+    object_key TEXT NOT NULL,
+    size_bytes BIGINT NOT NULL DEFAULT 0,
+    last_error TEXT,
+    started_at TIMESTAMPTZ,
+    completed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_database_backups_database_created_at ON database_backups (database_id, created_at, id);
+
+CREATE TYPE database_restore_status AS ENUM ('pending', 'running', 'succeeded', 'failed');
+
+-- A restore of a backup into its database. Progress is the number of bytes
+-- of the dump read so far.
+CREATE TABLE database_restores (
+    id VARCHAR(32) PRIMARY KEY,
+    database_id VARCHAR(32) NOT NULL REFERENCES managed_databases (id) ON DELETE CASCADE,
+    backup_id VARCHAR(32) REFERENCES database_backups (id) ON DELETE SET NULL,
+    status database_restore_status NOT NULL DEFAULT 'pending',
+    bytes_total BIGINT NOT NULL DEFAULT 0,
+    bytes_restored BIGINT NOT NULL DEFAULT 0,
+    last_error TEXT,
+    started_at TIMESTAMPTZ,
+    completed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_database_restores_database_created_at ON database_restores (database_id, created_at, id);
+
+-- One restore at a time per database.
+CREATE UNIQUE INDEX idx_database_restores_active ON database_restores (database_id)
+    WHERE status IN ('pending', 'running');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS database_restores;
+
+DROP TYPE IF EXISTS database_restore_status;
+
+DROP TABLE IF EXISTS database_backups;
+
+DROP TYPE IF EXISTS database_backup_status;
+
+DROP INDEX IF EXISTS idx_managed_databases_next_backup_at;
+
+ALTER TABLE managed_databases
+    DROP COLUMN IF EXISTS next_backup_at,
+    DROP COLUMN IF EXISTS backup_retention,
+    DROP COLUMN IF EXISTS backup_schedule;
+-- +goose StatementEnd
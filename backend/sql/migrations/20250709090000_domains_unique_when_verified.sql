@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Any project may claim a name, but only one can have it verified. A global
+-- UNIQUE let whoever claimed a name first hold it without ever proving
+-- control of it.
+ALTER TABLE domains DROP CONSTRAINT domains_name_key;
+
+CREATE UNIQUE INDEX idx_domains_project_name ON domains (project_id, name);
+
+CREATE UNIQUE INDEX idx_domains_name_verified ON domains (name) WHERE status = 'verified';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_domains_name_verified;
+
+DROP INDEX IF EXISTS idx_domains_project_name;
+
+ALTER TABLE domains ADD CONSTRAINT domains_name_key UNIQUE (name);
+-- +goose StatementEnd
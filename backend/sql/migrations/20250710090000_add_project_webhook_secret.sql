@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Each project verifies webhook deliveries with its own secret, so a tenant
+-- cannot sign deliveries for another tenant's repository. Projects created
+-- before this have none and ignore webhooks until one is generated.
+ALTER TABLE projects ADD COLUMN encrypted_webhook_secret BYTEA;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE projects DROP COLUMN IF EXISTS encrypted_webhook_secret;
+-- +goose StatementEnd
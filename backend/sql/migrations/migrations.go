@@ -0,0 +1,8 @@
+// Package migrations embeds the goose migrations in this directory, so the
+// server binary can apply its schema without the source tree.
+package migrations
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS
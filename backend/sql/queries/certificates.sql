@@ -0,0 +1,75 @@
+-- name: GetACMEAccount :one
+SELECT * FROM acme_accounts
+WHERE directory_url = $1;
+
+-- name: CreateACMEAccount :one
+INSERT INTO acme_accounts (directory_url, uri, email, encrypted_key)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (directory_url) DO NOTHING
+RETURNING *;
+
+-- name: UpsertACMEChallenge :exec
+INSERT INTO acme_challenges (token, key_authorization, expires_at)
+VALUES ($1, $2, $3)
+ON CONFLICT (token) DO UPDATE
+SET key_authorization = EXCLUDED.key_authorization,
+    expires_at = EXCLUDED.expires_at;
+
+-- name: GetACMEChallenge :one
+SELECT key_authorization FROM acme_challenges
+WHERE token = $1 AND expires_at > NOW();
+
+-- name: DeleteACMEChallenge :exec
+DELETE FROM acme_challenges
+WHERE token = $1;
+
+-- name: CreateMissingCertificates :execrows
+INSERT INTO certificates (domain_id, next_attempt_at)
+SELECT id, sqlc.arg('next_attempt_at') FROM domains
+WHERE status = 'verified'
+ON CONFLICT (domain_id) DO NOTHING;
+
+-- name: ClaimDueCertificates :many
+UPDATE certificates
+SET next_attempt_at = sqlc.arg('lease_until')
+WHERE domain_id IN (
+    SELECT c.domain_id FROM certificates c
+    JOIN domains d ON d.id = c.domain_id
+    WHERE d.status = 'verified' AND c.next_attempt_at <= sqlc.arg('now')
+    ORDER BY c.next_attempt_at
+    LIMIT sqlc.arg('batch_size')
+    FOR UPDATE OF c SKIP LOCKED
+)
+RETURNING domain_id;
+
+-- name: GetCertificate :one
+SELECT * FROM certificates
+WHERE domain_id = $1;
+
+-- name: GetIssuedCertificateByName :one
+SELECT c.* FROM certificates c
+JOIN domains d ON d.id = c.domain_id
+WHERE d.name = $1 AND d.status = 'verified' AND c.certificate_pem IS NOT NULL;
+
+-- name: UpdateCertificateIssued :one
+UPDATE certificates
+SET status = 'issued',
+    certificate_pem = $1,
+    encrypted_key = $2,
+    not_before = $3,
+    not_after = $4,
+    issued_at = $5,
+    last_error = NULL,
+    next_attempt_at = $6,
+    updated_at = NOW()
+WHERE domain_id = $7
+RETURNING *;
+
+-- name: UpdateCertificateFailure :one
+UPDATE certificates
+SET status = $1,
+    last_error = $2,
+    next_attempt_at = $3,
+    updated_at = NOW()
+WHERE domain_id = $4
+RETURNING *;
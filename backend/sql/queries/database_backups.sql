@@ -0,0 +1,71 @@
+-- name: CreateDatabaseBackup :one
+INSERT INTO database_backups (id, database_id, object_key)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+-- name: GetDatabaseBackup :one
+SELECT * FROM database_backups
+WHERE id = $1;
+
+-- name: GetDatabaseBackupForDatabase :one
+SELECT * FROM database_backups
+WHERE id = $1 AND database_id = $2;
+
+-- name: GetLatestDatabaseBackupBefore :one
+SELECT * FROM database_backups
+WHERE database_id = $1 AND status = 'succeeded' AND completed_at <= $2
+ORDER BY completed_at DESC, id DESC
+LIMIT 1;
+
+-- name: ListDatabaseBackups :many
+SELECT * FROM database_backups
+WHERE database_id = $1
+ORDER BY created_at DESC, id DESC;
+
+-- name: ListExpiredDatabaseBackups :many
+SELECT * FROM database_backups b
+WHERE b.database_id = sqlc.arg('database_id')
+  AND b.status IN ('succeeded', 'failed')
+  AND (b.created_at, b.id) < (
+      SELECT k.created_at, k.id FROM database_backups k
+      WHERE k.database_id = sqlc.arg('database_id') AND k.status = 'succeeded'
+      ORDER BY k.created_at DESC, k.id DESC
+      OFFSET sqlc.arg('keep')::integer - 1
+      LIMIT 1
+  )
+  AND NOT EXISTS (
+      SELECT 1 FROM database_restores r
+      WHERE r.backup_id = b.id AND r.status IN ('pending', 'running')
+  )
+ORDER BY b.created_at, b.id;
+
+-- name: UpdateDatabaseBackupRunning :one
+UPDATE database_backups
+SET status = 'running',
+    started_at = $1,
+    updated_at = NOW()
+WHERE id = $2 AND status IN ('pending', 'running')
+RETURNING *;
+
+-- name: UpdateDatabaseBackupSucceeded :one
+UPDATE database_backups
+SET status = 'succeeded',
+    size_bytes = $1,
+    completed_at = $2,
+    last_error = NULL,
+    updated_at = NOW()
+WHERE id = $3
+RETURNING *;
+
+-- name: UpdateDatabaseBackupFailed :one
+UPDATE database_backups
+SET status = 'failed',
+    last_error = $1,
+    completed_at = $2,
+    updated_at = NOW()
+WHERE id = $3
+RETURNING *;
+
+-- name: DeleteDatabaseBackup :exec
+DELETE FROM database_backups
+WHERE id = $1;
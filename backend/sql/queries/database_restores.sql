@@ -0,0 +1,51 @@
+-- name: CreateDatabaseRestore :one
+INSERT INTO database_restores (id, database_id, backup_id, bytes_total)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: GetDatabaseRestore :one
+SELECT * FROM database_restores
+WHERE id = $1;
+
+-- name: GetDatabaseRestoreForDatabase :one
+SELECT * FROM database_restores
+WHERE id = $1 AND database_id = $2;
+
+-- name: ListDatabaseRestores :many
+SELECT * FROM database_restores
+WHERE database_id = $1
+ORDER BY created_at DESC, id DESC;
+
+-- name: UpdateDatabaseRestoreRunning :one
+UPDATE database_restores
+SET status = 'running',
+    started_at = $1,
+    bytes_restored = 0,
+    updated_at = NOW()
+WHERE id = $2 AND status IN ('pending', 'running')
+RETURNING *;
+
+-- name: UpdateDatabaseRestoreProgress :exec
+UPDATE database_restores
+SET bytes_restored = $1,
+    updated_at = NOW()
+WHERE id = $2 AND status = 'running';
+
+-- name: UpdateDatabaseRestoreSucceeded :one
+UPDATE database_restores
+SET status = 'succeeded',
+    bytes_restored = bytes_total,
+    completed_at = $1,
+    last_error = NULL,
+    updated_at = NOW()
+WHERE id = $2
+RETURNING *;
+
+-- name: UpdateDatabaseRestoreFailed :one
+UPDATE database_restores
+SET status = 'failed',
+    last_error = $1,
+    completed_at = $2,
+    updated_at = NOW()
+WHERE id = $3
+RETURNING *;
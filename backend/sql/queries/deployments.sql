@@ -0,0 +1,120 @@
+-- name: GetDeployment :one
+SELECT * FROM deployments
+WHERE id = $1;
+
+-- name: GetDeploymentForUpdate :one
+SELECT * FROM deployments
+WHERE id = $1
+FOR UPDATE;
+
+-- name: UpdateDeploymentStatus :one
+UPDATE deployments
+SET status = @status,
+    deployed_at = CASE WHEN @status::deployment_status = 'success' THEN NOW() ELSE deployed_at END,
+    updated_at = NOW()
+WHERE id = @id
+RETURNING *;
+
+-- name: CreateDeployment :one
+INSERT INTO deployments (id, project_id, commit_hash, branch)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: GetDeploymentForProject :one
+SELECT * FROM deployments
+WHERE id = $1 AND project_id = $2;
+
+-- name: ListDeploymentsForProject :many
+SELECT * FROM deployments
+WHERE project_id = @project_id
+  AND (sqlc.narg('branch')::varchar IS NULL OR branch = sqlc.narg('branch')::varchar)
+  AND (cardinality(@statuses::text[]) = 0 OR status::text = ANY(@statuses::text[]))
+  AND (
+    sqlc.narg('cursor_created_at')::timestamptz IS NULL
+    OR (created_at, id) < (sqlc.narg('cursor_created_at')::timestamptz, sqlc.narg('cursor_id')::varchar)
+  )
+ORDER BY created_at DESC, id DESC
+LIMIT @page_limit;
+
+-- name: SetDeploymentImage :one
+UPDATE deployments
+SET image_ref = $1,
+    updated_at = NOW()
+WHERE id = $2
+RETURNING *;
+
+-- name: AppendDeploymentLogs :exec
+INSERT INTO deployment_logs (deployment_id, stream, level, line, created_at)
+SELECT @deployment_id::varchar, @stream::varchar,
+    unnest(@levels::text[]), unnest(@lines::text[]), unnest(@created_ats::timestamptz[]);
+
+-- name: ListDeploymentLogs :many
+SELECT * FROM deployment_logs
+WHERE deployment_id = @deployment_id AND id > @after_id
+ORDER BY id
+LIMIT @page_limit;
+
+-- name: ListDeploymentLogsSince :many
+SELECT * FROM deployment_logs
+WHERE deployment_id = @deployment_id AND stream = @stream AND created_at > @since
+ORDER BY created_at, id
+LIMIT @page_limit;
+
+-- name: GetLatestDeploymentLogTime :one
+SELECT MAX(created_at)::timestamptz AS latest FROM deployment_logs
+WHERE deployment_id = @deployment_id AND stream = @stream;
+
+-- name: SearchDeploymentLogs :many
+SELECT l.* FROM deployment_logs l
+JOIN deployments d ON d.id = l.deployment_id
+WHERE d.project_id = @project_id
+  AND (sqlc.narg('deployment_id')::varchar IS NULL OR l.deployment_id = sqlc.narg('deployment_id')::varchar)
+  AND (cardinality(@levels::text[]) = 0 OR l.level = ANY(@levels::text[]))
+  AND (sqlc.narg('stream')::varchar IS NULL OR l.stream = sqlc.narg('stream')::varchar)
+  AND (sqlc.narg('pattern')::text IS NULL OR l.line ILIKE sqlc.narg('pattern')::text)
+  AND (sqlc.narg('since')::timestamptz IS NULL OR l.created_at >= sqlc.narg('since')::timestamptz)
+  AND (sqlc.narg('until')::timestamptz IS NULL OR l.created_at < sqlc.narg('until')::timestamptz)
+  AND (
+    sqlc.narg('cursor_created_at')::timestamptz IS NULL
+    OR (l.created_at, l.id) < (sqlc.narg('cursor_created_at')::timestamptz, sqlc.narg('cursor_id')::bigint)
+  )
+ORDER BY l.created_at DESC, l.id DESC
+LIMIT @page_limit;
+
+-- name: ListDeploymentLogPartitions :many
+SELECT c.relname::text AS name FROM pg_inherits i
+JOIN pg_class c ON c.oid = i.inhrelid
+WHERE i.inhparent = 'deployment_logs'::regclass
+ORDER BY name;
+
+-- name: DeleteDefaultPartitionLogsBefore :execrows
+DELETE FROM deployment_logs_default
+WHERE created_at < @before;
+
+-- name: SetDeploymentContainer :one
+UPDATE deployments
+SET container_id = $1,
+    updated_at = NOW()
+WHERE id = $2
+RETURNING *;
+
+-- name: ListDeploymentsWithContainers :many
+SELECT * FROM deployments
+WHERE project_id = $1 AND branch = $2 AND id <> $3 AND container_id IS NOT NULL
+ORDER BY created_at;
+
+-- name: CreateRollbackDeployment :one
+INSERT INTO deployments (id, project_id, commit_hash, image_ref, rollback_target_id, branch)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: CancelBranchDeployments :many
+UPDATE deployments
+SET status = 'cancelled',
+    updated_at = NOW()
+WHERE project_id = $1 AND branch = $2 AND status IN ('pending', 'in_progress')
+RETURNING *;
+
+-- name: IsDeploymentActive :one
+SELECT EXISTS (SELECT 1 FROM projects WHERE active_deployment_id = @id::varchar)
+    OR EXISTS (SELECT 1 FROM preview_environments WHERE active_deployment_id = @id::varchar) AS active;
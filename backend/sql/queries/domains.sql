@@ -0,0 +1,45 @@
+-- name: CreateDomain :one
+INSERT INTO domains (id, project_id, name, verification_token, next_check_at)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: GetDomain :one
+SELECT * FROM domains
+WHERE id = $1;
+
+-- name: GetDomainForProject :one
+SELECT * FROM domains
+WHERE id = $1 AND project_id = $2;
+
+-- name: ListDomains :many
+SELECT * FROM domains
+WHERE project_id = $1
+ORDER BY created_at, id;
+
+-- name: UpdateDomainVerification :one
+UPDATE domains
+SET status = $1,
+    verification_started_at = $2,
+    verified_at = $3,
+    last_checked_at = $4,
+    last_error = $5,
+    next_check_at = $6,
+    updated_at = NOW()
+WHERE id = $7
+RETURNING *;
+
+-- name: ClaimDueDomainChecks :many
+UPDATE domains
+SET next_check_at = sqlc.arg('lease_until')
+WHERE id IN (
+    SELECT id FROM domains
+    WHERE next_check_at <= sqlc.arg('now')
+    ORDER BY next_check_at
+    LIMIT sqlc.arg('batch_size')
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING id;
+
+-- name: DeleteDomainForProject :execrows
+DELETE FROM domains
+WHERE id = $1 AND project_id = $2;
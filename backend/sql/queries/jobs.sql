@@ -0,0 +1,95 @@
+-- name: EnqueueJob :one
+INSERT INTO jobs (id, queue, kind, payload, max_attempts, run_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: ClaimJobs :many
+UPDATE jobs
+SET status = 'running',
+    attempts = attempts + 1,
+    locked_by = @worker_id,
+    locked_until = @locked_until,
+    updated_at = NOW()
+WHERE id IN (
+    SELECT id FROM jobs
+    WHERE queue = @queue
+      AND (
+        (status = 'pending' AND run_at <= NOW())
+        OR (status = 'running' AND locked_until < NOW() AND attempts < max_attempts)
+      )
+    ORDER BY run_at
+    LIMIT @batch_size
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: DeadLetterExpiredJobs :execrows
+UPDATE jobs
+SET status = 'dead',
+    last_error = 'job lock expired on its final attempt',
+    locked_by = NULL,
+    locked_until = NULL,
+    updated_at = NOW()
+WHERE queue = $1
+  AND status = 'running'
+  AND locked_until < NOW()
+  AND attempts >= max_attempts;
+
+-- name: ExtendJobLock :execrows
+UPDATE jobs
+SET locked_until = @locked_until,
+    updated_at = NOW()
+WHERE id = @id AND locked_by = @worker_id AND status = 'running';
+
+-- name: CompleteJob :execrows
+UPDATE jobs
+SET status = 'completed',
+    locked_by = NULL,
+    locked_until = NULL,
+    last_error = NULL,
+    updated_at = NOW()
+WHERE id = @id AND locked_by = @worker_id;
+
+-- name: RetryJob :execrows
+UPDATE jobs
+SET status = 'pending',
+    run_at = @run_at,
+    last_error = @last_error,
+    locked_by = NULL,
+    locked_until = NULL,
+    updated_at = NOW()
+WHERE id = @id AND locked_by = @worker_id;
+
+-- name: ReleaseJob :execrows
+UPDATE jobs
+SET status = 'pending',
+    attempts = GREATEST(attempts - 1, 0),
+    run_at = @run_at,
+    last_error = @last_error,
+    locked_by = NULL,
+    locked_until = NULL,
+    updated_at = NOW()
+WHERE id = @id AND locked_by = @worker_id;
+
+-- name: MarkJobDead :execrows
+UPDATE jobs
+SET status = 'dead',
+    last_error = @last_error,
+    locked_by = NULL,
+    locked_until = NULL,
+    updated_at = NOW()
+WHERE id = @id AND locked_by = @worker_id;
+
+-- name: ListDeadJobs :many
+SELECT * FROM jobs
+WHERE queue = $1 AND status = 'dead'
+ORDER BY updated_at DESC
+LIMIT $2;
+
+-- name: RequeueDeadJob :execrows
+UPDATE jobs
+SET status = 'pending',
+    attempts = 0,
+    run_at = NOW(),
+    updated_at = NOW()
+WHERE id = $1 AND status = 'dead';
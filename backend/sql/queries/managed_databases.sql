@@ -0,0 +1,84 @@
+-- name: CreateManagedDatabase :one
+INSERT INTO managed_databases (id, project_id, name, type, version, size)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: GetManagedDatabase :one
+SELECT * FROM managed_databases
+WHERE id = $1;
+
+-- name: GetManagedDatabaseForProject :one
+SELECT * FROM managed_databases
+WHERE id = $1 AND project_id = $2;
+
+-- name: ListManagedDatabases :many
+SELECT * FROM managed_databases
+WHERE project_id = $1
+ORDER BY created_at, id;
+
+-- name: ListRunningManagedDatabases :many
+SELECT * FROM managed_databases
+WHERE project_id = $1 AND status = 'running'
+ORDER BY created_at, id;
+
+-- name: SetManagedDatabaseContainer :exec
+UPDATE managed_databases
+SET container_id = $1,
+    updated_at = NOW()
+WHERE id = $2;
+
+-- name: UpdateManagedDatabaseRunning :one
+UPDATE managed_databases
+SET status = 'running',
+    encrypted_connection_string = $1,
+    last_error = NULL,
+    updated_at = NOW()
+WHERE id = $2 AND status = 'provisioning'
+RETURNING *;
+
+-- name: UpdateManagedDatabaseFailed :one
+UPDATE managed_databases
+SET status = 'failed',
+    last_error = $1,
+    updated_at = NOW()
+WHERE id = $2 AND status = 'provisioning'
+RETURNING *;
+
+-- name: MarkManagedDatabaseDeleting :one
+UPDATE managed_databases
+SET status = 'deleting',
+    updated_at = NOW()
+WHERE id = $1 AND project_id = $2
+RETURNING *;
+
+-- name: DeleteManagedDatabase :exec
+DELETE FROM managed_databases
+WHERE id = $1;
+
+-- name: UpdateManagedDatabaseBackupSchedule :one
+UPDATE managed_databases
+SET backup_schedule = $1,
+    backup_retention = $2,
+    next_backup_at = $3,
+    updated_at = NOW()
+WHERE id = $4 AND project_id = $5
+RETURNING *;
+
+-- name: ClaimDueDatabaseBackups :many
+UPDATE managed_databases
+SET next_backup_at = sqlc.arg('lease_until')
+WHERE id IN (
+    SELECT id FROM managed_databases
+    WHERE backup_schedule IS NOT NULL
+      AND status = 'running'
+      AND next_backup_at <= sqlc.arg('now')
+    ORDER BY next_backup_at
+    LIMIT sqlc.arg('batch_size')
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: SetManagedDatabaseNextBackup :exec
+UPDATE managed_databases
+SET next_backup_at = $1
+WHERE id = $2 AND backup_schedule = $3;
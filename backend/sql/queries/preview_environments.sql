@@ -0,0 +1,37 @@
+-- name: UpsertPreviewEnvironment :one
+INSERT INTO preview_environments (id, project_id, branch, subdomain)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (project_id, branch) DO UPDATE
+SET updated_at = NOW()
+RETURNING *;
+
+-- name: GetPreviewEnvironmentForProject :one
+SELECT * FROM preview_environments
+WHERE id = $1 AND project_id = $2;
+
+-- name: GetPreviewEnvironmentByBranchForUpdate :one
+SELECT * FROM preview_environments
+WHERE project_id = $1 AND branch = $2
+FOR UPDATE;
+
+-- name: ListPreviewEnvironments :many
+SELECT * FROM preview_environments
+WHERE project_id = $1
+ORDER BY created_at, id;
+
+-- name: SetPreviewEnvironmentActiveDeployment :exec
+UPDATE preview_environments
+SET active_deployment_id = $1,
+    updated_at = NOW()
+WHERE id = $2;
+
+-- name: SetPreviewEnvironmentPullRequest :one
+UPDATE preview_environments
+SET pull_request = $1,
+    updated_at = NOW()
+WHERE project_id = $2 AND branch = $3
+RETURNING *;
+
+-- name: DeletePreviewEnvironment :exec
+DELETE FROM preview_environments
+WHERE id = $1;
@@ -0,0 +1,23 @@
+-- name: ListProjectEnvVars :many
+SELECT * FROM project_env_vars
+WHERE project_id = $1 AND branch = $2
+ORDER BY key;
+
+-- name: ResolveProjectEnvVars :many
+SELECT DISTINCT ON (key) * FROM project_env_vars
+WHERE project_id = $1 AND branch IN ('', $2)
+ORDER BY key, branch DESC;
+
+-- name: UpsertProjectEnvVar :one
+INSERT INTO project_env_vars (project_id, branch, key, value, encrypted_value, secret)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (project_id, branch, key) DO UPDATE
+SET value = EXCLUDED.value,
+    encrypted_value = EXCLUDED.encrypted_value,
+    secret = EXCLUDED.secret,
+    updated_at = NOW()
+RETURNING *;
+
+-- name: DeleteProjectEnvVar :execrows
+DELETE FROM project_env_vars
+WHERE project_id = $1 AND branch = $2 AND key = $3;
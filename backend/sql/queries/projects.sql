@@ -0,0 +1,59 @@
+-- name: CreateProject :one
+INSERT INTO projects (id, name, description, repository_url, user_id, branch, previews_enabled, encrypted_webhook_secret)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING *;
+
+-- name: GetProjectForUser :one
+SELECT * FROM projects
+WHERE id = $1 AND user_id = $2;
+
+-- name: ListProjectsForUser :many
+SELECT * FROM projects
+WHERE user_id = @user_id
+  AND (
+    sqlc.narg('cursor_created_at')::timestamptz IS NULL
+    OR (created_at, id) < (sqlc.narg('cursor_created_at')::timestamptz, sqlc.narg('cursor_id')::varchar)
+  )
+ORDER BY created_at DESC, id DESC
+LIMIT @page_limit;
+
+-- name: UpdateProject :one
+UPDATE projects
+SET name = COALESCE(sqlc.narg('name'), name),
+    description = COALESCE(sqlc.narg('description'), description),
+    repository_url = COALESCE(sqlc.narg('repository_url'), repository_url),
+    branch = COALESCE(sqlc.narg('branch'), branch),
+    previews_enabled = COALESCE(sqlc.narg('previews_enabled'), previews_enabled),
+    updated_at = NOW()
+WHERE id = @id AND user_id = @user_id
+RETURNING *;
+
+-- name: DeleteProject :execrows
+DELETE FROM projects
+WHERE id = $1 AND user_id = $2;
+
+-- name: GetProject :one
+SELECT * FROM projects
+WHERE id = $1;
+
+-- name: GetProjectForUpdate :one
+SELECT * FROM projects
+WHERE id = $1
+FOR UPDATE;
+
+-- name: SetProjectActiveDeployment :exec
+UPDATE projects
+SET active_deployment_id = $1
+WHERE id = $2;
+
+-- name: SetProjectWebhookSecret :one
+UPDATE projects
+SET encrypted_webhook_secret = $1,
+    updated_at = NOW()
+WHERE id = $2 AND user_id = $3
+RETURNING *;
+
+-- name: ListProjectsByRepository :many
+SELECT * FROM projects
+WHERE lower(repository_url) = ANY(@repository_urls::text[])
+ORDER BY created_at, id;
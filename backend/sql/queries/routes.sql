@@ -0,0 +1,18 @@
+-- name: ListRouteUpstreams :many
+WITH environments AS (
+    SELECT lower(d.name) AS host, p.id AS project_id, p.branch, p.active_deployment_id
+    FROM domains d
+    JOIN projects p ON p.id = d.project_id
+    WHERE d.status = 'verified' AND p.active_deployment_id IS NOT NULL
+    UNION ALL
+    SELECT pe.subdomain || '.' || @preview_domain::text, pe.project_id, pe.branch, pe.active_deployment_id
+    FROM preview_environments pe
+    WHERE pe.active_deployment_id IS NOT NULL AND @preview_domain::text <> ''
+)
+SELECT e.host::text AS host, dep.id AS deployment_id, dep.container_id::varchar AS container_id,
+    (dep.id = e.active_deployment_id)::boolean AS active
+FROM environments e
+JOIN deployments dep ON dep.project_id = e.project_id AND dep.branch = e.branch
+WHERE dep.container_id IS NOT NULL
+  AND (dep.id = e.active_deployment_id OR dep.status = 'success')
+ORDER BY host, active DESC, dep.created_at DESC;
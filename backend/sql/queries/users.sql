@@ -0,0 +1,16 @@
+-- name: CreateUser :one
+INSERT INTO users (id, username, email, password_hash, is_admin)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: GetUserByID :one
+SELECT * FROM users
+WHERE id = $1;
+
+-- name: GetUserByEmail :one
+SELECT * FROM users
+WHERE email = $1;
+
+-- name: GetUserByUsername :one
+SELECT * FROM users
+WHERE username = $1;